@@ -1,15 +1,22 @@
 package main
 
 import (
+	"crypto/rand"
 	"log"
+	"net"
 	"net/http"
 	"os"
+	"strconv"
 	"time"
 
-	_ "github.com/gitvam/platform-go-challenge/docs"
+	"github.com/gitvam/platform-go-challenge/docs"
+	"github.com/gitvam/platform-go-challenge/internal/apidocs"
+	"github.com/gitvam/platform-go-challenge/internal/grpcapi"
 	"github.com/gitvam/platform-go-challenge/internal/handlers"
 	"github.com/gitvam/platform-go-challenge/internal/middleware"
 	"github.com/gitvam/platform-go-challenge/internal/store"
+	"github.com/gitvam/platform-go-challenge/internal/utils"
+	"github.com/gitvam/platform-go-challenge/internal/webhooks"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/httprate"
 	httpSwagger "github.com/swaggo/http-swagger"
@@ -27,12 +34,36 @@ func main() {
 	}
 
 	h := handlers.NewHandler(s)
+	h.Heartbeat = durationFromEnv("SSE_HEARTBEAT", h.Heartbeat)
+	h.ReportKey = reportSigningKey()
+
+	if err := apidocs.RegisterAssetSchemas(docs.SwaggerInfo); err != nil {
+		log.Fatalf("failed to build swagger docs: %v", err)
+	}
+
+	idempotencyTTL := durationFromEnv("IDEMPOTENCY_TTL", 24*time.Hour)
+	idempotencyLease := durationFromEnv("IDEMPOTENCY_LEASE", time.Minute)
+	idempotent := middleware.Idempotency(func(tenantID string) middleware.IdempotencyStore {
+		return s.ForTenant(tenantID)
+	}, idempotencyTTL, idempotencyLease)
+	go purgeExpiredIdempotencyKeys(s, time.Hour)
+	go purgeTrash(s, durationFromEnv("TRASH_RETENTION", 30*24*time.Hour), time.Hour)
+	go webhooks.NewDispatcher(s).Run(durationFromEnv("WEBHOOK_POLL_INTERVAL", 5*time.Second))
+	go purgeFavoriteEvents(s, durationFromEnv("FAVORITE_EVENT_RETENTION", 7*24*time.Hour), time.Hour)
 
 	r := chi.NewRouter()
 
 	// Global middleware
-	r.Use(httprate.LimitByIP(10, 1*time.Minute))
+	r.Use(middleware.RequestID)
+	r.Use(httprate.Limit(10, 1*time.Minute,
+		httprate.WithKeyByIP(),
+		httprate.WithLimitHandler(func(w http.ResponseWriter, r *http.Request) {
+			utils.WriteProblem(w, http.StatusTooManyRequests, utils.CodeRateLimited, "rate limit exceeded, retry later")
+		}),
+	))
 	r.Use(middleware.Logging)
+	r.Use(middleware.MaxBodySize(int64(intFromEnv("MAX_BODY_BYTES", 1<<20))))
+	r.Use(middleware.Compress)
 
 	// No auth for Swagger docs
 	r.Get("/swagger/*", httpSwagger.WrapHandler)
@@ -42,15 +73,167 @@ func main() {
 		api.Use(middleware.JWTAuthMiddleware)
 
 		api.Route("/v1/users/{userID}/favorites", func(sr chi.Router) {
-			sr.Get("/", h.ListFavorites)
-			sr.Post("/", h.AddFavorite)
-			sr.Delete("/{assetID}", h.RemoveFavorite)
-			sr.Patch("/{assetID}", h.EditFavoriteDescription)
+			// These stream or read formats of their own instead of negotiating one
+			sr.Get("/events", h.FavoriteEvents)
+			sr.Get("/export", h.ExportFavorites)
+			// Import reads files in its own formats but answers in the negotiated one
+			sr.With(middleware.NegotiateResponse, idempotent).Post("/import", h.ImportFavorites)
+
+			sr.Group(func(sr chi.Router) {
+				sr.Use(middleware.Negotiate)
+				sr.Get("/", h.ListFavorites)
+				sr.With(idempotent).Post("/", h.AddFavorite)
+				sr.Get("/trash", h.ListTrash)
+				sr.Get("/history", h.FavoriteHistory)
+				sr.With(idempotent).Post("/{assetID}/restore", h.RestoreFavorite)
+				sr.With(idempotent).Delete("/{assetID}", h.RemoveFavorite)
+				sr.With(idempotent).Patch("/{assetID}", h.EditFavoriteDescription)
+				sr.With(idempotent).Post("/{assetID}/tags", h.AddFavoriteTags)
+				sr.With(idempotent).Delete("/{assetID}/tags/{tag}", h.RemoveFavoriteTag)
+				sr.With(idempotent).Put("/{assetID}/pin", h.PinFavorite)
+				sr.With(idempotent).Delete("/{assetID}/pin", h.UnpinFavorite)
+				sr.With(idempotent).Post("/{assetID}/move", h.MoveFavorite)
+				sr.Get("/{assetID}/shares", h.ListFavoriteShares)
+				sr.With(idempotent).Post("/{assetID}/shares", h.ShareFavorite)
+			})
+		})
+		api.With(idempotent).Post("/graphql", h.GraphQL)
+		// Not idempotent: a stored response would keep the erased user's ID
+		api.Route("/v1/admin/users/{userID}/data", func(sr chi.Router) {
+			sr.Use(middleware.RequireAdmin)
+			sr.Get("/", h.UserDataArchive)
+			sr.With(middleware.Negotiate).Delete("/", h.EraseUserData)
+		})
+
+		// Every other endpoint answers in the format negotiated from Accept and reads
+		// bodies in the format of their Content-Type
+		api.Group(func(api chi.Router) {
+			api.Use(middleware.Negotiate)
+
+			api.Get("/v1/users/{userID}/tags", h.ListTags)
+
+			api.Route("/v1/users/{userID}/collections", func(sr chi.Router) {
+				sr.Get("/", h.ListCollections)
+				sr.With(idempotent).Post("/", h.CreateCollection)
+				sr.Get("/{collectionID}", h.GetCollection)
+				sr.With(idempotent).Patch("/{collectionID}", h.UpdateCollection)
+				sr.With(idempotent).Delete("/{collectionID}", h.DeleteCollection)
+				sr.Get("/{collectionID}/favorites", h.ListCollectionFavorites)
+				sr.With(idempotent).Put("/{collectionID}/favorites/{assetID}", h.AddToCollection)
+				sr.With(idempotent).Delete("/{collectionID}/favorites/{assetID}", h.RemoveFromCollection)
+				sr.Get("/{collectionID}/shares", h.ListCollectionShares)
+				sr.With(idempotent).Post("/{collectionID}/shares", h.ShareCollection)
+			})
+
+			api.With(idempotent).Delete("/v1/users/{userID}/shares/{shareID}", h.RevokeShare)
+			api.Route("/v1/users/{userID}/shared-with-me", func(sr chi.Router) {
+				sr.Get("/", h.ListSharedWithMe)
+				sr.Get("/{shareID}", h.GetSharedItem)
+				sr.With(idempotent).Patch("/{shareID}", h.EditSharedItem)
+			})
+			api.Get("/v1/shared-links/{token}", h.OpenShareLink)
+
+			api.With(middleware.RequireAdmin).Get("/v1/admin/audit-events", h.ListAuditEvents)
+			api.Route("/v1/admin/webhooks", func(sr chi.Router) {
+				sr.Use(middleware.RequireAdmin)
+				sr.Get("/", h.ListWebhooks)
+				sr.With(idempotent).Post("/", h.CreateWebhook)
+				sr.With(idempotent).Delete("/{webhookID}", h.DeleteWebhook)
+				sr.Get("/{webhookID}/deliveries", h.ListWebhookDeliveries)
+				sr.With(idempotent).Post("/{webhookID}/deliveries/{deliveryID}/replay", h.ReplayWebhookDelivery)
+			})
 		})
 	})
 
+	grpcAddr := os.Getenv("GRPC_ADDR")
+	if grpcAddr == "" {
+		grpcAddr = ":9090"
+	}
+	lis, err := net.Listen("tcp", grpcAddr)
+	if err != nil {
+		log.Fatalf("could not listen for gRPC: %v", err)
+	}
+	go func() {
+		log.Printf("gRPC server running on %s ...", grpcAddr)
+		if err := grpcapi.NewServer(s).Serve(lis); err != nil {
+			log.Fatalf("could not start gRPC server: %v", err)
+		}
+	}()
+
 	log.Println("Server running on http://localhost:8080 ...")
 	if err := http.ListenAndServe(":8080", r); err != nil {
 		log.Fatalf("could not start server: %v", err)
 	}
 }
+
+// durationFromEnv parses a time.Duration such as "24h" from the environment, returning a default if unset or invalid
+func durationFromEnv(key string, defaultVal time.Duration) time.Duration {
+	if d, err := time.ParseDuration(os.Getenv(key)); err == nil && d > 0 {
+		return d
+	}
+	return defaultVal
+}
+
+// intFromEnv parses an integer from the environment, returning a default if unset or invalid
+func intFromEnv(key string, defaultVal int) int {
+	if v, err := strconv.Atoi(os.Getenv(key)); err == nil && v > 0 {
+		return v
+	}
+	return defaultVal
+}
+
+// reportSigningKey reads the key erasure reports are signed with, falling back to a random
+// per-process key whose signatures cannot be checked after a restart
+func reportSigningKey() []byte {
+	if key := os.Getenv("REPORT_SIGNING_KEY"); key != "" {
+		return []byte(key)
+	}
+	log.Println("[WARN] REPORT_SIGNING_KEY is not set, erasure reports are signed with a random key")
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		log.Fatalf("failed to generate report signing key: %v", err)
+	}
+	return key
+}
+
+// purgeExpiredIdempotencyKeys periodically deletes idempotency records past their TTL
+func purgeExpiredIdempotencyKeys(s store.Store, every time.Duration) {
+	for range time.Tick(every) {
+		n, err := s.PurgeExpiredIdempotencyKeys()
+		if err != nil {
+			log.Printf("[ERROR] purging idempotency keys: %v", err)
+			continue
+		}
+		if n > 0 {
+			log.Printf("purged %d expired idempotency keys", n)
+		}
+	}
+}
+
+// purgeTrash periodically deletes favorites that have been in the trash longer than retention
+func purgeTrash(s store.Store, retention, every time.Duration) {
+	for range time.Tick(every) {
+		n, err := s.PurgeTrash(retention)
+		if err != nil {
+			log.Printf("[ERROR] purging trashed favorites: %v", err)
+			continue
+		}
+		if n > 0 {
+			log.Printf("purged %d trashed favorites", n)
+		}
+	}
+}
+
+// purgeFavoriteEvents periodically trims the favorite event log that streams resume from
+func purgeFavoriteEvents(s store.Store, retention, every time.Duration) {
+	for range time.Tick(every) {
+		n, err := s.PurgeFavoriteEvents(retention)
+		if err != nil {
+			log.Printf("[ERROR] purging favorite events: %v", err)
+			continue
+		}
+		if n > 0 {
+			log.Printf("purged %d favorite events", n)
+		}
+	}
+}
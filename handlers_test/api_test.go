@@ -1,33 +1,53 @@
 package main
 
 import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/gitvam/platform-go-challenge/internal/handlers"
 	"github.com/gitvam/platform-go-challenge/internal/middleware"
+	"github.com/gitvam/platform-go-challenge/internal/models"
 	"github.com/gitvam/platform-go-challenge/internal/store"
+	"github.com/gitvam/platform-go-challenge/internal/utils"
+	"github.com/gitvam/platform-go-challenge/internal/webhooks"
 	"github.com/go-chi/chi/v5"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/vmihailenco/msgpack/v5"
 )
 
 func getSignedToken(userID string) string {
+	return signToken(jwt.MapClaims{"sub": userID})
+}
+
+// getTenantToken signs a token for a user of the org tenant
+func getTenantToken(userID, org string) string {
+	return signToken(jwt.MapClaims{"sub": userID, "org": org})
+}
+
+func signToken(claims jwt.MapClaims) string {
 	secret := os.Getenv("JWT_SECRET")
 	if secret == "" {
 		secret = "my_super_secret"
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"sub": userID,
-	})
-	signedToken, _ := token.SignedString([]byte(secret))
+	signedToken, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
 	return signedToken
 }
 
-func setupTestRouter() http.Handler {
+func testStore() *store.PostgresStore {
 	host := os.Getenv("DB_HOST")
 	if host == "" {
 		host = "localhost"
@@ -38,22 +58,73 @@ func setupTestRouter() http.Handler {
 	if err != nil {
 		panic(fmt.Errorf("failed to connect to db: %w", err))
 	}
+	return s
+}
+
+// testReportKey signs erasure reports in tests
+var testReportKey = []byte("test-report-key")
+
+func setupTestRouter() http.Handler {
+	s := testStore()
 
 	// Ensure test chart exists
 	_, _ = s.DB().Exec(`
-		INSERT INTO charts (external_id, title, x_axis_title, y_axis_title, data, description)
-		VALUES ('chart_engagement_2024', 'Engagement Q1', 'Month', 'Engagement', ARRAY[10,20,30], 'A seeded chart')
-		ON CONFLICT (external_id) DO NOTHING
+		INSERT INTO charts (external_id, title, x_axis_title, y_axis_title, x_axis, series, description)
+		VALUES ('chart_engagement_2024', 'Engagement Q1', 'Month', 'Engagement',
+			'{"kind": "category", "categories": ["Jan", "Feb", "Mar"]}',
+			'[{"name": "Engagement", "unit": "k", "values": [10, 20, 30]}]', 'A seeded chart')
+		ON CONFLICT DO NOTHING
 	`)
 
 	h := handlers.NewHandler(s)
+	h.ReportKey = testReportKey
 
 	r := chi.NewRouter()
+	r.Use(middleware.RequestID)
+	r.Use(middleware.MaxBodySize(1 << 10))
+	r.Use(middleware.Compress)
 	r.Use(middleware.JWTAuthMiddleware)
-	r.Get("/v1/users/{userID}/favorites", h.ListFavorites)
-	r.Post("/v1/users/{userID}/favorites", h.AddFavorite)
-	r.Delete("/v1/users/{userID}/favorites/{assetID}", h.RemoveFavorite)
-	r.Patch("/v1/users/{userID}/favorites/{assetID}", h.EditFavoriteDescription)
+	r.With(middleware.Negotiate).Get("/v1/users/{userID}/favorites", h.ListFavorites)
+	idempotent := middleware.Idempotency(func(tenantID string) middleware.IdempotencyStore {
+		return s.ForTenant(tenantID)
+	}, time.Hour, time.Minute)
+	r.With(middleware.Negotiate, idempotent).Post("/v1/users/{userID}/favorites", h.AddFavorite)
+	r.With(idempotent).Delete("/v1/users/{userID}/favorites/{assetID}", h.RemoveFavorite)
+	r.Get("/v1/users/{userID}/favorites/trash", h.ListTrash)
+	r.Get("/v1/users/{userID}/favorites/history", h.FavoriteHistory)
+	r.Get("/v1/users/{userID}/favorites/events", h.FavoriteEvents)
+	r.Get("/v1/users/{userID}/favorites/export", h.ExportFavorites)
+	r.Post("/v1/users/{userID}/favorites/import", h.ImportFavorites)
+	r.Post("/v1/users/{userID}/favorites/{assetID}/restore", h.RestoreFavorite)
+	r.With(idempotent).Patch("/v1/users/{userID}/favorites/{assetID}", h.EditFavoriteDescription)
+	r.Post("/v1/users/{userID}/favorites/{assetID}/tags", h.AddFavoriteTags)
+	r.Delete("/v1/users/{userID}/favorites/{assetID}/tags/{tag}", h.RemoveFavoriteTag)
+	r.Get("/v1/users/{userID}/tags", h.ListTags)
+	r.Put("/v1/users/{userID}/favorites/{assetID}/pin", h.PinFavorite)
+	r.Delete("/v1/users/{userID}/favorites/{assetID}/pin", h.UnpinFavorite)
+	r.Post("/v1/users/{userID}/favorites/{assetID}/move", h.MoveFavorite)
+	r.Get("/v1/users/{userID}/collections", h.ListCollections)
+	r.Post("/v1/users/{userID}/collections", h.CreateCollection)
+	r.Patch("/v1/users/{userID}/collections/{collectionID}", h.UpdateCollection)
+	r.Delete("/v1/users/{userID}/collections/{collectionID}", h.DeleteCollection)
+	r.Get("/v1/users/{userID}/collections/{collectionID}/favorites", h.ListCollectionFavorites)
+	r.Put("/v1/users/{userID}/collections/{collectionID}/favorites/{assetID}", h.AddToCollection)
+	r.Post("/v1/users/{userID}/favorites/{assetID}/shares", h.ShareFavorite)
+	r.Post("/v1/users/{userID}/collections/{collectionID}/shares", h.ShareCollection)
+	r.Delete("/v1/users/{userID}/shares/{shareID}", h.RevokeShare)
+	r.Get("/v1/users/{userID}/shared-with-me", h.ListSharedWithMe)
+	r.Get("/v1/users/{userID}/shared-with-me/{shareID}", h.GetSharedItem)
+	r.Patch("/v1/users/{userID}/shared-with-me/{shareID}", h.EditSharedItem)
+	r.Get("/v1/shared-links/{token}", h.OpenShareLink)
+	r.Post("/graphql", h.GraphQL)
+	r.With(middleware.RequireAdmin).Get("/v1/admin/audit-events", h.ListAuditEvents)
+	r.With(middleware.RequireAdmin).Get("/v1/admin/webhooks", h.ListWebhooks)
+	r.With(middleware.RequireAdmin).Post("/v1/admin/webhooks", h.CreateWebhook)
+	r.With(middleware.RequireAdmin).Delete("/v1/admin/webhooks/{webhookID}", h.DeleteWebhook)
+	r.With(middleware.RequireAdmin).Get("/v1/admin/webhooks/{webhookID}/deliveries", h.ListWebhookDeliveries)
+	r.With(middleware.RequireAdmin).Post("/v1/admin/webhooks/{webhookID}/deliveries/{deliveryID}/replay", h.ReplayWebhookDelivery)
+	r.With(middleware.RequireAdmin).Get("/v1/admin/users/{userID}/data", h.UserDataArchive)
+	r.With(middleware.RequireAdmin).Delete("/v1/admin/users/{userID}/data", h.EraseUserData)
 
 	return r
 }
@@ -92,4 +163,1063 @@ func TestAddAndListFavorite(t *testing.T) {
 	if respList.Code != http.StatusOK {
 		t.Errorf("expected status 200, got %d", respList.Code)
 	}
-}
\ No newline at end of file
+}
+
+func TestAddFavorite_IdempotencyKey(t *testing.T) {
+	router := setupTestRouter()
+	userID := "33333333-3333-3333-3333-333333333333"
+	token := getSignedToken(userID)
+	key := fmt.Sprintf("add-%d", time.Now().UnixNano())
+
+	addBody := `{"type": "chart", "external_id": "chart_engagement_2024", "title": "Engagement Chart", "description": "retry me"}`
+	send := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/v1/users/"+userID+"/favorites", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Idempotency-Key", key)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	first := send(addBody)
+	if first.Code != http.StatusCreated && first.Code != http.StatusConflict {
+		t.Fatalf("expected 201 or 409 on first attempt, got %d", first.Code)
+	}
+
+	retry := send(addBody)
+	if retry.Code != first.Code {
+		t.Errorf("expected replayed status %d, got %d", first.Code, retry.Code)
+	}
+	if retry.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("expected replayed response to be marked")
+	}
+	if retry.Body.String() != first.Body.String() {
+		t.Errorf("expected replayed body %q, got %q", first.Body.String(), retry.Body.String())
+	}
+
+	mismatch := send(`{"type": "chart", "external_id": "chart_engagement_2024", "title": "Engagement Chart", "description": "changed"}`)
+	if mismatch.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422 for reused key with different body, got %d", mismatch.Code)
+	}
+}
+
+func TestListFavorites_ETag(t *testing.T) {
+	router := setupTestRouter()
+	userID := "11111111-1111-1111-1111-111111111111"
+	token := getSignedToken(userID)
+
+	req := httptest.NewRequest("GET", "/v1/users/"+userID+"/favorites", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	etag := resp.Header().Get("ETag")
+	if resp.Code != http.StatusOK || etag == "" {
+		t.Fatalf("expected 200 with ETag, got %d %q", resp.Code, etag)
+	}
+
+	req = httptest.NewRequest("GET", "/v1/users/"+userID+"/favorites", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("If-None-Match", etag)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	if resp.Code != http.StatusNotModified {
+		t.Errorf("expected 304, got %d", resp.Code)
+	}
+	if resp.Body.Len() != 0 {
+		t.Errorf("expected empty body on 304, got %q", resp.Body.String())
+	}
+}
+
+func TestEditFavoriteDescription_IfMatch(t *testing.T) {
+	router := setupTestRouter()
+	userID := "44444444-4444-4444-4444-444444444444"
+	token := getSignedToken(userID)
+
+	add := httptest.NewRequest("POST", "/v1/users/"+userID+"/favorites", strings.NewReader(`{"type": "chart", "external_id": "chart_engagement_2024", "title": "Engagement Chart", "description": "v1"}`))
+	add.Header.Set("Authorization", "Bearer "+token)
+	router.ServeHTTP(httptest.NewRecorder(), add)
+
+	edit := func(ifMatch, desc string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("PATCH", "/v1/users/"+userID+"/favorites/chart_engagement_2024?type=chart", strings.NewReader(`{"description": "`+desc+`"}`))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("If-Match", ifMatch)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	first := edit("*", "tab one")
+	if first.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", first.Code, first.Body.String())
+	}
+	etag := first.Header().Get("ETag")
+
+	if resp := edit(etag, "tab one again"); resp.Code != http.StatusOK {
+		t.Fatalf("expected 200 with current ETag, got %d", resp.Code)
+	}
+	if resp := edit(etag, "tab two"); resp.Code != http.StatusPreconditionFailed {
+		t.Errorf("expected 412 with stale ETag, got %d", resp.Code)
+	}
+}
+
+func TestErrors_ProblemJSON(t *testing.T) {
+	router := setupTestRouter()
+	userID := "11111111-1111-1111-1111-111111111111"
+	token := getSignedToken(userID)
+
+	cases := []struct {
+		name, method, path, body string
+		status                   int
+		code                     string
+	}{
+		{"unknown asset", "POST", "/v1/users/" + userID + "/favorites", `{"type": "chart", "external_id": "no_such_chart", "title": "t"}`, http.StatusNotFound, utils.CodeNotFound},
+		{"invalid type", "DELETE", "/v1/users/" + userID + "/favorites/x?type=video", "", http.StatusBadRequest, utils.CodeInvalidAssetType},
+		{"validation", "POST", "/v1/users/" + userID + "/favorites", `{"type": "chart", "external_id": ""}`, http.StatusBadRequest, utils.CodeValidationFailed},
+		{"bad json", "POST", "/v1/users/" + userID + "/favorites", `{`, http.StatusBadRequest, utils.CodeInvalidJSON},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := httptest.NewRequest(c.method, c.path, strings.NewReader(c.body))
+			req.Header.Set("Authorization", "Bearer "+token)
+			req.Header.Set("X-Request-ID", "req-"+c.name)
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			if resp.Code != c.status {
+				t.Fatalf("expected %d, got %d: %s", c.status, resp.Code, resp.Body.String())
+			}
+			if ct := resp.Header().Get("Content-Type"); ct != "application/problem+json" {
+				t.Errorf("expected problem+json, got %q", ct)
+			}
+			var p utils.Problem
+			if err := json.NewDecoder(resp.Body).Decode(&p); err != nil {
+				t.Fatal(err)
+			}
+			if p.Code != c.code || p.Status != c.status || p.RequestID != "req-"+c.name {
+				t.Errorf("unexpected problem: %+v", p)
+			}
+			if strings.Contains(p.Detail, "sql:") {
+				t.Errorf("database error leaked: %q", p.Detail)
+			}
+			if c.code == utils.CodeValidationFailed && len(p.Errors) != 2 {
+				t.Errorf("expected field errors for external_id and title, got %+v", p.Errors)
+			}
+		})
+	}
+}
+
+func TestAddFavorite_StrictDecoding(t *testing.T) {
+	router := setupTestRouter()
+	userID := "11111111-1111-1111-1111-111111111111"
+	token := getSignedToken(userID)
+
+	cases := []struct {
+		name   string
+		body   string
+		status int
+		field  string
+	}{
+		{"unknown field", `{"type": "chart", "external_id": "chart_engagement_2024", "title": "t", "colour": "red"}`, http.StatusBadRequest, "colour"},
+		{"duplicate key", `{"type": "chart", "external_id": "chart_engagement_2024", "title": "t", "title": "u"}`, http.StatusBadRequest, "title"},
+		{"client id", `{"type": "chart", "id": 1, "external_id": "chart_engagement_2024", "title": "t"}`, http.StatusBadRequest, "id"},
+		{"too large", `{"type": "chart", "external_id": "chart_engagement_2024", "title": "` + strings.Repeat("t", 2<<10) + `"}`, http.StatusRequestEntityTooLarge, ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/v1/users/"+userID+"/favorites", strings.NewReader(c.body))
+			req.Header.Set("Authorization", "Bearer "+token)
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+			if resp.Code != c.status {
+				t.Fatalf("expected %d, got %d: %s", c.status, resp.Code, resp.Body.String())
+			}
+			var p utils.Problem
+			json.NewDecoder(resp.Body).Decode(&p)
+			if c.field != "" && (len(p.Errors) != 1 || p.Errors[0].Field != c.field) {
+				t.Errorf("expected error on field %q, got %+v", c.field, p.Errors)
+			}
+		})
+	}
+}
+
+func TestListFavorites_ExpandMembers(t *testing.T) {
+	router := setupTestRouter()
+	userID := "22222222-2222-2222-2222-222222222222"
+	token := getSignedToken(userID)
+
+	for _, c := range []struct {
+		query  string
+		status int
+	}{
+		{"?expand=members", http.StatusOK},
+		{"?expand=owner", http.StatusBadRequest},
+	} {
+		req := httptest.NewRequest("GET", "/v1/users/"+userID+"/favorites"+c.query, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		if resp.Code != c.status {
+			t.Errorf("%s: expected %d, got %d", c.query, c.status, resp.Code)
+		}
+	}
+}
+
+func TestCollections(t *testing.T) {
+	router := setupTestRouter()
+	userID := "55555555-5555-5555-5555-555555555555"
+	token := getSignedToken(userID)
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/v1/users/"+userID+path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	send("POST", "/favorites", `{"type": "chart", "external_id": "chart_engagement_2024", "title": "Engagement Chart"}`)
+
+	name := fmt.Sprintf("Q3 pitch %d", time.Now().UnixNano())
+	created := send("POST", "/collections", `{"name": "`+name+`", "description": "for the client"}`)
+	if created.Code != http.StatusCreated || created.Header().Get("Location") == "" {
+		t.Fatalf("expected 201 with Location, got %d: %s", created.Code, created.Body.String())
+	}
+	var body struct {
+		Data struct {
+			ID int `json:"id"`
+		} `json:"data"`
+	}
+	json.NewDecoder(created.Body).Decode(&body)
+	path := fmt.Sprintf("/collections/%d", body.Data.ID)
+
+	if resp := send("POST", "/collections", `{"name": "`+name+`"}`); resp.Code != http.StatusConflict {
+		t.Errorf("expected 409 for a duplicate name, got %d", resp.Code)
+	}
+	if resp := send("PATCH", path, `{}`); resp.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an empty update, got %d", resp.Code)
+	}
+	if resp := send("PUT", path+"/favorites/chart_engagement_2024?type=chart", ""); resp.Code != http.StatusNoContent {
+		t.Fatalf("expected 204 adding a favorite, got %d: %s", resp.Code, resp.Body.String())
+	}
+
+	list := send("GET", path+"/favorites?limit=5", "")
+	if list.Code != http.StatusOK || !strings.Contains(list.Body.String(), "chart_engagement_2024") {
+		t.Errorf("expected the chart in the collection, got %d: %s", list.Code, list.Body.String())
+	}
+	if resp := send("GET", "/collections/abc/favorites", ""); resp.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for a malformed collection ID, got %d", resp.Code)
+	}
+	if resp := send("DELETE", path, ""); resp.Code != http.StatusNoContent {
+		t.Errorf("expected 204 deleting the collection, got %d", resp.Code)
+	}
+}
+
+func TestTags(t *testing.T) {
+	router := setupTestRouter()
+	userID := "66666666-6666-6666-6666-666666666666"
+	token := getSignedToken(userID)
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/v1/users/"+userID+path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	send("DELETE", "/favorites/chart_engagement_2024?type=chart", "")
+	if resp := send("POST", "/favorites", `{"type": "chart", "external_id": "chart_engagement_2024", "title": "t", "tags": ["Client-X"]}`); resp.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", resp.Code, resp.Body.String())
+	}
+
+	resp := send("POST", "/favorites/chart_engagement_2024/tags?type=chart", `{"tags": ["urgent", " client-x "]}`)
+	if resp.Code != http.StatusOK || !strings.Contains(resp.Body.String(), `"tags":["client-x","urgent"]`) {
+		t.Fatalf("expected merged tags, got %d: %s", resp.Code, resp.Body.String())
+	}
+	if resp := send("POST", "/favorites/chart_engagement_2024/tags?type=chart", `{"tags": ["no spaces"]}`); resp.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an invalid tag, got %d", resp.Code)
+	}
+
+	if resp := send("GET", "/favorites?tag=urgent,missing&tag_match=all", ""); strings.Contains(resp.Body.String(), "chart_engagement_2024") {
+		t.Errorf("expected no match for all of urgent and missing: %s", resp.Body.String())
+	}
+	if resp := send("GET", "/favorites?tag=urgent,missing", ""); !strings.Contains(resp.Body.String(), "chart_engagement_2024") {
+		t.Errorf("expected a match for any of urgent and missing: %s", resp.Body.String())
+	}
+	if resp := send("GET", "/favorites?tag_match=some", ""); resp.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unknown tag_match, got %d", resp.Code)
+	}
+
+	if resp := send("GET", "/tags", ""); !strings.Contains(resp.Body.String(), `{"tag":"client-x","count":1}`) {
+		t.Errorf("expected tag counts, got %s", resp.Body.String())
+	}
+	if resp := send("DELETE", "/favorites/chart_engagement_2024/tags/URGENT?type=chart", ""); resp.Code != http.StatusOK {
+		t.Errorf("expected 200 removing a tag, got %d", resp.Code)
+	}
+	if resp := send("DELETE", "/favorites/chart_engagement_2024/tags/urgent?type=chart", ""); resp.Code != http.StatusNotFound {
+		t.Errorf("expected 404 removing a missing tag, got %d", resp.Code)
+	}
+}
+
+func TestPinAndMoveFavorites(t *testing.T) {
+	router := setupTestRouter()
+	userID := "77777777-7777-7777-7777-777777777777"
+	token := getSignedToken(userID)
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/v1/users/"+userID+path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	send("DELETE", "/favorites/chart_engagement_2024?type=chart", "")
+	send("DELETE", "/favorites/insight_active_users?type=insight", "")
+	send("POST", "/favorites", `{"type": "chart", "external_id": "chart_engagement_2024", "title": "t"}`)
+	send("POST", "/favorites", `{"type": "insight", "external_id": "insight_active_users", "text": "t"}`)
+
+	resp := send("PUT", "/favorites/insight_active_users/pin?type=insight", "")
+	if resp.Code != http.StatusOK || !strings.Contains(resp.Body.String(), `"pinned":true`) || resp.Header().Get("ETag") != `"2"` {
+		t.Fatalf("expected pinned at version 2, got %d %s: %s", resp.Code, resp.Header().Get("ETag"), resp.Body.String())
+	}
+	resp = send("GET", "/favorites?sort=manual", "")
+	if body := resp.Body.String(); strings.Index(body, "insight_active_users") > strings.Index(body, "chart_engagement_2024") {
+		t.Errorf("expected the pinned insight first: %s", body)
+	}
+
+	resp = send("POST", "/favorites/chart_engagement_2024/move?type=chart", `{"before": {"type": "insight", "external_id": "insight_active_users"}}`)
+	if resp.Code != http.StatusOK || !strings.Contains(resp.Body.String(), `"pinned":true`) {
+		t.Fatalf("expected the moved chart to be pinned, got %d: %s", resp.Code, resp.Body.String())
+	}
+	resp = send("GET", "/favorites?sort=manual", "")
+	if body := resp.Body.String(); strings.Index(body, "chart_engagement_2024") > strings.Index(body, "insight_active_users") {
+		t.Errorf("expected the chart first after the move: %s", body)
+	}
+
+	if resp := send("POST", "/favorites/chart_engagement_2024/move?type=chart", `{}`); resp.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 without before or after, got %d", resp.Code)
+	}
+	if resp := send("GET", "/favorites?sort=newest", ""); resp.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unknown sort, got %d", resp.Code)
+	}
+	for _, query := range []string{"?sort=manual&limit=-5&offset=10", "?sort=manual&limit=101", "?offset=-1"} {
+		if resp := send("GET", "/favorites"+query, ""); resp.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 for %s, got %d", query, resp.Code)
+		}
+	}
+	if resp := send("GET", "/favorites/trash?limit=-5&offset=10", ""); resp.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a negative limit on the trash, got %d", resp.Code)
+	}
+	if resp := send("DELETE", "/favorites/missing/pin?type=chart", ""); resp.Code != http.StatusNotFound {
+		t.Errorf("expected 404 unpinning a missing favorite, got %d", resp.Code)
+	}
+}
+
+func TestSharing(t *testing.T) {
+	router := setupTestRouter()
+	owner := "88888888-8888-8888-8888-888888888888"
+	viewer := "99999999-9999-9999-9999-999999999999"
+	send := func(userID, method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+getSignedToken(userID))
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+	var created struct {
+		Data models.Share `json:"data"`
+	}
+
+	send(owner, "DELETE", "/v1/users/"+owner+"/favorites/chart_engagement_2024?type=chart", "")
+	send(owner, "POST", "/v1/users/"+owner+"/favorites", `{"type": "chart", "external_id": "chart_engagement_2024", "title": "t"}`)
+
+	resp := send(owner, "POST", "/v1/users/"+owner+"/favorites/chart_engagement_2024/shares?type=chart",
+		`{"user_id": "`+viewer+`", "permission": "view"}`)
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", resp.Code, resp.Body.String())
+	}
+	json.Unmarshal(resp.Body.Bytes(), &created)
+	sharePath := fmt.Sprintf("/v1/users/%s/shared-with-me/%d", viewer, created.Data.ID)
+
+	if resp := send(viewer, "GET", "/v1/users/"+viewer+"/shared-with-me", ""); !strings.Contains(resp.Body.String(), "chart_engagement_2024") {
+		t.Errorf("expected the shared chart, got %s", resp.Body.String())
+	}
+	if resp := send(viewer, "PATCH", sharePath, `{"description": "edited"}`); resp.Code != http.StatusForbidden {
+		t.Errorf("expected 403 editing a view-only share, got %d", resp.Code)
+	}
+	if resp := send(owner, "GET", fmt.Sprintf("/v1/users/%s/shared-with-me/%d", owner, created.Data.ID), ""); resp.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for a share not granted to the user, got %d", resp.Code)
+	}
+	if resp := send(owner, "POST", "/v1/users/"+owner+"/favorites/chart_engagement_2024/shares?type=chart",
+		`{"link": true, "permission": "edit"}`); resp.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an editable link, got %d", resp.Code)
+	}
+
+	resp = send(owner, "POST", "/v1/users/"+owner+"/favorites/chart_engagement_2024/shares?type=chart", `{"link": true, "permission": "view"}`)
+	json.Unmarshal(resp.Body.Bytes(), &created)
+	if resp.Code != http.StatusCreated || created.Data.Token == "" {
+		t.Fatalf("expected a share link, got %d: %s", resp.Code, resp.Body.String())
+	}
+	if resp := send(viewer, "GET", "/v1/shared-links/"+created.Data.Token, ""); resp.Code != http.StatusOK {
+		t.Errorf("expected 200 opening the link, got %d", resp.Code)
+	}
+	if resp := send(owner, "DELETE", fmt.Sprintf("/v1/users/%s/shares/%d", owner, created.Data.ID), ""); resp.Code != http.StatusNoContent {
+		t.Errorf("expected 204 revoking the link, got %d", resp.Code)
+	}
+	if resp := send(viewer, "GET", "/v1/shared-links/"+created.Data.Token, ""); resp.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for a revoked link, got %d", resp.Code)
+	}
+}
+
+func TestTenantIsolation(t *testing.T) {
+	router := setupTestRouter()
+	userID := "77777777-7777-7777-7777-777777777777"
+	send := func(token, method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/v1/users/"+userID+path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+	acme, globex := getTenantToken(userID, "acme"), getTenantToken(userID, "globex")
+
+	send(acme, "DELETE", "/favorites/insight_acme_panel?type=insight", "")
+	if resp := send(acme, "POST", "/favorites", `{"type": "insight", "external_id": "insight_acme_panel", "text": "t"}`); resp.Code != http.StatusCreated {
+		t.Fatalf("expected 201 adding a tenant asset, got %d: %s", resp.Code, resp.Body.String())
+	}
+	if resp := send(globex, "GET", "/favorites", ""); strings.Contains(resp.Body.String(), "insight_acme_panel") {
+		t.Errorf("expected another tenant not to see the favorite: %s", resp.Body.String())
+	}
+	if resp := send(globex, "POST", "/favorites", `{"type": "insight", "external_id": "insight_acme_panel", "text": "t"}`); resp.Code != http.StatusNotFound {
+		t.Errorf("expected 404 adding another tenant's asset, got %d", resp.Code)
+	}
+	if resp := send(globex, "DELETE", "/favorites/insight_acme_panel?type=insight", ""); resp.Code != http.StatusNotFound {
+		t.Errorf("expected 404 removing another tenant's favorite, got %d", resp.Code)
+	}
+	if resp := send(getSignedToken(userID), "GET", "/favorites", ""); strings.Contains(resp.Body.String(), "insight_acme_panel") {
+		t.Errorf("expected tokens without org to use the default tenant: %s", resp.Body.String())
+	}
+	if resp := send(signToken(jwt.MapClaims{"sub": userID, "org": ""}), "GET", "/favorites", ""); resp.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 for an empty org claim, got %d", resp.Code)
+	}
+}
+
+func TestTrash(t *testing.T) {
+	router := setupTestRouter()
+	userID := "66666666-6666-6666-6666-666666666666"
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/v1/users/"+userID+path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+getSignedToken(userID))
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	send("DELETE", "/favorites/chart_engagement_2024?type=chart", "")
+	send("POST", "/favorites", `{"type": "chart", "external_id": "chart_engagement_2024", "title": "t", "description": "keep me"}`)
+	if resp := send("DELETE", "/favorites/chart_engagement_2024?type=chart", ""); resp.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", resp.Code)
+	}
+	if resp := send("GET", "/favorites", ""); strings.Contains(resp.Body.String(), "chart_engagement_2024") {
+		t.Errorf("expected the removed favorite to be left out: %s", resp.Body.String())
+	}
+	if resp := send("GET", "/favorites/trash", ""); resp.Code != http.StatusOK || !strings.Contains(resp.Body.String(), "deleted_at") {
+		t.Errorf("expected the favorite in the trash, got %d: %s", resp.Code, resp.Body.String())
+	}
+
+	resp := send("POST", "/favorites/chart_engagement_2024/restore?type=chart", "")
+	if resp.Code != http.StatusOK || resp.Header().Get("ETag") == "" {
+		t.Fatalf("expected 200 with an ETag, got %d: %s", resp.Code, resp.Body.String())
+	}
+	if resp := send("GET", "/favorites", ""); !strings.Contains(resp.Body.String(), "keep me") {
+		t.Errorf("expected the restored favorite with its description: %s", resp.Body.String())
+	}
+	if resp := send("POST", "/favorites/chart_engagement_2024/restore?type=chart", ""); resp.Code != http.StatusNotFound {
+		t.Errorf("expected 404 restoring a favorite that is not trashed, got %d", resp.Code)
+	}
+}
+
+func TestAuditTrail(t *testing.T) {
+	router := setupTestRouter()
+	userID := "77777777-7777-7777-7777-777777777777"
+	adminID := "88888888-8888-8888-8888-888888888888"
+	adminToken := signToken(jwt.MapClaims{"sub": adminID, "role": "admin"})
+	send := func(token, method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+	userToken := getSignedToken(userID)
+	favorites := "/v1/users/" + userID + "/favorites"
+
+	send(userToken, "DELETE", favorites+"/chart_engagement_2024?type=chart", "")
+	send(userToken, "POST", favorites, `{"type": "chart", "external_id": "chart_engagement_2024", "title": "t", "description": "mine"}`)
+	// An admin edits on the user's behalf and is recorded as the actor
+	if resp := send(adminToken, "PATCH", favorites+"/chart_engagement_2024?type=chart", `{"description": "fixed by support"}`); resp.Code != http.StatusOK {
+		t.Fatalf("expected the admin edit to succeed, got %d: %s", resp.Code, resp.Body.String())
+	}
+
+	resp := send(userToken, "GET", favorites+"/history?type=chart&asset_id=chart_engagement_2024&limit=1", "")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", resp.Code, resp.Body.String())
+	}
+	var history struct {
+		Data []models.AuditEvent `json:"data"`
+	}
+	json.Unmarshal(resp.Body.Bytes(), &history)
+	if len(history.Data) != 1 || history.Data[0].Action != models.ActionFavoriteDescribed || history.Data[0].ActorID != adminID {
+		t.Errorf("expected the admin's edit first in the history, got %+v", history.Data)
+	}
+	if resp := send(userToken, "GET", favorites+"/history?asset_id=chart_engagement_2024", ""); resp.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for asset_id without type, got %d", resp.Code)
+	}
+
+	if resp := send(userToken, "GET", "/v1/admin/audit-events", ""); resp.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for a non-admin, got %d", resp.Code)
+	}
+	if resp := send(adminToken, "GET", "/v1/admin/audit-events?since=yesterday", ""); resp.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a malformed since, got %d", resp.Code)
+	}
+	resp = send(adminToken, "GET", "/v1/admin/audit-events?actor_id="+adminID, "")
+	if resp.Code != http.StatusOK || !strings.Contains(resp.Body.String(), "fixed by support") {
+		t.Errorf("expected the admin's events, got %d: %s", resp.Code, resp.Body.String())
+	}
+}
+
+func TestWebhooks(t *testing.T) {
+	router := setupTestRouter()
+	userID := "99999999-0000-0000-0000-000000000001"
+	adminToken := signToken(jwt.MapClaims{"sub": "99999999-0000-0000-0000-000000000002", "org": "hooks", "role": "admin"})
+	userToken := getTenantToken(userID, "hooks")
+	send := func(token, method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	// Stand-in receiver recording what it is sent; it answers 500 while failing is set
+	var failing atomic.Bool
+	received := make(chan *http.Request, 10)
+	bodies := make(chan []byte, 10)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		received <- r
+		bodies <- body
+	}))
+	defer receiver.Close()
+
+	dispatcher := webhooks.NewDispatcher(testStore())
+	dispatcher.MaxAttempts = 1
+	// Other tests leave outbox events behind, so dispatch until ours arrives or we give up
+	dispatchUntil := func(done func() bool) bool {
+		for i := 0; i < 50; i++ {
+			if _, err := dispatcher.RunOnce(); err != nil {
+				t.Fatal(err)
+			}
+			if done() {
+				return true
+			}
+		}
+		return false
+	}
+
+	if resp := send(userToken, "POST", "/v1/admin/webhooks", `{"url": "https://example.com"}`); resp.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for a non-admin, got %d", resp.Code)
+	}
+	resp := send(adminToken, "POST", "/v1/admin/webhooks", `{"url": "`+receiver.URL+`", "events": ["favorite.added"]}`)
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", resp.Code, resp.Body.String())
+	}
+	var created struct {
+		Data models.Webhook `json:"data"`
+	}
+	json.Unmarshal(resp.Body.Bytes(), &created)
+	hook := created.Data
+	defer send(adminToken, "DELETE", fmt.Sprintf("/v1/admin/webhooks/%d", hook.ID), "")
+
+	favorites := "/v1/users/" + userID + "/favorites"
+	send(userToken, "DELETE", favorites+"/chart_engagement_2024?type=chart", "")
+	send(userToken, "POST", favorites, `{"type": "chart", "external_id": "chart_engagement_2024", "title": "t", "description": "hooked"}`)
+
+	var got *http.Request
+	var body []byte
+	if !dispatchUntil(func() bool {
+		select {
+		case got = <-received:
+			body = <-bodies
+			return true
+		default:
+			return false
+		}
+	}) {
+		t.Fatal("expected the receiver to get the favorite.added event")
+	}
+	timestamp, _ := strconv.ParseInt(got.Header.Get(webhooks.TimestampHeader), 10, 64)
+	if got.Header.Get(webhooks.SignatureHeader) != webhooks.Sign(hook.Secret, timestamp, body) {
+		t.Errorf("expected a signature made with the webhook's secret")
+	}
+	var event models.FavoriteEvent
+	json.Unmarshal(body, &event)
+	if event.Type != models.EventFavoriteAdded || event.UserID != userID || event.Data.Description != "hooked" {
+		t.Errorf("unexpected event %s", body)
+	}
+
+	// A failing receiver dead-letters the delivery, and replaying it after the fix delivers it
+	failing.Store(true)
+	send(userToken, "DELETE", favorites+"/chart_engagement_2024?type=chart", "")
+	send(userToken, "POST", favorites, `{"type": "chart", "external_id": "chart_engagement_2024", "title": "t"}`)
+	deliveries := fmt.Sprintf("/v1/admin/webhooks/%d/deliveries", hook.ID)
+	var dead struct {
+		Data []models.WebhookDelivery `json:"data"`
+	}
+	if !dispatchUntil(func() bool {
+		json.Unmarshal(send(adminToken, "GET", deliveries+"?status=dead", "").Body.Bytes(), &dead)
+		return len(dead.Data) == 1
+	}) {
+		t.Fatal("expected a dead-lettered delivery")
+	}
+	failing.Store(false)
+	resp = send(adminToken, "POST", fmt.Sprintf("%s/%d/replay", deliveries, dead.Data[0].ID), "")
+	if resp.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d: %s", resp.Code, resp.Body.String())
+	}
+	if !dispatchUntil(func() bool { return len(received) > 0 }) {
+		t.Error("expected the replayed delivery to arrive")
+	}
+}
+
+func TestFavoriteEventStream(t *testing.T) {
+	server := httptest.NewServer(setupTestRouter())
+	defer server.Close()
+	userID := "99999999-0000-0000-0000-000000000003"
+	token := getSignedToken(userID)
+	favorites := server.URL + "/v1/users/" + userID + "/favorites"
+	do := func(method, url, body string) {
+		req, _ := http.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	// stream opens the event stream and returns a function reading the next event's id and type
+	stream := func(ctx context.Context, lastEventID string) func() (string, string) {
+		req, _ := http.NewRequestWithContext(ctx, "GET", favorites+"/events", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		if lastEventID != "" {
+			req.Header.Set("Last-Event-ID", lastEventID)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+			t.Fatalf("expected an event stream, got %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+		}
+		lines := bufio.NewScanner(resp.Body)
+		return func() (id, event string) {
+			for lines.Scan() {
+				line := lines.Text()
+				switch {
+				case strings.HasPrefix(line, "id: "):
+					id = strings.TrimPrefix(line, "id: ")
+				case strings.HasPrefix(line, "event: "):
+					event = strings.TrimPrefix(line, "event: ")
+				case line == "" && event != "":
+					return id, event
+				}
+			}
+			t.Fatalf("stream ended: %v", lines.Err())
+			return "", ""
+		}
+	}
+
+	do("DELETE", favorites+"/chart_engagement_2024?type=chart", "")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	next := stream(ctx, "")
+	do("POST", favorites, `{"type": "chart", "external_id": "chart_engagement_2024", "title": "t"}`)
+	addedID, event := next()
+	if event != models.EventFavoriteAdded {
+		t.Fatalf("expected favorite.added, got %s", event)
+	}
+	do("PATCH", favorites+"/chart_engagement_2024?type=chart", `{"description": "streamed"}`)
+	if _, event := next(); event != models.EventFavoriteUpdated {
+		t.Fatalf("expected favorite.updated, got %s", event)
+	}
+
+	// Resuming after the first event replays the update
+	resumeCtx, cancelResume := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelResume()
+	if _, event := stream(resumeCtx, addedID)(); event != models.EventFavoriteUpdated {
+		t.Errorf("expected the resumed stream to replay favorite.updated, got %s", event)
+	}
+	// An event that has left the log asks the client to reload
+	if _, event := stream(resumeCtx, "1")(); event != "reset" {
+		t.Errorf("expected a reset for an expired Last-Event-ID, got %s", event)
+	}
+}
+
+func TestExportFavorites(t *testing.T) {
+	router := setupTestRouter()
+	userID := "99999999-0000-0000-0000-000000000004"
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/v1/users/"+userID+path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+getSignedToken(userID))
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	if resp := send("GET", "/favorites/export", ""); resp.Code != http.StatusOK || strings.TrimSpace(resp.Body.String()) != "[]" {
+		t.Fatalf("expected an empty JSON array, got %d: %s", resp.Code, resp.Body.String())
+	}
+	send("POST", "/favorites", `{"type": "chart", "external_id": "chart_engagement_2024", "title": "t", "description": "exported"}`)
+	send("POST", "/favorites", `{"type": "insight", "external_id": "insight_active_users", "text": "t", "tags": ["kpi"]}`)
+
+	resp := send("GET", "/favorites/export", "")
+	var assets []map[string]any
+	if err := json.Unmarshal(resp.Body.Bytes(), &assets); err != nil || len(assets) != 2 {
+		t.Fatalf("expected a JSON array of 2 favorites, got %s (%v)", resp.Body.String(), err)
+	}
+	if cd := resp.Header().Get("Content-Disposition"); cd != `attachment; filename="favorites.json"` {
+		t.Errorf("unexpected Content-Disposition %q", cd)
+	}
+
+	resp = send("GET", "/favorites/export?format=ndjson", "")
+	if lines := strings.Split(strings.TrimSpace(resp.Body.String()), "\n"); resp.Header().Get("Content-Type") != "application/x-ndjson" || len(lines) != 2 {
+		t.Errorf("expected 2 NDJSON lines, got %q", resp.Body.String())
+	}
+
+	resp = send("GET", "/favorites/export?format=csv", "")
+	records, err := csv.NewReader(resp.Body).ReadAll()
+	if err != nil || len(records) != 3 {
+		t.Fatalf("expected a header and 2 rows, got %v (%v)", records, err)
+	}
+	row := map[string]string{}
+	for i, c := range records[0] {
+		row[c] = records[2][i]
+	}
+	if row["type"] != "insight" || row["tags"] != "kpi" || row["insight.text"] == "" || row["chart.title"] != "" {
+		t.Errorf("unexpected insight row %v", row)
+	}
+
+	if resp := send("GET", "/favorites/export?format=xml", ""); resp.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unknown format, got %d", resp.Code)
+	}
+}
+
+func TestImportFavorites(t *testing.T) {
+	router := setupTestRouter()
+	userID := "99999999-0000-0000-0000-000000000005"
+	send := func(path, contentType, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/v1/users/"+userID+"/favorites/import"+path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+getSignedToken(userID))
+		req.Header.Set("Content-Type", contentType)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+	file := "type,external_id,description,tags\nchart,chart_engagement_2024,imported,kpi;q1\ninsight,no_such_insight,,\n"
+
+	resp := send("?dry_run=true", "text/csv", file)
+	var dry struct {
+		Data models.ImportReport `json:"data"`
+	}
+	json.Unmarshal(resp.Body.Bytes(), &dry)
+	if resp.Code != http.StatusOK || !dry.Data.DryRun || dry.Data.Added != 1 || dry.Data.UnknownAssets != 1 {
+		t.Fatalf("expected a dry run report, got %d: %s", resp.Code, resp.Body.String())
+	}
+
+	resp = send("", "text/csv", file)
+	if resp.Code != http.StatusBadRequest || !strings.Contains(resp.Body.String(), "rows[1].external_id") {
+		t.Fatalf("expected the unknown asset to reject the import, got %d: %s", resp.Code, resp.Body.String())
+	}
+
+	file = "type,external_id,description,tags\nchart,chart_engagement_2024,imported,kpi;q1\n"
+	if resp := send("", "text/csv", file); resp.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", resp.Code, resp.Body.String())
+	}
+	if resp := send("?on_conflict=fail", "text/csv", file); resp.Code != http.StatusConflict || !strings.Contains(resp.Body.String(), "rows[0]") {
+		t.Errorf("expected 409 for an existing favorite, got %d: %s", resp.Code, resp.Body.String())
+	}
+	ndjson := `{"type": "chart", "external_id": "chart_engagement_2024", "description": "overwritten"}`
+	if resp := send("?on_conflict=overwrite", "application/x-ndjson", ndjson); resp.Code != http.StatusOK || !strings.Contains(resp.Body.String(), `"overwritten":1`) {
+		t.Errorf("expected the favorite to be overwritten, got %d: %s", resp.Code, resp.Body.String())
+	}
+
+	// The file is read in its own format and the report written in the negotiated one
+	req := httptest.NewRequest("POST", "/v1/users/"+userID+"/favorites/import?dry_run=true", strings.NewReader(ndjson))
+	req.Header.Set("Authorization", "Bearer "+getSignedToken(userID))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	req.Header.Set("Accept", "application/msgpack")
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	var packed struct {
+		Data struct {
+			Duplicates int `msgpack:"duplicates"`
+		} `msgpack:"data"`
+	}
+	if err := msgpack.Unmarshal(resp.Body.Bytes(), &packed); err != nil || packed.Data.Duplicates != 1 {
+		t.Errorf("expected the report in MessagePack, got %d %s (%v)", resp.Code, resp.Header().Get("Content-Type"), err)
+	}
+	req.Header.Set("Accept", "text/html")
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	if resp.Code != http.StatusNotAcceptable {
+		t.Errorf("expected 406 for an unsupported Accept, got %d", resp.Code)
+	}
+
+	if resp := send("?on_conflict=merge", "application/json", "[]"); resp.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unknown conflict policy, got %d", resp.Code)
+	}
+	if resp := send("", "application/json", `[{"type": }]`); resp.Code != http.StatusBadRequest || !strings.Contains(resp.Body.String(), "invalid_json") {
+		t.Errorf("expected invalid_json for a malformed file, got %d: %s", resp.Code, resp.Body.String())
+	}
+}
+
+func TestUserDataArchiveAndErasure(t *testing.T) {
+	router := setupTestRouter()
+	userID := "99999999-0000-0000-0000-000000000006"
+	adminID := "88888888-8888-8888-8888-888888888888"
+	adminToken := signToken(jwt.MapClaims{"sub": adminID, "role": "admin"})
+	send := func(token, method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+	userToken := getSignedToken(userID)
+	data := "/v1/admin/users/" + userID + "/data"
+
+	send(userToken, "POST", "/v1/users/"+userID+"/favorites", `{"type": "chart", "external_id": "chart_engagement_2024", "title": "t", "description": "mine"}`)
+	if resp := send(userToken, "GET", data, ""); resp.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for a non-admin, got %d", resp.Code)
+	}
+
+	resp := send(adminToken, "GET", data, "")
+	if resp.Code != http.StatusOK || resp.Header().Get("Content-Type") != "application/zip" {
+		t.Fatalf("expected a zip archive, got %d: %s", resp.Code, resp.Body.String())
+	}
+	archive, err := zip.NewReader(bytes.NewReader(resp.Body.Bytes()), int64(resp.Body.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]*zip.File{}
+	for _, f := range archive.File {
+		files[f.Name] = f
+	}
+	if files["manifest.json"] == nil || files["favorites.json"] == nil || files["audit_events.json"] == nil {
+		t.Fatalf("expected a manifest and a file per table, got %v", files)
+	}
+	f, _ := files["favorites.json"].Open()
+	var favorites []map[string]any
+	json.NewDecoder(f).Decode(&favorites)
+	f.Close()
+	if len(favorites) != 1 || favorites[0]["description"] != "mine" {
+		t.Errorf("unexpected favorites in the archive %v", favorites)
+	}
+
+	resp = send(adminToken, "DELETE", data, "")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", resp.Code, resp.Body.String())
+	}
+	var erased struct {
+		Data models.ErasureReport `json:"data"`
+	}
+	json.Unmarshal(resp.Body.Bytes(), &erased)
+	if erased.Data.UserID != userID || erased.Data.ErasedBy != adminID || erased.Data.Deleted["favorites"] != 1 {
+		t.Errorf("unexpected report %+v", erased.Data)
+	}
+	if !erased.Data.Verify(testReportKey) {
+		t.Error("expected the report signature to verify")
+	}
+	erased.Data.Deleted["favorites"] = 0
+	if erased.Data.Verify(testReportKey) {
+		t.Error("expected a changed report to fail verification")
+	}
+
+	resp = send(userToken, "GET", "/v1/users/"+userID+"/favorites", "")
+	if strings.Contains(resp.Body.String(), "chart_engagement_2024") {
+		t.Errorf("expected no favorites after erasure, got %s", resp.Body.String())
+	}
+}
+
+func TestContentNegotiation(t *testing.T) {
+	router := setupTestRouter()
+	userID := "99999999-0000-0000-0000-000000000008"
+	token := getSignedToken(userID)
+	send := func(method, accept, contentType string, body []byte) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/v1/users/"+userID+"/favorites", bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	body, _ := msgpack.Marshal(map[string]any{"type": "chart", "external_id": "chart_engagement_2024", "title": "Engagement", "description": "packed"})
+	resp := send("POST", "application/msgpack", "application/msgpack", body)
+	if resp.Code != http.StatusCreated || resp.Header().Get("Content-Type") != "application/msgpack" {
+		t.Fatalf("expected 201 in MessagePack, got %d %s: %s", resp.Code, resp.Header().Get("Content-Type"), resp.Body.String())
+	}
+	var added struct {
+		Data struct {
+			Description string `msgpack:"description"`
+		} `msgpack:"data"`
+	}
+	if err := msgpack.Unmarshal(resp.Body.Bytes(), &added); err != nil || added.Data.Description != "packed" {
+		t.Errorf("expected the added favorite in MessagePack, got %+v (%v)", added, err)
+	}
+
+	resp = send("GET", "application/msgpack", "", nil)
+	var listed struct {
+		Data []map[string]any `msgpack:"data"`
+	}
+	if err := msgpack.Unmarshal(resp.Body.Bytes(), &listed); err != nil || len(listed.Data) != 1 || listed.Data[0]["external_id"] != "chart_engagement_2024" {
+		t.Errorf("expected the favorite listed in MessagePack, got %+v (%v)", listed, err)
+	}
+
+	resp = send("GET", "text/csv", "", nil)
+	records, err := csv.NewReader(resp.Body).ReadAll()
+	if err != nil || len(records) != 2 || strings.Join(records[0], ",") != strings.Join(models.CSVHeader(), ",") || records[1][1] != "chart_engagement_2024" {
+		t.Errorf("expected the favorites in the export CSV layout, got %v (%v)", records, err)
+	}
+
+	if resp := send("GET", "text/html", "", nil); resp.Code != http.StatusNotAcceptable {
+		t.Errorf("expected 406 for an unsupported Accept, got %d", resp.Code)
+	}
+	if resp := send("POST", "", "text/plain", []byte("chart")); resp.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("expected 415 for an unsupported Content-Type, got %d", resp.Code)
+	}
+	if resp := send("POST", "", "application/msgpack", []byte{0xc1}); resp.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for invalid MessagePack, got %d", resp.Code)
+	}
+}
+
+func TestSparseFieldsAndCompression(t *testing.T) {
+	router := setupTestRouter()
+	userID := "99999999-0000-0000-0000-000000000009"
+	token := getSignedToken(userID)
+	list := func(query, acceptEncoding string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/v1/users/"+userID+"/favorites"+query, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		if acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	addReq := httptest.NewRequest("POST", "/v1/users/"+userID+"/favorites", strings.NewReader(`{"type": "chart", "external_id": "chart_engagement_2024", "title": "Engagement"}`))
+	addReq.Header.Set("Authorization", "Bearer "+token)
+	addResp := httptest.NewRecorder()
+	router.ServeHTTP(addResp, addReq)
+	if addResp.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", addResp.Code, addResp.Body.String())
+	}
+
+	resp := list("?fields=title", "")
+	var sparse struct {
+		Data []map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &sparse); err != nil || len(sparse.Data) != 1 || len(sparse.Data[0]) != 3 || sparse.Data[0]["title"] != "Engagement Q1" {
+		t.Errorf("expected only title, type and external_id, got %s (%v)", resp.Body.String(), err)
+	}
+	if resp := list("?fields=title,colour", ""); resp.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unknown field, got %d", resp.Code)
+	}
+	if resp := list("?fields=data", ""); resp.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for the request-only chart data, got %d", resp.Code)
+	}
+
+	resp = list("", "gzip")
+	if resp.Header().Get("Content-Encoding") != "" {
+		t.Errorf("expected a small list to be sent uncompressed, got %v", resp.Header())
+	}
+	resp = list("?fields=series&limit=1", "zstd, gzip;q=0.5")
+	if resp.Code != http.StatusOK || !strings.Contains(resp.Header().Get("Vary"), "Accept-Encoding") {
+		t.Errorf("expected Vary: Accept-Encoding, got %d %v", resp.Code, resp.Header())
+	}
+}
+
+func TestGraphQL(t *testing.T) {
+	router := setupTestRouter()
+	userID := "99999999-0000-0000-0000-000000000007"
+	send := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/graphql", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+getSignedToken(userID))
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+	type result struct {
+		Data   map[string]json.RawMessage `json:"data"`
+		Errors []struct {
+			Message    string         `json:"message"`
+			Extensions map[string]any `json:"extensions"`
+		} `json:"errors"`
+	}
+	query := func(q string, variables map[string]any) result {
+		body, _ := json.Marshal(handlers.GraphQLRequest{Query: q, Variables: variables})
+		resp := send(string(body))
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", resp.Code, resp.Body.String())
+		}
+		var res result
+		json.Unmarshal(resp.Body.Bytes(), &res)
+		return res
+	}
+
+	res := query(`mutation($asset: JSON!) { addFavorite(asset: $asset) { type externalId version ... on Chart { title } } }`,
+		map[string]any{"asset": map[string]any{"type": "chart", "external_id": "chart_engagement_2024", "title": "t", "description": "via graphql"}})
+	if len(res.Errors) > 0 || !strings.Contains(string(res.Data["addFavorite"]), `"title":"Engagement Q1"`) {
+		t.Fatalf("expected the stored chart, got %+v", res)
+	}
+	res = query(`mutation { addFavorite(asset: {type: "insight", external_id: "insight_active_users", text: "t", tags: ["kpi"]}) { version } }`, nil)
+	if len(res.Errors) > 0 {
+		t.Fatalf("unexpected errors %+v", res.Errors)
+	}
+
+	res = query(`{ favorites(first: 1, filter: {tags: ["kpi"]}) { edges { node { externalId ... on Insight { text tags } } } pageInfo { hasNextPage } } }`, nil)
+	if got := string(res.Data["favorites"]); !strings.Contains(got, `"externalId":"insight_active_users"`) || !strings.Contains(got, `"hasNextPage":false`) {
+		t.Errorf("expected only the tagged insight, got %s %+v", got, res.Errors)
+	}
+
+	res = query(`mutation { editFavorite(type: CHART, externalId: "chart_engagement_2024", description: "edited", expectedVersion: 99) { version } }`, nil)
+	if len(res.Errors) != 1 || res.Errors[0].Extensions["code"] != utils.CodePreconditionFailed {
+		t.Errorf("expected a precondition_failed error, got %+v", res.Errors)
+	}
+	res = query(`mutation { editFavorite(type: CHART, externalId: "chart_engagement_2024", description: "edited") { description version } }`, nil)
+	if got := string(res.Data["editFavorite"]); !strings.Contains(got, `"description":"edited"`) {
+		t.Errorf("expected the edited description, got %s %+v", got, res.Errors)
+	}
+
+	res = query(`mutation { removeFavorite(type: CHART, externalId: "chart_engagement_2024") }`, nil)
+	if string(res.Data["removeFavorite"]) != "true" {
+		t.Errorf("expected removal to succeed, got %+v", res)
+	}
+	res = query(`{ favorite(type: CHART, externalId: "chart_engagement_2024") { version } }`, nil)
+	if string(res.Data["favorite"]) != "null" {
+		t.Errorf("expected no favorite after removal, got %s", res.Data["favorite"])
+	}
+
+	if resp := send(`{"query": 1}`); resp.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a malformed request, got %d", resp.Code)
+	}
+}
@@ -3,66 +3,315 @@ BEGIN;
 -- Schema
 CREATE TABLE charts (
     id SERIAL PRIMARY KEY,
-    external_id TEXT UNIQUE NOT NULL,
+    tenant_id TEXT, -- NULL for the global catalog shared by every tenant
+    external_id TEXT NOT NULL,
     title TEXT NOT NULL,
+    kind TEXT NOT NULL DEFAULT 'line' CHECK (kind IN ('line', 'bar', 'pie')),
     x_axis_title TEXT,
     y_axis_title TEXT,
-    data INTEGER[] NOT NULL,
+    x_axis JSONB NOT NULL DEFAULT '{"kind": "category"}',
+    series JSONB NOT NULL DEFAULT '[]',
     description TEXT
 );
 
 CREATE TABLE insights (
     id SERIAL PRIMARY KEY,
-    external_id TEXT UNIQUE NOT NULL,
+    tenant_id TEXT, -- NULL for the global catalog shared by every tenant
+    external_id TEXT NOT NULL,
     text TEXT NOT NULL,
     description TEXT
 );
 
 CREATE TABLE audiences (
     id SERIAL PRIMARY KEY,
-    external_id TEXT UNIQUE NOT NULL,
+    tenant_id TEXT, -- NULL for the global catalog shared by every tenant
+    external_id TEXT NOT NULL,
     gender TEXT,
     birth_country TEXT,
     age_groups TEXT[],
     hours_on_social INT,
     purchases_last_month INT,
+    -- Boolean filter expression; NULL for audiences defined by the flat columns
+    criteria JSONB,
     description TEXT
 );
 
+CREATE TABLE dashboards (
+    id SERIAL PRIMARY KEY,
+    tenant_id TEXT, -- NULL for the global catalog shared by every tenant
+    external_id TEXT NOT NULL,
+    title TEXT NOT NULL,
+    layout TEXT NOT NULL DEFAULT 'grid',
+    description TEXT
+);
+
+-- One row per registered asset type (see models.Register); the store adds missing rows on startup
+CREATE TABLE asset_types (
+    name TEXT PRIMARY KEY,
+    catalog_table TEXT NOT NULL
+);
+
+INSERT INTO asset_types (name, catalog_table) VALUES
+  ('chart', 'charts'),
+  ('insight', 'insights'),
+  ('audience', 'audiences'),
+  ('dashboard', 'dashboards');
+
+-- Ordered members of composite assets such as dashboards
+CREATE TABLE asset_members (
+    parent_type TEXT NOT NULL REFERENCES asset_types(name),
+    parent_id INT NOT NULL,
+    position INT NOT NULL,
+    member_type TEXT NOT NULL REFERENCES asset_types(name),
+    member_id INT NOT NULL,
+    PRIMARY KEY (parent_type, parent_id, position)
+);
+
 CREATE TABLE favorites (
     id SERIAL PRIMARY KEY,
+    tenant_id TEXT NOT NULL,
     user_id UUID NOT NULL,
     asset_id INT NOT NULL,
-    asset_type TEXT NOT NULL CHECK (asset_type IN ('chart', 'insight', 'audience')),
+    asset_type TEXT NOT NULL REFERENCES asset_types(name),
     description TEXT NOT NULL,
-    UNIQUE (user_id, asset_type, asset_id)
+    -- Free-form, lower-case tags chosen by the user, kept sorted
+    tags TEXT[] NOT NULL DEFAULT '{}',
+    -- Manual order: pinned favorites first, then by fractional position so a
+    -- move only rewrites the moved row
+    pinned BOOLEAN NOT NULL DEFAULT false,
+    position DOUBLE PRECISION NOT NULL,
+    version INT NOT NULL DEFAULT 1,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    -- Set when the favorite is removed; trashed rows are purged after the retention period
+    deleted_at TIMESTAMPTZ,
+    UNIQUE (tenant_id, user_id, asset_type, asset_id)
+);
+
+-- User-defined folders of favorites, ordered by position within each user
+CREATE TABLE collections (
+    id SERIAL PRIMARY KEY,
+    tenant_id TEXT NOT NULL,
+    user_id UUID NOT NULL,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    position INT NOT NULL,
+    version INT NOT NULL DEFAULT 1,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- A favorite can be in several collections; removing either side removes the link
+CREATE TABLE collection_favorites (
+    collection_id INT NOT NULL REFERENCES collections(id) ON DELETE CASCADE,
+    favorite_id INT NOT NULL REFERENCES favorites(id) ON DELETE CASCADE,
+    added_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (collection_id, favorite_id)
+);
+
+-- Favorites and collections shared with another user (grantee_id) or through a
+-- link (token_hash, the SHA-256 of the token). Revoking a share deletes it.
+CREATE TABLE shares (
+    id SERIAL PRIMARY KEY,
+    tenant_id TEXT NOT NULL,
+    owner_id UUID NOT NULL,
+    favorite_id INT REFERENCES favorites(id) ON DELETE CASCADE,
+    collection_id INT REFERENCES collections(id) ON DELETE CASCADE,
+    grantee_id UUID,
+    token_hash TEXT UNIQUE,
+    permission TEXT NOT NULL CHECK (permission IN ('view', 'edit')),
+    expires_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK ((favorite_id IS NULL) <> (collection_id IS NULL)),
+    CHECK ((grantee_id IS NULL) <> (token_hash IS NULL))
+);
+
+-- Stored responses for requests sent with an Idempotency-Key header.
+-- A row with status_code 0 marks a request that is still being processed.
+CREATE TABLE idempotency_keys (
+    tenant_id TEXT NOT NULL,
+    user_id UUID NOT NULL,
+    key TEXT NOT NULL,
+    request_hash TEXT NOT NULL,
+    status_code INT NOT NULL DEFAULT 0,
+    content_type TEXT NOT NULL DEFAULT '',
+    response_headers JSONB NOT NULL DEFAULT '{}',
+    response_body BYTEA,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    -- An unfinished request holding the key past its lease is taken to have crashed
+    lease_expires_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    -- Identifies the request holding the key, so one whose lease was taken over cannot complete it
+    reservation UUID NOT NULL DEFAULT gen_random_uuid(),
+    PRIMARY KEY (tenant_id, user_id, key)
+);
+
+-- Append-only record of every change to favorites, collections and shares,
+-- written in the same transaction as the change. actor_id is NULL for
+-- maintenance such as purging the trash.
+CREATE TABLE audit_events (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id TEXT NOT NULL,
+    user_id UUID NOT NULL,
+    actor_id UUID,
+    action TEXT NOT NULL,
+    entity_type TEXT NOT NULL CHECK (entity_type IN ('favorite', 'collection', 'share', 'webhook')),
+    entity_id TEXT NOT NULL,
+    before JSONB,
+    after JSONB,
+    request_id TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Erasing a user pseudonymizes their events in its own transaction, after SET
+-- LOCAL audit.pseudonymize = 'on'; what happened, to what and when never changes.
+CREATE FUNCTION reject_audit_change() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' AND current_setting('audit.pseudonymize', true) = 'on'
+       AND (NEW.id, NEW.tenant_id, NEW.action, NEW.entity_type, NEW.entity_id, NEW.created_at)
+           IS NOT DISTINCT FROM (OLD.id, OLD.tenant_id, OLD.action, OLD.entity_type, OLD.entity_id, OLD.created_at) THEN
+        RETURN NEW;
+    END IF;
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_events_append_only
+    BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW EXECUTE FUNCTION reject_audit_change();
+
+-- Favorite change events written in the same transaction as the change. The
+-- webhook dispatcher fans each event out to the tenant's webhooks and sets
+-- dispatched_at. xact_id is the writing transaction, which orders the event
+-- log by commit rather than by ID.
+CREATE TABLE outbox_events (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id TEXT NOT NULL,
+    user_id UUID NOT NULL,
+    event_type TEXT NOT NULL,
+    data JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    dispatched_at TIMESTAMPTZ,
+    xact_id XID8 NOT NULL DEFAULT pg_current_xact_id()
+);
+
+-- URLs receiving a tenant's favorite change events; an empty events array
+-- subscribes to every event. secret signs the deliveries.
+CREATE TABLE webhooks (
+    id SERIAL PRIMARY KEY,
+    tenant_id TEXT NOT NULL,
+    url TEXT NOT NULL,
+    events TEXT[] NOT NULL DEFAULT '{}',
+    secret TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- One event's delivery to one webhook. Pending deliveries are retried at
+-- next_attempt_at until delivered or dead-lettered.
+CREATE TABLE webhook_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    webhook_id INT NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+    event_id BIGINT NOT NULL REFERENCES outbox_events(id) ON DELETE CASCADE,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'delivered', 'dead')),
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_error TEXT NOT NULL DEFAULT '',
+    delivered_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (webhook_id, event_id)
 );
 
 -- Indexes
 CREATE INDEX idx_favorites_user_id ON favorites(user_id);
+CREATE UNIQUE INDEX idx_charts_tenant_external_id ON charts((COALESCE(tenant_id, '')), external_id);
+CREATE UNIQUE INDEX idx_insights_tenant_external_id ON insights((COALESCE(tenant_id, '')), external_id);
+CREATE UNIQUE INDEX idx_audiences_tenant_external_id ON audiences((COALESCE(tenant_id, '')), external_id);
+CREATE UNIQUE INDEX idx_dashboards_tenant_external_id ON dashboards((COALESCE(tenant_id, '')), external_id);
 CREATE INDEX idx_charts_external_id ON charts(external_id);
 CREATE INDEX idx_insights_external_id ON insights(external_id);
 CREATE INDEX idx_audiences_external_id ON audiences(external_id);
+CREATE INDEX idx_dashboards_external_id ON dashboards(external_id);
+CREATE INDEX idx_favorites_tags ON favorites USING GIN (tags);
+CREATE INDEX idx_favorites_deleted_at ON favorites(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_favorites_user_manual_order ON favorites(tenant_id, user_id, pinned DESC, position);
+CREATE UNIQUE INDEX idx_collections_user_name ON collections(tenant_id, user_id, lower(name));
+CREATE INDEX idx_collections_user_position ON collections(tenant_id, user_id, position);
+CREATE INDEX idx_collection_favorites_favorite_id ON collection_favorites(favorite_id);
+CREATE UNIQUE INDEX idx_shares_item_grantee ON shares((COALESCE(favorite_id, 0)), (COALESCE(collection_id, 0)), grantee_id);
+CREATE INDEX idx_shares_grantee_id ON shares(tenant_id, grantee_id);
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
+CREATE INDEX idx_audit_events_user ON audit_events(tenant_id, user_id, created_at DESC);
+CREATE INDEX idx_audit_events_created_at ON audit_events(tenant_id, created_at DESC);
+CREATE INDEX idx_outbox_events_undispatched ON outbox_events(id) WHERE dispatched_at IS NULL;
+CREATE INDEX idx_outbox_events_user_log ON outbox_events(tenant_id, user_id, xact_id, id);
+CREATE INDEX idx_webhooks_tenant_id ON webhooks(tenant_id);
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';
 
 -- Dummy Data
-INSERT INTO charts (external_id, title, x_axis_title, y_axis_title, data, description) VALUES
-  ('chart_engagement_2024', 'Q1 2024 Social Media Engagement', 'Month', 'Engagement (k)', ARRAY[85,92,110,130], 'Tracks monthly engagement for all channels in Q1 2024.'),
-  ('chart_ecom_conversion', 'E-commerce Conversion Rates 2024', 'Week', 'Conversion Rate (%)', ARRAY[2,2,3,4,3,5,4], 'Weekly conversion rate trend for Q2 2024.');
+INSERT INTO charts (external_id, title, kind, x_axis_title, y_axis_title, x_axis, series, description) VALUES
+  ('chart_engagement_2024', 'Q1 2024 Social Media Engagement', 'bar', 'Month', 'Engagement (k)',
+   '{"kind": "category", "categories": ["Jan", "Feb", "Mar", "Apr"]}',
+   '[{"name": "Engagement", "unit": "k", "values": [85, 92, 110, 130]}]',
+   'Tracks monthly engagement for all channels in Q1 2024.'),
+  ('chart_ecom_conversion', 'E-commerce Conversion Rates 2024', 'line', 'Week', 'Conversion Rate (%)',
+   '{"kind": "category", "categories": ["W1", "W2", "W3", "W4", "W5", "W6", "W7"]}',
+   '[{"name": "Conversion rate", "unit": "%", "values": [2.1, 2.4, 3.0, 3.8, 3.2, 4.6, 4.1]}]',
+   'Weekly conversion rate trend for Q2 2024.');
 
 INSERT INTO insights (external_id, text, description) VALUES
   ('insight_active_users', '78% of millennials engage with branded content daily.', 'Based on 2024 survey data across EMEA.'),
   ('insight_genz_tiktok', 'Gen Z users are 3x more likely to purchase after seeing a TikTok ad.', 'Finding from global digital consumer study 2024.');
 
 INSERT INTO audiences (external_id, gender, birth_country, age_groups, hours_on_social, purchases_last_month, description) VALUES
-  ('aud_greece_men_24_35', 'male', 'Greece', ARRAY['24-35'], 4, 3, 'Digitally active Greek men aged 24-35 with high purchasing intent.'),
-  ('aud_uk_females_18_24', 'female', 'UK', ARRAY['18-24'], 6, 5, 'UK-based young women, highly active on Instagram and TikTok.');
-
-INSERT INTO favorites (user_id, asset_id, asset_type, description) VALUES
-  ('11111111-1111-1111-1111-111111111111', 1, 'chart', 'Tracks monthly engagement for all channels in Q1 2024.'),
-  ('11111111-1111-1111-1111-111111111111', 1, 'insight', 'Based on 2024 survey data across EMEA.'),
-  ('11111111-1111-1111-1111-111111111111', 1, 'audience', 'Digitally active Greek men aged 24-35 with high purchasing intent.'),
-  ('22222222-2222-2222-2222-222222222222', 2, 'chart', 'Weekly conversion rate trend for Q2 2024.'),
-  ('22222222-2222-2222-2222-222222222222', 2, 'insight', 'Finding from global digital consumer study 2024.'),
-  ('22222222-2222-2222-2222-222222222222', 2, 'audience', 'UK-based young women, highly active on Instagram and TikTok.');
+  ('aud_greece_men_24_35', 'male', 'GR', ARRAY['25-34'], 4, 3, 'Digitally active Greek men aged 24-35 with high purchasing intent.'),
+  ('aud_uk_females_18_24', 'female', 'GB', ARRAY['18-24'], 6, 5, 'UK-based young women, highly active on Instagram and TikTok.');
+
+INSERT INTO audiences (external_id, criteria, description) VALUES
+  ('aud_uk_ie_women_heavy_social', '{"and": [
+      {"attribute": "gender", "op": "eq", "value": "female"},
+      {"or": [
+        {"attribute": "birth_country", "op": "eq", "value": "GB"},
+        {"attribute": "birth_country", "op": "eq", "value": "IE"}
+      ]},
+      {"attribute": "age_group", "op": "in", "value": ["18-24", "25-34"]},
+      {"attribute": "hours_on_social", "op": "gte", "value": 4}
+    ]}', 'Women aged 18-34 born in the UK or Ireland spending at least 4 hours a day on social media.');
+
+-- Private to the acme tenant; every other tenant only sees the global catalog above
+INSERT INTO insights (tenant_id, external_id, text, description) VALUES
+  ('acme', 'insight_acme_panel', 'Acme panel members rate loyalty programs above discounts.', 'From the Acme customer panel, Q2 2024.');
+
+INSERT INTO dashboards (external_id, title, layout, description) VALUES
+  ('dash_social_overview', 'Social Media Overview 2024', 'grid', 'Engagement, active users and the UK Gen Z audience on one page.');
+
+INSERT INTO asset_members (parent_type, parent_id, position, member_type, member_id) VALUES
+  ('dashboard', 1, 0, 'chart', 1),
+  ('dashboard', 1, 1, 'insight', 1),
+  ('dashboard', 1, 2, 'audience', 2);
+
+INSERT INTO favorites (tenant_id, user_id, asset_id, asset_type, description, tags, pinned, position) VALUES
+  ('default', '11111111-1111-1111-1111-111111111111', 1, 'chart', 'Tracks monthly engagement for all channels in Q1 2024.', '{client-x,urgent}', false, 1),
+  ('default', '11111111-1111-1111-1111-111111111111', 1, 'insight', 'Based on 2024 survey data across EMEA.', '{client-x}', true, 2),
+  ('default', '11111111-1111-1111-1111-111111111111', 1, 'audience', 'Digitally active Greek men aged 24-35 with high purchasing intent.', '{}', false, 3),
+  ('default', '22222222-2222-2222-2222-222222222222', 2, 'chart', 'Weekly conversion rate trend for Q2 2024.', '{}', false, 1),
+  ('default', '22222222-2222-2222-2222-222222222222', 2, 'insight', 'Finding from global digital consumer study 2024.', '{}', false, 2),
+  ('default', '22222222-2222-2222-2222-222222222222', 2, 'audience', 'UK-based young women, highly active on Instagram and TikTok.', '{}', false, 3),
+  ('default', '22222222-2222-2222-2222-222222222222', 3, 'audience', 'Women aged 18-34 born in the UK or Ireland spending at least 4 hours a day on social media.', '{}', false, 4),
+  ('default', '22222222-2222-2222-2222-222222222222', 1, 'dashboard', 'Engagement, active users and the UK Gen Z audience on one page.', '{}', false, 5);
+
+INSERT INTO collections (tenant_id, user_id, name, description, position) VALUES
+  ('default', '11111111-1111-1111-1111-111111111111', 'Q3 pitch', 'Engagement numbers for the Q3 client pitch.', 0),
+  ('default', '11111111-1111-1111-1111-111111111111', 'Competitor research', '', 1);
+
+INSERT INTO collection_favorites (collection_id, favorite_id) VALUES
+  (1, 1),
+  (1, 2),
+  (2, 3);
+
+INSERT INTO favorites (tenant_id, user_id, asset_id, asset_type, description, tags, pinned, position) VALUES
+  ('acme', '33333333-3333-3333-3333-333333333333', 3, 'insight', 'From the Acme customer panel, Q2 2024.', '{}', false, 1);
+
+-- User 2222 can view the "Q3 pitch" collection of user 1111
+INSERT INTO shares (tenant_id, owner_id, collection_id, grantee_id, permission) VALUES
+  ('default', '11111111-1111-1111-1111-111111111111', 1, '22222222-2222-2222-2222-222222222222', 'view');
 
 COMMIT;
@@ -0,0 +1,166 @@
+// Package apidocs extends the swag-generated Swagger document with details
+// that annotations cannot express, such as the polymorphic asset schema.
+package apidocs
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gitvam/platform-go-challenge/internal/models"
+	"github.com/swaggo/swag"
+)
+
+// readOnlyFields are assigned by the server and documented as read-only
+var readOnlyFields = map[string]bool{"id": true, "version": true}
+
+// RegisterAssetSchemas adds a schema for every registered asset type to spec,
+// with models.Asset as a base discriminated on "type". New asset types show up
+// in Swagger as soon as they call models.Register.
+func RegisterAssetSchemas(spec *swag.Spec) error {
+	var doc map[string]any
+	if err := json.Unmarshal([]byte(spec.ReadDoc()), &doc); err != nil {
+		return fmt.Errorf("parsing swagger doc: %w", err)
+	}
+	defs, _ := doc["definitions"].(map[string]any)
+	if defs == nil {
+		defs = map[string]any{}
+		doc["definitions"] = defs
+	}
+
+	b := newSchemaBuilder(defs)
+	var typeNames []string
+	for _, t := range models.Types() {
+		typeNames = append(typeNames, string(t.Type))
+		defs[DefinitionName(t)] = map[string]any{
+			"allOf": []any{
+				map[string]any{"$ref": "#/definitions/models.Asset"},
+				b.schemaFor(t),
+			},
+		}
+	}
+	defs["models.Asset"] = map[string]any{
+		"type":          "object",
+		"discriminator": "type",
+		"required":      []string{"type"},
+		"properties": map[string]any{
+			"type": map[string]any{"type": "string", "enum": typeNames},
+		},
+	}
+	pointAssetParamsAtBase(doc)
+
+	out, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	spec.SwaggerTemplate = string(out)
+	return nil
+}
+
+// DefinitionName is the Swagger definition name for an asset type, e.g. "models.Chart"
+func DefinitionName(t models.TypeSpec) string {
+	return "models." + reflect.TypeOf(t.New()).Elem().Name()
+}
+
+// schemaBuilder derives schemas from Go types. Recursive structs, such as
+// audience criteria, are emitted once as definitions and referenced by $ref.
+type schemaBuilder struct {
+	defs     map[string]any
+	building map[reflect.Type]bool
+	refs     map[reflect.Type]bool
+}
+
+func newSchemaBuilder(defs map[string]any) *schemaBuilder {
+	return &schemaBuilder{defs: defs, building: map[reflect.Type]bool{}, refs: map[reflect.Type]bool{}}
+}
+
+// schemaFor derives an object schema from the asset struct's JSON tags
+func (b *schemaBuilder) schemaFor(t models.TypeSpec) map[string]any {
+	schema := b.structSchema(reflect.TypeOf(t.New()).Elem(), true)
+	if len(t.Required) > 0 {
+		schema["required"] = t.Required
+	}
+	return schema
+}
+
+// structSchema describes a struct's JSON fields; the top-level asset omits "type",
+// which the base models.Asset schema already declares
+func (b *schemaBuilder) structSchema(typ reflect.Type, isAsset bool) map[string]any {
+	props := map[string]any{}
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" || (isAsset && name == "type") || !f.IsExported() {
+			continue
+		}
+		prop := b.schemaForType(f.Type)
+		if readOnlyFields[name] {
+			prop["readOnly"] = true
+		}
+		props[name] = prop
+	}
+	return map[string]any{"type": "object", "properties": props}
+}
+
+func (b *schemaBuilder) schemaForType(t reflect.Type) map[string]any {
+	if t == reflect.TypeOf(time.Time{}) {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return b.schemaForType(t.Elem())
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": b.schemaForType(t.Elem())}
+	case reflect.Interface:
+		if t == reflect.TypeOf((*models.Asset)(nil)).Elem() {
+			return map[string]any{"$ref": "#/definitions/models.Asset"}
+		}
+		return map[string]any{} // any JSON value
+	case reflect.Struct:
+		ref := map[string]any{"$ref": "#/definitions/models." + t.Name()}
+		if b.building[t] {
+			b.refs[t] = true
+			return ref
+		}
+		b.building[t] = true
+		schema := b.structSchema(t, false)
+		delete(b.building, t)
+		if b.refs[t] {
+			b.defs["models."+t.Name()] = schema
+			return ref
+		}
+		return schema
+	default:
+		return map[string]any{"type": "object"}
+	}
+}
+
+// pointAssetParamsAtBase replaces the empty schema swag emits for models.Asset
+// body parameters with a reference to the discriminated base schema
+func pointAssetParamsAtBase(doc map[string]any) {
+	paths, _ := doc["paths"].(map[string]any)
+	for _, p := range paths {
+		ops, _ := p.(map[string]any)
+		for _, op := range ops {
+			params, _ := op.(map[string]any)["parameters"].([]any)
+			for _, param := range params {
+				pm, _ := param.(map[string]any)
+				schema, ok := pm["schema"].(map[string]any)
+				if pm["in"] == "body" && ok && len(schema) == 0 {
+					pm["schema"] = map[string]any{"$ref": "#/definitions/models.Asset"}
+				}
+			}
+		}
+	}
+}
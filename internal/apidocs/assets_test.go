@@ -0,0 +1,66 @@
+package apidocs
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/gitvam/platform-go-challenge/internal/models"
+	"github.com/swaggo/swag"
+)
+
+func TestRegisterAssetSchemas(t *testing.T) {
+	spec := &swag.Spec{
+		SwaggerTemplate: `{"swagger":"2.0","paths":{"/favorites":{"post":{"parameters":[{"in":"body","name":"asset","schema":{}}]}}},"definitions":{}}`,
+		LeftDelim:       "{{",
+		RightDelim:      "}}",
+	}
+	if err := RegisterAssetSchemas(spec); err != nil {
+		t.Fatal(err)
+	}
+
+	var doc struct {
+		Paths       map[string]map[string]struct{ Parameters []map[string]any }
+		Definitions map[string]map[string]any
+	}
+	if err := json.Unmarshal([]byte(spec.ReadDoc()), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	if doc.Definitions["models.Asset"]["discriminator"] != "type" {
+		t.Errorf("expected models.Asset to be discriminated on type, got %v", doc.Definitions["models.Asset"])
+	}
+	for _, typ := range models.Types() {
+		if _, ok := doc.Definitions[DefinitionName(typ)]; !ok {
+			t.Errorf("missing definition for %s", typ.Type)
+		}
+	}
+	param := doc.Paths["/favorites"]["post"].Parameters[0]
+	if ref := param["schema"].(map[string]any)["$ref"]; ref != "#/definitions/models.Asset" {
+		t.Errorf("expected asset body to reference models.Asset, got %v", ref)
+	}
+}
+
+func TestSchemaForType_Time(t *testing.T) {
+	s := newSchemaBuilder(map[string]any{}).schemaForType(reflect.TypeOf(models.XAxis{}))
+	ts := s["properties"].(map[string]any)["timestamps"].(map[string]any)["items"].(map[string]any)
+	if ts["type"] != "string" || ts["format"] != "date-time" {
+		t.Errorf("expected timestamps to be date-time strings, got %v", ts)
+	}
+}
+
+func TestSchemaForType_RecursiveStructIsReferenced(t *testing.T) {
+	defs := map[string]any{}
+	s := newSchemaBuilder(defs).schemaForType(reflect.TypeOf(models.Criteria{}))
+	if s["$ref"] != "#/definitions/models.Criteria" {
+		t.Fatalf("expected a reference, got %v", s)
+	}
+	def, ok := defs["models.Criteria"].(map[string]any)
+	if !ok {
+		t.Fatal("expected models.Criteria to be defined")
+	}
+	not := def["properties"].(map[string]any)["not"].(map[string]any)
+	if not["$ref"] != "#/definitions/models.Criteria" {
+		t.Errorf("expected not to reference models.Criteria, got %v", not)
+	}
+}
@@ -0,0 +1,174 @@
+package graph
+
+import (
+	"strings"
+
+	"github.com/gitvam/platform-go-challenge/internal/models"
+	"github.com/graph-gophers/graphql-go"
+)
+
+// typeEnum and assetType convert between asset types and AssetType enum values
+func typeEnum(t models.AssetType) string {
+	return strings.ToUpper(string(t))
+}
+
+func assetType(enum string) models.AssetType {
+	return models.AssetType(strings.ToLower(enum))
+}
+
+// assetResolver resolves the Asset interface and the fields every asset type shares
+type assetResolver struct {
+	asset models.Asset
+}
+
+func newAssetResolver(a models.Asset) *assetResolver {
+	if a == nil {
+		return nil
+	}
+	return &assetResolver{asset: a}
+}
+
+func (r *assetResolver) Type() string           { return typeEnum(r.asset.GetType()) }
+func (r *assetResolver) ExternalID() graphql.ID { return graphql.ID(r.asset.GetID()) }
+func (r *assetResolver) Description() string    { return r.asset.GetDescription() }
+func (r *assetResolver) Pinned() bool           { return r.asset.GetPinned() }
+func (r *assetResolver) Version() int32         { return int32(r.asset.GetVersion()) }
+
+func (r *assetResolver) Tags() []string {
+	if tags := r.asset.GetTags(); tags != nil {
+		return tags
+	}
+	return []string{}
+}
+
+func (r *assetResolver) ToChart() (*chartResolver, bool) {
+	c, ok := r.asset.(*models.Chart)
+	return &chartResolver{r, c}, ok
+}
+
+func (r *assetResolver) ToInsight() (*insightResolver, bool) {
+	i, ok := r.asset.(*models.Insight)
+	return &insightResolver{r, i}, ok
+}
+
+func (r *assetResolver) ToAudience() (*audienceResolver, bool) {
+	a, ok := r.asset.(*models.Audience)
+	return &audienceResolver{r, a}, ok
+}
+
+func (r *assetResolver) ToDashboard() (*dashboardResolver, bool) {
+	d, ok := r.asset.(*models.Dashboard)
+	return &dashboardResolver{r, d}, ok
+}
+
+type chartResolver struct {
+	*assetResolver
+	chart *models.Chart
+}
+
+func (r *chartResolver) Title() string      { return r.chart.Title }
+func (r *chartResolver) Kind() string       { return r.chart.Kind }
+func (r *chartResolver) XAxisTitle() string { return r.chart.XAxisTitle }
+func (r *chartResolver) YAxisTitle() string { return r.chart.YAxisTitle }
+
+func (r *chartResolver) XAxis() *xAxisResolver {
+	return &xAxisResolver{r.chart.XAxis}
+}
+
+func (r *chartResolver) Series() []*seriesResolver {
+	series := make([]*seriesResolver, len(r.chart.Series))
+	for i := range r.chart.Series {
+		series[i] = &seriesResolver{r.chart.Series[i]}
+	}
+	return series
+}
+
+type xAxisResolver struct {
+	axis models.XAxis
+}
+
+func (r *xAxisResolver) Kind() string { return r.axis.Kind }
+
+func (r *xAxisResolver) Categories() []string {
+	if r.axis.Categories != nil {
+		return r.axis.Categories
+	}
+	return []string{}
+}
+
+func (r *xAxisResolver) Timestamps() []graphql.Time {
+	timestamps := make([]graphql.Time, len(r.axis.Timestamps))
+	for i, t := range r.axis.Timestamps {
+		timestamps[i] = graphql.Time{Time: t}
+	}
+	return timestamps
+}
+
+type seriesResolver struct {
+	series models.Series
+}
+
+func (r *seriesResolver) Name() string { return r.series.Name }
+func (r *seriesResolver) Unit() string { return r.series.Unit }
+
+func (r *seriesResolver) Values() []float64 {
+	if r.series.Values != nil {
+		return r.series.Values
+	}
+	return []float64{}
+}
+
+type insightResolver struct {
+	*assetResolver
+	insight *models.Insight
+}
+
+func (r *insightResolver) Text() string { return r.insight.Text }
+
+type audienceResolver struct {
+	*assetResolver
+	audience *models.Audience
+}
+
+func (r *audienceResolver) Gender() string            { return r.audience.Gender }
+func (r *audienceResolver) BirthCountry() string      { return r.audience.BirthCountry }
+func (r *audienceResolver) HoursOnSocial() int32      { return int32(r.audience.HoursOnSocial) }
+func (r *audienceResolver) PurchasesLastMonth() int32 { return int32(r.audience.PurchasesLastMonth) }
+func (r *audienceResolver) Summary() string           { return r.audience.Summary() }
+
+func (r *audienceResolver) AgeGroups() []string {
+	if r.audience.AgeGroups != nil {
+		return r.audience.AgeGroups
+	}
+	return []string{}
+}
+
+func (r *audienceResolver) Criteria() JSON {
+	return JSON{Value: r.audience.EffectiveCriteria()}
+}
+
+type dashboardResolver struct {
+	*assetResolver
+	dashboard *models.Dashboard
+}
+
+func (r *dashboardResolver) Title() string  { return r.dashboard.Title }
+func (r *dashboardResolver) Layout() string { return r.dashboard.Layout }
+
+func (r *dashboardResolver) Members() []*memberResolver {
+	members := make([]*memberResolver, len(r.dashboard.Members))
+	for i := range r.dashboard.Members {
+		members[i] = &memberResolver{r.dashboard.Members[i]}
+	}
+	return members
+}
+
+// memberResolver resolves a dashboard member. Its asset is only set when the query that
+// loaded the dashboard selected it, so members are loaded in one batch by the store.
+type memberResolver struct {
+	ref models.AssetRef
+}
+
+func (r *memberResolver) Type() string           { return typeEnum(r.ref.Type) }
+func (r *memberResolver) ExternalID() graphql.ID { return graphql.ID(r.ref.ExternalID) }
+func (r *memberResolver) Asset() *assetResolver  { return newAssetResolver(r.ref.Asset) }
@@ -0,0 +1,146 @@
+// Package graph serves favorites over GraphQL. Assets are modeled as an interface with an
+// object type per asset type, and resolvers read and write through store.Store.
+package graph
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"log"
+
+	"github.com/gitvam/platform-go-challenge/internal/models"
+	"github.com/gitvam/platform-go-challenge/internal/store"
+	"github.com/gitvam/platform-go-challenge/internal/utils"
+	"github.com/graph-gophers/graphql-go"
+)
+
+//go:embed schema.graphql
+var schemaSDL string
+
+// Limits on queries, keeping a single request from doing unbounded work
+const (
+	MaxDepth       = 10
+	MaxQueryLength = 10000
+	MaxPageSize    = 100
+)
+
+// NewSchema parses the schema and binds it to the resolvers. Requests must carry a Caller.
+func NewSchema() (*graphql.Schema, error) {
+	return graphql.ParseSchema(schemaSDL, &Resolver{},
+		graphql.UseStringDescriptions(),
+		graphql.MaxDepth(MaxDepth),
+		graphql.MaxQueryLength(MaxQueryLength),
+	)
+}
+
+// Caller is who a request is made by, with the store limited to their tenant
+type Caller struct {
+	Store  store.Store
+	UserID string
+	Admin  bool
+}
+
+type callerKey struct{}
+
+// WithCaller returns a context carrying the caller of a request
+func WithCaller(ctx context.Context, c Caller) context.Context {
+	return context.WithValue(ctx, callerKey{}, c)
+}
+
+// callerFor returns the caller and the user whose favorites they asked for: the userId
+// argument for admins and the caller themselves otherwise
+func callerFor(ctx context.Context, userID *graphql.ID) (Caller, string, error) {
+	c, ok := ctx.Value(callerKey{}).(Caller)
+	if !ok {
+		return c, "", &Error{Code: utils.CodeUnauthorized, Message: "caller missing from context"}
+	}
+	if userID != nil && string(*userID) != c.UserID && c.Admin {
+		if !models.IsUUID(string(*userID)) {
+			return c, "", &Error{Code: utils.CodeBadRequest, Message: "userId must be a UUID"}
+		}
+		return c, string(*userID), nil
+	}
+	return c, c.UserID, nil
+}
+
+// Error is a resolver error. Its code and field errors are reported in the error's
+// extensions and match those of the REST API's problem responses.
+type Error struct {
+	Code    string
+	Message string
+	Fields  []utils.FieldError
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Extensions implements the interface the GraphQL runtime uses for error extensions
+func (e *Error) Extensions() map[string]any {
+	ext := map[string]any{"code": e.Code}
+	if len(e.Fields) > 0 {
+		ext["fields"] = e.Fields
+	}
+	return ext
+}
+
+// storeError maps store errors as the REST handlers do. Unexpected errors are logged
+// and reported without their details.
+func storeError(err error) error {
+	var verrs models.ValidationErrors
+	switch {
+	case errors.As(err, &verrs):
+		fields := make([]utils.FieldError, len(verrs))
+		for i, v := range verrs {
+			fields[i] = utils.FieldError{Field: v.Field, Reason: v.Reason}
+		}
+		return &Error{Code: utils.CodeValidationFailed, Message: "request failed validation", Fields: fields}
+	case errors.Is(err, store.ErrValidation):
+		return &Error{Code: utils.CodeValidationFailed, Message: err.Error()}
+	case errors.Is(err, store.ErrInvalidType):
+		return &Error{Code: utils.CodeInvalidAssetType, Message: err.Error()}
+	case errors.Is(err, store.ErrForbidden):
+		return &Error{Code: utils.CodeForbidden, Message: err.Error()}
+	case errors.Is(err, store.ErrNotFound):
+		return &Error{Code: utils.CodeNotFound, Message: err.Error()}
+	case errors.Is(err, store.ErrAlreadyExists):
+		return &Error{Code: utils.CodeAlreadyExists, Message: err.Error()}
+	case errors.Is(err, store.ErrVersionConflict):
+		return &Error{Code: utils.CodePreconditionFailed, Message: err.Error()}
+	default:
+		log.Printf("[ERROR] store: %v", err)
+		return &Error{Code: utils.CodeInternal, Message: "internal server error"}
+	}
+}
+
+// decodeError maps errors decoding an asset as the REST handlers do
+func decodeError(err error) error {
+	if errors.Is(err, models.ErrUnknownAssetType) {
+		return &Error{Code: utils.CodeInvalidAssetType, Message: err.Error()}
+	}
+	e := &Error{Code: utils.CodeInvalidJSON, Message: err.Error()}
+	var de *models.DecodeError
+	if errors.As(err, &de) && de.Field != "" {
+		e.Fields = []utils.FieldError{{Field: de.Field, Reason: de.Msg}}
+	}
+	return e
+}
+
+// JSON is the JSON scalar, holding any JSON value
+type JSON struct {
+	Value any
+}
+
+func (JSON) ImplementsGraphQLType(name string) bool {
+	return name == "JSON"
+}
+
+func (j *JSON) UnmarshalGraphQL(input any) error {
+	j.Value = input
+	return nil
+}
+
+func (j JSON) MarshalJSON() ([]byte, error) {
+	return json.Marshal(j.Value)
+}
@@ -0,0 +1,180 @@
+package graph
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/gitvam/platform-go-challenge/internal/models"
+	"github.com/gitvam/platform-go-challenge/internal/store"
+	"github.com/gitvam/platform-go-challenge/internal/store/storetest"
+)
+
+func execute(t *testing.T, s *storetest.Store, admin bool, query string, variables map[string]any) (map[string]any, []map[string]any) {
+	t.Helper()
+	schema, err := NewSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := WithCaller(context.Background(), Caller{Store: s, UserID: storetest.CallerID, Admin: admin})
+	resp := schema.Exec(ctx, query, "", variables)
+	var data map[string]any
+	json.Unmarshal(resp.Data, &data)
+	var errs []map[string]any
+	raw, _ := json.Marshal(resp.Errors)
+	json.Unmarshal(raw, &errs)
+	return data, errs
+}
+
+func TestSchema_CoversEveryAssetType(t *testing.T) {
+	data, errs := execute(t, storetest.New(), false, `{
+		asset: __type(name: "Asset") { possibleTypes { name } }
+		types: __type(name: "AssetType") { enumValues { name } }
+	}`, nil)
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	raw, _ := json.Marshal(data)
+	for _, spec := range models.Types() {
+		name := string(spec.Type)
+		object := strings.ToUpper(name[:1]) + name[1:]
+		if !strings.Contains(string(raw), `"name":"`+object+`"`) || !strings.Contains(string(raw), `"name":"`+typeEnum(spec.Type)+`"`) {
+			t.Errorf("%s has no %s object type or AssetType value: %s", name, object, raw)
+		}
+	}
+}
+
+func TestFavorites_PagesWithCursors(t *testing.T) {
+	s := storetest.New()
+	query := `query($after: String) {
+		favorites(first: 3, after: $after) {
+			edges { cursor node { type externalId ... on Chart { title } } }
+			pageInfo { hasNextPage endCursor }
+		}
+	}`
+	data, errs := execute(t, s, false, query, nil)
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	conn := data["favorites"].(map[string]any)
+	edges := conn["edges"].([]any)
+	page := conn["pageInfo"].(map[string]any)
+	if len(edges) != 3 || page["hasNextPage"] != true {
+		t.Fatalf("expected 3 favorites and a next page, got %v", conn)
+	}
+	chart := edges[0].(map[string]any)["node"].(map[string]any)
+	if chart["type"] != "CHART" || chart["title"] != "Reach" {
+		t.Errorf("unexpected chart %v", chart)
+	}
+	if opts := s.Lists[0]; opts.Limit != 4 || opts.After != nil || !opts.ManualOrder || opts.ExpandMembers {
+		t.Errorf("unexpected list options %+v", opts)
+	}
+	if fields := fmt.Sprint(s.Lists[0].Fields); fields != "[external_id type title]" {
+		t.Errorf("expected only the queried fields to be loaded, got %s", fields)
+	}
+
+	data, errs = execute(t, s, false, query, map[string]any{"after": page["endCursor"]})
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	conn = data["favorites"].(map[string]any)
+	if edges := conn["edges"].([]any); len(edges) != 2 || conn["pageInfo"].(map[string]any)["hasNextPage"] != false {
+		t.Errorf("expected the last 2 favorites, got %v", conn)
+	}
+	if after := s.Lists[1].After; after == nil || *after != (store.FavoriteKey{Position: 3, ID: 3}) {
+		t.Errorf("expected the second page after the third favorite, got %+v", after)
+	}
+
+	if _, errs := execute(t, s, false, `{ favorites(first: 2, after: "bogus") { pageInfo { hasNextPage } } }`, nil); len(errs) != 1 || errs[0]["extensions"].(map[string]any)["code"] != "validation_failed" {
+		t.Errorf("expected a validation error for a bad cursor, got %v", errs)
+	}
+	if _, errs := execute(t, s, false, `{ favorites(first: 1000) { pageInfo { hasNextPage } } }`, nil); len(errs) != 1 {
+		t.Errorf("expected an error for a page over the limit, got %v", errs)
+	}
+}
+
+func TestFavorites_FiltersAndUser(t *testing.T) {
+	s := storetest.New()
+	query := `query($user: ID) {
+		favorites(userId: $user, filter: {types: [INSIGHT, DASHBOARD], tags: [" KPI "], matchAllTags: true}) { edges { cursor } }
+	}`
+	if _, errs := execute(t, s, false, query, map[string]any{"user": storetest.OtherID}); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	opts := s.Lists[0]
+	if fmt.Sprint(opts.Types) != "[insight dashboard]" || fmt.Sprint(opts.Tags) != "[kpi]" || !opts.MatchAllTags || opts.Limit != 11 {
+		t.Errorf("unexpected list options %+v", opts)
+	}
+	if s.Users[0] != storetest.CallerID {
+		t.Errorf("expected userId to be ignored for non-admins, listed %s", s.Users[0])
+	}
+	if _, errs := execute(t, s, true, query, map[string]any{"user": storetest.OtherID}); len(errs) > 0 || s.Users[1] != storetest.OtherID {
+		t.Errorf("expected admins to list another user's favorites, listed %s (%v)", s.Users[1], errs)
+	}
+	if _, errs := execute(t, s, true, query, map[string]any{"user": "not-a-uuid"}); len(errs) != 1 {
+		t.Errorf("expected an error for a userId that is not a UUID, got %v", errs)
+	}
+}
+
+func TestFavorites_LoadsMembersInOneBatch(t *testing.T) {
+	s := storetest.New()
+	data, errs := execute(t, s, false, `{
+		favorites(filter: {types: [DASHBOARD]}) {
+			edges { node { ...members } }
+		}
+	}
+	fragment members on Dashboard { members { externalId asset { ... on Insight { text } } } }`, nil)
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	if len(s.Lists) != 1 || !s.Lists[0].ExpandMembers {
+		t.Fatalf("expected members to be loaded with the favorites in one call, got %+v", s.Lists)
+	}
+	raw, _ := json.Marshal(data)
+	for _, id := range []string{"i1", "i2", "i3"} {
+		if !strings.Contains(string(raw), `"text":"member `+id+`"`) {
+			t.Errorf("expected member %s to be loaded: %s", id, raw)
+		}
+	}
+}
+
+func TestMutations_ReportStoreErrors(t *testing.T) {
+	s := storetest.New()
+	s.Err = store.ErrVersionConflict
+	_, errs := execute(t, s, false, `mutation { editFavorite(type: CHART, externalId: "c1", description: "d", expectedVersion: 1) { version } }`, nil)
+	if len(errs) != 1 || errs[0]["extensions"].(map[string]any)["code"] != "precondition_failed" {
+		t.Errorf("expected a precondition_failed error, got %v", errs)
+	}
+
+	_, errs = execute(t, s, false, `mutation { addFavorite(asset: {type: "chart", external_id: "c9", bogus: 1}) { version } }`, nil)
+	if len(errs) != 1 || errs[0]["extensions"].(map[string]any)["code"] != "invalid_json" {
+		t.Errorf("expected an invalid_json error for an unknown field, got %v", errs)
+	}
+
+	s.Err = nil
+	data, errs := execute(t, s, false, `{ favorite(type: INSIGHT, externalId: "nope") { version } }`, nil)
+	if len(errs) > 0 || data["favorite"] != nil {
+		t.Errorf("expected null for a missing favorite, got %v %v", data, errs)
+	}
+}
+
+func TestCursor_RoundTrip(t *testing.T) {
+	for _, key := range []store.FavoriteKey{{ID: 42}, {Pinned: true, Position: 1.25e-7, ID: 7}, {Position: -3, ID: 1}} {
+		if got, err := decodeCursor(encodeCursor(key)); err != nil || got != key {
+			t.Errorf("expected %+v, got %+v (%v)", key, got, err)
+		}
+	}
+	for _, bad := range []string{"", "!!", encodeCursorOf("other:false:1:1"), encodeCursorOf("favorites:3"),
+		encodeCursorOf("favorites:maybe:1:1"), encodeCursorOf("favorites:false:NaN:1"), encodeCursorOf("favorites:false:1:-1")} {
+		if _, err := decodeCursor(bad); err == nil {
+			t.Errorf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func encodeCursorOf(s string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(s))
+}
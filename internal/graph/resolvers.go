@@ -0,0 +1,270 @@
+package graph
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gitvam/platform-go-challenge/internal/models"
+	"github.com/gitvam/platform-go-challenge/internal/store"
+	"github.com/gitvam/platform-go-challenge/internal/utils"
+	"github.com/graph-gophers/graphql-go"
+)
+
+// Resolver is the root of the query and mutation resolvers
+type Resolver struct{}
+
+// Dashboard members are loaded for every dashboard of a response in one batch by the
+// store, and only when the query selects their assets
+const (
+	membersPath     = "members.asset"
+	edgeMembersPath = "edges.node." + membersPath
+)
+
+type favoriteFilter struct {
+	Types        *[]string
+	Tags         *[]string
+	MatchAllTags bool
+}
+
+func (r *Resolver) Favorites(ctx context.Context, args struct {
+	UserID *graphql.ID
+	First  int32
+	After  *string
+	Filter *favoriteFilter
+}) (*connectionResolver, error) {
+	c, userID, err := callerFor(ctx, args.UserID)
+	if err != nil {
+		return nil, err
+	}
+	var v models.Validator
+	v.Range("first", int(args.First), 1, MaxPageSize)
+	opts := store.ListOptions{
+		// One more than asked for tells whether there is a next page
+		Limit:         int(args.First) + 1,
+		ManualOrder:   true,
+		ExpandMembers: graphql.HasSelectedField(ctx, edgeMembersPath),
+		Fields:        nodeFields(ctx),
+	}
+	if args.After != nil {
+		after, err := decodeCursor(*args.After)
+		if err != nil {
+			v.Add("after", "is not a cursor returned by this API")
+		}
+		opts.After = &after
+	}
+	if f := args.Filter; f != nil {
+		if f.Types != nil {
+			for _, t := range *f.Types {
+				opts.Types = append(opts.Types, assetType(t))
+			}
+		}
+		if f.Tags != nil {
+			v.Tags("filter.tags", *f.Tags)
+			opts.Tags = models.NormalizeTags(*f.Tags)
+		}
+		opts.MatchAllTags = f.MatchAllTags
+	}
+	if err := v.Err(); err != nil {
+		return nil, storeError(err)
+	}
+
+	favorites, err := c.Store.ListKeyedFavorites(userID, opts)
+	if err != nil {
+		return nil, storeError(err)
+	}
+	conn := &connectionResolver{hasNextPage: len(favorites) > int(args.First)}
+	for _, f := range favorites[:min(len(favorites), int(args.First))] {
+		conn.edges = append(conn.edges, &edgeResolver{cursor: encodeCursor(f.Key), node: newAssetResolver(f.Asset)})
+	}
+	return conn, nil
+}
+
+func (r *Resolver) Favorite(ctx context.Context, args struct {
+	UserID     *graphql.ID
+	Type       string
+	ExternalID graphql.ID
+}) (*assetResolver, error) {
+	c, userID, err := callerFor(ctx, args.UserID)
+	if err != nil {
+		return nil, err
+	}
+	asset, err := c.Store.GetFavorite(userID, string(assetType(args.Type)), string(args.ExternalID), graphql.HasSelectedField(ctx, membersPath))
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, storeError(err)
+	}
+	return newAssetResolver(asset), nil
+}
+
+func (r *Resolver) AddFavorite(ctx context.Context, args struct {
+	UserID *graphql.ID
+	Asset  JSON
+}) (*assetResolver, error) {
+	c, userID, err := callerFor(ctx, args.UserID)
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(args.Asset.Value)
+	if err != nil {
+		return nil, &Error{Code: utils.CodeInvalidJSON, Message: err.Error()}
+	}
+	asset, err := models.DecodeAsset(body)
+	if err != nil {
+		return nil, decodeError(err)
+	}
+	if err := c.Store.AddFavorite(userID, asset); err != nil {
+		return nil, storeError(err)
+	}
+	return favorite(ctx, c, userID, asset.GetType(), asset.GetID())
+}
+
+func (r *Resolver) RemoveFavorite(ctx context.Context, args struct {
+	UserID     *graphql.ID
+	Type       string
+	ExternalID graphql.ID
+}) (bool, error) {
+	c, userID, err := callerFor(ctx, args.UserID)
+	if err != nil {
+		return false, err
+	}
+	if err := c.Store.RemoveFavorite(userID, string(assetType(args.Type)), string(args.ExternalID)); err != nil {
+		return false, storeError(err)
+	}
+	return true, nil
+}
+
+func (r *Resolver) EditFavorite(ctx context.Context, args struct {
+	UserID          *graphql.ID
+	Type            string
+	ExternalID      graphql.ID
+	Description     string
+	ExpectedVersion *int32
+}) (*assetResolver, error) {
+	c, userID, err := callerFor(ctx, args.UserID)
+	if err != nil {
+		return nil, err
+	}
+	expected := 0
+	if args.ExpectedVersion != nil {
+		expected = int(*args.ExpectedVersion)
+	}
+	if _, err := c.Store.EditFavoriteDescription(userID, string(assetType(args.Type)), string(args.ExternalID), args.Description, expected); err != nil {
+		return nil, storeError(err)
+	}
+	return favorite(ctx, c, userID, assetType(args.Type), string(args.ExternalID))
+}
+
+// favorite reads back a favorite a mutation changed, so the response shows it as stored
+func favorite(ctx context.Context, c Caller, userID string, t models.AssetType, externalID string) (*assetResolver, error) {
+	asset, err := c.Store.GetFavorite(userID, string(t), externalID, graphql.HasSelectedField(ctx, membersPath))
+	if err != nil {
+		return nil, storeError(err)
+	}
+	return newAssetResolver(asset), nil
+}
+
+// nodeFields lists the JSON fields of the assets a favorites query selects, so the store
+// loads nothing else, such as chart series. It always includes the external ID, since
+// an empty list would load whole assets.
+func nodeFields(ctx context.Context) []string {
+	const prefix = "edges.node."
+	fields := []string{"external_id"}
+	for _, name := range graphql.SelectedFieldNames(ctx) {
+		name, ok := strings.CutPrefix(name, prefix)
+		if !ok || strings.Contains(name, ".") {
+			continue
+		}
+		if f := jsonField(name); !slices.Contains(fields, f) {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// jsonField turns a schema field name such as xAxisTitle into its JSON name, x_axis_title
+func jsonField(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		if unicode.IsUpper(r) {
+			b.WriteByte('_')
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Cursors are opaque to clients and hold the manual order key of the edge's favorite,
+// so pages stay in place when favorites before them are added or removed
+const cursorPrefix = "favorites:"
+
+func encodeCursor(key store.FavoriteKey) string {
+	cursor := cursorPrefix + strconv.FormatBool(key.Pinned) + ":" +
+		strconv.FormatFloat(key.Position, 'g', -1, 64) + ":" + strconv.Itoa(key.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(cursor))
+}
+
+func decodeCursor(cursor string) (store.FavoriteKey, error) {
+	var key store.FavoriteKey
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return key, err
+	}
+	parts := strings.Split(strings.TrimPrefix(string(b), cursorPrefix), ":")
+	if !strings.HasPrefix(string(b), cursorPrefix) || len(parts) != 3 {
+		return key, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	pinned, errPinned := strconv.ParseBool(parts[0])
+	position, errPosition := strconv.ParseFloat(parts[1], 64)
+	id, errID := strconv.Atoi(parts[2])
+	if errPinned != nil || errPosition != nil || math.IsNaN(position) || math.IsInf(position, 0) || errID != nil || id <= 0 {
+		return key, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	return store.FavoriteKey{Pinned: pinned, Position: position, ID: id}, nil
+}
+
+type connectionResolver struct {
+	edges       []*edgeResolver
+	hasNextPage bool
+}
+
+func (r *connectionResolver) Edges() []*edgeResolver {
+	if r.edges == nil {
+		return []*edgeResolver{}
+	}
+	return r.edges
+}
+
+func (r *connectionResolver) PageInfo() *pageInfoResolver {
+	p := &pageInfoResolver{hasNextPage: r.hasNextPage}
+	if len(r.edges) > 0 {
+		p.endCursor = &r.edges[len(r.edges)-1].cursor
+	}
+	return p
+}
+
+type edgeResolver struct {
+	cursor string
+	node   *assetResolver
+}
+
+func (r *edgeResolver) Cursor() string       { return r.cursor }
+func (r *edgeResolver) Node() *assetResolver { return r.node }
+
+type pageInfoResolver struct {
+	hasNextPage bool
+	endCursor   *string
+}
+
+func (r *pageInfoResolver) HasNextPage() bool  { return r.hasNextPage }
+func (r *pageInfoResolver) EndCursor() *string { return r.endCursor }
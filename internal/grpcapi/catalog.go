@@ -0,0 +1,53 @@
+package grpcapi
+
+import (
+	"context"
+
+	pb "github.com/gitvam/platform-go-challenge/internal/grpcapi/favoritesv1"
+	"github.com/gitvam/platform-go-challenge/internal/models"
+	"github.com/gitvam/platform-go-challenge/internal/store"
+)
+
+// catalogServer serves CatalogService, the assets of the caller's tenant that can be favorited
+type catalogServer struct {
+	pb.UnimplementedCatalogServiceServer
+	store store.Store
+}
+
+func (s *catalogServer) ListAssets(ctx context.Context, req *pb.ListAssetsRequest) (*pb.ListAssetsResponse, error) {
+	st, _, err := callerFor(ctx, s.store, "")
+	if err != nil {
+		return nil, err
+	}
+	var v models.Validator
+	validatePage(&v, req.GetLimit(), req.GetOffset())
+	if err := v.Err(); err != nil {
+		return nil, storeError(err)
+	}
+	opts := store.ListOptions{
+		Limit:         pageSize(req.GetLimit()),
+		Offset:        int(req.GetOffset()),
+		ExpandMembers: req.GetExpandMembers(),
+	}
+	assets, err := st.ListCatalog(string(assetType(req.GetType())), opts)
+	if err != nil {
+		return nil, storeError(err)
+	}
+	resp := &pb.ListAssetsResponse{}
+	for _, a := range assets {
+		resp.Assets = append(resp.Assets, toProto(a))
+	}
+	return resp, nil
+}
+
+func (s *catalogServer) GetAsset(ctx context.Context, req *pb.GetAssetRequest) (*pb.GetAssetResponse, error) {
+	st, _, err := callerFor(ctx, s.store, "")
+	if err != nil {
+		return nil, err
+	}
+	asset, err := st.GetCatalogAsset(string(assetType(req.GetType())), req.GetExternalId(), req.GetExpandMembers())
+	if err != nil {
+		return nil, storeError(err)
+	}
+	return &pb.GetAssetResponse{Asset: toProto(asset)}, nil
+}
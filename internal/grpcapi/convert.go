@@ -0,0 +1,180 @@
+package grpcapi
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	pb "github.com/gitvam/platform-go-challenge/internal/grpcapi/favoritesv1"
+	"github.com/gitvam/platform-go-challenge/internal/models"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// assetType and typeEnum convert between AssetType enum values and asset types;
+// ASSET_TYPE_CHART is "chart" and ASSET_TYPE_UNSPECIFIED is ""
+func assetType(t pb.AssetType) models.AssetType {
+	if t == pb.AssetType_ASSET_TYPE_UNSPECIFIED {
+		return ""
+	}
+	return models.AssetType(strings.ToLower(strings.TrimPrefix(t.String(), "ASSET_TYPE_")))
+}
+
+func typeEnum(t models.AssetType) pb.AssetType {
+	return pb.AssetType(pb.AssetType_value["ASSET_TYPE_"+strings.ToUpper(string(t))])
+}
+
+// toProto converts a favorite or catalog asset to its message, with members when loaded
+func toProto(a models.Asset) *pb.Asset {
+	if a == nil {
+		return nil
+	}
+	m := &pb.Asset{
+		ExternalId:  a.GetID(),
+		Description: a.GetDescription(),
+		Tags:        a.GetTags(),
+		Pinned:      a.GetPinned(),
+		Version:     int32(a.GetVersion()),
+	}
+	switch a := a.(type) {
+	case *models.Chart:
+		chart := &pb.Chart{
+			Title:      a.Title,
+			Kind:       a.Kind,
+			XAxisTitle: a.XAxisTitle,
+			YAxisTitle: a.YAxisTitle,
+			XAxis:      &pb.XAxis{Kind: a.XAxis.Kind, Categories: a.XAxis.Categories},
+		}
+		for _, t := range a.XAxis.Timestamps {
+			chart.XAxis.Timestamps = append(chart.XAxis.Timestamps, timestamppb.New(t))
+		}
+		for _, s := range a.Series {
+			chart.Series = append(chart.Series, &pb.Series{Name: s.Name, Unit: s.Unit, Values: s.Values})
+		}
+		m.Kind = &pb.Asset_Chart{Chart: chart}
+	case *models.Insight:
+		m.Kind = &pb.Asset_Insight{Insight: &pb.Insight{Text: a.Text}}
+	case *models.Audience:
+		m.Kind = &pb.Asset_Audience{Audience: &pb.Audience{
+			Gender:             a.Gender,
+			BirthCountry:       a.BirthCountry,
+			AgeGroups:          a.AgeGroups,
+			HoursOnSocial:      int32(a.HoursOnSocial),
+			PurchasesLastMonth: int32(a.PurchasesLastMonth),
+			Criteria:           criteriaToProto(a.EffectiveCriteria()),
+			Summary:            a.Summary(),
+		}}
+	case *models.Dashboard:
+		dashboard := &pb.Dashboard{Title: a.Title, Layout: a.Layout}
+		for _, ref := range a.Members {
+			dashboard.Members = append(dashboard.Members, &pb.AssetRef{
+				Type:       typeEnum(ref.Type),
+				ExternalId: ref.ExternalID,
+				Asset:      toProto(ref.Asset),
+			})
+		}
+		m.Kind = &pb.Asset_Dashboard{Dashboard: dashboard}
+	}
+	return m
+}
+
+func criteriaToProto(c *models.Criteria) *pb.Criteria {
+	if c == nil {
+		return nil
+	}
+	m := &pb.Criteria{Not: criteriaToProto(c.Not), Attribute: c.Attribute, Op: c.Op}
+	for i := range c.And {
+		m.And = append(m.And, criteriaToProto(&c.And[i]))
+	}
+	for i := range c.Or {
+		m.Or = append(m.Or, criteriaToProto(&c.Or[i]))
+	}
+	if c.Value != nil {
+		// Values come from JSON and the store, so they are always representable
+		m.Value, _ = structpb.NewValue(normalizeValue(c.Value))
+	}
+	return m
+}
+
+// normalizeValue turns the typed slices criteria values may hold into []any for structpb
+func normalizeValue(v any) any {
+	if s, ok := v.([]string); ok {
+		values := make([]any, len(s))
+		for i, e := range s {
+			values[i] = e
+		}
+		return values
+	}
+	return v
+}
+
+// fromProto converts an asset message to the asset it describes, normalized as
+// models.DecodeAsset normalizes JSON bodies. Members and the server-assigned version
+// and summary are ignored; validation is left to the store.
+func fromProto(m *pb.Asset) (models.Asset, error) {
+	var a models.Asset
+	switch k := m.GetKind().(type) {
+	case *pb.Asset_Chart:
+		c := &models.Chart{
+			ExternalID: m.GetExternalId(),
+			Type:       string(models.AssetTypeChart),
+			Title:      k.Chart.GetTitle(),
+			Kind:       k.Chart.GetKind(),
+			XAxisTitle: k.Chart.GetXAxisTitle(),
+			YAxisTitle: k.Chart.GetYAxisTitle(),
+			XAxis:      models.XAxis{Kind: k.Chart.GetXAxis().GetKind(), Categories: k.Chart.GetXAxis().GetCategories()},
+		}
+		for _, t := range k.Chart.GetXAxis().GetTimestamps() {
+			c.XAxis.Timestamps = append(c.XAxis.Timestamps, t.AsTime().In(time.UTC))
+		}
+		for _, s := range k.Chart.GetSeries() {
+			c.Series = append(c.Series, models.Series{Name: s.GetName(), Unit: s.GetUnit(), Values: s.GetValues()})
+		}
+		a = c
+	case *pb.Asset_Insight:
+		a = &models.Insight{ExternalID: m.GetExternalId(), Type: string(models.AssetTypeInsight), Text: k.Insight.GetText()}
+	case *pb.Asset_Audience:
+		a = &models.Audience{
+			ExternalID:         m.GetExternalId(),
+			Type:               string(models.AssetTypeAudience),
+			Gender:             k.Audience.GetGender(),
+			BirthCountry:       k.Audience.GetBirthCountry(),
+			AgeGroups:          k.Audience.GetAgeGroups(),
+			HoursOnSocial:      int(k.Audience.GetHoursOnSocial()),
+			PurchasesLastMonth: int(k.Audience.GetPurchasesLastMonth()),
+			Criteria:           criteriaFromProto(k.Audience.GetCriteria()),
+		}
+	case *pb.Asset_Dashboard:
+		d := &models.Dashboard{ExternalID: m.GetExternalId(), Type: string(models.AssetTypeDashboard), Title: k.Dashboard.GetTitle(), Layout: k.Dashboard.GetLayout(), Members: []models.AssetRef{}}
+		for _, ref := range k.Dashboard.GetMembers() {
+			d.Members = append(d.Members, models.AssetRef{Type: assetType(ref.GetType()), ExternalID: ref.GetExternalId()})
+		}
+		a = d
+	default:
+		return nil, errors.New("asset must set one of chart, insight, audience or dashboard")
+	}
+	a.SetDescription(m.GetDescription())
+	a.SetTags(m.GetTags())
+	a.SetPinned(m.GetPinned())
+	if n, ok := a.(models.Normalizer); ok {
+		n.Normalize()
+	}
+	return a, nil
+}
+
+func criteriaFromProto(m *pb.Criteria) *models.Criteria {
+	if m == nil {
+		return nil
+	}
+	c := &models.Criteria{Not: criteriaFromProto(m.GetNot()), Attribute: m.GetAttribute(), Op: m.GetOp()}
+	for _, and := range m.GetAnd() {
+		c.And = append(c.And, *criteriaFromProto(and))
+	}
+	for _, or := range m.GetOr() {
+		c.Or = append(c.Or, *criteriaFromProto(or))
+	}
+	if m.GetValue() != nil {
+		c.Value = m.GetValue().AsInterface()
+	}
+	return c
+}
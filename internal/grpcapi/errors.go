@@ -0,0 +1,49 @@
+package grpcapi
+
+import (
+	"errors"
+	"log"
+
+	"github.com/gitvam/platform-go-challenge/internal/models"
+	"github.com/gitvam/platform-go-challenge/internal/store"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// storeError maps store errors to statuses as the REST handlers map them to problem
+// responses. Validation errors list the offending fields in a BadRequest detail, and
+// unexpected errors are logged and reported without their details.
+func storeError(err error) error {
+	var verrs models.ValidationErrors
+	switch {
+	case errors.As(err, &verrs):
+		return invalidArgument("request failed validation", verrs)
+	case errors.Is(err, store.ErrValidation), errors.Is(err, store.ErrInvalidType):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, store.ErrForbidden):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, store.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, store.ErrAlreadyExists):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, store.ErrVersionConflict):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		log.Printf("[ERROR] store: %v", err)
+		return status.Error(codes.Internal, "internal server error")
+	}
+}
+
+// invalidArgument reports validation errors with a BadRequest detail
+func invalidArgument(msg string, verrs models.ValidationErrors) error {
+	br := &errdetails.BadRequest{}
+	for _, v := range verrs {
+		br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{Field: v.Field, Description: v.Reason})
+	}
+	st, err := status.New(codes.InvalidArgument, msg).WithDetails(br)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, msg)
+	}
+	return st.Err()
+}
@@ -0,0 +1,106 @@
+package grpcapi
+
+import (
+	"context"
+
+	pb "github.com/gitvam/platform-go-challenge/internal/grpcapi/favoritesv1"
+	"github.com/gitvam/platform-go-challenge/internal/models"
+	"github.com/gitvam/platform-go-challenge/internal/store"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// defaultLimit is the page size when a request leaves limit unset, as in the REST API
+	defaultLimit = 10
+	// maxLimit is the largest page size, as for GraphQL's first
+	maxLimit = 100
+)
+
+// validatePage records violations of a request's limit and offset in v
+func validatePage(v *models.Validator, limit, offset int32) {
+	v.Range("limit", int(limit), 0, maxLimit)
+	v.NonNegative("offset", int(offset))
+}
+
+// pageSize returns a valid limit, or defaultLimit when it is unset
+func pageSize(limit int32) int {
+	if limit == 0 {
+		return defaultLimit
+	}
+	return int(limit)
+}
+
+// favoritesServer serves FavoritesService like the favorites endpoints of the REST API
+type favoritesServer struct {
+	pb.UnimplementedFavoritesServiceServer
+	store store.Store
+}
+
+func (s *favoritesServer) ListFavorites(ctx context.Context, req *pb.ListFavoritesRequest) (*pb.ListFavoritesResponse, error) {
+	st, userID, err := callerFor(ctx, s.store, req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	var v models.Validator
+	validatePage(&v, req.GetLimit(), req.GetOffset())
+	v.Tags("tags", req.GetTags())
+	if err := v.Err(); err != nil {
+		return nil, storeError(err)
+	}
+	opts := store.ListOptions{
+		Limit:         pageSize(req.GetLimit()),
+		Offset:        int(req.GetOffset()),
+		ExpandMembers: req.GetExpandMembers(),
+		Tags:          models.NormalizeTags(req.GetTags()),
+		MatchAllTags:  req.GetMatchAllTags(),
+		ManualOrder:   req.GetSort() == pb.SortOrder_SORT_ORDER_MANUAL,
+	}
+	favorites, err := st.ListFavorites(userID, opts)
+	if err != nil {
+		return nil, storeError(err)
+	}
+	resp := &pb.ListFavoritesResponse{}
+	for _, a := range favorites {
+		resp.Favorites = append(resp.Favorites, toProto(a))
+	}
+	return resp, nil
+}
+
+func (s *favoritesServer) AddFavorite(ctx context.Context, req *pb.AddFavoriteRequest) (*pb.AddFavoriteResponse, error) {
+	st, userID, err := callerFor(ctx, s.store, req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	asset, err := fromProto(req.GetAsset())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := st.AddFavorite(userID, asset); err != nil {
+		return nil, storeError(err)
+	}
+	return &pb.AddFavoriteResponse{Favorite: toProto(asset)}, nil
+}
+
+func (s *favoritesServer) RemoveFavorite(ctx context.Context, req *pb.RemoveFavoriteRequest) (*pb.RemoveFavoriteResponse, error) {
+	st, userID, err := callerFor(ctx, s.store, req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	if err := st.RemoveFavorite(userID, string(assetType(req.GetType())), req.GetExternalId()); err != nil {
+		return nil, storeError(err)
+	}
+	return &pb.RemoveFavoriteResponse{}, nil
+}
+
+func (s *favoritesServer) EditFavoriteDescription(ctx context.Context, req *pb.EditFavoriteDescriptionRequest) (*pb.EditFavoriteDescriptionResponse, error) {
+	st, userID, err := callerFor(ctx, s.store, req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	version, err := st.EditFavoriteDescription(userID, string(assetType(req.GetType())), req.GetExternalId(), req.GetDescription(), int(req.GetExpectedVersion()))
+	if err != nil {
+		return nil, storeError(err)
+	}
+	return &pb.EditFavoriteDescriptionResponse{Version: int32(version)}, nil
+}
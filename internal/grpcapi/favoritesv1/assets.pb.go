@@ -0,0 +1,949 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: favorites/v1/assets.proto
+
+package favoritesv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type AssetType int32
+
+const (
+	AssetType_ASSET_TYPE_UNSPECIFIED AssetType = 0
+	AssetType_ASSET_TYPE_CHART       AssetType = 1
+	AssetType_ASSET_TYPE_INSIGHT     AssetType = 2
+	AssetType_ASSET_TYPE_AUDIENCE    AssetType = 3
+	AssetType_ASSET_TYPE_DASHBOARD   AssetType = 4
+)
+
+// Enum value maps for AssetType.
+var (
+	AssetType_name = map[int32]string{
+		0: "ASSET_TYPE_UNSPECIFIED",
+		1: "ASSET_TYPE_CHART",
+		2: "ASSET_TYPE_INSIGHT",
+		3: "ASSET_TYPE_AUDIENCE",
+		4: "ASSET_TYPE_DASHBOARD",
+	}
+	AssetType_value = map[string]int32{
+		"ASSET_TYPE_UNSPECIFIED": 0,
+		"ASSET_TYPE_CHART":       1,
+		"ASSET_TYPE_INSIGHT":     2,
+		"ASSET_TYPE_AUDIENCE":    3,
+		"ASSET_TYPE_DASHBOARD":   4,
+	}
+)
+
+func (x AssetType) Enum() *AssetType {
+	p := new(AssetType)
+	*p = x
+	return p
+}
+
+func (x AssetType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (AssetType) Descriptor() protoreflect.EnumDescriptor {
+	return file_favorites_v1_assets_proto_enumTypes[0].Descriptor()
+}
+
+func (AssetType) Type() protoreflect.EnumType {
+	return &file_favorites_v1_assets_proto_enumTypes[0]
+}
+
+func (x AssetType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use AssetType.Descriptor instead.
+func (AssetType) EnumDescriptor() ([]byte, []int) {
+	return file_favorites_v1_assets_proto_rawDescGZIP(), []int{0}
+}
+
+// Asset is a catalog asset, with the user's own description, tags and ordering when it is a
+// favorite. Exactly one of the asset types is set.
+type Asset struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	ExternalId  string                 `protobuf:"bytes,1,opt,name=external_id,json=externalId,proto3" json:"external_id,omitempty"`
+	Description string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	// Favorite tags, lower-case and sorted
+	Tags []string `protobuf:"bytes,3,rep,name=tags,proto3" json:"tags,omitempty"`
+	// Pinned to the top of the favorites
+	Pinned bool `protobuf:"varint,4,opt,name=pinned,proto3" json:"pinned,omitempty"`
+	// Favorite version, increased by every change; ignored when adding a favorite
+	Version int32 `protobuf:"varint,5,opt,name=version,proto3" json:"version,omitempty"`
+	// Types that are valid to be assigned to Kind:
+	//
+	//	*Asset_Chart
+	//	*Asset_Insight
+	//	*Asset_Audience
+	//	*Asset_Dashboard
+	Kind          isAsset_Kind `protobuf_oneof:"kind"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Asset) Reset() {
+	*x = Asset{}
+	mi := &file_favorites_v1_assets_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Asset) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Asset) ProtoMessage() {}
+
+func (x *Asset) ProtoReflect() protoreflect.Message {
+	mi := &file_favorites_v1_assets_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Asset.ProtoReflect.Descriptor instead.
+func (*Asset) Descriptor() ([]byte, []int) {
+	return file_favorites_v1_assets_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Asset) GetExternalId() string {
+	if x != nil {
+		return x.ExternalId
+	}
+	return ""
+}
+
+func (x *Asset) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Asset) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *Asset) GetPinned() bool {
+	if x != nil {
+		return x.Pinned
+	}
+	return false
+}
+
+func (x *Asset) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *Asset) GetKind() isAsset_Kind {
+	if x != nil {
+		return x.Kind
+	}
+	return nil
+}
+
+func (x *Asset) GetChart() *Chart {
+	if x != nil {
+		if x, ok := x.Kind.(*Asset_Chart); ok {
+			return x.Chart
+		}
+	}
+	return nil
+}
+
+func (x *Asset) GetInsight() *Insight {
+	if x != nil {
+		if x, ok := x.Kind.(*Asset_Insight); ok {
+			return x.Insight
+		}
+	}
+	return nil
+}
+
+func (x *Asset) GetAudience() *Audience {
+	if x != nil {
+		if x, ok := x.Kind.(*Asset_Audience); ok {
+			return x.Audience
+		}
+	}
+	return nil
+}
+
+func (x *Asset) GetDashboard() *Dashboard {
+	if x != nil {
+		if x, ok := x.Kind.(*Asset_Dashboard); ok {
+			return x.Dashboard
+		}
+	}
+	return nil
+}
+
+type isAsset_Kind interface {
+	isAsset_Kind()
+}
+
+type Asset_Chart struct {
+	Chart *Chart `protobuf:"bytes,10,opt,name=chart,proto3,oneof"`
+}
+
+type Asset_Insight struct {
+	Insight *Insight `protobuf:"bytes,11,opt,name=insight,proto3,oneof"`
+}
+
+type Asset_Audience struct {
+	Audience *Audience `protobuf:"bytes,12,opt,name=audience,proto3,oneof"`
+}
+
+type Asset_Dashboard struct {
+	Dashboard *Dashboard `protobuf:"bytes,13,opt,name=dashboard,proto3,oneof"`
+}
+
+func (*Asset_Chart) isAsset_Kind() {}
+
+func (*Asset_Insight) isAsset_Kind() {}
+
+func (*Asset_Audience) isAsset_Kind() {}
+
+func (*Asset_Dashboard) isAsset_Kind() {}
+
+type Chart struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Title string                 `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`
+	// line, bar or pie; defaults to line
+	Kind          string    `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"`
+	XAxisTitle    string    `protobuf:"bytes,3,opt,name=x_axis_title,json=xAxisTitle,proto3" json:"x_axis_title,omitempty"`
+	YAxisTitle    string    `protobuf:"bytes,4,opt,name=y_axis_title,json=yAxisTitle,proto3" json:"y_axis_title,omitempty"`
+	XAxis         *XAxis    `protobuf:"bytes,5,opt,name=x_axis,json=xAxis,proto3" json:"x_axis,omitempty"`
+	Series        []*Series `protobuf:"bytes,6,rep,name=series,proto3" json:"series,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Chart) Reset() {
+	*x = Chart{}
+	mi := &file_favorites_v1_assets_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Chart) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Chart) ProtoMessage() {}
+
+func (x *Chart) ProtoReflect() protoreflect.Message {
+	mi := &file_favorites_v1_assets_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Chart.ProtoReflect.Descriptor instead.
+func (*Chart) Descriptor() ([]byte, []int) {
+	return file_favorites_v1_assets_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Chart) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Chart) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *Chart) GetXAxisTitle() string {
+	if x != nil {
+		return x.XAxisTitle
+	}
+	return ""
+}
+
+func (x *Chart) GetYAxisTitle() string {
+	if x != nil {
+		return x.YAxisTitle
+	}
+	return ""
+}
+
+func (x *Chart) GetXAxis() *XAxis {
+	if x != nil {
+		return x.XAxis
+	}
+	return nil
+}
+
+func (x *Chart) GetSeries() []*Series {
+	if x != nil {
+		return x.Series
+	}
+	return nil
+}
+
+// XAxis labels the points of every series, either by category or by timestamp
+type XAxis struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// category or time; defaults to category
+	Kind          string                   `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`
+	Categories    []string                 `protobuf:"bytes,2,rep,name=categories,proto3" json:"categories,omitempty"`
+	Timestamps    []*timestamppb.Timestamp `protobuf:"bytes,3,rep,name=timestamps,proto3" json:"timestamps,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *XAxis) Reset() {
+	*x = XAxis{}
+	mi := &file_favorites_v1_assets_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *XAxis) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*XAxis) ProtoMessage() {}
+
+func (x *XAxis) ProtoReflect() protoreflect.Message {
+	mi := &file_favorites_v1_assets_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use XAxis.ProtoReflect.Descriptor instead.
+func (*XAxis) Descriptor() ([]byte, []int) {
+	return file_favorites_v1_assets_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *XAxis) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *XAxis) GetCategories() []string {
+	if x != nil {
+		return x.Categories
+	}
+	return nil
+}
+
+func (x *XAxis) GetTimestamps() []*timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamps
+	}
+	return nil
+}
+
+type Series struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// e.g. "%" or "k"
+	Unit          string    `protobuf:"bytes,2,opt,name=unit,proto3" json:"unit,omitempty"`
+	Values        []float64 `protobuf:"fixed64,3,rep,packed,name=values,proto3" json:"values,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Series) Reset() {
+	*x = Series{}
+	mi := &file_favorites_v1_assets_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Series) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Series) ProtoMessage() {}
+
+func (x *Series) ProtoReflect() protoreflect.Message {
+	mi := &file_favorites_v1_assets_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Series.ProtoReflect.Descriptor instead.
+func (*Series) Descriptor() ([]byte, []int) {
+	return file_favorites_v1_assets_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Series) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Series) GetUnit() string {
+	if x != nil {
+		return x.Unit
+	}
+	return ""
+}
+
+func (x *Series) GetValues() []float64 {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+type Insight struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Text          string                 `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Insight) Reset() {
+	*x = Insight{}
+	mi := &file_favorites_v1_assets_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Insight) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Insight) ProtoMessage() {}
+
+func (x *Insight) ProtoReflect() protoreflect.Message {
+	mi := &file_favorites_v1_assets_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Insight.ProtoReflect.Descriptor instead.
+func (*Insight) Descriptor() ([]byte, []int) {
+	return file_favorites_v1_assets_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Insight) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+// Audience is defined by criteria, or by the flat fields for audiences defined before
+// criteria existed. Responses always include the effective criteria and its summary.
+type Audience struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Gender             string                 `protobuf:"bytes,1,opt,name=gender,proto3" json:"gender,omitempty"`
+	BirthCountry       string                 `protobuf:"bytes,2,opt,name=birth_country,json=birthCountry,proto3" json:"birth_country,omitempty"`
+	AgeGroups          []string               `protobuf:"bytes,3,rep,name=age_groups,json=ageGroups,proto3" json:"age_groups,omitempty"`
+	HoursOnSocial      int32                  `protobuf:"varint,4,opt,name=hours_on_social,json=hoursOnSocial,proto3" json:"hours_on_social,omitempty"`
+	PurchasesLastMonth int32                  `protobuf:"varint,5,opt,name=purchases_last_month,json=purchasesLastMonth,proto3" json:"purchases_last_month,omitempty"`
+	Criteria           *Criteria              `protobuf:"bytes,6,opt,name=criteria,proto3" json:"criteria,omitempty"`
+	// The criteria in words; ignored in requests
+	Summary       string `protobuf:"bytes,7,opt,name=summary,proto3" json:"summary,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Audience) Reset() {
+	*x = Audience{}
+	mi := &file_favorites_v1_assets_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Audience) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Audience) ProtoMessage() {}
+
+func (x *Audience) ProtoReflect() protoreflect.Message {
+	mi := &file_favorites_v1_assets_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Audience.ProtoReflect.Descriptor instead.
+func (*Audience) Descriptor() ([]byte, []int) {
+	return file_favorites_v1_assets_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Audience) GetGender() string {
+	if x != nil {
+		return x.Gender
+	}
+	return ""
+}
+
+func (x *Audience) GetBirthCountry() string {
+	if x != nil {
+		return x.BirthCountry
+	}
+	return ""
+}
+
+func (x *Audience) GetAgeGroups() []string {
+	if x != nil {
+		return x.AgeGroups
+	}
+	return nil
+}
+
+func (x *Audience) GetHoursOnSocial() int32 {
+	if x != nil {
+		return x.HoursOnSocial
+	}
+	return 0
+}
+
+func (x *Audience) GetPurchasesLastMonth() int32 {
+	if x != nil {
+		return x.PurchasesLastMonth
+	}
+	return 0
+}
+
+func (x *Audience) GetCriteria() *Criteria {
+	if x != nil {
+		return x.Criteria
+	}
+	return nil
+}
+
+func (x *Audience) GetSummary() string {
+	if x != nil {
+		return x.Summary
+	}
+	return ""
+}
+
+// Criteria is a tree of predicates on audience attributes combined with and, or and not.
+// A leaf sets attribute, op and value; other nodes set exactly one of and, or and not.
+type Criteria struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	And           []*Criteria            `protobuf:"bytes,1,rep,name=and,proto3" json:"and,omitempty"`
+	Or            []*Criteria            `protobuf:"bytes,2,rep,name=or,proto3" json:"or,omitempty"`
+	Not           *Criteria              `protobuf:"bytes,3,opt,name=not,proto3" json:"not,omitempty"`
+	Attribute     string                 `protobuf:"bytes,4,opt,name=attribute,proto3" json:"attribute,omitempty"`
+	Op            string                 `protobuf:"bytes,5,opt,name=op,proto3" json:"op,omitempty"`
+	Value         *structpb.Value        `protobuf:"bytes,6,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Criteria) Reset() {
+	*x = Criteria{}
+	mi := &file_favorites_v1_assets_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Criteria) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Criteria) ProtoMessage() {}
+
+func (x *Criteria) ProtoReflect() protoreflect.Message {
+	mi := &file_favorites_v1_assets_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Criteria.ProtoReflect.Descriptor instead.
+func (*Criteria) Descriptor() ([]byte, []int) {
+	return file_favorites_v1_assets_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Criteria) GetAnd() []*Criteria {
+	if x != nil {
+		return x.And
+	}
+	return nil
+}
+
+func (x *Criteria) GetOr() []*Criteria {
+	if x != nil {
+		return x.Or
+	}
+	return nil
+}
+
+func (x *Criteria) GetNot() *Criteria {
+	if x != nil {
+		return x.Not
+	}
+	return nil
+}
+
+func (x *Criteria) GetAttribute() string {
+	if x != nil {
+		return x.Attribute
+	}
+	return ""
+}
+
+func (x *Criteria) GetOp() string {
+	if x != nil {
+		return x.Op
+	}
+	return ""
+}
+
+func (x *Criteria) GetValue() *structpb.Value {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+type Dashboard struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Title string                 `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`
+	// grid, rows or columns
+	Layout        string      `protobuf:"bytes,2,opt,name=layout,proto3" json:"layout,omitempty"`
+	Members       []*AssetRef `protobuf:"bytes,3,rep,name=members,proto3" json:"members,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Dashboard) Reset() {
+	*x = Dashboard{}
+	mi := &file_favorites_v1_assets_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Dashboard) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Dashboard) ProtoMessage() {}
+
+func (x *Dashboard) ProtoReflect() protoreflect.Message {
+	mi := &file_favorites_v1_assets_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Dashboard.ProtoReflect.Descriptor instead.
+func (*Dashboard) Descriptor() ([]byte, []int) {
+	return file_favorites_v1_assets_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Dashboard) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Dashboard) GetLayout() string {
+	if x != nil {
+		return x.Layout
+	}
+	return ""
+}
+
+func (x *Dashboard) GetMembers() []*AssetRef {
+	if x != nil {
+		return x.Members
+	}
+	return nil
+}
+
+// AssetRef points at another asset by type and external ID
+type AssetRef struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Type       AssetType              `protobuf:"varint,1,opt,name=type,proto3,enum=favorites.v1.AssetType" json:"type,omitempty"`
+	ExternalId string                 `protobuf:"bytes,2,opt,name=external_id,json=externalId,proto3" json:"external_id,omitempty"`
+	// The full member, set when members are expanded
+	Asset         *Asset `protobuf:"bytes,3,opt,name=asset,proto3" json:"asset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AssetRef) Reset() {
+	*x = AssetRef{}
+	mi := &file_favorites_v1_assets_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AssetRef) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AssetRef) ProtoMessage() {}
+
+func (x *AssetRef) ProtoReflect() protoreflect.Message {
+	mi := &file_favorites_v1_assets_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AssetRef.ProtoReflect.Descriptor instead.
+func (*AssetRef) Descriptor() ([]byte, []int) {
+	return file_favorites_v1_assets_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *AssetRef) GetType() AssetType {
+	if x != nil {
+		return x.Type
+	}
+	return AssetType_ASSET_TYPE_UNSPECIFIED
+}
+
+func (x *AssetRef) GetExternalId() string {
+	if x != nil {
+		return x.ExternalId
+	}
+	return ""
+}
+
+func (x *AssetRef) GetAsset() *Asset {
+	if x != nil {
+		return x.Asset
+	}
+	return nil
+}
+
+var File_favorites_v1_assets_proto protoreflect.FileDescriptor
+
+const file_favorites_v1_assets_proto_rawDesc = "" +
+	"\n" +
+	"\x19favorites/v1/assets.proto\x12\ffavorites.v1\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xe7\x02\n" +
+	"\x05Asset\x12\x1f\n" +
+	"\vexternal_id\x18\x01 \x01(\tR\n" +
+	"externalId\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12\x12\n" +
+	"\x04tags\x18\x03 \x03(\tR\x04tags\x12\x16\n" +
+	"\x06pinned\x18\x04 \x01(\bR\x06pinned\x12\x18\n" +
+	"\aversion\x18\x05 \x01(\x05R\aversion\x12+\n" +
+	"\x05chart\x18\n" +
+	" \x01(\v2\x13.favorites.v1.ChartH\x00R\x05chart\x121\n" +
+	"\ainsight\x18\v \x01(\v2\x15.favorites.v1.InsightH\x00R\ainsight\x124\n" +
+	"\baudience\x18\f \x01(\v2\x16.favorites.v1.AudienceH\x00R\baudience\x127\n" +
+	"\tdashboard\x18\r \x01(\v2\x17.favorites.v1.DashboardH\x00R\tdashboardB\x06\n" +
+	"\x04kind\"\xcf\x01\n" +
+	"\x05Chart\x12\x14\n" +
+	"\x05title\x18\x01 \x01(\tR\x05title\x12\x12\n" +
+	"\x04kind\x18\x02 \x01(\tR\x04kind\x12 \n" +
+	"\fx_axis_title\x18\x03 \x01(\tR\n" +
+	"xAxisTitle\x12 \n" +
+	"\fy_axis_title\x18\x04 \x01(\tR\n" +
+	"yAxisTitle\x12*\n" +
+	"\x06x_axis\x18\x05 \x01(\v2\x13.favorites.v1.XAxisR\x05xAxis\x12,\n" +
+	"\x06series\x18\x06 \x03(\v2\x14.favorites.v1.SeriesR\x06series\"w\n" +
+	"\x05XAxis\x12\x12\n" +
+	"\x04kind\x18\x01 \x01(\tR\x04kind\x12\x1e\n" +
+	"\n" +
+	"categories\x18\x02 \x03(\tR\n" +
+	"categories\x12:\n" +
+	"\n" +
+	"timestamps\x18\x03 \x03(\v2\x1a.google.protobuf.TimestampR\n" +
+	"timestamps\"H\n" +
+	"\x06Series\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04unit\x18\x02 \x01(\tR\x04unit\x12\x16\n" +
+	"\x06values\x18\x03 \x03(\x01R\x06values\"\x1d\n" +
+	"\aInsight\x12\x12\n" +
+	"\x04text\x18\x01 \x01(\tR\x04text\"\x8e\x02\n" +
+	"\bAudience\x12\x16\n" +
+	"\x06gender\x18\x01 \x01(\tR\x06gender\x12#\n" +
+	"\rbirth_country\x18\x02 \x01(\tR\fbirthCountry\x12\x1d\n" +
+	"\n" +
+	"age_groups\x18\x03 \x03(\tR\tageGroups\x12&\n" +
+	"\x0fhours_on_social\x18\x04 \x01(\x05R\rhoursOnSocial\x120\n" +
+	"\x14purchases_last_month\x18\x05 \x01(\x05R\x12purchasesLastMonth\x122\n" +
+	"\bcriteria\x18\x06 \x01(\v2\x16.favorites.v1.CriteriaR\bcriteria\x12\x18\n" +
+	"\asummary\x18\a \x01(\tR\asummary\"\xe2\x01\n" +
+	"\bCriteria\x12(\n" +
+	"\x03and\x18\x01 \x03(\v2\x16.favorites.v1.CriteriaR\x03and\x12&\n" +
+	"\x02or\x18\x02 \x03(\v2\x16.favorites.v1.CriteriaR\x02or\x12(\n" +
+	"\x03not\x18\x03 \x01(\v2\x16.favorites.v1.CriteriaR\x03not\x12\x1c\n" +
+	"\tattribute\x18\x04 \x01(\tR\tattribute\x12\x0e\n" +
+	"\x02op\x18\x05 \x01(\tR\x02op\x12,\n" +
+	"\x05value\x18\x06 \x01(\v2\x16.google.protobuf.ValueR\x05value\"k\n" +
+	"\tDashboard\x12\x14\n" +
+	"\x05title\x18\x01 \x01(\tR\x05title\x12\x16\n" +
+	"\x06layout\x18\x02 \x01(\tR\x06layout\x120\n" +
+	"\amembers\x18\x03 \x03(\v2\x16.favorites.v1.AssetRefR\amembers\"\x83\x01\n" +
+	"\bAssetRef\x12+\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x17.favorites.v1.AssetTypeR\x04type\x12\x1f\n" +
+	"\vexternal_id\x18\x02 \x01(\tR\n" +
+	"externalId\x12)\n" +
+	"\x05asset\x18\x03 \x01(\v2\x13.favorites.v1.AssetR\x05asset*\x88\x01\n" +
+	"\tAssetType\x12\x1a\n" +
+	"\x16ASSET_TYPE_UNSPECIFIED\x10\x00\x12\x14\n" +
+	"\x10ASSET_TYPE_CHART\x10\x01\x12\x16\n" +
+	"\x12ASSET_TYPE_INSIGHT\x10\x02\x12\x17\n" +
+	"\x13ASSET_TYPE_AUDIENCE\x10\x03\x12\x18\n" +
+	"\x14ASSET_TYPE_DASHBOARD\x10\x04BRZPgithub.com/gitvam/platform-go-challenge/internal/grpcapi/favoritesv1;favoritesv1b\x06proto3"
+
+var (
+	file_favorites_v1_assets_proto_rawDescOnce sync.Once
+	file_favorites_v1_assets_proto_rawDescData []byte
+)
+
+func file_favorites_v1_assets_proto_rawDescGZIP() []byte {
+	file_favorites_v1_assets_proto_rawDescOnce.Do(func() {
+		file_favorites_v1_assets_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_favorites_v1_assets_proto_rawDesc), len(file_favorites_v1_assets_proto_rawDesc)))
+	})
+	return file_favorites_v1_assets_proto_rawDescData
+}
+
+var file_favorites_v1_assets_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_favorites_v1_assets_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_favorites_v1_assets_proto_goTypes = []any{
+	(AssetType)(0),                // 0: favorites.v1.AssetType
+	(*Asset)(nil),                 // 1: favorites.v1.Asset
+	(*Chart)(nil),                 // 2: favorites.v1.Chart
+	(*XAxis)(nil),                 // 3: favorites.v1.XAxis
+	(*Series)(nil),                // 4: favorites.v1.Series
+	(*Insight)(nil),               // 5: favorites.v1.Insight
+	(*Audience)(nil),              // 6: favorites.v1.Audience
+	(*Criteria)(nil),              // 7: favorites.v1.Criteria
+	(*Dashboard)(nil),             // 8: favorites.v1.Dashboard
+	(*AssetRef)(nil),              // 9: favorites.v1.AssetRef
+	(*timestamppb.Timestamp)(nil), // 10: google.protobuf.Timestamp
+	(*structpb.Value)(nil),        // 11: google.protobuf.Value
+}
+var file_favorites_v1_assets_proto_depIdxs = []int32{
+	2,  // 0: favorites.v1.Asset.chart:type_name -> favorites.v1.Chart
+	5,  // 1: favorites.v1.Asset.insight:type_name -> favorites.v1.Insight
+	6,  // 2: favorites.v1.Asset.audience:type_name -> favorites.v1.Audience
+	8,  // 3: favorites.v1.Asset.dashboard:type_name -> favorites.v1.Dashboard
+	3,  // 4: favorites.v1.Chart.x_axis:type_name -> favorites.v1.XAxis
+	4,  // 5: favorites.v1.Chart.series:type_name -> favorites.v1.Series
+	10, // 6: favorites.v1.XAxis.timestamps:type_name -> google.protobuf.Timestamp
+	7,  // 7: favorites.v1.Audience.criteria:type_name -> favorites.v1.Criteria
+	7,  // 8: favorites.v1.Criteria.and:type_name -> favorites.v1.Criteria
+	7,  // 9: favorites.v1.Criteria.or:type_name -> favorites.v1.Criteria
+	7,  // 10: favorites.v1.Criteria.not:type_name -> favorites.v1.Criteria
+	11, // 11: favorites.v1.Criteria.value:type_name -> google.protobuf.Value
+	9,  // 12: favorites.v1.Dashboard.members:type_name -> favorites.v1.AssetRef
+	0,  // 13: favorites.v1.AssetRef.type:type_name -> favorites.v1.AssetType
+	1,  // 14: favorites.v1.AssetRef.asset:type_name -> favorites.v1.Asset
+	15, // [15:15] is the sub-list for method output_type
+	15, // [15:15] is the sub-list for method input_type
+	15, // [15:15] is the sub-list for extension type_name
+	15, // [15:15] is the sub-list for extension extendee
+	0,  // [0:15] is the sub-list for field type_name
+}
+
+func init() { file_favorites_v1_assets_proto_init() }
+func file_favorites_v1_assets_proto_init() {
+	if File_favorites_v1_assets_proto != nil {
+		return
+	}
+	file_favorites_v1_assets_proto_msgTypes[0].OneofWrappers = []any{
+		(*Asset_Chart)(nil),
+		(*Asset_Insight)(nil),
+		(*Asset_Audience)(nil),
+		(*Asset_Dashboard)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_favorites_v1_assets_proto_rawDesc), len(file_favorites_v1_assets_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   9,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_favorites_v1_assets_proto_goTypes,
+		DependencyIndexes: file_favorites_v1_assets_proto_depIdxs,
+		EnumInfos:         file_favorites_v1_assets_proto_enumTypes,
+		MessageInfos:      file_favorites_v1_assets_proto_msgTypes,
+	}.Build()
+	File_favorites_v1_assets_proto = out.File
+	file_favorites_v1_assets_proto_goTypes = nil
+	file_favorites_v1_assets_proto_depIdxs = nil
+}
@@ -0,0 +1,327 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: favorites/v1/catalog.proto
+
+package favoritesv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ListAssetsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Type  AssetType              `protobuf:"varint,1,opt,name=type,proto3,enum=favorites.v1.AssetType" json:"type,omitempty"`
+	// Page size, 10 when unset and at most 100
+	Limit int32 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	// Number of items to skip, not negative
+	Offset int32 `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
+	// Include each dashboard member's full asset instead of just its reference
+	ExpandMembers bool `protobuf:"varint,4,opt,name=expand_members,json=expandMembers,proto3" json:"expand_members,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAssetsRequest) Reset() {
+	*x = ListAssetsRequest{}
+	mi := &file_favorites_v1_catalog_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAssetsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAssetsRequest) ProtoMessage() {}
+
+func (x *ListAssetsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_favorites_v1_catalog_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAssetsRequest.ProtoReflect.Descriptor instead.
+func (*ListAssetsRequest) Descriptor() ([]byte, []int) {
+	return file_favorites_v1_catalog_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ListAssetsRequest) GetType() AssetType {
+	if x != nil {
+		return x.Type
+	}
+	return AssetType_ASSET_TYPE_UNSPECIFIED
+}
+
+func (x *ListAssetsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListAssetsRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *ListAssetsRequest) GetExpandMembers() bool {
+	if x != nil {
+		return x.ExpandMembers
+	}
+	return false
+}
+
+type ListAssetsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Assets        []*Asset               `protobuf:"bytes,1,rep,name=assets,proto3" json:"assets,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAssetsResponse) Reset() {
+	*x = ListAssetsResponse{}
+	mi := &file_favorites_v1_catalog_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAssetsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAssetsResponse) ProtoMessage() {}
+
+func (x *ListAssetsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_favorites_v1_catalog_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAssetsResponse.ProtoReflect.Descriptor instead.
+func (*ListAssetsResponse) Descriptor() ([]byte, []int) {
+	return file_favorites_v1_catalog_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListAssetsResponse) GetAssets() []*Asset {
+	if x != nil {
+		return x.Assets
+	}
+	return nil
+}
+
+type GetAssetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          AssetType              `protobuf:"varint,1,opt,name=type,proto3,enum=favorites.v1.AssetType" json:"type,omitempty"`
+	ExternalId    string                 `protobuf:"bytes,2,opt,name=external_id,json=externalId,proto3" json:"external_id,omitempty"`
+	ExpandMembers bool                   `protobuf:"varint,3,opt,name=expand_members,json=expandMembers,proto3" json:"expand_members,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAssetRequest) Reset() {
+	*x = GetAssetRequest{}
+	mi := &file_favorites_v1_catalog_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAssetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAssetRequest) ProtoMessage() {}
+
+func (x *GetAssetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_favorites_v1_catalog_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAssetRequest.ProtoReflect.Descriptor instead.
+func (*GetAssetRequest) Descriptor() ([]byte, []int) {
+	return file_favorites_v1_catalog_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetAssetRequest) GetType() AssetType {
+	if x != nil {
+		return x.Type
+	}
+	return AssetType_ASSET_TYPE_UNSPECIFIED
+}
+
+func (x *GetAssetRequest) GetExternalId() string {
+	if x != nil {
+		return x.ExternalId
+	}
+	return ""
+}
+
+func (x *GetAssetRequest) GetExpandMembers() bool {
+	if x != nil {
+		return x.ExpandMembers
+	}
+	return false
+}
+
+type GetAssetResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Asset         *Asset                 `protobuf:"bytes,1,opt,name=asset,proto3" json:"asset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAssetResponse) Reset() {
+	*x = GetAssetResponse{}
+	mi := &file_favorites_v1_catalog_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAssetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAssetResponse) ProtoMessage() {}
+
+func (x *GetAssetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_favorites_v1_catalog_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAssetResponse.ProtoReflect.Descriptor instead.
+func (*GetAssetResponse) Descriptor() ([]byte, []int) {
+	return file_favorites_v1_catalog_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetAssetResponse) GetAsset() *Asset {
+	if x != nil {
+		return x.Asset
+	}
+	return nil
+}
+
+var File_favorites_v1_catalog_proto protoreflect.FileDescriptor
+
+const file_favorites_v1_catalog_proto_rawDesc = "" +
+	"\n" +
+	"\x1afavorites/v1/catalog.proto\x12\ffavorites.v1\x1a\x19favorites/v1/assets.proto\"\x95\x01\n" +
+	"\x11ListAssetsRequest\x12+\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x17.favorites.v1.AssetTypeR\x04type\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06offset\x18\x03 \x01(\x05R\x06offset\x12%\n" +
+	"\x0eexpand_members\x18\x04 \x01(\bR\rexpandMembers\"A\n" +
+	"\x12ListAssetsResponse\x12+\n" +
+	"\x06assets\x18\x01 \x03(\v2\x13.favorites.v1.AssetR\x06assets\"\x86\x01\n" +
+	"\x0fGetAssetRequest\x12+\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x17.favorites.v1.AssetTypeR\x04type\x12\x1f\n" +
+	"\vexternal_id\x18\x02 \x01(\tR\n" +
+	"externalId\x12%\n" +
+	"\x0eexpand_members\x18\x03 \x01(\bR\rexpandMembers\"=\n" +
+	"\x10GetAssetResponse\x12)\n" +
+	"\x05asset\x18\x01 \x01(\v2\x13.favorites.v1.AssetR\x05asset2\xac\x01\n" +
+	"\x0eCatalogService\x12O\n" +
+	"\n" +
+	"ListAssets\x12\x1f.favorites.v1.ListAssetsRequest\x1a .favorites.v1.ListAssetsResponse\x12I\n" +
+	"\bGetAsset\x12\x1d.favorites.v1.GetAssetRequest\x1a\x1e.favorites.v1.GetAssetResponseBRZPgithub.com/gitvam/platform-go-challenge/internal/grpcapi/favoritesv1;favoritesv1b\x06proto3"
+
+var (
+	file_favorites_v1_catalog_proto_rawDescOnce sync.Once
+	file_favorites_v1_catalog_proto_rawDescData []byte
+)
+
+func file_favorites_v1_catalog_proto_rawDescGZIP() []byte {
+	file_favorites_v1_catalog_proto_rawDescOnce.Do(func() {
+		file_favorites_v1_catalog_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_favorites_v1_catalog_proto_rawDesc), len(file_favorites_v1_catalog_proto_rawDesc)))
+	})
+	return file_favorites_v1_catalog_proto_rawDescData
+}
+
+var file_favorites_v1_catalog_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_favorites_v1_catalog_proto_goTypes = []any{
+	(*ListAssetsRequest)(nil),  // 0: favorites.v1.ListAssetsRequest
+	(*ListAssetsResponse)(nil), // 1: favorites.v1.ListAssetsResponse
+	(*GetAssetRequest)(nil),    // 2: favorites.v1.GetAssetRequest
+	(*GetAssetResponse)(nil),   // 3: favorites.v1.GetAssetResponse
+	(AssetType)(0),             // 4: favorites.v1.AssetType
+	(*Asset)(nil),              // 5: favorites.v1.Asset
+}
+var file_favorites_v1_catalog_proto_depIdxs = []int32{
+	4, // 0: favorites.v1.ListAssetsRequest.type:type_name -> favorites.v1.AssetType
+	5, // 1: favorites.v1.ListAssetsResponse.assets:type_name -> favorites.v1.Asset
+	4, // 2: favorites.v1.GetAssetRequest.type:type_name -> favorites.v1.AssetType
+	5, // 3: favorites.v1.GetAssetResponse.asset:type_name -> favorites.v1.Asset
+	0, // 4: favorites.v1.CatalogService.ListAssets:input_type -> favorites.v1.ListAssetsRequest
+	2, // 5: favorites.v1.CatalogService.GetAsset:input_type -> favorites.v1.GetAssetRequest
+	1, // 6: favorites.v1.CatalogService.ListAssets:output_type -> favorites.v1.ListAssetsResponse
+	3, // 7: favorites.v1.CatalogService.GetAsset:output_type -> favorites.v1.GetAssetResponse
+	6, // [6:8] is the sub-list for method output_type
+	4, // [4:6] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_favorites_v1_catalog_proto_init() }
+func file_favorites_v1_catalog_proto_init() {
+	if File_favorites_v1_catalog_proto != nil {
+		return
+	}
+	file_favorites_v1_assets_proto_init()
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_favorites_v1_catalog_proto_rawDesc), len(file_favorites_v1_catalog_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   4,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_favorites_v1_catalog_proto_goTypes,
+		DependencyIndexes: file_favorites_v1_catalog_proto_depIdxs,
+		MessageInfos:      file_favorites_v1_catalog_proto_msgTypes,
+	}.Build()
+	File_favorites_v1_catalog_proto = out.File
+	file_favorites_v1_catalog_proto_goTypes = nil
+	file_favorites_v1_catalog_proto_depIdxs = nil
+}
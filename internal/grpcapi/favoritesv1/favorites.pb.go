@@ -0,0 +1,642 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: favorites/v1/favorites.proto
+
+package favoritesv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type SortOrder int32
+
+const (
+	// Groups favorites by type
+	SortOrder_SORT_ORDER_UNSPECIFIED SortOrder = 0
+	// Lists pinned favorites first and then the user's own order
+	SortOrder_SORT_ORDER_MANUAL SortOrder = 1
+)
+
+// Enum value maps for SortOrder.
+var (
+	SortOrder_name = map[int32]string{
+		0: "SORT_ORDER_UNSPECIFIED",
+		1: "SORT_ORDER_MANUAL",
+	}
+	SortOrder_value = map[string]int32{
+		"SORT_ORDER_UNSPECIFIED": 0,
+		"SORT_ORDER_MANUAL":      1,
+	}
+)
+
+func (x SortOrder) Enum() *SortOrder {
+	p := new(SortOrder)
+	*p = x
+	return p
+}
+
+func (x SortOrder) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SortOrder) Descriptor() protoreflect.EnumDescriptor {
+	return file_favorites_v1_favorites_proto_enumTypes[0].Descriptor()
+}
+
+func (SortOrder) Type() protoreflect.EnumType {
+	return &file_favorites_v1_favorites_proto_enumTypes[0]
+}
+
+func (x SortOrder) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SortOrder.Descriptor instead.
+func (SortOrder) EnumDescriptor() ([]byte, []int) {
+	return file_favorites_v1_favorites_proto_rawDescGZIP(), []int{0}
+}
+
+type ListFavoritesRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	UserId string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// Page size, 10 when unset and at most 100
+	Limit int32 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	// Number of items to skip, not negative
+	Offset int32 `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
+	// Include each dashboard member's full asset instead of just its reference
+	ExpandMembers bool `protobuf:"varint,4,opt,name=expand_members,json=expandMembers,proto3" json:"expand_members,omitempty"`
+	// Keep favorites carrying any of the tags, or all of them with match_all_tags
+	Tags          []string  `protobuf:"bytes,5,rep,name=tags,proto3" json:"tags,omitempty"`
+	MatchAllTags  bool      `protobuf:"varint,6,opt,name=match_all_tags,json=matchAllTags,proto3" json:"match_all_tags,omitempty"`
+	Sort          SortOrder `protobuf:"varint,7,opt,name=sort,proto3,enum=favorites.v1.SortOrder" json:"sort,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFavoritesRequest) Reset() {
+	*x = ListFavoritesRequest{}
+	mi := &file_favorites_v1_favorites_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFavoritesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFavoritesRequest) ProtoMessage() {}
+
+func (x *ListFavoritesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_favorites_v1_favorites_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFavoritesRequest.ProtoReflect.Descriptor instead.
+func (*ListFavoritesRequest) Descriptor() ([]byte, []int) {
+	return file_favorites_v1_favorites_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ListFavoritesRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ListFavoritesRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListFavoritesRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *ListFavoritesRequest) GetExpandMembers() bool {
+	if x != nil {
+		return x.ExpandMembers
+	}
+	return false
+}
+
+func (x *ListFavoritesRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *ListFavoritesRequest) GetMatchAllTags() bool {
+	if x != nil {
+		return x.MatchAllTags
+	}
+	return false
+}
+
+func (x *ListFavoritesRequest) GetSort() SortOrder {
+	if x != nil {
+		return x.Sort
+	}
+	return SortOrder_SORT_ORDER_UNSPECIFIED
+}
+
+type ListFavoritesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Favorites     []*Asset               `protobuf:"bytes,1,rep,name=favorites,proto3" json:"favorites,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFavoritesResponse) Reset() {
+	*x = ListFavoritesResponse{}
+	mi := &file_favorites_v1_favorites_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFavoritesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFavoritesResponse) ProtoMessage() {}
+
+func (x *ListFavoritesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_favorites_v1_favorites_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFavoritesResponse.ProtoReflect.Descriptor instead.
+func (*ListFavoritesResponse) Descriptor() ([]byte, []int) {
+	return file_favorites_v1_favorites_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListFavoritesResponse) GetFavorites() []*Asset {
+	if x != nil {
+		return x.Favorites
+	}
+	return nil
+}
+
+type AddFavoriteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Asset         *Asset                 `protobuf:"bytes,2,opt,name=asset,proto3" json:"asset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddFavoriteRequest) Reset() {
+	*x = AddFavoriteRequest{}
+	mi := &file_favorites_v1_favorites_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddFavoriteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddFavoriteRequest) ProtoMessage() {}
+
+func (x *AddFavoriteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_favorites_v1_favorites_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddFavoriteRequest.ProtoReflect.Descriptor instead.
+func (*AddFavoriteRequest) Descriptor() ([]byte, []int) {
+	return file_favorites_v1_favorites_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *AddFavoriteRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *AddFavoriteRequest) GetAsset() *Asset {
+	if x != nil {
+		return x.Asset
+	}
+	return nil
+}
+
+type AddFavoriteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Favorite      *Asset                 `protobuf:"bytes,1,opt,name=favorite,proto3" json:"favorite,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddFavoriteResponse) Reset() {
+	*x = AddFavoriteResponse{}
+	mi := &file_favorites_v1_favorites_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddFavoriteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddFavoriteResponse) ProtoMessage() {}
+
+func (x *AddFavoriteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_favorites_v1_favorites_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddFavoriteResponse.ProtoReflect.Descriptor instead.
+func (*AddFavoriteResponse) Descriptor() ([]byte, []int) {
+	return file_favorites_v1_favorites_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *AddFavoriteResponse) GetFavorite() *Asset {
+	if x != nil {
+		return x.Favorite
+	}
+	return nil
+}
+
+type RemoveFavoriteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Type          AssetType              `protobuf:"varint,2,opt,name=type,proto3,enum=favorites.v1.AssetType" json:"type,omitempty"`
+	ExternalId    string                 `protobuf:"bytes,3,opt,name=external_id,json=externalId,proto3" json:"external_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveFavoriteRequest) Reset() {
+	*x = RemoveFavoriteRequest{}
+	mi := &file_favorites_v1_favorites_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveFavoriteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveFavoriteRequest) ProtoMessage() {}
+
+func (x *RemoveFavoriteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_favorites_v1_favorites_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveFavoriteRequest.ProtoReflect.Descriptor instead.
+func (*RemoveFavoriteRequest) Descriptor() ([]byte, []int) {
+	return file_favorites_v1_favorites_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *RemoveFavoriteRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *RemoveFavoriteRequest) GetType() AssetType {
+	if x != nil {
+		return x.Type
+	}
+	return AssetType_ASSET_TYPE_UNSPECIFIED
+}
+
+func (x *RemoveFavoriteRequest) GetExternalId() string {
+	if x != nil {
+		return x.ExternalId
+	}
+	return ""
+}
+
+type RemoveFavoriteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveFavoriteResponse) Reset() {
+	*x = RemoveFavoriteResponse{}
+	mi := &file_favorites_v1_favorites_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveFavoriteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveFavoriteResponse) ProtoMessage() {}
+
+func (x *RemoveFavoriteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_favorites_v1_favorites_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveFavoriteResponse.ProtoReflect.Descriptor instead.
+func (*RemoveFavoriteResponse) Descriptor() ([]byte, []int) {
+	return file_favorites_v1_favorites_proto_rawDescGZIP(), []int{5}
+}
+
+type EditFavoriteDescriptionRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	UserId      string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Type        AssetType              `protobuf:"varint,2,opt,name=type,proto3,enum=favorites.v1.AssetType" json:"type,omitempty"`
+	ExternalId  string                 `protobuf:"bytes,3,opt,name=external_id,json=externalId,proto3" json:"external_id,omitempty"`
+	Description string                 `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
+	// When set, the edit only succeeds while the favorite still has this version
+	ExpectedVersion int32 `protobuf:"varint,5,opt,name=expected_version,json=expectedVersion,proto3" json:"expected_version,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *EditFavoriteDescriptionRequest) Reset() {
+	*x = EditFavoriteDescriptionRequest{}
+	mi := &file_favorites_v1_favorites_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EditFavoriteDescriptionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EditFavoriteDescriptionRequest) ProtoMessage() {}
+
+func (x *EditFavoriteDescriptionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_favorites_v1_favorites_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EditFavoriteDescriptionRequest.ProtoReflect.Descriptor instead.
+func (*EditFavoriteDescriptionRequest) Descriptor() ([]byte, []int) {
+	return file_favorites_v1_favorites_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *EditFavoriteDescriptionRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *EditFavoriteDescriptionRequest) GetType() AssetType {
+	if x != nil {
+		return x.Type
+	}
+	return AssetType_ASSET_TYPE_UNSPECIFIED
+}
+
+func (x *EditFavoriteDescriptionRequest) GetExternalId() string {
+	if x != nil {
+		return x.ExternalId
+	}
+	return ""
+}
+
+func (x *EditFavoriteDescriptionRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *EditFavoriteDescriptionRequest) GetExpectedVersion() int32 {
+	if x != nil {
+		return x.ExpectedVersion
+	}
+	return 0
+}
+
+type EditFavoriteDescriptionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Version       int32                  `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EditFavoriteDescriptionResponse) Reset() {
+	*x = EditFavoriteDescriptionResponse{}
+	mi := &file_favorites_v1_favorites_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EditFavoriteDescriptionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EditFavoriteDescriptionResponse) ProtoMessage() {}
+
+func (x *EditFavoriteDescriptionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_favorites_v1_favorites_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EditFavoriteDescriptionResponse.ProtoReflect.Descriptor instead.
+func (*EditFavoriteDescriptionResponse) Descriptor() ([]byte, []int) {
+	return file_favorites_v1_favorites_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *EditFavoriteDescriptionResponse) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+var File_favorites_v1_favorites_proto protoreflect.FileDescriptor
+
+const file_favorites_v1_favorites_proto_rawDesc = "" +
+	"\n" +
+	"\x1cfavorites/v1/favorites.proto\x12\ffavorites.v1\x1a\x19favorites/v1/assets.proto\"\xeb\x01\n" +
+	"\x14ListFavoritesRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06offset\x18\x03 \x01(\x05R\x06offset\x12%\n" +
+	"\x0eexpand_members\x18\x04 \x01(\bR\rexpandMembers\x12\x12\n" +
+	"\x04tags\x18\x05 \x03(\tR\x04tags\x12$\n" +
+	"\x0ematch_all_tags\x18\x06 \x01(\bR\fmatchAllTags\x12+\n" +
+	"\x04sort\x18\a \x01(\x0e2\x17.favorites.v1.SortOrderR\x04sort\"J\n" +
+	"\x15ListFavoritesResponse\x121\n" +
+	"\tfavorites\x18\x01 \x03(\v2\x13.favorites.v1.AssetR\tfavorites\"X\n" +
+	"\x12AddFavoriteRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12)\n" +
+	"\x05asset\x18\x02 \x01(\v2\x13.favorites.v1.AssetR\x05asset\"F\n" +
+	"\x13AddFavoriteResponse\x12/\n" +
+	"\bfavorite\x18\x01 \x01(\v2\x13.favorites.v1.AssetR\bfavorite\"~\n" +
+	"\x15RemoveFavoriteRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12+\n" +
+	"\x04type\x18\x02 \x01(\x0e2\x17.favorites.v1.AssetTypeR\x04type\x12\x1f\n" +
+	"\vexternal_id\x18\x03 \x01(\tR\n" +
+	"externalId\"\x18\n" +
+	"\x16RemoveFavoriteResponse\"\xd4\x01\n" +
+	"\x1eEditFavoriteDescriptionRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12+\n" +
+	"\x04type\x18\x02 \x01(\x0e2\x17.favorites.v1.AssetTypeR\x04type\x12\x1f\n" +
+	"\vexternal_id\x18\x03 \x01(\tR\n" +
+	"externalId\x12 \n" +
+	"\vdescription\x18\x04 \x01(\tR\vdescription\x12)\n" +
+	"\x10expected_version\x18\x05 \x01(\x05R\x0fexpectedVersion\";\n" +
+	"\x1fEditFavoriteDescriptionResponse\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\x05R\aversion*>\n" +
+	"\tSortOrder\x12\x1a\n" +
+	"\x16SORT_ORDER_UNSPECIFIED\x10\x00\x12\x15\n" +
+	"\x11SORT_ORDER_MANUAL\x10\x012\x95\x03\n" +
+	"\x10FavoritesService\x12X\n" +
+	"\rListFavorites\x12\".favorites.v1.ListFavoritesRequest\x1a#.favorites.v1.ListFavoritesResponse\x12R\n" +
+	"\vAddFavorite\x12 .favorites.v1.AddFavoriteRequest\x1a!.favorites.v1.AddFavoriteResponse\x12[\n" +
+	"\x0eRemoveFavorite\x12#.favorites.v1.RemoveFavoriteRequest\x1a$.favorites.v1.RemoveFavoriteResponse\x12v\n" +
+	"\x17EditFavoriteDescription\x12,.favorites.v1.EditFavoriteDescriptionRequest\x1a-.favorites.v1.EditFavoriteDescriptionResponseBRZPgithub.com/gitvam/platform-go-challenge/internal/grpcapi/favoritesv1;favoritesv1b\x06proto3"
+
+var (
+	file_favorites_v1_favorites_proto_rawDescOnce sync.Once
+	file_favorites_v1_favorites_proto_rawDescData []byte
+)
+
+func file_favorites_v1_favorites_proto_rawDescGZIP() []byte {
+	file_favorites_v1_favorites_proto_rawDescOnce.Do(func() {
+		file_favorites_v1_favorites_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_favorites_v1_favorites_proto_rawDesc), len(file_favorites_v1_favorites_proto_rawDesc)))
+	})
+	return file_favorites_v1_favorites_proto_rawDescData
+}
+
+var file_favorites_v1_favorites_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_favorites_v1_favorites_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_favorites_v1_favorites_proto_goTypes = []any{
+	(SortOrder)(0),                          // 0: favorites.v1.SortOrder
+	(*ListFavoritesRequest)(nil),            // 1: favorites.v1.ListFavoritesRequest
+	(*ListFavoritesResponse)(nil),           // 2: favorites.v1.ListFavoritesResponse
+	(*AddFavoriteRequest)(nil),              // 3: favorites.v1.AddFavoriteRequest
+	(*AddFavoriteResponse)(nil),             // 4: favorites.v1.AddFavoriteResponse
+	(*RemoveFavoriteRequest)(nil),           // 5: favorites.v1.RemoveFavoriteRequest
+	(*RemoveFavoriteResponse)(nil),          // 6: favorites.v1.RemoveFavoriteResponse
+	(*EditFavoriteDescriptionRequest)(nil),  // 7: favorites.v1.EditFavoriteDescriptionRequest
+	(*EditFavoriteDescriptionResponse)(nil), // 8: favorites.v1.EditFavoriteDescriptionResponse
+	(*Asset)(nil),                           // 9: favorites.v1.Asset
+	(AssetType)(0),                          // 10: favorites.v1.AssetType
+}
+var file_favorites_v1_favorites_proto_depIdxs = []int32{
+	0,  // 0: favorites.v1.ListFavoritesRequest.sort:type_name -> favorites.v1.SortOrder
+	9,  // 1: favorites.v1.ListFavoritesResponse.favorites:type_name -> favorites.v1.Asset
+	9,  // 2: favorites.v1.AddFavoriteRequest.asset:type_name -> favorites.v1.Asset
+	9,  // 3: favorites.v1.AddFavoriteResponse.favorite:type_name -> favorites.v1.Asset
+	10, // 4: favorites.v1.RemoveFavoriteRequest.type:type_name -> favorites.v1.AssetType
+	10, // 5: favorites.v1.EditFavoriteDescriptionRequest.type:type_name -> favorites.v1.AssetType
+	1,  // 6: favorites.v1.FavoritesService.ListFavorites:input_type -> favorites.v1.ListFavoritesRequest
+	3,  // 7: favorites.v1.FavoritesService.AddFavorite:input_type -> favorites.v1.AddFavoriteRequest
+	5,  // 8: favorites.v1.FavoritesService.RemoveFavorite:input_type -> favorites.v1.RemoveFavoriteRequest
+	7,  // 9: favorites.v1.FavoritesService.EditFavoriteDescription:input_type -> favorites.v1.EditFavoriteDescriptionRequest
+	2,  // 10: favorites.v1.FavoritesService.ListFavorites:output_type -> favorites.v1.ListFavoritesResponse
+	4,  // 11: favorites.v1.FavoritesService.AddFavorite:output_type -> favorites.v1.AddFavoriteResponse
+	6,  // 12: favorites.v1.FavoritesService.RemoveFavorite:output_type -> favorites.v1.RemoveFavoriteResponse
+	8,  // 13: favorites.v1.FavoritesService.EditFavoriteDescription:output_type -> favorites.v1.EditFavoriteDescriptionResponse
+	10, // [10:14] is the sub-list for method output_type
+	6,  // [6:10] is the sub-list for method input_type
+	6,  // [6:6] is the sub-list for extension type_name
+	6,  // [6:6] is the sub-list for extension extendee
+	0,  // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_favorites_v1_favorites_proto_init() }
+func file_favorites_v1_favorites_proto_init() {
+	if File_favorites_v1_favorites_proto != nil {
+		return
+	}
+	file_favorites_v1_assets_proto_init()
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_favorites_v1_favorites_proto_rawDesc), len(file_favorites_v1_favorites_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_favorites_v1_favorites_proto_goTypes,
+		DependencyIndexes: file_favorites_v1_favorites_proto_depIdxs,
+		EnumInfos:         file_favorites_v1_favorites_proto_enumTypes,
+		MessageInfos:      file_favorites_v1_favorites_proto_msgTypes,
+	}.Build()
+	File_favorites_v1_favorites_proto = out.File
+	file_favorites_v1_favorites_proto_goTypes = nil
+	file_favorites_v1_favorites_proto_depIdxs = nil
+}
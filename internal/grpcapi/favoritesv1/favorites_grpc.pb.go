@@ -0,0 +1,251 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: favorites/v1/favorites.proto
+
+package favoritesv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	FavoritesService_ListFavorites_FullMethodName           = "/favorites.v1.FavoritesService/ListFavorites"
+	FavoritesService_AddFavorite_FullMethodName             = "/favorites.v1.FavoritesService/AddFavorite"
+	FavoritesService_RemoveFavorite_FullMethodName          = "/favorites.v1.FavoritesService/RemoveFavorite"
+	FavoritesService_EditFavoriteDescription_FullMethodName = "/favorites.v1.FavoritesService/EditFavoriteDescription"
+)
+
+// FavoritesServiceClient is the client API for FavoritesService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// FavoritesService mirrors the favorites endpoints of the REST API. Calls carry a JWT in the
+// authorization metadata as "Bearer <token>". user_id defaults to the caller and is only
+// honored for admin tokens.
+type FavoritesServiceClient interface {
+	// ListFavorites lists the user's favorites
+	ListFavorites(ctx context.Context, in *ListFavoritesRequest, opts ...grpc.CallOption) (*ListFavoritesResponse, error)
+	// AddFavorite adds a catalog asset to the user's favorites
+	AddFavorite(ctx context.Context, in *AddFavoriteRequest, opts ...grpc.CallOption) (*AddFavoriteResponse, error)
+	// RemoveFavorite moves a favorite to the trash
+	RemoveFavorite(ctx context.Context, in *RemoveFavoriteRequest, opts ...grpc.CallOption) (*RemoveFavoriteResponse, error)
+	// EditFavoriteDescription changes a favorite's description
+	EditFavoriteDescription(ctx context.Context, in *EditFavoriteDescriptionRequest, opts ...grpc.CallOption) (*EditFavoriteDescriptionResponse, error)
+}
+
+type favoritesServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewFavoritesServiceClient(cc grpc.ClientConnInterface) FavoritesServiceClient {
+	return &favoritesServiceClient{cc}
+}
+
+func (c *favoritesServiceClient) ListFavorites(ctx context.Context, in *ListFavoritesRequest, opts ...grpc.CallOption) (*ListFavoritesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListFavoritesResponse)
+	err := c.cc.Invoke(ctx, FavoritesService_ListFavorites_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *favoritesServiceClient) AddFavorite(ctx context.Context, in *AddFavoriteRequest, opts ...grpc.CallOption) (*AddFavoriteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AddFavoriteResponse)
+	err := c.cc.Invoke(ctx, FavoritesService_AddFavorite_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *favoritesServiceClient) RemoveFavorite(ctx context.Context, in *RemoveFavoriteRequest, opts ...grpc.CallOption) (*RemoveFavoriteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RemoveFavoriteResponse)
+	err := c.cc.Invoke(ctx, FavoritesService_RemoveFavorite_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *favoritesServiceClient) EditFavoriteDescription(ctx context.Context, in *EditFavoriteDescriptionRequest, opts ...grpc.CallOption) (*EditFavoriteDescriptionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EditFavoriteDescriptionResponse)
+	err := c.cc.Invoke(ctx, FavoritesService_EditFavoriteDescription_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// FavoritesServiceServer is the server API for FavoritesService service.
+// All implementations must embed UnimplementedFavoritesServiceServer
+// for forward compatibility.
+//
+// FavoritesService mirrors the favorites endpoints of the REST API. Calls carry a JWT in the
+// authorization metadata as "Bearer <token>". user_id defaults to the caller and is only
+// honored for admin tokens.
+type FavoritesServiceServer interface {
+	// ListFavorites lists the user's favorites
+	ListFavorites(context.Context, *ListFavoritesRequest) (*ListFavoritesResponse, error)
+	// AddFavorite adds a catalog asset to the user's favorites
+	AddFavorite(context.Context, *AddFavoriteRequest) (*AddFavoriteResponse, error)
+	// RemoveFavorite moves a favorite to the trash
+	RemoveFavorite(context.Context, *RemoveFavoriteRequest) (*RemoveFavoriteResponse, error)
+	// EditFavoriteDescription changes a favorite's description
+	EditFavoriteDescription(context.Context, *EditFavoriteDescriptionRequest) (*EditFavoriteDescriptionResponse, error)
+	mustEmbedUnimplementedFavoritesServiceServer()
+}
+
+// UnimplementedFavoritesServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedFavoritesServiceServer struct{}
+
+func (UnimplementedFavoritesServiceServer) ListFavorites(context.Context, *ListFavoritesRequest) (*ListFavoritesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListFavorites not implemented")
+}
+func (UnimplementedFavoritesServiceServer) AddFavorite(context.Context, *AddFavoriteRequest) (*AddFavoriteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddFavorite not implemented")
+}
+func (UnimplementedFavoritesServiceServer) RemoveFavorite(context.Context, *RemoveFavoriteRequest) (*RemoveFavoriteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveFavorite not implemented")
+}
+func (UnimplementedFavoritesServiceServer) EditFavoriteDescription(context.Context, *EditFavoriteDescriptionRequest) (*EditFavoriteDescriptionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EditFavoriteDescription not implemented")
+}
+func (UnimplementedFavoritesServiceServer) mustEmbedUnimplementedFavoritesServiceServer() {}
+func (UnimplementedFavoritesServiceServer) testEmbeddedByValue()                          {}
+
+// UnsafeFavoritesServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to FavoritesServiceServer will
+// result in compilation errors.
+type UnsafeFavoritesServiceServer interface {
+	mustEmbedUnimplementedFavoritesServiceServer()
+}
+
+func RegisterFavoritesServiceServer(s grpc.ServiceRegistrar, srv FavoritesServiceServer) {
+	// If the following call pancis, it indicates UnimplementedFavoritesServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&FavoritesService_ServiceDesc, srv)
+}
+
+func _FavoritesService_ListFavorites_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListFavoritesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FavoritesServiceServer).ListFavorites(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FavoritesService_ListFavorites_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FavoritesServiceServer).ListFavorites(ctx, req.(*ListFavoritesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FavoritesService_AddFavorite_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddFavoriteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FavoritesServiceServer).AddFavorite(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FavoritesService_AddFavorite_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FavoritesServiceServer).AddFavorite(ctx, req.(*AddFavoriteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FavoritesService_RemoveFavorite_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveFavoriteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FavoritesServiceServer).RemoveFavorite(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FavoritesService_RemoveFavorite_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FavoritesServiceServer).RemoveFavorite(ctx, req.(*RemoveFavoriteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FavoritesService_EditFavoriteDescription_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EditFavoriteDescriptionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FavoritesServiceServer).EditFavoriteDescription(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FavoritesService_EditFavoriteDescription_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FavoritesServiceServer).EditFavoriteDescription(ctx, req.(*EditFavoriteDescriptionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// FavoritesService_ServiceDesc is the grpc.ServiceDesc for FavoritesService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var FavoritesService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "favorites.v1.FavoritesService",
+	HandlerType: (*FavoritesServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListFavorites",
+			Handler:    _FavoritesService_ListFavorites_Handler,
+		},
+		{
+			MethodName: "AddFavorite",
+			Handler:    _FavoritesService_AddFavorite_Handler,
+		},
+		{
+			MethodName: "RemoveFavorite",
+			Handler:    _FavoritesService_RemoveFavorite_Handler,
+		},
+		{
+			MethodName: "EditFavoriteDescription",
+			Handler:    _FavoritesService_EditFavoriteDescription_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "favorites/v1/favorites.proto",
+}
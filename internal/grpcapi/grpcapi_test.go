@@ -0,0 +1,244 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"net"
+	"slices"
+	"testing"
+
+	pb "github.com/gitvam/platform-go-challenge/internal/grpcapi/favoritesv1"
+	"github.com/gitvam/platform-go-challenge/internal/models"
+	"github.com/gitvam/platform-go-challenge/internal/store"
+	"github.com/gitvam/platform-go-challenge/internal/store/storetest"
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// dial serves s over an in-process connection and returns a connection to it
+func dial(t *testing.T, s store.Store) *grpc.ClientConn {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := NewServer(s)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// withToken returns a context carrying a bearer token signed with claims
+func withToken(claims jwt.MapClaims) context.Context {
+	token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("my_super_secret"))
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+func TestAuthentication(t *testing.T) {
+	client := pb.NewFavoritesServiceClient(dial(t, storetest.New()))
+	for name, ctx := range map[string]context.Context{
+		"no token":  context.Background(),
+		"bad token": metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer nope"),
+		"no bearer": metadata.AppendToOutgoingContext(context.Background(), "authorization", "nope"),
+	} {
+		if _, err := client.ListFavorites(ctx, &pb.ListFavoritesRequest{}); status.Code(err) != codes.Unauthenticated {
+			t.Errorf("%s: expected Unauthenticated, got %v", name, err)
+		}
+	}
+}
+
+func TestListFavorites_ReturnsEveryKind(t *testing.T) {
+	s := storetest.New()
+	client := pb.NewFavoritesServiceClient(dial(t, s))
+	ctx := metadata.AppendToOutgoingContext(withToken(jwt.MapClaims{"sub": storetest.CallerID, "org": "acme"}), "x-request-id", "req-1")
+	var header metadata.MD
+	resp, err := client.ListFavorites(ctx, &pb.ListFavoritesRequest{
+		UserId: storetest.OtherID, Tags: []string{" KPI "}, Sort: pb.SortOrder_SORT_ORDER_MANUAL, ExpandMembers: true,
+	}, grpc.Header(&header))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Users[0] != storetest.CallerID || s.Tenant != "acme" || s.Actor != storetest.CallerID || s.RequestID != "req-1" {
+		t.Errorf("expected user_id to be ignored for non-admins and the call scoped to the caller, got user %s tenant %s actor %s request %s",
+			s.Users[0], s.Tenant, s.Actor, s.RequestID)
+	}
+	if got := header.Get("x-request-id"); len(got) != 1 || got[0] != "req-1" {
+		t.Errorf("expected the request ID to be echoed, got %v", got)
+	}
+	opts := s.Lists[0]
+	if opts.Limit != defaultLimit || !opts.ManualOrder || !opts.ExpandMembers || !slices.Equal(opts.Tags, []string{"kpi"}) {
+		t.Errorf("unexpected list options %+v", opts)
+	}
+
+	favorites := resp.GetFavorites()
+	if len(favorites) != 5 {
+		t.Fatalf("expected 5 favorites, got %d", len(favorites))
+	}
+	if c := favorites[0].GetChart(); c.GetTitle() != "Reach" || c.GetKind() != "bar" || favorites[0].GetVersion() != 2 {
+		t.Errorf("unexpected chart %v", favorites[0])
+	}
+	if i := favorites[1].GetInsight(); i.GetText() != "t" || !slices.Equal(favorites[1].GetTags(), []string{"kpi"}) {
+		t.Errorf("unexpected insight %v", favorites[1])
+	}
+	a := favorites[2].GetAudience()
+	if and := a.GetCriteria().GetAnd(); a.GetSummary() == "" || len(and) == 0 || and[0].GetValue().GetStringValue() != "female" {
+		t.Errorf("expected the audience's effective criteria and summary, got %v", a)
+	}
+	members := favorites[3].GetDashboard().GetMembers()
+	if len(members) != 2 || members[0].GetType() != pb.AssetType_ASSET_TYPE_INSIGHT || members[0].GetAsset().GetInsight().GetText() != "member i1" {
+		t.Errorf("unexpected dashboard members %v", members)
+	}
+}
+
+func TestAdminUserID(t *testing.T) {
+	s := storetest.New()
+	client := pb.NewFavoritesServiceClient(dial(t, s))
+	ctx := withToken(jwt.MapClaims{"sub": storetest.CallerID, "role": "admin"})
+	if _, err := client.ListFavorites(ctx, &pb.ListFavoritesRequest{UserId: storetest.OtherID}); err != nil || s.Users[0] != storetest.OtherID {
+		t.Errorf("expected admins to list another user's favorites, listed %v (%v)", s.Users, err)
+	}
+	if _, err := client.ListFavorites(ctx, &pb.ListFavoritesRequest{UserId: "not-a-uuid"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for a user_id that is not a UUID, got %v", err)
+	}
+}
+
+func TestAddFavorite(t *testing.T) {
+	s := storetest.New()
+	client := pb.NewFavoritesServiceClient(dial(t, s))
+	ctx := withToken(jwt.MapClaims{"sub": storetest.CallerID})
+
+	resp, err := client.AddFavorite(ctx, &pb.AddFavoriteRequest{Asset: &pb.Asset{
+		ExternalId: "i9",
+		Tags:       []string{"kpi"},
+		Kind:       &pb.Asset_Insight{Insight: &pb.Insight{Text: "Sales are up"}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	insight, ok := s.Added[0].(*models.Insight)
+	if !ok || insight.ExternalID != "i9" || insight.Text != "Sales are up" || insight.Type != "insight" || !slices.Equal(insight.Tags, []string{"kpi"}) {
+		t.Errorf("unexpected asset added %+v", s.Added[0])
+	}
+	if resp.GetFavorite().GetInsight().GetText() != "Sales are up" {
+		t.Errorf("unexpected response %v", resp)
+	}
+
+	_, err = client.AddFavorite(ctx, &pb.AddFavoriteRequest{Asset: &pb.Asset{ExternalId: "i9"}})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for an asset without a kind, got %v", err)
+	}
+
+	_, err = client.AddFavorite(ctx, &pb.AddFavoriteRequest{Asset: &pb.Asset{
+		Kind: &pb.Asset_Audience{Audience: &pb.Audience{Gender: "robot"}},
+	}})
+	st := status.Convert(err)
+	if st.Code() != codes.InvalidArgument || len(st.Details()) != 1 {
+		t.Fatalf("expected InvalidArgument with field violations, got %v", err)
+	}
+	var fields []string
+	for _, v := range st.Details()[0].(*errdetails.BadRequest).GetFieldViolations() {
+		fields = append(fields, v.GetField())
+	}
+	if !slices.Contains(fields, "external_id") || !slices.Contains(fields, "gender") {
+		t.Errorf("expected external_id and gender violations, got %v", fields)
+	}
+}
+
+func TestEditFavoriteDescription_Conflict(t *testing.T) {
+	s := storetest.New()
+	client := pb.NewFavoritesServiceClient(dial(t, s))
+	ctx := withToken(jwt.MapClaims{"sub": storetest.CallerID})
+	req := &pb.EditFavoriteDescriptionRequest{Type: pb.AssetType_ASSET_TYPE_CHART, ExternalId: "c1", Description: "d", ExpectedVersion: 2}
+	if resp, err := client.EditFavoriteDescription(ctx, req); err != nil || resp.GetVersion() != 3 {
+		t.Errorf("expected version 3, got %v (%v)", resp, err)
+	}
+	s.Err = store.ErrVersionConflict
+	if _, err := client.EditFavoriteDescription(ctx, req); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition, got %v", err)
+	}
+	s.Err = errors.New("connection refused")
+	if _, err := client.EditFavoriteDescription(ctx, req); status.Code(err) != codes.Internal || status.Convert(err).Message() != "internal server error" {
+		t.Errorf("expected an Internal error without details, got %v", err)
+	}
+}
+
+func TestCatalog_ListAssets(t *testing.T) {
+	s := storetest.New()
+	client := pb.NewCatalogServiceClient(dial(t, s))
+	resp, err := client.ListAssets(withToken(jwt.MapClaims{"sub": storetest.CallerID}), &pb.ListAssetsRequest{Type: pb.AssetType_ASSET_TYPE_CHART, Limit: 2, Offset: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.GetAssets()) != 2 || s.Lists[0].Limit != 2 || s.Lists[0].Offset != 1 {
+		t.Errorf("unexpected assets %v with options %+v", resp.GetAssets(), s.Lists[0])
+	}
+}
+
+func TestPaging_RejectsOutOfRange(t *testing.T) {
+	s := storetest.New()
+	conn := dial(t, s)
+	favorites, catalog := pb.NewFavoritesServiceClient(conn), pb.NewCatalogServiceClient(conn)
+	ctx := withToken(jwt.MapClaims{"sub": storetest.CallerID})
+	for name, page := range map[string][2]int32{
+		"negative limit":  {-1, 0},
+		"limit over max":  {maxLimit + 1, 0},
+		"negative offset": {10, -1},
+	} {
+		_, err := favorites.ListFavorites(ctx, &pb.ListFavoritesRequest{Limit: page[0], Offset: page[1]})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("ListFavorites with %s: expected InvalidArgument, got %v", name, err)
+		}
+		_, err = catalog.ListAssets(ctx, &pb.ListAssetsRequest{Type: pb.AssetType_ASSET_TYPE_CHART, Limit: page[0], Offset: page[1]})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("ListAssets with %s: expected InvalidArgument, got %v", name, err)
+		}
+	}
+	if len(s.Lists) != 0 {
+		t.Errorf("expected nothing to be listed, got %+v", s.Lists)
+	}
+	if _, err := favorites.ListFavorites(ctx, &pb.ListFavoritesRequest{Limit: maxLimit}); err != nil || s.Lists[0].Limit != maxLimit {
+		t.Errorf("expected the largest page to be allowed, got %v", err)
+	}
+}
+
+func TestReflection_ListsServices(t *testing.T) {
+	stream, err := reflectionpb.NewServerReflectionClient(dial(t, storetest.New())).ServerReflectionInfo(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.Send(&reflectionpb.ServerReflectionRequest{MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{}}); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var services []string
+	for _, s := range resp.GetListServicesResponse().GetService() {
+		services = append(services, s.GetName())
+	}
+	for _, want := range []string{"favorites.v1.FavoritesService", "favorites.v1.CatalogService"} {
+		if !slices.Contains(services, want) {
+			t.Errorf("expected %s to be listed, got %v", want, services)
+		}
+	}
+}
+
+func TestAssetType_RoundTrip(t *testing.T) {
+	for _, spec := range models.Types() {
+		if got := assetType(typeEnum(spec.Type)); got != spec.Type {
+			t.Errorf("%s has no AssetType value, got %q back", spec.Type, got)
+		}
+	}
+}
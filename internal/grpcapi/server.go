@@ -0,0 +1,100 @@
+// Package grpcapi serves the favorites and catalog gRPC services defined in proto/favorites/v1,
+// on the same store and JWT authentication as the REST API.
+package grpcapi
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	pb "github.com/gitvam/platform-go-challenge/internal/grpcapi/favoritesv1"
+	"github.com/gitvam/platform-go-challenge/internal/middleware"
+	"github.com/gitvam/platform-go-challenge/internal/models"
+	"github.com/gitvam/platform-go-challenge/internal/store"
+	"github.com/gitvam/platform-go-challenge/internal/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// NewServer returns a gRPC server with the favorites and catalog services and server
+// reflection. Calls to the services must carry a JWT as "authorization: Bearer <token>";
+// reflection is open, like the Swagger docs of the REST API.
+func NewServer(s store.Store, opts ...grpc.ServerOption) *grpc.Server {
+	srv := grpc.NewServer(append(opts, grpc.ChainUnaryInterceptor(logging, authenticate))...)
+	pb.RegisterFavoritesServiceServer(srv, &favoritesServer{store: s})
+	pb.RegisterCatalogServiceServer(srv, &catalogServer{store: s})
+	reflection.Register(srv)
+	return srv
+}
+
+// requestIDKey is the metadata key of the request ID, as in the REST API's header
+var requestIDKey = strings.ToLower(utils.RequestIDHeader)
+
+// caller is who a call is made by
+type caller struct {
+	middleware.Identity
+	requestID string
+}
+
+type callerKey struct{}
+
+// logging logs every call like the REST API's logging middleware
+func logging(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	addr := ""
+	if p, ok := peer.FromContext(ctx); ok {
+		addr = p.Addr.String()
+	}
+	log.Printf("%s gRPC %s %s %v", addr, info.FullMethod, status.Code(err), time.Since(start))
+	return resp, err
+}
+
+// authenticate verifies the bearer token of a call and tags it with a request ID, reusing
+// the client's x-request-id when present and echoing it in the response header
+func authenticate(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	c := caller{requestID: middleware.RequestIDOrNew(first(md.Get(requestIDKey)))}
+	grpc.SetHeader(ctx, metadata.Pairs(requestIDKey, c.requestID))
+
+	auth := first(md.Get("authorization"))
+	if !strings.HasPrefix(auth, "Bearer ") {
+		return nil, status.Error(codes.Unauthenticated, "invalid or missing token")
+	}
+	id, err := middleware.ParseToken(strings.TrimPrefix(auth, "Bearer "))
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	c.Identity = id
+	return handler(context.WithValue(ctx, callerKey{}, c), req)
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// callerFor returns the store limited to the caller's tenant and auditing them as the actor,
+// and the user a request is for: user_id for admins and the caller themselves otherwise, as
+// with the {userID} path of the REST API
+func callerFor(ctx context.Context, s store.Store, userID string) (store.Store, string, error) {
+	c, ok := ctx.Value(callerKey{}).(caller)
+	if !ok {
+		return nil, "", status.Error(codes.Unauthenticated, "caller missing from context")
+	}
+	scoped := s.ForTenant(c.TenantID).AuditAs(c.UserID, c.requestID)
+	if userID != "" && userID != c.UserID && c.Admin {
+		if !models.IsUUID(userID) {
+			return nil, "", status.Error(codes.InvalidArgument, "user_id must be a UUID")
+		}
+		return scoped, userID, nil
+	}
+	return scoped, c.UserID, nil
+}
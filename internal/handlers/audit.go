@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gitvam/platform-go-challenge/internal/models"
+	"github.com/gitvam/platform-go-challenge/internal/store"
+	"github.com/gitvam/platform-go-challenge/internal/utils"
+)
+
+var auditEntityTypes = []string{models.AuditEntityFavorite, models.AuditEntityCollection, models.AuditEntityShare, models.AuditEntityWebhook}
+
+// FavoriteHistory godoc
+// @Summary      List a user's favorite history
+// @Description  Get the audit events of the user's favorites, newest first: who added, removed, restored, re-described,
+// @Description  tagged, pinned or moved each favorite and when, with its state before and after the change.
+// @Description  Pass type and asset_id to see the history of one favorite.
+// @Tags         audit
+// @Param        userID path string true "User ID"
+// @Param        type query string false "Asset Type (chart, insight, audience, dashboard); required with asset_id"
+// @Param        asset_id query string false "Asset External ID"
+// @Param        limit query int false "Page size" default(10)
+// @Param        offset query int false "Page offset" default(0)
+// @Param        If-None-Match header string false "ETag from a previous response; returns 304 if unchanged"
+// @Success      200 {object} utils.SuccessResponse{data=[]models.AuditEvent}
+// @Success      304 "Not Modified"
+// @Header       200 {string} ETag "Weak ETag of the response body"
+// @Failure      400 {object} utils.Problem
+// @Failure      401 {object} utils.Problem
+// @Router       /v1/users/{userID}/favorites/history [get]
+func (h *Handler) FavoriteHistory(w http.ResponseWriter, r *http.Request) {
+	userID, ok := getUserIDOrAbort(w, r)
+	if !ok {
+		return
+	}
+	filter := store.AuditFilter{
+		UserID:     userID,
+		EntityType: models.AuditEntityFavorite,
+		Limit:      utils.ParseQueryInt(r, "limit", 10),
+		Offset:     utils.ParseQueryInt(r, "offset", 0),
+	}
+	if assetID := r.URL.Query().Get("asset_id"); assetID != "" {
+		assetType := r.URL.Query().Get("type")
+		if _, ok := models.Lookup(models.AssetType(assetType)); !ok {
+			utils.WriteProblem(w, http.StatusBadRequest, utils.CodeInvalidAssetType, fmt.Sprintf("invalid asset type %q", assetType))
+			return
+		}
+		filter.EntityID = models.FavoriteEntityID(assetType, assetID)
+	}
+	h.writeAuditEvents(w, r, filter)
+}
+
+// ListAuditEvents godoc
+// @Summary      Query the audit trail
+// @Description  Admin only. Get the tenant's audit events for favorites, collections and shares, newest first.
+// @Description  Every filter is optional; since is inclusive and until exclusive.
+// @Tags         audit
+// @Param        user_id query string false "Owner of the changed data"
+// @Param        actor_id query string false "User who made the change"
+// @Param        action query string false "Action, e.g. favorite.described"
+// @Param        entity_type query string false "Entity type" Enums(favorite, collection, share, webhook)
+// @Param        entity_id query string false "Entity ID; type:external_id for favorites"
+// @Param        since query string false "RFC 3339 time"
+// @Param        until query string false "RFC 3339 time"
+// @Param        limit query int false "Page size" default(10)
+// @Param        offset query int false "Page offset" default(0)
+// @Param        If-None-Match header string false "ETag from a previous response; returns 304 if unchanged"
+// @Success      200 {object} utils.SuccessResponse{data=[]models.AuditEvent}
+// @Success      304 "Not Modified"
+// @Header       200 {string} ETag "Weak ETag of the response body"
+// @Failure      400 {object} utils.Problem
+// @Failure      401 {object} utils.Problem
+// @Failure      403 {object} utils.Problem "Token lacks the admin role"
+// @Router       /v1/admin/audit-events [get]
+func (h *Handler) ListAuditEvents(w http.ResponseWriter, r *http.Request) {
+	if _, ok := getUserIDOrAbort(w, r); !ok {
+		return
+	}
+	q := r.URL.Query()
+	filter := store.AuditFilter{
+		UserID:     q.Get("user_id"),
+		ActorID:    q.Get("actor_id"),
+		Action:     q.Get("action"),
+		EntityType: q.Get("entity_type"),
+		EntityID:   q.Get("entity_id"),
+		Limit:      utils.ParseQueryInt(r, "limit", 10),
+		Offset:     utils.ParseQueryInt(r, "offset", 0),
+	}
+	var v models.Validator
+	if filter.UserID != "" && !models.IsUUID(filter.UserID) {
+		v.Add("user_id", "must be a UUID")
+	}
+	if filter.ActorID != "" && !models.IsUUID(filter.ActorID) {
+		v.Add("actor_id", "must be a UUID")
+	}
+	v.OneOf("entity_type", filter.EntityType, auditEntityTypes)
+	filter.Since = parseQueryTime(&v, q.Get("since"), "since")
+	filter.Until = parseQueryTime(&v, q.Get("until"), "until")
+	if err := v.Err(); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	h.writeAuditEvents(w, r, filter)
+}
+
+// parseQueryTime parses an optional RFC 3339 query value, recording a field error if malformed
+func parseQueryTime(v *models.Validator, raw, field string) time.Time {
+	if raw == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		v.Add(field, "must be an RFC 3339 time")
+	}
+	return t
+}
+
+func (h *Handler) writeAuditEvents(w http.ResponseWriter, r *http.Request, filter store.AuditFilter) {
+	events, err := h.storeFor(r).ListAuditEvents(filter)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	utils.WriteWithETag(w, r, http.StatusOK, utils.SuccessResponse{
+		Status: "success",
+		Data:   events,
+	})
+}
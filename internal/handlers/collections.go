@@ -0,0 +1,332 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gitvam/platform-go-challenge/internal/models"
+	"github.com/gitvam/platform-go-challenge/internal/store"
+	"github.com/gitvam/platform-go-challenge/internal/utils"
+	"github.com/go-chi/chi/v5"
+)
+
+// ListCollections godoc
+// @Summary      List a user's collections
+// @Description  Get the user's collections in their saved order, with the number of favorites in each.
+// @Tags         collections
+// @Param        userID path string true "User ID"
+// @Param        If-None-Match header string false "ETag from a previous response; returns 304 if unchanged"
+// @Success      200 {object} utils.SuccessResponse{data=[]models.Collection}
+// @Success      304 "Not Modified"
+// @Header       200 {string} ETag "Weak ETag of the response body"
+// @Failure      401 {object} utils.Problem
+// @Router       /v1/users/{userID}/collections [get]
+func (h *Handler) ListCollections(w http.ResponseWriter, r *http.Request) {
+	userID, ok := getUserIDOrAbort(w, r)
+	if !ok {
+		return
+	}
+	collections, err := h.storeFor(r).ListCollections(userID)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	utils.WriteWithETag(w, r, http.StatusOK, utils.SuccessResponse{
+		Status: "success",
+		Data:   collections,
+	})
+}
+
+// CreateCollection godoc
+// @Summary      Create a collection
+// @Description  Create a named collection of favorites, placed after the user's existing collections.
+// @Tags         collections
+// @Param        userID path string true "User ID"
+// @Param        body body handlers.CollectionRequest true "Name and optional description"
+// @Param        Idempotency-Key header string false "Key that makes retries of this request safe"
+// @Success      201 {object} utils.SuccessResponse{data=models.Collection}
+// @Header       201 {string} Location "URL of the new collection"
+// @Header       201 {string} ETag "Version of the new collection"
+// @Failure      400 {object} utils.Problem
+// @Failure      401 {object} utils.Problem
+// @Failure      409 {object} utils.Problem "A collection with this name already exists"
+// @Failure      413 {object} utils.Problem "Request body too large"
+// @Failure      422 {object} utils.Problem "Idempotency-Key reused with a different request"
+// @Router       /v1/users/{userID}/collections [post]
+func (h *Handler) CreateCollection(w http.ResponseWriter, r *http.Request) {
+	userID, ok := getUserIDOrAbort(w, r)
+	if !ok {
+		return
+	}
+	req, ok := decodeCollectionUpdate(w, r)
+	if !ok {
+		return
+	}
+	if req.Name == nil {
+		writeDecodeError(w, &models.DecodeError{Field: "name", Msg: "is required"})
+		return
+	}
+	if req.Position != nil {
+		writeDecodeError(w, &models.DecodeError{Field: "position", Msg: "can only be changed with PATCH"})
+		return
+	}
+	c := &models.Collection{Name: *req.Name}
+	if req.Description != nil {
+		c.Description = *req.Description
+	}
+	if err := h.storeFor(r).CreateCollection(userID, c); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/v1/users/%s/collections/%d", chi.URLParam(r, "userID"), c.ID))
+	w.Header().Set("ETag", utils.VersionETag(c.Version))
+	utils.Write(w, r, http.StatusCreated, utils.SuccessResponse{Status: "success", Data: c})
+}
+
+// GetCollection godoc
+// @Summary      Get a collection
+// @Tags         collections
+// @Param        userID path string true "User ID"
+// @Param        collectionID path int true "Collection ID"
+// @Success      200 {object} utils.SuccessResponse{data=models.Collection}
+// @Header       200 {string} ETag "Version of the collection"
+// @Failure      401 {object} utils.Problem
+// @Failure      404 {object} utils.Problem
+// @Router       /v1/users/{userID}/collections/{collectionID} [get]
+func (h *Handler) GetCollection(w http.ResponseWriter, r *http.Request) {
+	userID, ok := getUserIDOrAbort(w, r)
+	if !ok {
+		return
+	}
+	collectionID, ok := parseCollectionID(w, r)
+	if !ok {
+		return
+	}
+	c, err := h.storeFor(r).GetCollection(userID, collectionID)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	w.Header().Set("ETag", utils.VersionETag(c.Version))
+	utils.Write(w, r, http.StatusOK, utils.SuccessResponse{Status: "success", Data: c})
+}
+
+// UpdateCollection godoc
+// @Summary      Update a collection
+// @Description  Rename, re-describe or move a collection; fields left out are unchanged. Moving to a position
+// @Description  shifts the collections in between. Send the collection's ETag in If-Match to make the update conditional.
+// @Tags         collections
+// @Param        userID path string true "User ID"
+// @Param        collectionID path int true "Collection ID"
+// @Param        body body handlers.CollectionRequest true "Fields to change"
+// @Param        If-Match header string false "ETag of the collection being updated"
+// @Param        Idempotency-Key header string false "Key that makes retries of this request safe"
+// @Success      200 {object} utils.SuccessResponse{data=models.Collection}
+// @Header       200 {string} ETag "New version of the collection"
+// @Failure      400 {object} utils.Problem
+// @Failure      401 {object} utils.Problem
+// @Failure      404 {object} utils.Problem
+// @Failure      409 {object} utils.Problem "A collection with this name already exists"
+// @Failure      412 {object} utils.Problem "If-Match does not match the current version"
+// @Failure      413 {object} utils.Problem "Request body too large"
+// @Failure      422 {object} utils.Problem "Idempotency-Key reused with a different request"
+// @Router       /v1/users/{userID}/collections/{collectionID} [patch]
+func (h *Handler) UpdateCollection(w http.ResponseWriter, r *http.Request) {
+	userID, ok := getUserIDOrAbort(w, r)
+	if !ok {
+		return
+	}
+	collectionID, ok := parseCollectionID(w, r)
+	if !ok {
+		return
+	}
+	expectedVersion, ok := parseIfMatch(w, r)
+	if !ok {
+		return
+	}
+	req, ok := decodeCollectionUpdate(w, r)
+	if !ok {
+		return
+	}
+	if req.Empty() {
+		writeDecodeError(w, &models.DecodeError{Msg: "set at least one of name, description or position"})
+		return
+	}
+	c, err := h.storeFor(r).UpdateCollection(userID, collectionID, *req, expectedVersion)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	w.Header().Set("ETag", utils.VersionETag(c.Version))
+	utils.Write(w, r, http.StatusOK, utils.SuccessResponse{Status: "success", Data: c})
+}
+
+// DeleteCollection godoc
+// @Summary      Delete a collection
+// @Description  Delete a collection. The favorites in it are kept.
+// @Tags         collections
+// @Param        userID path string true "User ID"
+// @Param        collectionID path int true "Collection ID"
+// @Param        Idempotency-Key header string false "Key that makes retries of this request safe"
+// @Success      204 "No Content"
+// @Failure      401 {object} utils.Problem
+// @Failure      404 {object} utils.Problem
+// @Failure      422 {object} utils.Problem "Idempotency-Key reused with a different request"
+// @Router       /v1/users/{userID}/collections/{collectionID} [delete]
+func (h *Handler) DeleteCollection(w http.ResponseWriter, r *http.Request) {
+	userID, ok := getUserIDOrAbort(w, r)
+	if !ok {
+		return
+	}
+	collectionID, ok := parseCollectionID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.storeFor(r).DeleteCollection(userID, collectionID); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListCollectionFavorites godoc
+// @Summary      List the favorites in a collection
+// @Description  Get the favorites in a collection, paged and expanded like the user's full list of favorites.
+// @Tags         collections
+// @Param        userID path string true "User ID"
+// @Param        collectionID path int true "Collection ID"
+// @Param        limit query int false "Page size" default(10) minimum(1) maximum(100)
+// @Param        offset query int false "Page offset" default(0) minimum(0)
+// @Param        expand query string false "Comma-separated expansions; supports: members"
+// @Param        tag query string false "Comma-separated tags to filter by"
+// @Param        tag_match query string false "Whether favorites need any or all of the tags" Enums(any, all) default(any)
+// @Param        sort query string false "type groups favorites by type; manual lists pinned favorites first, then the user's own order" Enums(type, manual) default(type)
+// @Param        fields query string false "Comma-separated fields to return for each favorite; type and external_id are always included"
+// @Param        If-None-Match header string false "ETag from a previous response; returns 304 if unchanged"
+// @Success      200 {object} utils.SuccessResponse
+// @Success      304 "Not Modified"
+// @Header       200 {string} ETag "Weak ETag of the response body"
+// @Failure      400 {object} utils.Problem
+// @Failure      401 {object} utils.Problem
+// @Failure      404 {object} utils.Problem
+// @Router       /v1/users/{userID}/collections/{collectionID}/favorites [get]
+func (h *Handler) ListCollectionFavorites(w http.ResponseWriter, r *http.Request) {
+	userID, ok := getUserIDOrAbort(w, r)
+	if !ok {
+		return
+	}
+	collectionID, ok := parseCollectionID(w, r)
+	if !ok {
+		return
+	}
+	opts, ok := parseListOptions(w, r)
+	if !ok {
+		return
+	}
+	if opts.Fields, ok = parseFields(w, r); !ok {
+		return
+	}
+	favorites, err := h.storeFor(r).ListCollectionFavorites(userID, collectionID, opts)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	utils.WriteWithETag(w, r, http.StatusOK, utils.SuccessResponse{
+		Status: "success",
+		Data:   favoritesData(favorites, opts.Fields),
+	})
+}
+
+// AddToCollection godoc
+// @Summary      Add a favorite to a collection
+// @Description  Add one of the user's favorites to a collection. Adding a favorite that is already there does nothing.
+// @Tags         collections
+// @Param        userID path string true "User ID"
+// @Param        collectionID path int true "Collection ID"
+// @Param        assetID path string true "Asset External ID"
+// @Param        type query string true "Asset Type (chart, insight, audience, dashboard)"
+// @Param        Idempotency-Key header string false "Key that makes retries of this request safe"
+// @Success      204 "No Content"
+// @Failure      400 {object} utils.Problem
+// @Failure      401 {object} utils.Problem
+// @Failure      404 {object} utils.Problem "Collection or favorite not found"
+// @Failure      422 {object} utils.Problem "Idempotency-Key reused with a different request"
+// @Router       /v1/users/{userID}/collections/{collectionID}/favorites/{assetID} [put]
+func (h *Handler) AddToCollection(w http.ResponseWriter, r *http.Request) {
+	h.changeCollectionMembership(w, r, store.Store.AddToCollection)
+}
+
+// RemoveFromCollection godoc
+// @Summary      Remove a favorite from a collection
+// @Description  Remove a favorite from a collection. The favorite itself is kept.
+// @Tags         collections
+// @Param        userID path string true "User ID"
+// @Param        collectionID path int true "Collection ID"
+// @Param        assetID path string true "Asset External ID"
+// @Param        type query string true "Asset Type (chart, insight, audience, dashboard)"
+// @Param        Idempotency-Key header string false "Key that makes retries of this request safe"
+// @Success      204 "No Content"
+// @Failure      400 {object} utils.Problem
+// @Failure      401 {object} utils.Problem
+// @Failure      404 {object} utils.Problem "Collection not found or favorite not in it"
+// @Failure      422 {object} utils.Problem "Idempotency-Key reused with a different request"
+// @Router       /v1/users/{userID}/collections/{collectionID}/favorites/{assetID} [delete]
+func (h *Handler) RemoveFromCollection(w http.ResponseWriter, r *http.Request) {
+	h.changeCollectionMembership(w, r, store.Store.RemoveFromCollection)
+}
+
+// changeCollectionMembership parses a collection favorite route and applies change to it
+func (h *Handler) changeCollectionMembership(w http.ResponseWriter, r *http.Request, change func(s store.Store, userID string, collectionID int, assetType, externalID string) error) {
+	userID, ok := getUserIDOrAbort(w, r)
+	if !ok {
+		return
+	}
+	collectionID, ok := parseCollectionID(w, r)
+	if !ok {
+		return
+	}
+	assetType := r.URL.Query().Get("type")
+	if assetType == "" {
+		utils.WriteProblem(w, http.StatusBadRequest, utils.CodeInvalidAssetType, "missing asset type")
+		return
+	}
+	if err := change(h.storeFor(r), userID, collectionID, assetType, chi.URLParam(r, "assetID")); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// parseCollectionID reads the collection ID path parameter; IDs that cannot exist are reported as 404
+func parseCollectionID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	raw := chi.URLParam(r, "collectionID")
+	id, err := strconv.Atoi(raw)
+	if err != nil || id <= 0 {
+		utils.WriteProblem(w, http.StatusNotFound, utils.CodeNotFound, fmt.Sprintf("collection %q: %v", raw, store.ErrNotFound))
+		return 0, false
+	}
+	return id, true
+}
+
+// decodeCollectionUpdate strictly decodes a collection create or update body
+func decodeCollectionUpdate(w http.ResponseWriter, r *http.Request) (*models.CollectionUpdate, bool) {
+	body, ok := readJSONBody(w, r)
+	if !ok {
+		return nil, false
+	}
+	var req models.CollectionUpdate
+	if err := models.DecodeStrict(body, &req); err != nil {
+		writeDecodeError(w, err)
+		return nil, false
+	}
+	return &req, true
+}
+
+// CollectionRequest is used in Swagger annotations
+type CollectionRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Position    int    `json:"position"`
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gitvam/platform-go-challenge/internal/models"
+	"github.com/gitvam/platform-go-challenge/internal/store"
+	"github.com/gitvam/platform-go-challenge/internal/utils"
+)
+
+// eventBatchSize is how many events are read from the log at a time while catching up
+const eventBatchSize = 100
+
+// FavoriteEvents godoc
+// @Summary      Stream a user's favorite changes
+// @Description  Server-Sent Events stream of the user's favorite.added, favorite.removed, favorite.updated and
+// @Description  favorite.restored events, each with the event ID as its id and the FavoriteEvent as its data.
+// @Description  Reconnecting with Last-Event-ID resumes after that event; when it has left the event log a
+// @Description  reset event is sent instead and the client should reload its favorites. Comments are sent as
+// @Description  heartbeats while nothing changes.
+// @Tags         favorites
+// @Produce      text/event-stream
+// @Param        userID path string true "User ID"
+// @Param        Last-Event-ID header string false "ID of the last event received"
+// @Success      200 {object} models.FavoriteEvent "Stream of events"
+// @Failure      400 {object} utils.Problem
+// @Failure      401 {object} utils.Problem
+// @Router       /v1/users/{userID}/favorites/events [get]
+func (h *Handler) FavoriteEvents(w http.ResponseWriter, r *http.Request) {
+	userID, ok := getUserIDOrAbort(w, r)
+	if !ok {
+		return
+	}
+	var lastID int64
+	if raw := r.Header.Get("Last-Event-ID"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || id < 0 {
+			utils.WriteProblem(w, http.StatusBadRequest, utils.CodeBadRequest, "Last-Event-ID must be an event ID")
+			return
+		}
+		lastID = id
+	}
+
+	s := h.storeFor(r)
+	// Subscribe before reading the log so no event falls between the two
+	sub, err := s.SubscribeFavoriteEvents(userID)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	defer sub.Close()
+	if lastID == 0 {
+		if lastID, err = s.LastFavoriteEventID(userID); err != nil {
+			writeStoreError(w, err)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	rc := http.NewResponseController(w)
+
+	heartbeat := time.NewTicker(h.Heartbeat)
+	defer heartbeat.Stop()
+	for {
+		if lastID, err = writeFavoriteEvents(w, s, userID, lastID); err != nil {
+			log.Printf("[ERROR] streaming favorite events: %v", err)
+			return
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+		select {
+		case <-r.Context().Done():
+			return
+		case <-sub.C:
+		case <-heartbeat.C:
+			if _, err := io.WriteString(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// writeFavoriteEvents writes every event after lastID and returns the ID of the last one written.
+// When the log no longer reaches back to lastID it writes a reset and continues from the latest event.
+func writeFavoriteEvents(w io.Writer, s store.Store, userID string, lastID int64) (int64, error) {
+	for {
+		events, err := s.ListFavoriteEvents(userID, lastID, eventBatchSize)
+		if errors.Is(err, store.ErrEventsExpired) {
+			if lastID, err = s.LastFavoriteEventID(userID); err != nil {
+				return lastID, err
+			}
+			_, err = fmt.Fprintf(w, "id: %d\nevent: reset\ndata: {}\n\n", lastID)
+			return lastID, err
+		}
+		if err != nil {
+			return lastID, err
+		}
+		for _, e := range events {
+			if err := writeFavoriteEvent(w, e); err != nil {
+				return lastID, err
+			}
+			lastID = e.ID
+		}
+		if len(events) < eventBatchSize {
+			return lastID, nil
+		}
+	}
+}
+
+func writeFavoriteEvent(w io.Writer, e models.FavoriteEvent) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+	return err
+}
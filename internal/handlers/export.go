@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gitvam/platform-go-challenge/internal/models"
+)
+
+var exportContentTypes = map[string]string{
+	models.FormatJSON:   "application/json",
+	models.FormatNDJSON: "application/x-ndjson",
+	models.FormatCSV:    "text/csv; charset=utf-8",
+}
+
+// ExportFavorites godoc
+// @Summary      Export all of a user's favorites
+// @Description  Download every favorite with its full asset, unpaged, as a JSON array, newline-delimited JSON or CSV.
+// @Description  The CSV has the type, external_id, description, tags and pinned columns, then each asset type's own
+// @Description  columns prefixed with the type (e.g. chart.title), left empty for favorites of other types. Lists
+// @Description  within a field are separated by ";" and nested values such as chart series are JSON. The export
+// @Description  is streamed from one snapshot; if it fails midway the connection is closed before the end.
+// @Tags         favorites
+// @Produce      json
+// @Produce      application/x-ndjson
+// @Produce      text/csv
+// @Param        userID path string true "User ID"
+// @Param        format query string false "Export format" Enums(json, ndjson, csv) default(json)
+// @Success      200 {array} models.Asset
+// @Failure      400 {object} utils.Problem
+// @Failure      401 {object} utils.Problem
+// @Router       /v1/users/{userID}/favorites/export [get]
+func (h *Handler) ExportFavorites(w http.ResponseWriter, r *http.Request) {
+	userID, ok := getUserIDOrAbort(w, r)
+	if !ok {
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = models.FormatJSON
+	}
+	var v models.Validator
+	v.OneOf("format", format, models.FileFormats)
+	if err := v.Err(); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	e := newExporter(w, format)
+	// The response starts with the first favorite, so errors before it get a problem response
+	start := func() {
+		if e.started {
+			return
+		}
+		e.started = true
+		w.Header().Set("Content-Type", exportContentTypes[format])
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="favorites.%s"`, format))
+		w.WriteHeader(http.StatusOK)
+	}
+	err := h.storeFor(r).ExportFavorites(userID, func(a models.Asset) error {
+		start()
+		return e.write(a)
+	})
+	if err == nil {
+		start()
+		err = e.finish()
+	}
+	if err != nil {
+		if !e.started {
+			writeStoreError(w, err)
+			return
+		}
+		// Aborting the connection tells the client the export is incomplete
+		log.Printf("[ERROR] exporting favorites: %v", err)
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// exporter writes favorites one at a time in an export format
+type exporter struct {
+	w       io.Writer
+	format  string
+	csv     *csv.Writer
+	count   int
+	started bool
+}
+
+func newExporter(w io.Writer, format string) *exporter {
+	e := &exporter{w: w, format: format}
+	if format == models.FormatCSV {
+		e.csv = csv.NewWriter(w)
+	}
+	return e
+}
+
+func (e *exporter) write(a models.Asset) error {
+	defer func() { e.count++ }()
+	switch e.format {
+	case models.FormatCSV:
+		if e.count == 0 {
+			if err := e.csv.Write(models.CSVHeader()); err != nil {
+				return err
+			}
+		}
+		record, err := models.CSVRecord(a)
+		if err != nil {
+			return err
+		}
+		return e.csv.Write(record)
+	case models.FormatNDJSON:
+		return json.NewEncoder(e.w).Encode(a)
+	default:
+		sep := ","
+		if e.count == 0 {
+			sep = "["
+		}
+		if _, err := io.WriteString(e.w, sep); err != nil {
+			return err
+		}
+		return json.NewEncoder(e.w).Encode(a)
+	}
+}
+
+// finish ends the export, which may have no favorites
+func (e *exporter) finish() error {
+	switch e.format {
+	case models.FormatCSV:
+		if e.count == 0 {
+			if err := e.csv.Write(models.CSVHeader()); err != nil {
+				return err
+			}
+		}
+		e.csv.Flush()
+		return e.csv.Error()
+	case models.FormatNDJSON:
+		return nil
+	default:
+		if e.count == 0 {
+			_, err := io.WriteString(e.w, "[]\n")
+			return err
+		}
+		_, err := io.WriteString(e.w, "]\n")
+		return err
+	}
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gitvam/platform-go-challenge/internal/graph"
+	"github.com/gitvam/platform-go-challenge/internal/middleware"
+	"github.com/gitvam/platform-go-challenge/internal/utils"
+)
+
+// GraphQLRequest is a GraphQL request, also used in Swagger annotations
+type GraphQLRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+}
+
+// GraphQLResponse is used in Swagger annotations
+type GraphQLResponse struct {
+	Data   any   `json:"data"`
+	Errors []any `json:"errors,omitempty"`
+}
+
+// GraphQL godoc
+// @Summary      Query and change favorites with GraphQL
+// @Description  Runs a GraphQL query or mutation against the schema in internal/graph/schema.graphql: favorites with
+// @Description  filters and cursor pagination, one favorite, and adding, removing and editing favorites. Assets are an
+// @Description  interface with an object type per asset type, so clients select only the fields they need. Errors
+// @Description  are returned with status 200 in the errors list, with the REST API's problem code in extensions.code.
+// @Description  Requests and responses are always JSON, whatever the Accept header.
+// @Tags         graphql
+// @Accept       json
+// @Produce      json
+// @Param        request body GraphQLRequest true "GraphQL request"
+// @Param        Idempotency-Key header string false "Key that makes retries of this request safe"
+// @Success      200 {object} GraphQLResponse
+// @Failure      400 {object} utils.Problem
+// @Failure      401 {object} utils.Problem
+// @Failure      413 {object} utils.Problem "Request body too large"
+// @Router       /graphql [post]
+func (h *Handler) GraphQL(w http.ResponseWriter, r *http.Request) {
+	userID, ok := getUserIDOrAbort(w, r)
+	if !ok {
+		return
+	}
+	body, ok := readBody(w, r)
+	if !ok {
+		return
+	}
+	var req GraphQLRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		utils.WriteProblem(w, http.StatusBadRequest, utils.CodeInvalidJSON, err.Error())
+		return
+	}
+	if req.Query == "" {
+		utils.WriteProblem(w, http.StatusBadRequest, utils.CodeBadRequest, "query is required")
+		return
+	}
+
+	ctx := graph.WithCaller(r.Context(), graph.Caller{Store: h.storeFor(r), UserID: userID, Admin: middleware.IsAdmin(r)})
+	// GraphQL over HTTP is JSON, so this endpoint does not negotiate formats
+	utils.WriteJSON(w, http.StatusOK, h.graphQL.Exec(ctx, req.Query, req.OperationName, req.Variables))
+}
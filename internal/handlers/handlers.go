@@ -1,34 +1,74 @@
 package handlers
 
 import (
-	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
 	"net/http"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/gitvam/platform-go-challenge/internal/graph"
 	"github.com/gitvam/platform-go-challenge/internal/middleware"
 	"github.com/gitvam/platform-go-challenge/internal/models"
 	"github.com/gitvam/platform-go-challenge/internal/store"
 	"github.com/gitvam/platform-go-challenge/internal/utils"
 	"github.com/go-chi/chi/v5"
+	"github.com/graph-gophers/graphql-go"
 )
 
 // Handler holds dependencies (store)
 type Handler struct {
 	Store store.Store
+	// Heartbeat is how often event streams send a comment while nothing changes,
+	// keeping proxies from closing idle connections
+	Heartbeat time.Duration
+	// ReportKey signs erasure reports so they can be shown to be genuine later
+	ReportKey []byte
+	graphQL   *graphql.Schema
 }
 
 // NewHandler creates a new Handler with dependencies injected
 func NewHandler(s store.Store) *Handler {
-	return &Handler{Store: s}
+	schema, err := graph.NewSchema()
+	if err != nil {
+		// The schema is embedded in the binary, so this can only fail in development
+		panic(err)
+	}
+	return &Handler{Store: s, Heartbeat: 15 * time.Second, graphQL: schema}
 }
 
 // ListFavorites godoc
 // @Summary      List all favorites for a user
-// @Description  Get all favorite assets (charts, insights, audiences) for the specified user.
+// @Description  Get all favorite assets (charts, insights, audiences, dashboards) for the specified user.
+// @Description  Dashboards list their members by reference; pass expand=members to include each member's full asset.
+// @Description  The response is JSON, MessagePack or CSV as negotiated from Accept; CSV has the layout of exports.
+// @Description  Pass fields to return only some fields of each favorite, which also leaves the other catalog columns, such as chart series, unread;
+// @Description  CSV then has a column per returned field.
 // @Tags         favorites
+// @Produce      json
+// @Produce      application/msgpack
+// @Produce      text/csv
 // @Param        userID path string true "User ID"
+// @Param        limit query int false "Page size" default(10) minimum(1) maximum(100)
+// @Param        offset query int false "Page offset" default(0) minimum(0)
+// @Param        expand query string false "Comma-separated expansions; supports: members"
+// @Param        tag query string false "Comma-separated tags to filter by"
+// @Param        tag_match query string false "Whether favorites need any or all of the tags" Enums(any, all) default(any)
+// @Param        sort query string false "type groups favorites by type; manual lists pinned favorites first, then the user's own order" Enums(type, manual) default(type)
+// @Param        fields query string false "Comma-separated fields to return for each favorite, such as external_id,title,type; type and external_id are always included"
+// @Param        If-None-Match header string false "ETag from a previous response; returns 304 if unchanged"
+// @Param        Accept-Encoding header string false "zstd or gzip to compress responses of 1 KB or more"
 // @Success      200 {object} utils.SuccessResponse
-// @Failure      401 {object} utils.ErrorResponse "Unauthorized - missing or invalid token"
-// @Failure      500 {object} utils.ErrorResponse "Internal server error"
+// @Success      304 "Not Modified"
+// @Header       200 {string} ETag "Weak ETag of the response body"
+// @Header       200 {string} Content-Encoding "zstd or gzip when the response is compressed"
+// @Failure      400 {object} utils.Problem
+// @Failure      401 {object} utils.Problem "Unauthorized - missing or invalid token"
+// @Failure      406 {object} utils.Problem "Accept allows none of the response formats"
+// @Failure      500 {object} utils.Problem "Internal server error"
 // @Router       /v1/users/{userID}/favorites [get]
 func (h *Handler) ListFavorites(w http.ResponseWriter, r *http.Request) {
 	userID, ok := getUserIDOrAbort(w, r)
@@ -36,30 +76,47 @@ func (h *Handler) ListFavorites(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	limit := utils.ParseQueryInt(r, "limit", 10)
-	offset := utils.ParseQueryInt(r, "offset", 0)
+	opts, ok := parseListOptions(w, r)
+	if !ok {
+		return
+	}
+	if opts.Fields, ok = parseFields(w, r); !ok {
+		return
+	}
 
-	favorites, err := h.Store.ListFavorites(userID, limit, offset)
+	favorites, err := h.storeFor(r).ListFavorites(userID, opts)
 	if err != nil {
-		utils.WriteJSONError(w, err.Error(), http.StatusInternalServerError)
+		writeStoreError(w, err)
 		return
 	}
-	utils.WriteJSON(w, http.StatusOK, utils.SuccessResponse{
+	utils.WriteWithETag(w, r, http.StatusOK, utils.SuccessResponse{
 		Status: "success",
-		Data:   favorites,
+		Data:   favoritesData(favorites, opts.Fields),
 	})
 }
 
 // AddFavorite godoc
 // @Summary      Add a favorite asset
-// @Description  Add a new favorite asset for the user (chart, insight, or audience).
+// @Description  Add a new favorite asset for the user (chart, insight, audience, or dashboard).
+// @Description  The body is decoded strictly: unknown, duplicate, null and server-assigned fields (id, version) are rejected.
+// @Description  It may be JSON or MessagePack, as given by Content-Type.
 // @Tags         favorites
+// @Accept       json
+// @Accept       application/msgpack
+// @Produce      json
+// @Produce      application/msgpack
+// @Produce      text/csv
 // @Param        userID path string true "User ID"
 // @Param        asset body models.Asset true "Asset to add"
+// @Param        Idempotency-Key header string false "Key that makes retries of this request safe"
 // @Success      201 {object} utils.SuccessResponse
-// @Failure      400 {object} utils.ErrorResponse
-// @Failure      401 {object} utils.ErrorResponse
-// @Failure      409 {object} utils.ErrorResponse
+// @Failure      400 {object} utils.Problem
+// @Failure      401 {object} utils.Problem
+// @Failure      406 {object} utils.Problem "Accept allows none of the response formats"
+// @Failure      409 {object} utils.Problem
+// @Failure      413 {object} utils.Problem "Request body too large"
+// @Failure      415 {object} utils.Problem "Body in a format other than JSON or MessagePack"
+// @Failure      422 {object} utils.Problem "Idempotency-Key reused with a different request"
 // @Router       /v1/users/{userID}/favorites [post]
 func (h *Handler) AddFavorite(w http.ResponseWriter, r *http.Request) {
 	userID, ok := getUserIDOrAbort(w, r)
@@ -67,30 +124,22 @@ func (h *Handler) AddFavorite(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var raw map[string]interface{}
-	if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
-		utils.WriteJSONError(w, "invalid JSON", http.StatusBadRequest)
+	body, ok := readJSONBody(w, r)
+	if !ok {
 		return
 	}
-
-	asset, err := models.DecodeAsset(raw)
+	asset, err := models.DecodeAsset(body)
 	if err != nil {
-		utils.WriteJSONError(w, err.Error(), http.StatusBadRequest)
+		writeDecodeError(w, err)
 		return
 	}
 
-	if err := h.Store.AddFavorite(userID, asset); err != nil {
-		if err.Error() == "asset already in favorites" {
-			utils.WriteJSONError(w, err.Error(), http.StatusConflict)
-			return
-		}
-		utils.WriteJSONError(w, err.Error(), http.StatusBadRequest)
+	if err := h.storeFor(r).AddFavorite(userID, asset); err != nil {
+		writeStoreError(w, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(utils.SuccessResponse{
+	utils.Write(w, r, http.StatusCreated, utils.SuccessResponse{
 		Status: "success",
 		Data:   asset,
 	})
@@ -98,15 +147,18 @@ func (h *Handler) AddFavorite(w http.ResponseWriter, r *http.Request) {
 
 // RemoveFavorite godoc
 // @Summary      Remove a favorite asset
-// @Description  Remove an asset from the user's favorites by asset external ID and type.
+// @Description  Remove an asset from the user's favorites by asset external ID and type. The favorite moves to the
+// @Description  trash, from where it can be restored until it is purged.
 // @Tags         favorites
 // @Param        userID path string true "User ID"
 // @Param        assetID path string true "Asset External ID"
-// @Param        type query string true "Asset Type (chart, insight, audience)"
+// @Param        type query string true "Asset Type (chart, insight, audience, dashboard)"
+// @Param        Idempotency-Key header string false "Key that makes retries of this request safe"
 // @Success      204 "No Content"
-// @Failure      400 {object} utils.ErrorResponse
-// @Failure      401 {object} utils.ErrorResponse
-// @Failure      404 {object} utils.ErrorResponse
+// @Failure      400 {object} utils.Problem
+// @Failure      401 {object} utils.Problem
+// @Failure      404 {object} utils.Problem
+// @Failure      422 {object} utils.Problem "Idempotency-Key reused with a different request"
 // @Router       /v1/users/{userID}/favorites/{assetID} [delete]
 func (h *Handler) RemoveFavorite(w http.ResponseWriter, r *http.Request) {
 	userID, ok := getUserIDOrAbort(w, r)
@@ -116,11 +168,11 @@ func (h *Handler) RemoveFavorite(w http.ResponseWriter, r *http.Request) {
 	assetID := chi.URLParam(r, "assetID")
 	assetType := r.URL.Query().Get("type")
 	if assetType == "" {
-		utils.WriteJSONError(w, "missing asset type", http.StatusBadRequest)
+		utils.WriteProblem(w, http.StatusBadRequest, utils.CodeInvalidAssetType, "missing asset type")
 		return
 	}
-	if err := h.Store.RemoveFavorite(userID, assetType, assetID); err != nil {
-		utils.WriteJSONError(w, err.Error(), http.StatusNotFound)
+	if err := h.storeFor(r).RemoveFavorite(userID, assetType, assetID); err != nil {
+		writeStoreError(w, err)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
@@ -128,16 +180,23 @@ func (h *Handler) RemoveFavorite(w http.ResponseWriter, r *http.Request) {
 
 // EditFavoriteDescription godoc
 // @Summary      Edit favorite asset description
-// @Description  Edit the description of a favorite asset.
+// @Description  Edit the description of a favorite asset. Send the favorite's ETag (its version) in If-Match
+// @Description  to make the edit conditional; a stale ETag is rejected with 412.
 // @Tags         favorites
 // @Param        userID path string true "User ID"
 // @Param        assetID path string true "Asset External ID"
-// @Param        type query string true "Asset Type (chart, insight, audience)"
+// @Param        type query string true "Asset Type (chart, insight, audience, dashboard)"
 // @Param        body body handlers.EditDescriptionRequest true "New Description"
+// @Param        If-Match header string false "ETag of the favorite being edited, e.g. \"3\""
+// @Param        Idempotency-Key header string false "Key that makes retries of this request safe"
 // @Success      200 {object} utils.SuccessResponse
-// @Failure      400 {object} utils.ErrorResponse
-// @Failure      401 {object} utils.ErrorResponse
-// @Failure      404 {object} utils.ErrorResponse
+// @Header       200 {string} ETag "New version of the favorite"
+// @Failure      400 {object} utils.Problem
+// @Failure      401 {object} utils.Problem
+// @Failure      404 {object} utils.Problem
+// @Failure      412 {object} utils.Problem "If-Match does not match the current version"
+// @Failure      413 {object} utils.Problem "Request body too large"
+// @Failure      422 {object} utils.Problem "Idempotency-Key reused with a different request"
 // @Router       /v1/users/{userID}/favorites/{assetID} [patch]
 func (h *Handler) EditFavoriteDescription(w http.ResponseWriter, r *http.Request) {
 	userID, ok := getUserIDOrAbort(w, r)
@@ -147,37 +206,239 @@ func (h *Handler) EditFavoriteDescription(w http.ResponseWriter, r *http.Request
 	assetID := chi.URLParam(r, "assetID")
 	assetType := r.URL.Query().Get("type")
 	if assetType == "" {
-		utils.WriteJSONError(w, "missing asset type", http.StatusBadRequest)
+		utils.WriteProblem(w, http.StatusBadRequest, utils.CodeInvalidAssetType, "missing asset type")
+		return
+	}
+	expectedVersion, ok := parseIfMatch(w, r)
+	if !ok {
+		return
+	}
+	body, ok := readJSONBody(w, r)
+	if !ok {
 		return
 	}
 	var req struct {
-		Description string `json:"description"`
+		Description *string `json:"description"`
 	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		utils.WriteJSONError(w, "invalid JSON", http.StatusBadRequest)
+	if err := models.DecodeStrict(body, &req); err != nil {
+		writeDecodeError(w, err)
 		return
 	}
-	if err := h.Store.EditFavoriteDescription(userID, assetType, assetID, req.Description); err != nil {
-		utils.WriteJSONError(w, err.Error(), http.StatusNotFound)
+	if req.Description == nil {
+		writeDecodeError(w, &models.DecodeError{Field: "description", Msg: "is required"})
+		return
+	}
+	version, err := h.storeFor(r).EditFavoriteDescription(userID, assetType, assetID, *req.Description, expectedVersion)
+	if err != nil {
+		writeStoreError(w, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(utils.SuccessResponse{
+	w.Header().Set("ETag", utils.VersionETag(version))
+	utils.Write(w, r, http.StatusOK, utils.SuccessResponse{
 		Status: "success",
-		Data: map[string]string{
+		Data: map[string]any{
 			"asset_id":    assetID,
-			"description": req.Description,
+			"description": *req.Description,
+			"version":     version,
 		},
 	})
 }
 
+// getUserIDOrAbort returns the user whose data the request works on: the token's subject, or
+// the {userID} path segment when an admin acts for another user. It also rejects requests
+// without a tenant, so storeFor can rely on one being set.
 func getUserIDOrAbort(w http.ResponseWriter, r *http.Request) (string, bool) {
 	userID, ok := middleware.GetUserIDFromContext(r)
 	if !ok {
-		utils.WriteJSONError(w, "user ID missing from context", http.StatusUnauthorized)
+		utils.WriteProblem(w, http.StatusUnauthorized, utils.CodeUnauthorized, "user ID missing from context")
+		return "", false
+	}
+	if _, ok := middleware.GetTenantIDFromContext(r); !ok {
+		utils.WriteProblem(w, http.StatusUnauthorized, utils.CodeUnauthorized, "tenant missing from context")
+		return "", false
+	}
+	if target := chi.URLParam(r, "userID"); target != "" && target != userID && middleware.IsAdmin(r) {
+		if !models.IsUUID(target) {
+			utils.WriteProblem(w, http.StatusBadRequest, utils.CodeBadRequest, "userID must be a UUID")
+			return "", false
+		}
+		return target, true
+	}
+	return userID, true
+}
+
+// storeFor returns the store limited to the caller's tenant, auditing changes with the token's
+// subject as the actor. It must be called after getUserIDOrAbort; there is deliberately no
+// fallback to the default tenant.
+func (h *Handler) storeFor(r *http.Request) store.Store {
+	tenantID, _ := middleware.GetTenantIDFromContext(r)
+	actorID, _ := middleware.GetUserIDFromContext(r)
+	return h.Store.ForTenant(tenantID).AuditAs(actorID, middleware.GetRequestID(r))
+}
+
+// readBody reads the whole request body, reporting bodies over the size limit as 413
+func readBody(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	body, err := io.ReadAll(r.Body)
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		utils.WriteProblem(w, http.StatusRequestEntityTooLarge, utils.CodePayloadTooLarge,
+			fmt.Sprintf("request body must be at most %d bytes", tooLarge.Limit))
+		return nil, false
+	}
+	if err != nil {
+		utils.WriteProblem(w, http.StatusBadRequest, utils.CodeBadRequest, "could not read request body")
+		return nil, false
+	}
+	return body, true
+}
+
+// readJSONBody reads the request body as JSON, converting it from the format of its
+// Content-Type, which middleware.Negotiate has checked
+func readJSONBody(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	body, ok := readBody(w, r)
+	if !ok {
+		return nil, false
+	}
+	body, err := utils.BodyToJSON(r, body)
+	if err != nil {
+		utils.WriteProblem(w, http.StatusBadRequest, utils.CodeBadRequest, err.Error())
+		return nil, false
+	}
+	return body, true
+}
+
+// writeDecodeError reports a rejected request body, pointing at the offending field when known
+func writeDecodeError(w http.ResponseWriter, err error) {
+	if errors.Is(err, models.ErrUnknownAssetType) {
+		utils.WriteProblem(w, http.StatusBadRequest, utils.CodeInvalidAssetType, err.Error())
+		return
+	}
+	var de *models.DecodeError
+	if errors.As(err, &de) && de.Field != "" {
+		utils.WriteValidationProblem(w, http.StatusBadRequest, utils.CodeInvalidJSON, de.Error(),
+			[]utils.FieldError{{Field: de.Field, Reason: de.Msg}})
+		return
+	}
+	utils.WriteProblem(w, http.StatusBadRequest, utils.CodeInvalidJSON, err.Error())
+}
+
+// writeStoreError maps store errors to problem responses. Unexpected errors are
+// logged and reported as a generic 500 so database details never reach the client.
+func writeStoreError(w http.ResponseWriter, err error) {
+	var verrs models.ValidationErrors
+	switch {
+	case errors.As(err, &verrs):
+		utils.WriteValidationProblem(w, http.StatusBadRequest, utils.CodeValidationFailed, "request failed validation", fieldErrors(verrs))
+	case errors.Is(err, store.ErrValidation):
+		utils.WriteProblem(w, http.StatusBadRequest, utils.CodeValidationFailed, err.Error())
+	case errors.Is(err, store.ErrInvalidType):
+		utils.WriteProblem(w, http.StatusBadRequest, utils.CodeInvalidAssetType, err.Error())
+	case errors.Is(err, store.ErrForbidden):
+		utils.WriteProblem(w, http.StatusForbidden, utils.CodeForbidden, err.Error())
+	case errors.Is(err, store.ErrNotFound):
+		utils.WriteProblem(w, http.StatusNotFound, utils.CodeNotFound, err.Error())
+	case errors.Is(err, store.ErrAlreadyExists):
+		utils.WriteProblem(w, http.StatusConflict, utils.CodeAlreadyExists, err.Error())
+	case errors.Is(err, store.ErrVersionConflict):
+		utils.WriteProblem(w, http.StatusPreconditionFailed, utils.CodePreconditionFailed, err.Error())
+	default:
+		log.Printf("[ERROR] store: %v", err)
+		utils.WriteProblem(w, http.StatusInternalServerError, utils.CodeInternal, "internal server error")
+	}
+}
+
+// fieldErrors converts validation errors to the field errors of a problem response
+func fieldErrors(verrs models.ValidationErrors) []utils.FieldError {
+	fields := make([]utils.FieldError, len(verrs))
+	for i, v := range verrs {
+		fields[i] = utils.FieldError{Field: v.Field, Reason: v.Reason}
+	}
+	return fields
+}
+
+// maxLimit is the largest page size of favorite listings, as for gRPC and GraphQL
+const maxLimit = 100
+
+// parseListOptions reads the paging, expand, tag filter and sort query parameters shared by favorite listings
+func parseListOptions(w http.ResponseWriter, r *http.Request) (store.ListOptions, bool) {
+	opts := store.ListOptions{
+		Limit:  utils.ParseQueryInt(r, "limit", 10),
+		Offset: utils.ParseQueryInt(r, "offset", 0),
+	}
+	var v models.Validator
+	v.Range("limit", opts.Limit, 1, maxLimit)
+	v.NonNegative("offset", opts.Offset)
+	for _, e := range utils.ParseQueryList(r, "expand") {
+		switch e {
+		case "members":
+			opts.ExpandMembers = true
+		default:
+			utils.WriteProblem(w, http.StatusBadRequest, utils.CodeBadRequest, fmt.Sprintf("unknown expand value %q", e))
+			return opts, false
+		}
+	}
+
+	tags := utils.ParseQueryList(r, "tag")
+	v.Tags("tag", tags)
+	if err := v.Err(); err != nil {
+		writeStoreError(w, err)
+		return opts, false
+	}
+	opts.Tags = models.NormalizeTags(tags)
+	switch match := r.URL.Query().Get("tag_match"); match {
+	case "", "any":
+	case "all":
+		opts.MatchAllTags = true
+	default:
+		utils.WriteProblem(w, http.StatusBadRequest, utils.CodeBadRequest, fmt.Sprintf("tag_match must be any or all, not %q", match))
+		return opts, false
+	}
+	switch sort := r.URL.Query().Get("sort"); sort {
+	case "", "type":
+	case "manual":
+		opts.ManualOrder = true
+	default:
+		utils.WriteProblem(w, http.StatusBadRequest, utils.CodeBadRequest, fmt.Sprintf("sort must be type or manual, not %q", sort))
+		return opts, false
+	}
+	return opts, true
+}
+
+// parseFields returns the fields query parameter, each a JSON field some asset type has
+func parseFields(w http.ResponseWriter, r *http.Request) ([]string, bool) {
+	fields := utils.ParseQueryList(r, "fields")
+	known := models.FieldNames()
+	for _, f := range fields {
+		if !slices.Contains(known, f) {
+			utils.WriteProblem(w, http.StatusBadRequest, utils.CodeBadRequest, fmt.Sprintf("unknown field %q", f))
+			return nil, false
+		}
+	}
+	return fields, true
+}
+
+// favoritesData is the response data for a list of favorites, whole or with only the given fields
+func favoritesData(favorites []models.Asset, fields []string) any {
+	if len(fields) > 0 {
+		return models.SparseAssets(favorites, fields)
+	}
+	return models.AssetList(favorites)
+}
+
+// parseIfMatch returns the favorite version the client expects to edit, or 0 when the
+// edit is unconditional. Only a single strong ETag or "*" is accepted.
+func parseIfMatch(w http.ResponseWriter, r *http.Request) (int, bool) {
+	ifMatch := strings.TrimSpace(r.Header.Get("If-Match"))
+	if ifMatch == "" || ifMatch == "*" {
+		return 0, true
+	}
+	version, ok := utils.ParseVersionETag(ifMatch)
+	if !ok {
+		utils.WriteProblem(w, http.StatusPreconditionFailed, utils.CodePreconditionFailed, "If-Match must be a single ETag returned for this favorite")
+		return 0, false
 	}
-	return userID, ok
+	return version, true
 }
 
 // EditDescriptionRequest is used in Swagger annotations
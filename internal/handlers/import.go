@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gitvam/platform-go-challenge/internal/models"
+	"github.com/gitvam/platform-go-challenge/internal/store"
+	"github.com/gitvam/platform-go-challenge/internal/utils"
+)
+
+// importFormats maps the Content-Type of an import to its format when format is not given
+var importFormats = map[string]string{
+	"application/json":     models.FormatJSON,
+	"application/x-ndjson": models.FormatNDJSON,
+	"text/csv":             models.FormatCSV,
+}
+
+// ImportFavorites godoc
+// @Summary      Import favorites from a file
+// @Description  Add favorites listed in a JSON array, NDJSON or CSV file sent as the body, e.g. an export. Each row
+// @Description  names a catalog asset by type and external_id, with an optional description and tags (";"-separated
+// @Description  in CSV); other fields are ignored. The import is applied in one transaction: any invalid row or
+// @Description  unknown asset rejects it with every offending row listed, and on_conflict decides whether rows
+// @Description  already in the favorites are skipped, overwrite the description and tags, or reject it. With
+// @Description  dry_run=true nothing changes and the report shows what would happen to each row.
+// @Tags         favorites
+// @Accept       json
+// @Accept       application/x-ndjson
+// @Accept       text/csv
+// @Produce      json
+// @Produce      application/msgpack
+// @Produce      text/csv
+// @Param        userID path string true "User ID"
+// @Param        format query string false "File format, by default taken from the Content-Type" Enums(json, ndjson, csv)
+// @Param        on_conflict query string false "Rows already in the favorites" Enums(skip, overwrite, fail) default(skip)
+// @Param        dry_run query bool false "Report without importing" default(false)
+// @Param        file body string true "File of favorites"
+// @Param        Idempotency-Key header string false "Key that makes retries of this request safe"
+// @Success      200 {object} utils.SuccessResponse{data=models.ImportReport}
+// @Failure      400 {object} utils.Problem "Unreadable file, or invalid rows and unknown assets"
+// @Failure      401 {object} utils.Problem
+// @Failure      406 {object} utils.Problem "Accept allows none of the response formats"
+// @Failure      409 {object} utils.Problem "Rows already in the favorites with on_conflict=fail"
+// @Failure      413 {object} utils.Problem "Request body too large"
+// @Failure      422 {object} utils.Problem "Idempotency-Key reused with a different request"
+// @Router       /v1/users/{userID}/favorites/import [post]
+func (h *Handler) ImportFavorites(w http.ResponseWriter, r *http.Request) {
+	userID, ok := getUserIDOrAbort(w, r)
+	if !ok {
+		return
+	}
+	q := r.URL.Query()
+	format := q.Get("format")
+	if format == "" {
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if format = importFormats[mediaType]; format == "" {
+			format = models.FormatJSON
+		}
+	}
+	opts := store.ImportOptions{OnConflict: q.Get("on_conflict")}
+	if opts.OnConflict == "" {
+		opts.OnConflict = models.ImportSkip
+	}
+	var v models.Validator
+	v.OneOf("format", format, models.FileFormats)
+	v.OneOf("on_conflict", opts.OnConflict, models.ImportConflictPolicies)
+	if raw := q.Get("dry_run"); raw != "" {
+		dryRun, err := strconv.ParseBool(raw)
+		if err != nil {
+			v.Add("dry_run", "must be true or false")
+		}
+		opts.DryRun = dryRun
+	}
+	if err := v.Err(); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	body, ok := readBody(w, r)
+	if !ok {
+		return
+	}
+	rows, err := models.ParseImport(format, body)
+	if err != nil {
+		var de *models.DecodeError
+		if errors.As(err, &de) {
+			writeDecodeError(w, err)
+			return
+		}
+		utils.WriteProblem(w, http.StatusBadRequest, utils.CodeBadRequest, err.Error())
+		return
+	}
+
+	report, err := h.storeFor(r).ImportFavorites(userID, rows, opts)
+	var verrs models.ValidationErrors
+	if errors.Is(err, store.ErrAlreadyExists) && errors.As(err, &verrs) {
+		utils.WriteValidationProblem(w, http.StatusConflict, utils.CodeAlreadyExists, "favorites already exist", fieldErrors(verrs))
+		return
+	}
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	utils.Write(w, r, http.StatusOK, utils.SuccessResponse{Status: "success", Data: report})
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gitvam/platform-go-challenge/internal/models"
+	"github.com/gitvam/platform-go-challenge/internal/utils"
+	"github.com/go-chi/chi/v5"
+)
+
+// PinFavorite godoc
+// @Summary      Pin a favorite
+// @Description  Pin a favorite to the top of the list returned with sort=manual. Pinning a pinned favorite changes nothing.
+// @Tags         favorites
+// @Param        userID path string true "User ID"
+// @Param        assetID path string true "Asset External ID"
+// @Param        type query string true "Asset Type (chart, insight, audience, dashboard)"
+// @Param        If-Match header string false "ETag of the favorite being pinned"
+// @Param        Idempotency-Key header string false "Key that makes retries of this request safe"
+// @Success      200 {object} utils.SuccessResponse
+// @Header       200 {string} ETag "New version of the favorite"
+// @Failure      400 {object} utils.Problem
+// @Failure      401 {object} utils.Problem
+// @Failure      404 {object} utils.Problem
+// @Failure      412 {object} utils.Problem "If-Match does not match the current version"
+// @Failure      422 {object} utils.Problem "Idempotency-Key reused with a different request"
+// @Router       /v1/users/{userID}/favorites/{assetID}/pin [put]
+func (h *Handler) PinFavorite(w http.ResponseWriter, r *http.Request) {
+	h.setPinned(w, r, true)
+}
+
+// UnpinFavorite godoc
+// @Summary      Unpin a favorite
+// @Description  Unpin a favorite. It stays just below the favorites that are still pinned.
+// @Tags         favorites
+// @Param        userID path string true "User ID"
+// @Param        assetID path string true "Asset External ID"
+// @Param        type query string true "Asset Type (chart, insight, audience, dashboard)"
+// @Param        If-Match header string false "ETag of the favorite being unpinned"
+// @Param        Idempotency-Key header string false "Key that makes retries of this request safe"
+// @Success      200 {object} utils.SuccessResponse
+// @Header       200 {string} ETag "New version of the favorite"
+// @Failure      400 {object} utils.Problem
+// @Failure      401 {object} utils.Problem
+// @Failure      404 {object} utils.Problem
+// @Failure      412 {object} utils.Problem "If-Match does not match the current version"
+// @Failure      422 {object} utils.Problem "Idempotency-Key reused with a different request"
+// @Router       /v1/users/{userID}/favorites/{assetID}/pin [delete]
+func (h *Handler) UnpinFavorite(w http.ResponseWriter, r *http.Request) {
+	h.setPinned(w, r, false)
+}
+
+func (h *Handler) setPinned(w http.ResponseWriter, r *http.Request, pinned bool) {
+	userID, ok := getUserIDOrAbort(w, r)
+	if !ok {
+		return
+	}
+	assetID := chi.URLParam(r, "assetID")
+	assetType := r.URL.Query().Get("type")
+	if assetType == "" {
+		utils.WriteProblem(w, http.StatusBadRequest, utils.CodeInvalidAssetType, "missing asset type")
+		return
+	}
+	expectedVersion, ok := parseIfMatch(w, r)
+	if !ok {
+		return
+	}
+	version, err := h.storeFor(r).SetFavoritePinned(userID, assetType, assetID, pinned, expectedVersion)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	writePlacement(w, r, assetID, pinned, version)
+}
+
+// MoveFavorite godoc
+// @Summary      Reorder a favorite
+// @Description  Move a favorite directly before or after another favorite in the order returned with sort=manual.
+// @Description  The moved favorite becomes pinned or unpinned to match the favorite it is placed next to.
+// @Tags         favorites
+// @Param        userID path string true "User ID"
+// @Param        assetID path string true "Asset External ID"
+// @Param        type query string true "Asset Type (chart, insight, audience, dashboard)"
+// @Param        body body models.FavoriteMove true "Favorite to move next to; set exactly one of before and after"
+// @Param        If-Match header string false "ETag of the favorite being moved"
+// @Param        Idempotency-Key header string false "Key that makes retries of this request safe"
+// @Success      200 {object} utils.SuccessResponse
+// @Header       200 {string} ETag "New version of the favorite"
+// @Failure      400 {object} utils.Problem
+// @Failure      401 {object} utils.Problem
+// @Failure      404 {object} utils.Problem "Either favorite does not exist"
+// @Failure      412 {object} utils.Problem "If-Match does not match the current version"
+// @Failure      413 {object} utils.Problem "Request body too large"
+// @Failure      422 {object} utils.Problem "Idempotency-Key reused with a different request"
+// @Router       /v1/users/{userID}/favorites/{assetID}/move [post]
+func (h *Handler) MoveFavorite(w http.ResponseWriter, r *http.Request) {
+	userID, ok := getUserIDOrAbort(w, r)
+	if !ok {
+		return
+	}
+	assetID := chi.URLParam(r, "assetID")
+	assetType := r.URL.Query().Get("type")
+	if assetType == "" {
+		utils.WriteProblem(w, http.StatusBadRequest, utils.CodeInvalidAssetType, "missing asset type")
+		return
+	}
+	expectedVersion, ok := parseIfMatch(w, r)
+	if !ok {
+		return
+	}
+	body, ok := readJSONBody(w, r)
+	if !ok {
+		return
+	}
+	var move models.FavoriteMove
+	if err := models.DecodeStrict(body, &move); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	pinned, version, err := h.storeFor(r).MoveFavorite(userID, assetType, assetID, move, expectedVersion)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	writePlacement(w, r, assetID, pinned, version)
+}
+
+// writePlacement reports whether a favorite ended up pinned, tagged with its new version
+func writePlacement(w http.ResponseWriter, r *http.Request, assetID string, pinned bool, version int) {
+	w.Header().Set("ETag", utils.VersionETag(version))
+	utils.Write(w, r, http.StatusOK, utils.SuccessResponse{
+		Status: "success",
+		Data: map[string]any{
+			"asset_id": assetID,
+			"pinned":   pinned,
+			"version":  version,
+		},
+	})
+}
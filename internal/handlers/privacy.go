@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gitvam/platform-go-challenge/internal/middleware"
+	"github.com/gitvam/platform-go-challenge/internal/utils"
+)
+
+// userDataManifest describes a subject access archive
+type userDataManifest struct {
+	UserID      string         `json:"user_id"`
+	TenantID    string         `json:"tenant_id"`
+	GeneratedAt time.Time      `json:"generated_at"`
+	Tables      map[string]int `json:"tables"` // rows in each <table>.json
+}
+
+// UserDataArchive godoc
+// @Summary      Download everything stored about a user
+// @Description  Admin only. Subject access report: a zip archive with manifest.json and one <table>.json per
+// @Description  table holding the user's data in the tenant, each a JSON array of rows with every column, read from
+// @Description  one snapshot. Audit events include those the user made for others and shares include those
+// @Description  received.
+// @Tags         privacy
+// @Produce      application/zip
+// @Param        userID path string true "User ID"
+// @Success      200 {file} file "Zip archive"
+// @Failure      400 {object} utils.Problem
+// @Failure      401 {object} utils.Problem
+// @Failure      403 {object} utils.Problem "Token lacks the admin role"
+// @Router       /v1/admin/users/{userID}/data [get]
+func (h *Handler) UserDataArchive(w http.ResponseWriter, r *http.Request) {
+	userID, ok := getUserIDOrAbort(w, r)
+	if !ok {
+		return
+	}
+	s := h.storeFor(r)
+	tables, err := s.UserData(userID)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	tenantID, _ := middleware.GetTenantIDFromContext(r)
+	manifest := userDataManifest{UserID: userID, TenantID: tenantID, GeneratedAt: time.Now().UTC(), Tables: map[string]int{}}
+	for _, t := range tables {
+		manifest.Tables[t.Table] = t.Count
+	}
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="user-data-%s.zip"`, userID))
+	w.WriteHeader(http.StatusOK)
+
+	zw := zip.NewWriter(w)
+	err = writeZipJSON(zw, "manifest.json", manifest)
+	for _, t := range tables {
+		if err != nil {
+			break
+		}
+		err = writeZipJSON(zw, t.Table+".json", t.Rows)
+	}
+	if err == nil {
+		err = zw.Close()
+	}
+	if err != nil {
+		// Aborting the connection tells the client the archive is incomplete
+		log.Printf("[ERROR] writing user data archive: %v", err)
+		panic(http.ErrAbortHandler)
+	}
+}
+
+func writeZipJSON(zw *zip.Writer, name string, v any) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// EraseUserData godoc
+// @Summary      Erase everything stored about a user
+// @Description  Admin only. Permanently delete the user's favorites and their tags, collections, shares made or
+// @Description  received (including link tokens), idempotency records and favorite events with their pending webhook
+// @Description  deliveries in the tenant, in one transaction. Audit events are kept with the user ID replaced by a
+// @Description  random pseudonym and the user's snapshots removed. Returns a completion report signed with
+// @Description  HMAC-SHA256 under the server's report key. Erasing again is harmless and reports nothing deleted.
+// @Tags         privacy
+// @Param        userID path string true "User ID"
+// @Success      200 {object} utils.SuccessResponse{data=models.ErasureReport}
+// @Failure      400 {object} utils.Problem
+// @Failure      401 {object} utils.Problem
+// @Failure      403 {object} utils.Problem "Token lacks the admin role"
+// @Router       /v1/admin/users/{userID}/data [delete]
+func (h *Handler) EraseUserData(w http.ResponseWriter, r *http.Request) {
+	userID, ok := getUserIDOrAbort(w, r)
+	if !ok {
+		return
+	}
+	report, err := h.storeFor(r).EraseUser(userID)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	if err := report.Sign(h.ReportKey); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	utils.Write(w, r, http.StatusOK, utils.SuccessResponse{Status: "success", Data: report})
+}
@@ -0,0 +1,359 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gitvam/platform-go-challenge/internal/models"
+	"github.com/gitvam/platform-go-challenge/internal/store"
+	"github.com/gitvam/platform-go-challenge/internal/utils"
+	"github.com/go-chi/chi/v5"
+)
+
+// ShareFavorite godoc
+// @Summary      Share a favorite
+// @Description  Share a favorite with another user with view or edit permission, or create a view-only share link.
+// @Description  Links expire after 7 days unless expires_at is set (at most 90 days); the token is only returned here.
+// @Description  Sharing again with the same user replaces the permission and expiry.
+// @Tags         sharing
+// @Param        userID path string true "User ID"
+// @Param        assetID path string true "Asset External ID"
+// @Param        type query string true "Asset Type (chart, insight, audience, dashboard)"
+// @Param        body body models.ShareRequest true "User to share with, or link: true"
+// @Param        Idempotency-Key header string false "Key that makes retries of this request safe"
+// @Success      201 {object} utils.SuccessResponse{data=models.Share}
+// @Failure      400 {object} utils.Problem
+// @Failure      401 {object} utils.Problem
+// @Failure      404 {object} utils.Problem
+// @Failure      413 {object} utils.Problem "Request body too large"
+// @Failure      422 {object} utils.Problem "Idempotency-Key reused with a different request"
+// @Router       /v1/users/{userID}/favorites/{assetID}/shares [post]
+func (h *Handler) ShareFavorite(w http.ResponseWriter, r *http.Request) {
+	userID, ok := getUserIDOrAbort(w, r)
+	if !ok {
+		return
+	}
+	assetType := r.URL.Query().Get("type")
+	if assetType == "" {
+		utils.WriteProblem(w, http.StatusBadRequest, utils.CodeInvalidAssetType, "missing asset type")
+		return
+	}
+	req, ok := decodeShareRequest(w, r)
+	if !ok {
+		return
+	}
+	share, err := h.storeFor(r).ShareFavorite(userID, assetType, chi.URLParam(r, "assetID"), *req)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	utils.Write(w, r, http.StatusCreated, utils.SuccessResponse{Status: "success", Data: share})
+}
+
+// ListFavoriteShares godoc
+// @Summary      List a favorite's shares
+// @Description  Get the unexpired shares and share links of one of the user's favorites. Link tokens are never listed.
+// @Tags         sharing
+// @Param        userID path string true "User ID"
+// @Param        assetID path string true "Asset External ID"
+// @Param        type query string true "Asset Type (chart, insight, audience, dashboard)"
+// @Success      200 {object} utils.SuccessResponse{data=[]models.Share}
+// @Failure      400 {object} utils.Problem
+// @Failure      401 {object} utils.Problem
+// @Failure      404 {object} utils.Problem
+// @Router       /v1/users/{userID}/favorites/{assetID}/shares [get]
+func (h *Handler) ListFavoriteShares(w http.ResponseWriter, r *http.Request) {
+	userID, ok := getUserIDOrAbort(w, r)
+	if !ok {
+		return
+	}
+	assetType := r.URL.Query().Get("type")
+	if assetType == "" {
+		utils.WriteProblem(w, http.StatusBadRequest, utils.CodeInvalidAssetType, "missing asset type")
+		return
+	}
+	shares, err := h.storeFor(r).ListFavoriteShares(userID, assetType, chi.URLParam(r, "assetID"))
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	utils.Write(w, r, http.StatusOK, utils.SuccessResponse{Status: "success", Data: shares})
+}
+
+// ShareCollection godoc
+// @Summary      Share a collection
+// @Description  Share a collection with another user with view or edit permission, or create a view-only share link.
+// @Description  Links expire after 7 days unless expires_at is set (at most 90 days); the token is only returned here.
+// @Tags         sharing
+// @Param        userID path string true "User ID"
+// @Param        collectionID path int true "Collection ID"
+// @Param        body body models.ShareRequest true "User to share with, or link: true"
+// @Param        Idempotency-Key header string false "Key that makes retries of this request safe"
+// @Success      201 {object} utils.SuccessResponse{data=models.Share}
+// @Failure      400 {object} utils.Problem
+// @Failure      401 {object} utils.Problem
+// @Failure      404 {object} utils.Problem
+// @Failure      413 {object} utils.Problem "Request body too large"
+// @Failure      422 {object} utils.Problem "Idempotency-Key reused with a different request"
+// @Router       /v1/users/{userID}/collections/{collectionID}/shares [post]
+func (h *Handler) ShareCollection(w http.ResponseWriter, r *http.Request) {
+	userID, ok := getUserIDOrAbort(w, r)
+	if !ok {
+		return
+	}
+	collectionID, ok := parseCollectionID(w, r)
+	if !ok {
+		return
+	}
+	req, ok := decodeShareRequest(w, r)
+	if !ok {
+		return
+	}
+	share, err := h.storeFor(r).ShareCollection(userID, collectionID, *req)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	utils.Write(w, r, http.StatusCreated, utils.SuccessResponse{Status: "success", Data: share})
+}
+
+// ListCollectionShares godoc
+// @Summary      List a collection's shares
+// @Description  Get the unexpired shares and share links of one of the user's collections. Link tokens are never listed.
+// @Tags         sharing
+// @Param        userID path string true "User ID"
+// @Param        collectionID path int true "Collection ID"
+// @Success      200 {object} utils.SuccessResponse{data=[]models.Share}
+// @Failure      401 {object} utils.Problem
+// @Failure      404 {object} utils.Problem
+// @Router       /v1/users/{userID}/collections/{collectionID}/shares [get]
+func (h *Handler) ListCollectionShares(w http.ResponseWriter, r *http.Request) {
+	userID, ok := getUserIDOrAbort(w, r)
+	if !ok {
+		return
+	}
+	collectionID, ok := parseCollectionID(w, r)
+	if !ok {
+		return
+	}
+	shares, err := h.storeFor(r).ListCollectionShares(userID, collectionID)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	utils.Write(w, r, http.StatusOK, utils.SuccessResponse{Status: "success", Data: shares})
+}
+
+// RevokeShare godoc
+// @Summary      Revoke a share
+// @Description  Delete one of the user's shares. A revoked share link stops working immediately.
+// @Tags         sharing
+// @Param        userID path string true "User ID"
+// @Param        shareID path int true "Share ID"
+// @Param        Idempotency-Key header string false "Key that makes retries of this request safe"
+// @Success      204 "No Content"
+// @Failure      401 {object} utils.Problem
+// @Failure      404 {object} utils.Problem
+// @Failure      422 {object} utils.Problem "Idempotency-Key reused with a different request"
+// @Router       /v1/users/{userID}/shares/{shareID} [delete]
+func (h *Handler) RevokeShare(w http.ResponseWriter, r *http.Request) {
+	userID, ok := getUserIDOrAbort(w, r)
+	if !ok {
+		return
+	}
+	shareID, ok := parseShareID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.storeFor(r).RevokeShare(userID, shareID); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListSharedWithMe godoc
+// @Summary      List items shared with the user
+// @Description  Get the favorites and collections other users shared with the user, newest first.
+// @Description  Collections are listed without their favorites; get the share to page through them.
+// @Tags         sharing
+// @Param        userID path string true "User ID"
+// @Success      200 {object} utils.SuccessResponse{data=[]models.SharedItem}
+// @Failure      401 {object} utils.Problem
+// @Router       /v1/users/{userID}/shared-with-me [get]
+func (h *Handler) ListSharedWithMe(w http.ResponseWriter, r *http.Request) {
+	userID, ok := getUserIDOrAbort(w, r)
+	if !ok {
+		return
+	}
+	items, err := h.storeFor(r).ListSharedWithMe(userID)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	utils.Write(w, r, http.StatusOK, utils.SuccessResponse{Status: "success", Data: items})
+}
+
+// GetSharedItem godoc
+// @Summary      Get an item shared with the user
+// @Description  Get a shared favorite, or a shared collection with a page of its favorites.
+// @Tags         sharing
+// @Param        userID path string true "User ID"
+// @Param        shareID path int true "Share ID"
+// @Param        limit query int false "Page size" default(10) minimum(1) maximum(100)
+// @Param        offset query int false "Page offset" default(0) minimum(0)
+// @Param        expand query string false "Comma-separated expansions; supports: members"
+// @Param        sort query string false "type groups favorites by type; manual follows the owner's order" Enums(type, manual) default(type)
+// @Success      200 {object} utils.SuccessResponse{data=models.SharedItem}
+// @Failure      400 {object} utils.Problem
+// @Failure      401 {object} utils.Problem
+// @Failure      404 {object} utils.Problem "Share does not exist, has expired or was not granted to the user"
+// @Router       /v1/users/{userID}/shared-with-me/{shareID} [get]
+func (h *Handler) GetSharedItem(w http.ResponseWriter, r *http.Request) {
+	userID, ok := getUserIDOrAbort(w, r)
+	if !ok {
+		return
+	}
+	shareID, ok := parseShareID(w, r)
+	if !ok {
+		return
+	}
+	opts, ok := parseListOptions(w, r)
+	if !ok {
+		return
+	}
+	item, err := h.storeFor(r).GetSharedItem(userID, shareID, opts)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	utils.Write(w, r, http.StatusOK, utils.SuccessResponse{Status: "success", Data: item})
+}
+
+// EditSharedItem godoc
+// @Summary      Edit an item shared with the user
+// @Description  Change the description of a shared favorite, or the name and description of a shared collection.
+// @Description  Requires edit permission. Send the item's ETag in If-Match to make the change conditional.
+// @Tags         sharing
+// @Param        userID path string true "User ID"
+// @Param        shareID path int true "Share ID"
+// @Param        body body models.SharedItemUpdate true "Fields to change"
+// @Param        If-Match header string false "ETag of the shared favorite or collection"
+// @Param        Idempotency-Key header string false "Key that makes retries of this request safe"
+// @Success      200 {object} utils.SuccessResponse{data=models.SharedItem}
+// @Header       200 {string} ETag "New version of the shared favorite or collection"
+// @Failure      400 {object} utils.Problem
+// @Failure      401 {object} utils.Problem
+// @Failure      403 {object} utils.Problem "Share is view-only"
+// @Failure      404 {object} utils.Problem "Share does not exist, has expired or was not granted to the user"
+// @Failure      409 {object} utils.Problem "Collection name taken"
+// @Failure      412 {object} utils.Problem "If-Match does not match the current version"
+// @Failure      413 {object} utils.Problem "Request body too large"
+// @Failure      422 {object} utils.Problem "Idempotency-Key reused with a different request"
+// @Router       /v1/users/{userID}/shared-with-me/{shareID} [patch]
+func (h *Handler) EditSharedItem(w http.ResponseWriter, r *http.Request) {
+	userID, ok := getUserIDOrAbort(w, r)
+	if !ok {
+		return
+	}
+	shareID, ok := parseShareID(w, r)
+	if !ok {
+		return
+	}
+	expectedVersion, ok := parseIfMatch(w, r)
+	if !ok {
+		return
+	}
+	body, ok := readJSONBody(w, r)
+	if !ok {
+		return
+	}
+	var update models.SharedItemUpdate
+	if err := models.DecodeStrict(body, &update); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	if update.Empty() {
+		writeDecodeError(w, &models.DecodeError{Msg: "set at least one of name or description"})
+		return
+	}
+
+	// Turn viewers away before touching the item; the store checks the permission again
+	// inside the transaction in case the share changes in between
+	current, err := h.storeFor(r).GetSharedItem(userID, shareID, store.ListOptions{})
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	if current.Share.Permission != models.PermissionEdit {
+		writeStoreError(w, fmt.Errorf("share %d is view-only: %w", shareID, store.ErrForbidden))
+		return
+	}
+
+	item, err := h.storeFor(r).EditSharedItem(userID, shareID, update, expectedVersion)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	if item.Asset != nil {
+		w.Header().Set("ETag", utils.VersionETag(item.Asset.GetVersion()))
+	} else {
+		w.Header().Set("ETag", utils.VersionETag(item.Collection.Version))
+	}
+	utils.Write(w, r, http.StatusOK, utils.SuccessResponse{Status: "success", Data: item})
+}
+
+// OpenShareLink godoc
+// @Summary      Open a share link
+// @Description  Get the favorite or collection behind a share link token. Any signed-in user holding the token can view it.
+// @Tags         sharing
+// @Param        token path string true "Share link token"
+// @Param        limit query int false "Page size" default(10) minimum(1) maximum(100)
+// @Param        offset query int false "Page offset" default(0) minimum(0)
+// @Param        expand query string false "Comma-separated expansions; supports: members"
+// @Param        sort query string false "type groups favorites by type; manual follows the owner's order" Enums(type, manual) default(type)
+// @Success      200 {object} utils.SuccessResponse{data=models.SharedItem}
+// @Failure      400 {object} utils.Problem
+// @Failure      401 {object} utils.Problem
+// @Failure      404 {object} utils.Problem "Link does not exist, has expired or was revoked"
+// @Router       /v1/shared-links/{token} [get]
+func (h *Handler) OpenShareLink(w http.ResponseWriter, r *http.Request) {
+	if _, ok := getUserIDOrAbort(w, r); !ok {
+		return
+	}
+	opts, ok := parseListOptions(w, r)
+	if !ok {
+		return
+	}
+	item, err := h.storeFor(r).OpenShareLink(chi.URLParam(r, "token"), opts)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	utils.Write(w, r, http.StatusOK, utils.SuccessResponse{Status: "success", Data: item})
+}
+
+func decodeShareRequest(w http.ResponseWriter, r *http.Request) (*models.ShareRequest, bool) {
+	body, ok := readJSONBody(w, r)
+	if !ok {
+		return nil, false
+	}
+	var req models.ShareRequest
+	if err := models.DecodeStrict(body, &req); err != nil {
+		writeDecodeError(w, err)
+		return nil, false
+	}
+	return &req, true
+}
+
+// parseShareID reads the shareID path parameter; IDs that cannot exist are reported as not found
+func parseShareID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	raw := chi.URLParam(r, "shareID")
+	id, err := strconv.Atoi(raw)
+	if err != nil || id <= 0 {
+		utils.WriteProblem(w, http.StatusNotFound, utils.CodeNotFound, fmt.Sprintf("share %q: %v", raw, store.ErrNotFound))
+		return 0, false
+	}
+	return id, true
+}
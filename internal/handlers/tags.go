@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gitvam/platform-go-challenge/internal/models"
+	"github.com/gitvam/platform-go-challenge/internal/utils"
+	"github.com/go-chi/chi/v5"
+)
+
+// ListTags godoc
+// @Summary      List a user's tags
+// @Description  Get every tag on the user's favorites with the number of favorites carrying it, most used first.
+// @Tags         tags
+// @Param        userID path string true "User ID"
+// @Param        If-None-Match header string false "ETag from a previous response; returns 304 if unchanged"
+// @Success      200 {object} utils.SuccessResponse{data=[]models.TagCount}
+// @Success      304 "Not Modified"
+// @Header       200 {string} ETag "Weak ETag of the response body"
+// @Failure      401 {object} utils.Problem
+// @Router       /v1/users/{userID}/tags [get]
+func (h *Handler) ListTags(w http.ResponseWriter, r *http.Request) {
+	userID, ok := getUserIDOrAbort(w, r)
+	if !ok {
+		return
+	}
+	tags, err := h.storeFor(r).ListTags(userID)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	utils.WriteWithETag(w, r, http.StatusOK, utils.SuccessResponse{
+		Status: "success",
+		Data:   tags,
+	})
+}
+
+// AddFavoriteTags godoc
+// @Summary      Tag a favorite
+// @Description  Add tags to a favorite. Tags are trimmed and lower-cased; tags the favorite already has are ignored.
+// @Description  Send the favorite's ETag in If-Match to make the change conditional.
+// @Tags         tags
+// @Param        userID path string true "User ID"
+// @Param        assetID path string true "Asset External ID"
+// @Param        type query string true "Asset Type (chart, insight, audience, dashboard)"
+// @Param        body body handlers.TagsRequest true "Tags to add"
+// @Param        If-Match header string false "ETag of the favorite being tagged"
+// @Param        Idempotency-Key header string false "Key that makes retries of this request safe"
+// @Success      200 {object} utils.SuccessResponse
+// @Header       200 {string} ETag "New version of the favorite"
+// @Failure      400 {object} utils.Problem
+// @Failure      401 {object} utils.Problem
+// @Failure      404 {object} utils.Problem
+// @Failure      412 {object} utils.Problem "If-Match does not match the current version"
+// @Failure      413 {object} utils.Problem "Request body too large"
+// @Failure      422 {object} utils.Problem "Idempotency-Key reused with a different request"
+// @Router       /v1/users/{userID}/favorites/{assetID}/tags [post]
+func (h *Handler) AddFavoriteTags(w http.ResponseWriter, r *http.Request) {
+	userID, ok := getUserIDOrAbort(w, r)
+	if !ok {
+		return
+	}
+	assetID := chi.URLParam(r, "assetID")
+	assetType := r.URL.Query().Get("type")
+	if assetType == "" {
+		utils.WriteProblem(w, http.StatusBadRequest, utils.CodeInvalidAssetType, "missing asset type")
+		return
+	}
+	expectedVersion, ok := parseIfMatch(w, r)
+	if !ok {
+		return
+	}
+	body, ok := readJSONBody(w, r)
+	if !ok {
+		return
+	}
+	var req struct {
+		Tags []string `json:"tags"`
+	}
+	if err := models.DecodeStrict(body, &req); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	if req.Tags == nil {
+		writeDecodeError(w, &models.DecodeError{Field: "tags", Msg: "is required"})
+		return
+	}
+	tags, version, err := h.storeFor(r).AddFavoriteTags(userID, assetType, assetID, req.Tags, expectedVersion)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	writeTags(w, r, assetID, tags, version)
+}
+
+// RemoveFavoriteTag godoc
+// @Summary      Untag a favorite
+// @Description  Remove a tag from a favorite. Send the favorite's ETag in If-Match to make the change conditional.
+// @Tags         tags
+// @Param        userID path string true "User ID"
+// @Param        assetID path string true "Asset External ID"
+// @Param        tag path string true "Tag to remove"
+// @Param        type query string true "Asset Type (chart, insight, audience, dashboard)"
+// @Param        If-Match header string false "ETag of the favorite being untagged"
+// @Param        Idempotency-Key header string false "Key that makes retries of this request safe"
+// @Success      200 {object} utils.SuccessResponse
+// @Header       200 {string} ETag "New version of the favorite"
+// @Failure      400 {object} utils.Problem
+// @Failure      401 {object} utils.Problem
+// @Failure      404 {object} utils.Problem "Favorite not found or does not have the tag"
+// @Failure      412 {object} utils.Problem "If-Match does not match the current version"
+// @Failure      422 {object} utils.Problem "Idempotency-Key reused with a different request"
+// @Router       /v1/users/{userID}/favorites/{assetID}/tags/{tag} [delete]
+func (h *Handler) RemoveFavoriteTag(w http.ResponseWriter, r *http.Request) {
+	userID, ok := getUserIDOrAbort(w, r)
+	if !ok {
+		return
+	}
+	assetID := chi.URLParam(r, "assetID")
+	assetType := r.URL.Query().Get("type")
+	if assetType == "" {
+		utils.WriteProblem(w, http.StatusBadRequest, utils.CodeInvalidAssetType, "missing asset type")
+		return
+	}
+	expectedVersion, ok := parseIfMatch(w, r)
+	if !ok {
+		return
+	}
+	tags, version, err := h.storeFor(r).RemoveFavoriteTag(userID, assetType, assetID, chi.URLParam(r, "tag"), expectedVersion)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	writeTags(w, r, assetID, tags, version)
+}
+
+// writeTags reports a favorite's tags after a change, tagged with its new version
+func writeTags(w http.ResponseWriter, r *http.Request, assetID string, tags []string, version int) {
+	w.Header().Set("ETag", utils.VersionETag(version))
+	utils.Write(w, r, http.StatusOK, utils.SuccessResponse{
+		Status: "success",
+		Data: map[string]any{
+			"asset_id": assetID,
+			"tags":     tags,
+			"version":  version,
+		},
+	})
+}
+
+// TagsRequest is used in Swagger annotations
+type TagsRequest struct {
+	Tags []string `json:"tags"`
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gitvam/platform-go-challenge/internal/utils"
+	"github.com/go-chi/chi/v5"
+)
+
+// ListTrash godoc
+// @Summary      List trashed favorites
+// @Description  Get the user's removed favorites, most recently removed first. They are purged permanently once
+// @Description  they have been in the trash longer than the retention period (TRASH_RETENTION, 30 days by default).
+// @Tags         favorites
+// @Param        userID path string true "User ID"
+// @Param        limit query int false "Page size" default(10) minimum(1) maximum(100)
+// @Param        offset query int false "Page offset" default(0) minimum(0)
+// @Param        expand query string false "Comma-separated expansions; supports: members"
+// @Param        tag query string false "Comma-separated tags to filter by"
+// @Param        tag_match query string false "Whether favorites need any or all of the tags" Enums(any, all) default(any)
+// @Param        If-None-Match header string false "ETag from a previous response; returns 304 if unchanged"
+// @Success      200 {object} utils.SuccessResponse{data=[]models.TrashedFavorite}
+// @Success      304 "Not Modified"
+// @Header       200 {string} ETag "Weak ETag of the response body"
+// @Failure      400 {object} utils.Problem
+// @Failure      401 {object} utils.Problem
+// @Router       /v1/users/{userID}/favorites/trash [get]
+func (h *Handler) ListTrash(w http.ResponseWriter, r *http.Request) {
+	userID, ok := getUserIDOrAbort(w, r)
+	if !ok {
+		return
+	}
+	opts, ok := parseListOptions(w, r)
+	if !ok {
+		return
+	}
+	trash, err := h.storeFor(r).ListTrash(userID, opts)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	utils.WriteWithETag(w, r, http.StatusOK, utils.SuccessResponse{
+		Status: "success",
+		Data:   trash,
+	})
+}
+
+// RestoreFavorite godoc
+// @Summary      Restore a trashed favorite
+// @Description  Bring a removed favorite back from the trash with its description, tags, pin, position and collections.
+// @Tags         favorites
+// @Param        userID path string true "User ID"
+// @Param        assetID path string true "Asset External ID"
+// @Param        type query string true "Asset Type (chart, insight, audience, dashboard)"
+// @Param        Idempotency-Key header string false "Key that makes retries of this request safe"
+// @Success      200 {object} utils.SuccessResponse
+// @Header       200 {string} ETag "New version of the favorite"
+// @Failure      400 {object} utils.Problem
+// @Failure      401 {object} utils.Problem
+// @Failure      404 {object} utils.Problem "Favorite is not in the trash"
+// @Failure      422 {object} utils.Problem "Idempotency-Key reused with a different request"
+// @Router       /v1/users/{userID}/favorites/{assetID}/restore [post]
+func (h *Handler) RestoreFavorite(w http.ResponseWriter, r *http.Request) {
+	userID, ok := getUserIDOrAbort(w, r)
+	if !ok {
+		return
+	}
+	assetID := chi.URLParam(r, "assetID")
+	assetType := r.URL.Query().Get("type")
+	if assetType == "" {
+		utils.WriteProblem(w, http.StatusBadRequest, utils.CodeInvalidAssetType, "missing asset type")
+		return
+	}
+	version, err := h.storeFor(r).RestoreFavorite(userID, assetType, assetID)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	w.Header().Set("ETag", utils.VersionETag(version))
+	utils.Write(w, r, http.StatusOK, utils.SuccessResponse{
+		Status: "success",
+		Data: map[string]any{
+			"asset_id": assetID,
+			"version":  version,
+		},
+	})
+}
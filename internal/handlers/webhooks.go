@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gitvam/platform-go-challenge/internal/models"
+	"github.com/gitvam/platform-go-challenge/internal/store"
+	"github.com/gitvam/platform-go-challenge/internal/utils"
+	"github.com/go-chi/chi/v5"
+)
+
+// CreateWebhook godoc
+// @Summary      Register a webhook
+// @Description  Admin only. Register a URL to receive the tenant's favorite change events (favorite.added,
+// @Description  favorite.removed, favorite.updated, favorite.restored), all of them unless events is set.
+// @Description  Each delivery is a signed POST; the secret is only returned here.
+// @Tags         webhooks
+// @Param        body body models.WebhookRequest true "URL and optional event types"
+// @Param        Idempotency-Key header string false "Key that makes retries of this request safe"
+// @Success      201 {object} utils.SuccessResponse{data=models.Webhook}
+// @Failure      400 {object} utils.Problem
+// @Failure      401 {object} utils.Problem
+// @Failure      403 {object} utils.Problem "Token lacks the admin role"
+// @Failure      413 {object} utils.Problem "Request body too large"
+// @Failure      422 {object} utils.Problem "Idempotency-Key reused with a different request"
+// @Router       /v1/admin/webhooks [post]
+func (h *Handler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := getUserIDOrAbort(w, r)
+	if !ok {
+		return
+	}
+	body, ok := readJSONBody(w, r)
+	if !ok {
+		return
+	}
+	var req models.WebhookRequest
+	if err := models.DecodeStrict(body, &req); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	wh, err := h.storeFor(r).CreateWebhook(adminID, req)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	w.Header().Set("Location", fmt.Sprintf("/v1/admin/webhooks/%d", wh.ID))
+	utils.Write(w, r, http.StatusCreated, utils.SuccessResponse{Status: "success", Data: wh})
+}
+
+// ListWebhooks godoc
+// @Summary      List webhooks
+// @Description  Admin only. Get the tenant's webhooks, without their secrets.
+// @Tags         webhooks
+// @Success      200 {object} utils.SuccessResponse{data=[]models.Webhook}
+// @Failure      401 {object} utils.Problem
+// @Failure      403 {object} utils.Problem "Token lacks the admin role"
+// @Router       /v1/admin/webhooks [get]
+func (h *Handler) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	if _, ok := getUserIDOrAbort(w, r); !ok {
+		return
+	}
+	webhooks, err := h.storeFor(r).ListWebhooks()
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	utils.Write(w, r, http.StatusOK, utils.SuccessResponse{Status: "success", Data: webhooks})
+}
+
+// DeleteWebhook godoc
+// @Summary      Delete a webhook
+// @Description  Admin only. Stop sending events to a webhook and discard its deliveries.
+// @Tags         webhooks
+// @Param        webhookID path int true "Webhook ID"
+// @Param        Idempotency-Key header string false "Key that makes retries of this request safe"
+// @Success      204 "No Content"
+// @Failure      401 {object} utils.Problem
+// @Failure      403 {object} utils.Problem "Token lacks the admin role"
+// @Failure      404 {object} utils.Problem
+// @Failure      422 {object} utils.Problem "Idempotency-Key reused with a different request"
+// @Router       /v1/admin/webhooks/{webhookID} [delete]
+func (h *Handler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := getUserIDOrAbort(w, r)
+	if !ok {
+		return
+	}
+	webhookID, ok := parseWebhookID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.storeFor(r).DeleteWebhook(adminID, webhookID); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListWebhookDeliveries godoc
+// @Summary      List a webhook's deliveries
+// @Description  Admin only. Get a webhook's deliveries newest first, e.g. status=dead for the dead-lettered ones.
+// @Tags         webhooks
+// @Param        webhookID path int true "Webhook ID"
+// @Param        status query string false "Delivery status" Enums(pending, delivered, dead)
+// @Param        limit query int false "Page size" default(10)
+// @Param        offset query int false "Page offset" default(0)
+// @Success      200 {object} utils.SuccessResponse{data=[]models.WebhookDelivery}
+// @Failure      400 {object} utils.Problem
+// @Failure      401 {object} utils.Problem
+// @Failure      403 {object} utils.Problem "Token lacks the admin role"
+// @Failure      404 {object} utils.Problem
+// @Router       /v1/admin/webhooks/{webhookID}/deliveries [get]
+func (h *Handler) ListWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	if _, ok := getUserIDOrAbort(w, r); !ok {
+		return
+	}
+	webhookID, ok := parseWebhookID(w, r)
+	if !ok {
+		return
+	}
+	status := r.URL.Query().Get("status")
+	var v models.Validator
+	v.OneOf("status", status, models.DeliveryStatuses)
+	if err := v.Err(); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	opts := store.ListOptions{
+		Limit:  utils.ParseQueryInt(r, "limit", 10),
+		Offset: utils.ParseQueryInt(r, "offset", 0),
+	}
+	deliveries, err := h.storeFor(r).ListWebhookDeliveries(webhookID, status, opts)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	utils.Write(w, r, http.StatusOK, utils.SuccessResponse{Status: "success", Data: deliveries})
+}
+
+// ReplayWebhookDelivery godoc
+// @Summary      Replay a webhook delivery
+// @Description  Admin only. Send a delivery again with a fresh set of attempts, typically after fixing the
+// @Description  receiver of a dead-lettered one. The event keeps its ID so receivers can drop duplicates.
+// @Tags         webhooks
+// @Param        webhookID path int true "Webhook ID"
+// @Param        deliveryID path int true "Delivery ID"
+// @Param        Idempotency-Key header string false "Key that makes retries of this request safe"
+// @Success      202 {object} utils.SuccessResponse{data=models.WebhookDelivery}
+// @Failure      401 {object} utils.Problem
+// @Failure      403 {object} utils.Problem "Token lacks the admin role"
+// @Failure      404 {object} utils.Problem
+// @Failure      422 {object} utils.Problem "Idempotency-Key reused with a different request"
+// @Router       /v1/admin/webhooks/{webhookID}/deliveries/{deliveryID}/replay [post]
+func (h *Handler) ReplayWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	if _, ok := getUserIDOrAbort(w, r); !ok {
+		return
+	}
+	webhookID, ok := parseWebhookID(w, r)
+	if !ok {
+		return
+	}
+	raw := chi.URLParam(r, "deliveryID")
+	deliveryID, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || deliveryID <= 0 {
+		utils.WriteProblem(w, http.StatusNotFound, utils.CodeNotFound, fmt.Sprintf("delivery %q: %v", raw, store.ErrNotFound))
+		return
+	}
+	d, err := h.storeFor(r).ReplayWebhookDelivery(webhookID, deliveryID)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	utils.Write(w, r, http.StatusAccepted, utils.SuccessResponse{Status: "success", Data: d})
+}
+
+// parseWebhookID reads the webhookID path parameter; IDs that cannot exist are reported as not found
+func parseWebhookID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	raw := chi.URLParam(r, "webhookID")
+	id, err := strconv.Atoi(raw)
+	if err != nil || id <= 0 {
+		utils.WriteProblem(w, http.StatusNotFound, utils.CodeNotFound, fmt.Sprintf("webhook %q: %v", raw, store.ErrNotFound))
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,14 @@
+package middleware
+
+import "net/http"
+
+// MaxBodySize caps request bodies at limit bytes. Reads past the limit fail with
+// *http.MaxBytesError, which handlers report as 413 Payload Too Large.
+func MaxBodySize(limit int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
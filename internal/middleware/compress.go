@@ -0,0 +1,222 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gitvam/platform-go-challenge/internal/utils"
+	"github.com/klauspost/compress/zstd"
+)
+
+// compressMinSize is the smallest response worth compressing; smaller ones are sent
+// as they are, since compression would save little or even add bytes
+const compressMinSize = 1024
+
+// encoder is a pooled gzip or zstd compressor
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// contentEncoding is a Content-Encoding the server can compress responses with
+type contentEncoding struct {
+	name string
+	pool *sync.Pool
+}
+
+// contentEncodings lists the supported encodings, preferred first when a client
+// accepts several equally
+var contentEncodings = []contentEncoding{
+	{name: "zstd", pool: &sync.Pool{New: func() any {
+		// Clients need not decode windows over 8 MB (RFC 9659), and one
+		// goroutine per encoder keeps concurrent responses from competing
+		enc, _ := zstd.NewWriter(nil, zstd.WithWindowSize(8<<20), zstd.WithEncoderConcurrency(1))
+		return enc
+	}}},
+	{name: "gzip", pool: &sync.Pool{New: func() any { return gzip.NewWriter(nil) }}},
+}
+
+// Compress compresses responses with the encoding the request's Accept-Encoding
+// prefers among zstd and gzip. Responses under compressMinSize, in media types that
+// do not compress well, or already encoded are sent as they are, and so are event
+// streams, which must reach clients as soon as each event is written. Strong ETags
+// of compressed responses get the encoding as a suffix, since they identify the
+// bytes sent; If-Match strips it to compare favorite versions.
+func Compress(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		enc, ok := negotiateEncoding(r.Header.Values("Accept-Encoding"))
+		if !ok || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+		cw := &compressWriter{ResponseWriter: w, encoding: enc}
+		defer cw.Close()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// negotiateEncoding picks the supported encoding with the highest quality in an
+// Accept-Encoding header, where "*" stands for any encoding not listed
+func negotiateEncoding(accept []string) (contentEncoding, bool) {
+	qualities := map[string]float64{}
+	for _, h := range accept {
+		for _, part := range strings.Split(h, ",") {
+			name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+			name = strings.ToLower(strings.TrimSpace(name))
+			if name == "x-gzip" {
+				name = "gzip"
+			}
+			q := 1.0
+			if k, v, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(k) == "q" {
+				var err error
+				if q, err = strconv.ParseFloat(strings.TrimSpace(v), 64); err != nil || q < 0 || q > 1 {
+					q = 0
+				}
+			}
+			if name != "" {
+				qualities[name] = q
+			}
+		}
+	}
+	best, bestQ := contentEncoding{}, 0.0
+	for _, enc := range contentEncodings {
+		q, ok := qualities[enc.name]
+		if !ok {
+			q = qualities["*"]
+		}
+		if q > bestQ {
+			best, bestQ = enc, q
+		}
+	}
+	return best, bestQ > 0
+}
+
+// compressible reports whether responses of a Content-Type are worth compressing
+func compressible(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	switch {
+	case mediaType == "text/event-stream":
+		return false
+	case strings.HasPrefix(mediaType, "text/"), strings.HasSuffix(mediaType, "+json"):
+		return true
+	}
+	switch mediaType {
+	case "application/json", "application/msgpack", "application/x-ndjson", "application/javascript", "application/yaml":
+		return true
+	}
+	return false
+}
+
+// compressWriter holds a response back until it is known to reach compressMinSize,
+// then compresses the rest of it. Responses that end or are flushed sooner, and those
+// not worth compressing, are passed through unchanged.
+type compressWriter struct {
+	http.ResponseWriter
+	encoding contentEncoding
+	status   int
+	buf      []byte
+	// passthrough is set once the response is sent uncompressed, and enc once it is compressed
+	passthrough bool
+	enc         encoder
+}
+
+func (cw *compressWriter) WriteHeader(status int) {
+	if cw.status != 0 || cw.passthrough || cw.enc != nil {
+		return
+	}
+	h := cw.Header()
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified ||
+		h.Get("Content-Encoding") != "" || !compressible(h.Get("Content-Type")) {
+		cw.passthrough = true
+		cw.ResponseWriter.WriteHeader(status)
+		return
+	}
+	cw.status = status
+}
+
+func (cw *compressWriter) Write(b []byte) (int, error) {
+	if cw.status == 0 && !cw.passthrough && cw.enc == nil {
+		cw.WriteHeader(http.StatusOK)
+	}
+	switch {
+	case cw.passthrough:
+		return cw.ResponseWriter.Write(b)
+	case cw.enc != nil:
+		return cw.enc.Write(b)
+	}
+	cw.buf = append(cw.buf, b...)
+	if len(cw.buf) >= compressMinSize {
+		if err := cw.startCompressing(); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// startCompressing sends the header with the encoding and compresses what is held back
+func (cw *compressWriter) startCompressing() error {
+	h := cw.Header()
+	h.Set("Content-Encoding", cw.encoding.name)
+	h.Del("Content-Length")
+	if etag := h.Get("ETag"); etag != "" {
+		h.Set("ETag", utils.EncodedETag(etag, cw.encoding.name))
+	}
+	cw.ResponseWriter.WriteHeader(cw.status)
+	cw.enc = cw.encoding.pool.Get().(encoder)
+	cw.enc.Reset(cw.ResponseWriter)
+	_, err := cw.enc.Write(cw.buf)
+	cw.buf = nil
+	return err
+}
+
+// sendUncompressed sends the header and whatever is held back as they are
+func (cw *compressWriter) sendUncompressed() error {
+	cw.passthrough = true
+	cw.ResponseWriter.WriteHeader(cw.status)
+	_, err := cw.ResponseWriter.Write(cw.buf)
+	cw.buf = nil
+	return err
+}
+
+// Flush sends what has been written so far, so a response flushed before reaching
+// compressMinSize is sent uncompressed
+func (cw *compressWriter) Flush() {
+	switch {
+	case cw.enc != nil:
+		cw.enc.Flush()
+	case cw.status != 0 && !cw.passthrough:
+		cw.sendUncompressed()
+	}
+	http.NewResponseController(cw.ResponseWriter).Flush()
+}
+
+// Close ends the response, sending anything still held back
+func (cw *compressWriter) Close() error {
+	switch {
+	case cw.enc != nil:
+		err := cw.enc.Close()
+		cw.enc.Reset(io.Discard)
+		cw.encoding.pool.Put(cw.enc)
+		cw.enc = nil
+		cw.passthrough = true
+		return err
+	case cw.status != 0 && !cw.passthrough:
+		return cw.sendUncompressed()
+	}
+	return nil
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
@@ -0,0 +1,146 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	cases := []struct {
+		accept string
+		want   string // empty when responses are sent uncompressed
+	}{
+		{"", ""},
+		{"gzip", "gzip"},
+		{"x-gzip", "gzip"},
+		{"gzip, deflate, br, zstd", "zstd"},
+		{"zstd;q=0.5, gzip", "gzip"},
+		{"*", "zstd"},
+		{"*;q=0.2, zstd;q=0", "gzip"},
+		{"gzip;q=0, identity", ""},
+		{"br, deflate", ""},
+	}
+	for _, c := range cases {
+		enc, ok := negotiateEncoding([]string{c.accept})
+		if ok != (c.want != "") || enc.name != c.want {
+			t.Errorf("Accept-Encoding %q: got %q (%v), want %q", c.accept, enc.name, ok, c.want)
+		}
+	}
+}
+
+func compressServe(h http.HandlerFunc, acceptEncoding string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest("GET", "/", nil)
+	if acceptEncoding != "" {
+		r.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	w := httptest.NewRecorder()
+	Compress(h).ServeHTTP(w, r)
+	return w
+}
+
+func TestCompress(t *testing.T) {
+	body := `{"data":[` + strings.Repeat(`{"title":"q3 revenue"},`, 100) + `{}]}`
+	writeJSON := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		// Written in pieces, so the response is held back until it is large enough
+		for i := 0; i < len(body); i += 100 {
+			io.WriteString(w, body[i:min(i+100, len(body))])
+		}
+	}
+
+	for name, decode := range map[string]func(io.Reader) (io.Reader, error){
+		"gzip": func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) },
+		"zstd": func(r io.Reader) (io.Reader, error) { return zstd.NewReader(r) },
+	} {
+		w := compressServe(writeJSON, name)
+		if w.Code != http.StatusCreated || w.Header().Get("Content-Encoding") != name || w.Header().Get("Vary") != "Accept-Encoding" {
+			t.Fatalf("%s: unexpected response %d %v", name, w.Code, w.Header())
+		}
+		if w.Body.Len() >= len(body) {
+			t.Errorf("%s: expected a smaller body, got %d bytes for %d", name, w.Body.Len(), len(body))
+		}
+		dec, err := decode(w.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := io.ReadAll(dec); err != nil || string(got) != body {
+			t.Errorf("%s: body did not round-trip (%v)", name, err)
+		}
+	}
+
+	if w := compressServe(writeJSON, ""); w.Header().Get("Content-Encoding") != "" || w.Body.String() != body {
+		t.Errorf("expected an uncompressed response without Accept-Encoding, got %v", w.Header())
+	}
+}
+
+func TestCompress_ETags(t *testing.T) {
+	large := strings.Repeat("x", 2*compressMinSize)
+	tagged := func(etag, body string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("ETag", etag)
+			io.WriteString(w, body)
+		}
+	}
+	if got := compressServe(tagged(`"3"`, large), "gzip").Header().Get("ETag"); got != `"3-gzip"` {
+		t.Errorf("expected the strong ETag to name the encoding, got %s", got)
+	}
+	if got := compressServe(tagged(`"3"`, large), "zstd").Header().Get("ETag"); got != `"3-zstd"` {
+		t.Errorf("expected the strong ETag to name the encoding, got %s", got)
+	}
+	if got := compressServe(tagged(`W/"abc"`, large), "gzip").Header().Get("ETag"); got != `W/"abc"` {
+		t.Errorf("expected the weak ETag to be left as it is, got %s", got)
+	}
+	if got := compressServe(tagged(`"3"`, "{}"), "gzip").Header().Get("ETag"); got != `"3"` {
+		t.Errorf("expected uncompressed responses to keep their ETag, got %s", got)
+	}
+}
+
+func TestCompress_SendsAsIs(t *testing.T) {
+	large := strings.Repeat("x", 2*compressMinSize)
+	cases := map[string]http.HandlerFunc{
+		"small": func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, `{"status":"success"}`)
+		},
+		"incompressible type": func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/zip")
+			io.WriteString(w, large)
+		},
+		"event stream": func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			io.WriteString(w, large)
+		},
+		"already encoded": func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/plain")
+			w.Header().Set("Content-Encoding", "br")
+			io.WriteString(w, large)
+		},
+		"flushed early": func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			io.WriteString(w, "{}\n")
+			http.NewResponseController(w).Flush()
+			io.WriteString(w, large)
+		},
+	}
+	for name, h := range cases {
+		w := compressServe(h, "gzip")
+		if enc := w.Header().Get("Content-Encoding"); enc != "" && enc != "br" {
+			t.Errorf("%s: expected no compression, got %q", name, enc)
+		}
+		if !strings.HasSuffix(w.Body.String(), "}") && !strings.HasSuffix(w.Body.String(), large) {
+			t.Errorf("%s: body changed: %q", name, w.Body.String())
+		}
+	}
+
+	if w := compressServe(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) }, "gzip"); w.Code != http.StatusNoContent {
+		t.Errorf("expected 204 to pass through, got %d", w.Code)
+	}
+}
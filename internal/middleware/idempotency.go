@@ -0,0 +1,170 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gitvam/platform-go-challenge/internal/store"
+	"github.com/gitvam/platform-go-challenge/internal/utils"
+)
+
+const (
+	// IdempotencyKeyHeader is the request header clients use to make writes safe to retry
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader is set on responses replayed from a stored record
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+)
+
+// replayedHeaders are the response headers stored with a response and sent again on replays
+var replayedHeaders = []string{"ETag", "Location"}
+
+// IdempotencyStore persists idempotency records so they are shared across replicas
+type IdempotencyStore interface {
+	ReserveIdempotencyKey(userID, key, requestHash string, ttl, lease time.Duration) (string, *store.IdempotencyRecord, error)
+	CompleteIdempotencyKey(userID, key, reservation string, statusCode int, contentType string, headers map[string]string, body []byte) error
+	ReleaseIdempotencyKey(userID, key, reservation string) error
+}
+
+// Idempotency honors the Idempotency-Key header. The first response for a user and key
+// is stored for ttl and replayed on identical retries; reusing a key with a different
+// request is rejected with 422. A key whose request has not finished within lease, as
+// when the server crashed while handling it, may be used again. Requests without the
+// header pass straight through. Keys are kept per tenant in the store forTenant
+// returns. It must run after JWTAuthMiddleware.
+func Idempotency(forTenant func(tenantID string) IdempotencyStore, ttl, lease time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				utils.WriteProblem(w, http.StatusBadRequest, utils.CodeBadRequest, "Idempotency-Key must be at most 255 characters")
+				return
+			}
+			userID, ok := GetUserIDFromContext(r)
+			if !ok {
+				utils.WriteProblem(w, http.StatusUnauthorized, utils.CodeUnauthorized, "user ID missing from context")
+				return
+			}
+			tenantID, ok := GetTenantIDFromContext(r)
+			if !ok {
+				utils.WriteProblem(w, http.StatusUnauthorized, utils.CodeUnauthorized, "tenant missing from context")
+				return
+			}
+			s := forTenant(tenantID)
+
+			body, err := io.ReadAll(r.Body)
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				utils.WriteProblem(w, http.StatusRequestEntityTooLarge, utils.CodePayloadTooLarge,
+					fmt.Sprintf("request body must be at most %d bytes", tooLarge.Limit))
+				return
+			}
+			if err != nil {
+				utils.WriteProblem(w, http.StatusBadRequest, utils.CodeBadRequest, "could not read request body")
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			hash := requestHash(r, body)
+			reservation, rec, err := s.ReserveIdempotencyKey(userID, key, hash, ttl, lease)
+			if err != nil {
+				log.Printf("[ERROR] reserving idempotency key: %v", err)
+				utils.WriteProblem(w, http.StatusInternalServerError, utils.CodeInternal, "could not check Idempotency-Key")
+				return
+			}
+			if rec != nil {
+				switch {
+				case rec.RequestHash != hash:
+					utils.WriteProblem(w, http.StatusUnprocessableEntity, utils.CodeIdempotencyKeyReused, "Idempotency-Key was already used with a different request")
+				case rec.InProgress():
+					utils.WriteProblem(w, http.StatusConflict, utils.CodeIdempotencyInProgress, "a request with this Idempotency-Key is still in progress")
+				default:
+					if rec.ContentType != "" {
+						w.Header().Set("Content-Type", rec.ContentType)
+					}
+					for name, value := range rec.Headers {
+						w.Header().Set(name, value)
+					}
+					w.Header().Set(IdempotentReplayedHeader, "true")
+					w.WriteHeader(rec.StatusCode)
+					w.Write(rec.Body)
+				}
+				return
+			}
+
+			rw := &recordingWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rw, r)
+
+			// Server errors are not stored so the client can retry with the same key
+			if rw.status >= http.StatusInternalServerError {
+				if err := s.ReleaseIdempotencyKey(userID, key, reservation); err != nil {
+					log.Printf("[ERROR] releasing idempotency key: %v", err)
+				}
+				return
+			}
+			headers := map[string]string{}
+			for _, name := range replayedHeaders {
+				if value := rw.Header().Get(name); value != "" {
+					headers[name] = value
+				}
+			}
+			err = s.CompleteIdempotencyKey(userID, key, reservation, rw.status, rw.Header().Get("Content-Type"), headers, rw.body.Bytes())
+			switch {
+			case errors.Is(err, store.ErrReservationLost):
+				// The lease ran out and a retry took the key over; its response is the one kept
+				log.Printf("[WARN] idempotent response not stored: %v", err)
+			case err != nil:
+				log.Printf("[ERROR] storing idempotent response: %v", err)
+			}
+		})
+	}
+}
+
+// requestHash fingerprints the parts of a request that must match on a retry
+func requestHash(r *http.Request, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, r.Method+"\n"+r.URL.Path+"\n"+r.URL.RawQuery+"\n")
+	// The body means the same only in the same format, and the stored response is in the negotiated one
+	io.WriteString(h, r.Header.Get("Content-Type")+"\n"+r.Header.Get("Accept")+"\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// recordingWriter passes the response through while keeping a copy of it
+type recordingWriter struct {
+	http.ResponseWriter
+	status      int
+	body        bytes.Buffer
+	wroteHeader bool
+}
+
+func (rw *recordingWriter) WriteHeader(status int) {
+	if !rw.wroteHeader {
+		rw.status = status
+		rw.wroteHeader = true
+	}
+	rw.ResponseWriter.WriteHeader(status)
+}
+
+func (rw *recordingWriter) Write(b []byte) (int, error) {
+	rw.wroteHeader = true
+	rw.body.Write(b)
+	return rw.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer to flush or set deadlines
+func (rw *recordingWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
@@ -0,0 +1,258 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gitvam/platform-go-challenge/internal/store"
+)
+
+type memIdempotencyStore struct {
+	mu           sync.Mutex
+	records      map[string]*store.IdempotencyRecord
+	leases       map[string]time.Time
+	reservations map[string]string
+	reserved     int
+}
+
+func newMemIdempotencyStore() *memIdempotencyStore {
+	return &memIdempotencyStore{records: map[string]*store.IdempotencyRecord{}, leases: map[string]time.Time{}, reservations: map[string]string{}}
+}
+
+func (m *memIdempotencyStore) ReserveIdempotencyKey(userID, key, requestHash string, ttl, lease time.Duration) (string, *store.IdempotencyRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	id := userID + "/" + key
+	if rec, ok := m.records[id]; ok && rec.ExpiresAt.After(time.Now()) && (!rec.InProgress() || m.leases[id].After(time.Now())) {
+		cp := *rec
+		return "", &cp, nil
+	}
+	m.reserved++
+	m.records[id] = &store.IdempotencyRecord{UserID: userID, Key: key, RequestHash: requestHash, ExpiresAt: time.Now().Add(ttl)}
+	m.leases[id] = time.Now().Add(lease)
+	m.reservations[id] = fmt.Sprint(m.reserved)
+	return m.reservations[id], nil, nil
+}
+
+func (m *memIdempotencyStore) CompleteIdempotencyKey(userID, key, reservation string, statusCode int, contentType string, headers map[string]string, body []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	id := userID + "/" + key
+	rec := m.records[id]
+	if rec == nil || !rec.InProgress() || m.reservations[id] != reservation {
+		return store.ErrReservationLost
+	}
+	rec.StatusCode, rec.ContentType, rec.Headers, rec.Body = statusCode, contentType, headers, append([]byte(nil), body...)
+	return nil
+}
+
+func (m *memIdempotencyStore) ReleaseIdempotencyKey(userID, key, reservation string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	id := userID + "/" + key
+	if m.reservations[id] == reservation {
+		delete(m.records, id)
+	}
+	return nil
+}
+
+// memTenants keeps a separate in-memory store per tenant
+type memTenants struct {
+	mu      sync.Mutex
+	tenants map[string]*memIdempotencyStore
+}
+
+func newMemTenants() *memTenants {
+	return &memTenants{tenants: map[string]*memIdempotencyStore{}}
+}
+
+func (m *memTenants) forTenant(tenantID string) IdempotencyStore {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.tenants[tenantID]; !ok {
+		m.tenants[tenantID] = newMemIdempotencyStore()
+	}
+	return m.tenants[tenantID]
+}
+
+func withUser(r *http.Request, userID string) *http.Request {
+	return withTenantUser(r, store.DefaultTenant, userID)
+}
+
+func withTenantUser(r *http.Request, tenantID, userID string) *http.Request {
+	ctx := context.WithValue(r.Context(), contextKeyUserID, userID)
+	return r.WithContext(context.WithValue(ctx, contextKeyTenantID, tenantID))
+}
+
+func TestIdempotency_ReplaysFirstResponse(t *testing.T) {
+	calls := 0
+	h := Idempotency(newMemTenants().forTenant, time.Hour, time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", `"1"`)
+		w.Header().Set("Location", "/v1/users/u1/favorites/chart_1")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"status":"success"}`))
+	}))
+
+	for i := 0; i < 2; i++ {
+		req := withUser(httptest.NewRequest("POST", "/favorites", strings.NewReader(`{"type":"chart"}`)), "u1")
+		req.Header.Set(IdempotencyKeyHeader, "k1")
+		resp := httptest.NewRecorder()
+		h.ServeHTTP(resp, req)
+
+		if resp.Code != http.StatusCreated {
+			t.Fatalf("attempt %d: expected 201, got %d", i, resp.Code)
+		}
+		if resp.Body.String() != `{"status":"success"}` {
+			t.Errorf("attempt %d: unexpected body %q", i, resp.Body.String())
+		}
+		if resp.Header().Get("ETag") != `"1"` || resp.Header().Get("Location") != "/v1/users/u1/favorites/chart_1" {
+			t.Errorf("attempt %d: expected ETag and Location, got %v", i, resp.Header())
+		}
+		if replayed := resp.Header().Get(IdempotentReplayedHeader) == "true"; replayed != (i == 1) {
+			t.Errorf("attempt %d: unexpected %s header %q", i, IdempotentReplayedHeader, resp.Header().Get(IdempotentReplayedHeader))
+		}
+	}
+	if calls != 1 {
+		t.Errorf("expected handler to run once, ran %d times", calls)
+	}
+}
+
+func TestIdempotency_RejectsDifferentBody(t *testing.T) {
+	h := Idempotency(newMemTenants().forTenant, time.Hour, time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	for i, body := range []string{`{"type":"chart"}`, `{"type":"insight"}`} {
+		req := withUser(httptest.NewRequest("POST", "/favorites", strings.NewReader(body)), "u1")
+		req.Header.Set(IdempotencyKeyHeader, "k1")
+		resp := httptest.NewRecorder()
+		h.ServeHTTP(resp, req)
+
+		want := []int{http.StatusCreated, http.StatusUnprocessableEntity}[i]
+		if resp.Code != want {
+			t.Errorf("attempt %d: expected %d, got %d", i, want, resp.Code)
+		}
+	}
+}
+
+func TestIdempotency_KeysAreScopedPerUser(t *testing.T) {
+	calls := 0
+	h := Idempotency(newMemTenants().forTenant, time.Hour, time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	for _, user := range []string{"u1", "u2"} {
+		req := withUser(httptest.NewRequest("POST", "/favorites", strings.NewReader(`{}`)), user)
+		req.Header.Set(IdempotencyKeyHeader, "shared")
+		h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	if calls != 2 {
+		t.Errorf("expected handler to run for each user, ran %d times", calls)
+	}
+}
+
+func TestIdempotency_KeysAreScopedPerTenant(t *testing.T) {
+	calls := 0
+	h := Idempotency(newMemTenants().forTenant, time.Hour, time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	for _, tenant := range []string{"acme", "globex"} {
+		req := withTenantUser(httptest.NewRequest("POST", "/favorites", strings.NewReader(`{}`)), tenant, "u1")
+		req.Header.Set(IdempotencyKeyHeader, "shared")
+		h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	if calls != 2 {
+		t.Errorf("expected handler to run for each tenant, ran %d times", calls)
+	}
+}
+
+func TestIdempotency_ServerErrorsAreNotStored(t *testing.T) {
+	calls := 0
+	h := Idempotency(newMemTenants().forTenant, time.Hour, time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	var last int
+	for i := 0; i < 2; i++ {
+		req := withUser(httptest.NewRequest("POST", "/favorites", strings.NewReader(`{}`)), "u1")
+		req.Header.Set(IdempotencyKeyHeader, "k1")
+		resp := httptest.NewRecorder()
+		h.ServeHTTP(resp, req)
+		last = resp.Code
+	}
+	if calls != 2 || last != http.StatusCreated {
+		t.Errorf("expected retry after 500 to run the handler, calls=%d last=%d", calls, last)
+	}
+}
+
+func TestIdempotency_StaleReservationIsTakenOver(t *testing.T) {
+	tenants := newMemTenants()
+	calls := 0
+	h := Idempotency(tenants.forTenant, time.Hour, time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusCreated)
+	}))
+	// A request that crashed, or is just slow, holds the key with a lease that has run out
+	s := tenants.forTenant(store.DefaultTenant)
+	stale, _, _ := s.ReserveIdempotencyKey("u1", "k1", "crashed", time.Hour, -time.Second)
+
+	req := withUser(httptest.NewRequest("POST", "/favorites", strings.NewReader(`{}`)), "u1")
+	req.Header.Set(IdempotencyKeyHeader, "k1")
+	resp := httptest.NewRecorder()
+	h.ServeHTTP(resp, req)
+	if calls != 1 || resp.Code != http.StatusCreated {
+		t.Errorf("expected the retry to run the handler, calls=%d code=%d", calls, resp.Code)
+	}
+
+	// The original request finishing late keeps the retry's response
+	if err := s.CompleteIdempotencyKey("u1", "k1", stale, http.StatusAccepted, "", nil, nil); !errors.Is(err, store.ErrReservationLost) {
+		t.Errorf("expected ErrReservationLost completing a reservation taken over, got %v", err)
+	}
+	s.ReleaseIdempotencyKey("u1", "k1", stale)
+	resp = httptest.NewRecorder()
+	h.ServeHTTP(resp, req)
+	if calls != 1 || resp.Code != http.StatusCreated || resp.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Errorf("expected the retry's response to be replayed, calls=%d code=%d", calls, resp.Code)
+	}
+}
+
+func TestIdempotency_FlushesThroughTheRecorder(t *testing.T) {
+	h := Idempotency(newMemTenants().forTenant, time.Hour, time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := http.NewResponseController(w).Flush(); err != nil {
+			t.Errorf("expected the recorded response to flush, got %v", err)
+		}
+	}))
+	req := withUser(httptest.NewRequest("POST", "/favorites", strings.NewReader(`{}`)), "u1")
+	req.Header.Set(IdempotencyKeyHeader, "k1")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+}
+
+func TestIdempotency_NoHeaderPassesThrough(t *testing.T) {
+	calls := 0
+	h := Idempotency(newMemTenants().forTenant, time.Hour, time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+	for i := 0; i < 2; i++ {
+		h.ServeHTTP(httptest.NewRecorder(), withUser(httptest.NewRequest("POST", "/favorites", strings.NewReader(`{}`)), "u1"))
+	}
+	if calls != 2 {
+		t.Errorf("expected handler to run twice, ran %d times", calls)
+	}
+}
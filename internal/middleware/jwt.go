@@ -2,10 +2,11 @@ package middleware
 
 import (
 	"context"
-	"log"
+	"errors"
 	"net/http"
 	"strings"
 
+	"github.com/gitvam/platform-go-challenge/internal/store"
 	"github.com/gitvam/platform-go-challenge/internal/utils"
 	"github.com/golang-jwt/jwt/v5"
 )
@@ -14,40 +15,69 @@ const jwtSecret = "my_super_secret"
 
 type contextKey string
 
-const contextKeyUserID = contextKey("userID")
+const (
+	contextKeyUserID   = contextKey("userID")
+	contextKeyTenantID = contextKey("tenantID")
+	contextKeyAdmin    = contextKey("admin")
+)
+
+// RoleAdmin in a token's role claim lets the user act for other users and query the audit trail
+const RoleAdmin = "admin"
+
+const maxTenantIDLength = 100
+
+// Identity is who a verified token was issued to
+type Identity struct {
+	UserID   string
+	TenantID string
+	Admin    bool
+}
+
+// ParseToken verifies a bearer token and returns its identity. Errors are fit to show the client.
+func ParseToken(tokenStr string) (Identity, error) {
+	token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrSignatureInvalid
+		}
+		return []byte(jwtSecret), nil
+	})
+	if err != nil || !token.Valid {
+		return Identity{}, errors.New("invalid or expired token")
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["sub"] == nil {
+		return Identity{}, errors.New("invalid token claims")
+	}
+	userID, ok := claims["sub"].(string)
+	if !ok {
+		return Identity{}, errors.New("userID claim missing")
+	}
+	// The org claim names the caller's tenant; tokens without one belong to the default tenant
+	tenantID := store.DefaultTenant
+	if org, present := claims["org"]; present {
+		tenantID, ok = org.(string)
+		if !ok || tenantID == "" || len(tenantID) > maxTenantIDLength {
+			return Identity{}, errors.New("invalid org claim")
+		}
+	}
+	return Identity{UserID: userID, TenantID: tenantID, Admin: claims["role"] == RoleAdmin}, nil
+}
 
 func JWTAuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		authHeader := r.Header.Get("Authorization")
 		if !strings.HasPrefix(authHeader, "Bearer ") {
-			utils.WriteJSONError(w, "unauthorized: invalid or missing token", http.StatusUnauthorized)
-			return
-		}
-		tokenStr := strings.TrimPrefix(authHeader, "Bearer ")
-		log.Println("[DEBUG] Raw token string:", tokenStr)
-		log.Println("[DEBUG] Secret being used:", jwtSecret)
-		token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
-			}
-			return []byte(jwtSecret), nil
-		})
-		log.Printf("[DEBUG] JWT parse result: valid=%v, err=%v\n", token.Valid, err)
-		if err != nil || !token.Valid {
-			utils.WriteJSONError(w, "unauthorized: "+err.Error(), http.StatusUnauthorized)
+			utils.WriteProblem(w, http.StatusUnauthorized, utils.CodeUnauthorized, "invalid or missing token")
 			return
 		}
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok || claims["sub"] == nil {
-			utils.WriteJSONError(w, "invalid token claims", http.StatusUnauthorized)
+		id, err := ParseToken(strings.TrimPrefix(authHeader, "Bearer "))
+		if err != nil {
+			utils.WriteProblem(w, http.StatusUnauthorized, utils.CodeUnauthorized, err.Error())
 			return
 		}
-		userID, ok := claims["sub"].(string)
-		if !ok {
-			utils.WriteJSONError(w, "userID claim missing", http.StatusUnauthorized)
-			return
-		}
-		ctx := context.WithValue(r.Context(), contextKeyUserID, userID)
+		ctx := context.WithValue(r.Context(), contextKeyUserID, id.UserID)
+		ctx = context.WithValue(ctx, contextKeyTenantID, id.TenantID)
+		ctx = context.WithValue(ctx, contextKeyAdmin, id.Admin)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
@@ -57,3 +87,26 @@ func GetUserIDFromContext(r *http.Request) (string, bool) {
 	userID, ok := r.Context().Value(contextKeyUserID).(string)
 	return userID, ok
 }
+
+// IsAdmin reports whether the JWT middleware found the admin role in the token
+func IsAdmin(r *http.Request) bool {
+	admin, _ := r.Context().Value(contextKeyAdmin).(bool)
+	return admin
+}
+
+// RequireAdmin rejects requests whose token lacks the admin role. It must run after JWTAuthMiddleware.
+func RequireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !IsAdmin(r) {
+			utils.WriteProblem(w, http.StatusForbidden, utils.CodeForbidden, "admin role required")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// GetTenantIDFromContext retrieves the tenant set by the JWT middleware
+func GetTenantIDFromContext(r *http.Request) (string, bool) {
+	tenantID, ok := r.Context().Value(contextKeyTenantID).(string)
+	return tenantID, ok && tenantID != ""
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gitvam/platform-go-challenge/internal/utils"
+)
+
+// Negotiate rejects requests whose Accept header allows none of the response formats
+// with 406 Not Acceptable, and bodies in a format that cannot be decoded with 415
+// Unsupported Media Type, before the handler changes anything. Handlers then write
+// with utils.Write and read bodies through utils.BodyToJSON.
+func Negotiate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !acceptable(w, r) {
+			return
+		}
+		if _, ok := utils.RequestCodec(r); !ok {
+			utils.WriteProblem(w, http.StatusUnsupportedMediaType, utils.CodeUnsupportedMediaType,
+				"Content-Type must be one of "+strings.Join(utils.RequestMediaTypes(), ", "))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// NegotiateResponse is Negotiate for handlers reading bodies in formats of their own,
+// such as import files: it only checks the Accept header
+func NegotiateResponse(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if acceptable(w, r) {
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+// acceptable answers 406 and returns false when Accept allows none of the response formats
+func acceptable(w http.ResponseWriter, r *http.Request) bool {
+	if _, ok := utils.ResponseCodec(r); !ok {
+		utils.WriteProblem(w, http.StatusNotAcceptable, utils.CodeNotAcceptable,
+			"Accept must allow one of "+strings.Join(utils.ResponseMediaTypes(), ", "))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/gitvam/platform-go-challenge/internal/utils"
+)
+
+const contextKeyRequestID = contextKey("requestID")
+
+const maxRequestIDLength = 128
+
+// RequestID tags every request with an ID, reusing the client's X-Request-ID when present.
+// The ID is echoed in the response header and included in error responses.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := RequestIDOrNew(r.Header.Get(utils.RequestIDHeader))
+		w.Header().Set(utils.RequestIDHeader, id)
+		ctx := context.WithValue(r.Context(), contextKeyRequestID, id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// GetRequestID retrieves the request ID set by the RequestID middleware
+func GetRequestID(r *http.Request) string {
+	id, _ := r.Context().Value(contextKeyRequestID).(string)
+	return id
+}
+
+// RequestIDOrNew returns the request ID a client sent, or a new one when it is missing or too long
+func RequestIDOrNew(id string) string {
+	if id == "" || len(id) > maxRequestIDLength {
+		return newRequestID()
+	}
+	return id
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,149 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"time"
+)
+
+func fields(t *testing.T, err error) []string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	var verrs ValidationErrors
+	if !errors.As(err, &verrs) {
+		t.Fatalf("expected ValidationErrors, got %T: %v", err, err)
+	}
+	out := make([]string, len(verrs))
+	for i, v := range verrs {
+		out[i] = v.Field
+	}
+	return out
+}
+
+func TestValidate_ReportsEveryField(t *testing.T) {
+	cases := []struct {
+		name  string
+		asset Asset
+		want  []string
+	}{
+		{
+			name:  "valid chart",
+			asset: &Chart{ExternalID: "c1", Title: "t", Series: []Series{{Name: "Reach", Unit: "%", Values: []float64{1.5, 2}}}},
+		},
+		{
+			name:  "chart missing everything",
+			asset: &Chart{},
+			want:  []string{"external_id", "title"},
+		},
+		{
+			name: "chart over limits",
+			asset: &Chart{
+				ExternalID: strings.Repeat("x", MaxExternalIDLength+1),
+				Title:      strings.Repeat("t", MaxTitleLength+1),
+				XAxisTitle: strings.Repeat("x", MaxTitleLength+1),
+				Series:     []Series{{Name: "s", Values: make([]float64, MaxChartDataPoints+1)}},
+			},
+			want: []string{"external_id", "title", "x_axis_title", "series[0].values"},
+		},
+		{
+			name: "chart with mismatched series and labels",
+			asset: &Chart{ExternalID: "c1", Title: "t", Kind: "area",
+				XAxis: XAxis{Kind: AxisKindCategory, Categories: []string{"Jan", ""}},
+				Series: []Series{
+					{Name: "a", Values: []float64{1, 2}},
+					{Unit: strings.Repeat("u", MaxUnitLength+1), Values: []float64{1}},
+				},
+			},
+			want: []string{"kind", "series[1].name", "series[1].unit", "series[1].values", "x_axis.categories[1]"},
+		},
+		{
+			name: "chart with unordered timestamps",
+			asset: &Chart{ExternalID: "c1", Title: "t",
+				XAxis:  XAxis{Kind: AxisKindTime, Timestamps: []time.Time{time.Unix(60, 0), time.Unix(0, 0)}},
+				Series: []Series{{Name: "a", Values: []float64{1, 2}}},
+			},
+			want: []string{"x_axis.timestamps[1]"},
+		},
+		{
+			name: "pie chart with two series and negative values",
+			asset: &Chart{ExternalID: "c1", Title: "t", Kind: ChartKindPie,
+				XAxis:  XAxis{Kind: AxisKindTime, Timestamps: []time.Time{time.Unix(0, 0)}},
+				Series: []Series{{Name: "a", Values: []float64{-1}}, {Name: "b", Values: []float64{1}}},
+			},
+			want: []string{"series[0].values[0]", "series", "x_axis.kind"},
+		},
+		{
+			name:  "insight missing text",
+			asset: &Insight{ExternalID: "i1", Description: strings.Repeat("d", MaxDescriptionLength+1)},
+			want:  []string{"text", "description"},
+		},
+		{
+			name:  "valid audience",
+			asset: &Audience{ExternalID: "a1", Gender: "female", BirthCountry: "GB", AgeGroups: []string{"18-24", "25-34"}, HoursOnSocial: 4},
+		},
+		{
+			name:  "audience with bad values",
+			asset: &Audience{ExternalID: "a1", Gender: "f", BirthCountry: "UK", AgeGroups: []string{"18-24", "24-35", "18-24"}, HoursOnSocial: -1, PurchasesLastMonth: -2},
+			want:  []string{"gender", "birth_country", "age_groups[1]", "age_groups[2]", "hours_on_social", "purchases_last_month"},
+		},
+		{
+			name:  "valid dashboard",
+			asset: &Dashboard{ExternalID: "d1", Title: "t", Layout: "grid", Members: []AssetRef{{Type: AssetTypeChart, ExternalID: "c1"}, {Type: AssetTypeInsight, ExternalID: "c1"}}},
+		},
+		{
+			name: "dashboard with bad members",
+			asset: &Dashboard{ExternalID: "d1", Title: "t", Layout: "tabs", Members: []AssetRef{
+				{Type: AssetTypeChart, ExternalID: "c1"},
+				{Type: "video", ExternalID: "v1"},
+				{Type: AssetTypeDashboard, ExternalID: "d2"},
+				{Type: AssetTypeChart},
+				{Type: AssetTypeChart, ExternalID: "c1"},
+			}},
+			want: []string{"layout", "members[1].type", "members[2].type", "members[3].external_id", "members[4]"},
+		},
+		{
+			name:  "empty dashboard",
+			asset: &Dashboard{ExternalID: "d1", Title: "t", Layout: "rows"},
+			want:  []string{"members"},
+		},
+		{
+			name:  "audience missing required",
+			asset: &Audience{ExternalID: "a1", HoursOnSocial: 25},
+			want:  []string{"gender", "birth_country", "hours_on_social"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := fields(t, c.asset.Validate())
+			if strings.Join(got, ",") != strings.Join(c.want, ",") {
+				t.Errorf("expected violations %v, got %v", c.want, got)
+			}
+		})
+	}
+}
+
+func TestValidateDescription(t *testing.T) {
+	if err := ValidateDescription(strings.Repeat("é", MaxDescriptionLength)); err != nil {
+		t.Errorf("expected limit to count characters, got %v", err)
+	}
+	if got := fields(t, ValidateDescription(strings.Repeat("d", MaxDescriptionLength+1))); len(got) != 1 || got[0] != "description" {
+		t.Errorf("expected description violation, got %v", got)
+	}
+}
+
+func TestIsCountryCode(t *testing.T) {
+	for _, c := range []string{"GB", "GR", "US", "IE"} {
+		if !IsCountryCode(c) {
+			t.Errorf("expected %s to be valid", c)
+		}
+	}
+	for _, c := range []string{"UK", "gb", "Greece", "", "GBR"} {
+		if IsCountryCode(c) {
+			t.Errorf("expected %s to be rejected", c)
+		}
+	}
+}
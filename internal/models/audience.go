@@ -0,0 +1,177 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// audienceColumns are the catalog columns defining an audience
+var audienceColumns = []string{"gender", "birth_country", "age_groups", "hours_on_social", "purchases_last_month", "criteria"}
+
+func init() {
+	Register(TypeSpec{
+		Type:     AssetTypeAudience,
+		New:      func() Asset { return &Audience{Type: string(AssetTypeAudience)} },
+		Required: []string{"external_id"},
+		RequiredAlternatives: [][]string{
+			{"criteria"},
+			{"gender", "birth_country", "hours_on_social", "purchases_last_month"},
+		},
+		Table:   "audiences",
+		Columns: append([]string{"id", "external_id"}, audienceColumns...),
+		ScanFields: func(a Asset) []any {
+			au := a.(*Audience)
+			return []any{&au.ID, &au.ExternalID, NullAsZero(&au.Gender), NullAsZero(&au.BirthCountry), &au.AgeGroups,
+				NullAsZero(&au.HoursOnSocial), NullAsZero(&au.PurchasesLastMonth), JSONColumn(&au.Criteria)}
+		},
+		// Criteria and the summary are derived from the flat fields when an audience
+		// has no criteria, and criteria-only audiences leave the flat fields out
+		FieldColumns: map[string][]string{
+			"criteria":             audienceColumns,
+			"summary":              audienceColumns,
+			"gender":               {"gender", "criteria"},
+			"birth_country":        {"birth_country", "criteria"},
+			"age_groups":           {"age_groups", "criteria"},
+			"hours_on_social":      {"hours_on_social", "criteria"},
+			"purchases_last_month": {"purchases_last_month", "criteria"},
+		},
+		CSVColumns: []string{"gender", "birth_country", "age_groups", "hours_on_social", "purchases_last_month", "criteria", "summary"},
+		CSVValues: func(a Asset) ([]string, error) {
+			au := a.(*Audience)
+			criteria, err := csvJSON(au.EffectiveCriteria())
+			// Criteria-only audiences leave the flat fields empty rather than zero
+			flat := make([]string, 5)
+			if au.hasFlatFields() {
+				flat = []string{au.Gender, au.BirthCountry, strings.Join(au.AgeGroups, CSVListSeparator),
+					strconv.Itoa(au.HoursOnSocial), strconv.Itoa(au.PurchasesLastMonth)}
+			}
+			return append(flat, criteria, au.Summary()), err
+		},
+	})
+}
+
+// Audience Asset. It is defined either by Criteria or, for older clients, by
+// the flat attribute fields, which describe a single AND of equalities.
+// swagger:model Audience
+type Audience struct {
+	ID                 int            `json:"id"`
+	ExternalID         string         `json:"external_id"`
+	Gender             string         `json:"gender"`
+	BirthCountry       string         `json:"birth_country"`
+	AgeGroups          pq.StringArray `json:"age_groups" db:"age_groups"`
+	HoursOnSocial      int            `json:"hours_on_social"`
+	PurchasesLastMonth int            `json:"purchases_last_month"`
+	Criteria           *Criteria      `json:"criteria,omitempty"`
+	Description        string         `json:"description"`
+	Tags               []string       `json:"tags,omitempty"`   // favorite tags, lower-case and sorted
+	Pinned             bool           `json:"pinned,omitempty"` // pinned to the top of the favorites
+	Type               string         `json:"type"`
+	Version            int            `json:"version,omitempty"` // favorite version, used as its ETag
+}
+
+func (a *Audience) GetID() string              { return a.ExternalID }
+func (a *Audience) GetType() AssetType         { return AssetTypeAudience }
+func (a *Audience) GetDescription() string     { return a.Description }
+func (a *Audience) SetDescription(desc string) { a.Description = desc }
+func (a *Audience) GetVersion() int            { return a.Version }
+func (a *Audience) SetVersion(v int)           { a.Version = v }
+func (a *Audience) GetTags() []string          { return a.Tags }
+func (a *Audience) SetTags(tags []string)      { a.Tags = tags }
+func (a *Audience) GetPinned() bool            { return a.Pinned }
+func (a *Audience) SetPinned(p bool)           { a.Pinned = p }
+
+// hasFlatFields reports whether any of the flat attribute fields are set
+func (a *Audience) hasFlatFields() bool {
+	return a.Gender != "" || a.BirthCountry != "" || len(a.AgeGroups) > 0 || a.HoursOnSocial != 0 || a.PurchasesLastMonth != 0
+}
+
+// flatCriteria expresses the flat attribute fields as criteria
+func (a *Audience) flatCriteria() *Criteria {
+	and := []Criteria{
+		{Attribute: AttrGender, Op: OpEq, Value: a.Gender},
+		{Attribute: AttrBirthCountry, Op: OpEq, Value: a.BirthCountry},
+	}
+	if len(a.AgeGroups) > 0 {
+		and = append(and, Criteria{Attribute: AttrAgeGroup, Op: OpIn, Value: []string(a.AgeGroups)})
+	}
+	and = append(and,
+		Criteria{Attribute: AttrHoursOnSocial, Op: OpEq, Value: float64(a.HoursOnSocial)},
+		Criteria{Attribute: AttrPurchasesLastMonth, Op: OpEq, Value: float64(a.PurchasesLastMonth)},
+	)
+	return &Criteria{And: and}
+}
+
+// EffectiveCriteria returns the audience's criteria, derived from the flat
+// fields for audiences defined before criteria existed
+func (a *Audience) EffectiveCriteria() *Criteria {
+	if a.Criteria != nil {
+		return a.Criteria
+	}
+	return a.flatCriteria()
+}
+
+// Summary renders the audience definition for people
+func (a *Audience) Summary() string {
+	return a.EffectiveCriteria().Summary()
+}
+
+// MarshalJSON always includes criteria and its summary. The flat fields are
+// kept for audiences that have them and left out of criteria-only ones.
+func (a *Audience) MarshalJSON() ([]byte, error) {
+	type audience Audience
+	if a.Criteria != nil && !a.hasFlatFields() {
+		return json.Marshal(struct {
+			ID          int       `json:"id"`
+			ExternalID  string    `json:"external_id"`
+			Criteria    *Criteria `json:"criteria"`
+			Summary     string    `json:"summary"`
+			Description string    `json:"description"`
+			Tags        []string  `json:"tags,omitempty"`
+			Pinned      bool      `json:"pinned,omitempty"`
+			Type        string    `json:"type"`
+			Version     int       `json:"version,omitempty"`
+		}{a.ID, a.ExternalID, a.Criteria, a.Summary(), a.Description, a.Tags, a.Pinned, a.Type, a.Version})
+	}
+	return json.Marshal(struct {
+		*audience
+		Criteria *Criteria `json:"criteria"`
+		Summary  string    `json:"summary"`
+	}{(*audience)(a), a.EffectiveCriteria(), a.Summary()})
+}
+
+func (a *Audience) Validate() error {
+	var v Validator
+	v.externalID(a.ExternalID)
+	v.MaxLength("description", a.Description, MaxDescriptionLength)
+	v.Tags("tags", a.Tags)
+	if a.Criteria != nil {
+		a.Criteria.validate(&v, "criteria")
+		if !a.hasFlatFields() {
+			return v.Err()
+		}
+		if a.Criteria.Summary() != a.flatCriteria().Summary() {
+			v.Add("criteria", "does not match the flat audience fields; send one or the other")
+		}
+	}
+	v.Required("gender", a.Gender)
+	v.OneOf("gender", a.Gender, Genders)
+	v.Required("birth_country", a.BirthCountry)
+	v.CountryCode("birth_country", a.BirthCountry)
+	seen := map[string]bool{}
+	for i, g := range a.AgeGroups {
+		field := fmt.Sprintf("age_groups[%d]", i)
+		v.Required(field, g)
+		v.OneOf(field, g, AgeGroups)
+		if seen[g] {
+			v.Add(field, "is a duplicate")
+		}
+		seen[g] = true
+	}
+	v.Range("hours_on_social", a.HoursOnSocial, 0, MaxHoursOnSocial)
+	v.NonNegative("purchases_last_month", a.PurchasesLastMonth)
+	return v.Err()
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// What an audit event changed
+const (
+	AuditEntityFavorite   = "favorite"
+	AuditEntityCollection = "collection"
+	AuditEntityShare      = "share"
+	AuditEntityWebhook    = "webhook"
+)
+
+// Audit event actions
+const (
+	ActionFavoriteAdded     = "favorite.added"
+	ActionFavoriteRemoved   = "favorite.removed"
+	ActionFavoriteRestored  = "favorite.restored"
+	ActionFavoritePurged    = "favorite.purged"
+	ActionFavoriteDescribed = "favorite.described"
+	ActionFavoriteTagged    = "favorite.tagged"
+	ActionFavoriteUntagged  = "favorite.untagged"
+	ActionFavoritePinned    = "favorite.pinned"
+	ActionFavoriteUnpinned  = "favorite.unpinned"
+	ActionFavoriteMoved     = "favorite.moved"
+	// ActionFavoriteReplaced is an import overwriting a favorite's description and tags
+	ActionFavoriteReplaced = "favorite.replaced"
+
+	ActionCollectionCreated         = "collection.created"
+	ActionCollectionUpdated         = "collection.updated"
+	ActionCollectionDeleted         = "collection.deleted"
+	ActionCollectionFavoriteAdded   = "collection.favorite_added"
+	ActionCollectionFavoriteRemoved = "collection.favorite_removed"
+
+	ActionShareCreated = "share.created"
+	ActionShareRevoked = "share.revoked"
+
+	ActionWebhookCreated = "webhook.created"
+	ActionWebhookDeleted = "webhook.deleted"
+)
+
+// AuditEvent records one change to a user's favorites, collections or shares.
+// Events are append-only and are written in the same transaction as the change.
+// swagger:model AuditEvent
+type AuditEvent struct {
+	ID int64 `json:"id"`
+	// UserID owns the changed data; ActorID made the change. They differ when an admin
+	// acts for the user or a grantee edits a shared item, and ActorID is empty for
+	// maintenance such as purging the trash.
+	UserID     string `json:"user_id"`
+	ActorID    string `json:"actor_id,omitempty"`
+	Action     string `json:"action"`
+	EntityType string `json:"entity_type"`
+	// EntityID is "type:external_id" for favorites and the numeric ID otherwise
+	EntityID  string          `json:"entity_id"`
+	Before    json.RawMessage `json:"before,omitempty" swaggertype:"object"`
+	After     json.RawMessage `json:"after,omitempty" swaggertype:"object"`
+	RequestID string          `json:"request_id,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// FavoriteEntityID is the EntityID of a favorite's audit events
+func FavoriteEntityID(assetType, externalID string) string {
+	return assetType + ":" + externalID
+}
@@ -0,0 +1,199 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Chart kinds and x-axis kinds
+const (
+	ChartKindLine = "line"
+	ChartKindBar  = "bar"
+	ChartKindPie  = "pie"
+
+	AxisKindCategory = "category"
+	AxisKindTime     = "time"
+)
+
+// Limits for chart series
+const (
+	MaxChartSeries    = 10
+	MaxSeriesNameLen  = 100
+	MaxUnitLength     = 20
+	MaxCategoryLength = 100
+	defaultSeriesName = "Series 1"
+)
+
+var (
+	ChartKinds = []string{ChartKindLine, ChartKindBar, ChartKindPie}
+	AxisKinds  = []string{AxisKindCategory, AxisKindTime}
+)
+
+func init() {
+	Register(TypeSpec{
+		Type:     AssetTypeChart,
+		New:      func() Asset { return &Chart{Type: string(AssetTypeChart)} },
+		Required: []string{"external_id", "title"},
+		Table:    "charts",
+		Columns:  []string{"id", "external_id", "title", "kind", "x_axis_title", "y_axis_title", "x_axis", "series"},
+		ScanFields: func(a Asset) []any {
+			c := a.(*Chart)
+			return []any{&c.ID, &c.ExternalID, &c.Title, &c.Kind, &c.XAxisTitle, &c.YAxisTitle, JSONColumn(&c.XAxis), JSONColumn(&c.Series)}
+		},
+		RequestOnly: []string{"data"},
+		CSVColumns:  []string{"title", "kind", "x_axis_title", "y_axis_title", "x_axis_kind", "x_axis_categories", "x_axis_timestamps", "series"},
+		CSVValues: func(a Asset) ([]string, error) {
+			c := a.(*Chart)
+			series, err := csvJSON(c.Series)
+			return []string{c.Title, c.Kind, c.XAxisTitle, c.YAxisTitle, c.XAxis.Kind,
+				strings.Join(c.XAxis.Categories, CSVListSeparator), csvTimes(c.XAxis.Timestamps), series}, err
+		},
+	})
+}
+
+// Chart Asset
+// swagger:model Chart
+type Chart struct {
+	ID         int      `json:"id"`
+	ExternalID string   `json:"external_id"` // business-slug used by API
+	Title      string   `json:"title"`
+	Kind       string   `json:"kind"` // line, bar or pie; defaults to line
+	XAxisTitle string   `json:"x_axis_title"`
+	YAxisTitle string   `json:"y_axis_title"`
+	XAxis      XAxis    `json:"x_axis"`
+	Series     []Series `json:"series"`
+	// Deprecated: Data is the pre-series single unlabeled integer series. It is
+	// accepted in requests, converted to Series by Normalize, and never returned.
+	Data        []int64  `json:"data,omitempty"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags,omitempty"`   // favorite tags, lower-case and sorted
+	Pinned      bool     `json:"pinned,omitempty"` // pinned to the top of the favorites
+	Type        string   `json:"type"`
+	Version     int      `json:"version,omitempty"` // favorite version, used as its ETag
+}
+
+// XAxis labels the points of every series, either by category or by timestamp
+type XAxis struct {
+	Kind       string      `json:"kind"` // category or time; defaults to category
+	Categories []string    `json:"categories,omitempty"`
+	Timestamps []time.Time `json:"timestamps,omitempty"`
+}
+
+// Series is one named line, set of bars or pie of a chart
+type Series struct {
+	Name   string    `json:"name"`
+	Unit   string    `json:"unit,omitempty"` // e.g. "%" or "k"
+	Values []float64 `json:"values"`
+}
+
+func (c *Chart) GetID() string              { return c.ExternalID }
+func (c *Chart) GetType() AssetType         { return AssetTypeChart }
+func (c *Chart) GetDescription() string     { return c.Description }
+func (c *Chart) SetDescription(desc string) { c.Description = desc }
+func (c *Chart) GetVersion() int            { return c.Version }
+func (c *Chart) SetVersion(v int)           { c.Version = v }
+func (c *Chart) GetTags() []string          { return c.Tags }
+func (c *Chart) SetTags(tags []string)      { c.Tags = tags }
+func (c *Chart) GetPinned() bool            { return c.Pinned }
+func (c *Chart) SetPinned(p bool)           { c.Pinned = p }
+
+// Normalize fills in defaults and converts legacy Data into a single series
+func (c *Chart) Normalize() {
+	if c.Kind == "" {
+		c.Kind = ChartKindLine
+	}
+	if c.XAxis.Kind == "" {
+		c.XAxis.Kind = AxisKindCategory
+	}
+	if len(c.Data) > 0 && len(c.Series) == 0 {
+		name := c.YAxisTitle
+		if name == "" {
+			name = defaultSeriesName
+		}
+		values := make([]float64, len(c.Data))
+		for i, d := range c.Data {
+			values[i] = float64(d)
+		}
+		c.Series = []Series{{Name: name, Values: values}}
+		c.Data = nil
+	}
+}
+
+func (c *Chart) Validate() error {
+	var v Validator
+	v.externalID(c.ExternalID)
+	v.Required("title", c.Title)
+	v.MaxLength("title", c.Title, MaxTitleLength)
+	v.OneOf("kind", c.Kind, ChartKinds)
+	v.MaxLength("x_axis_title", c.XAxisTitle, MaxTitleLength)
+	v.MaxLength("y_axis_title", c.YAxisTitle, MaxTitleLength)
+	if len(c.Data) > 0 && len(c.Series) > 0 {
+		v.Add("data", "cannot be combined with series")
+	}
+	v.MaxLength("description", c.Description, MaxDescriptionLength)
+	v.Tags("tags", c.Tags)
+
+	v.MaxItems("series", len(c.Series), MaxChartSeries)
+	points := -1
+	for i, s := range c.Series {
+		field := fmt.Sprintf("series[%d]", i)
+		v.Required(field+".name", s.Name)
+		v.MaxLength(field+".name", s.Name, MaxSeriesNameLen)
+		v.MaxLength(field+".unit", s.Unit, MaxUnitLength)
+		v.MaxItems(field+".values", len(s.Values), MaxChartDataPoints)
+		if points >= 0 && len(s.Values) != points {
+			v.Add(field+".values", fmt.Sprintf("must have %d values like the first series", points))
+		}
+		if points < 0 {
+			points = len(s.Values)
+		}
+		if c.Kind == ChartKindPie {
+			for j, val := range s.Values {
+				if val < 0 {
+					v.Add(fmt.Sprintf("%s.values[%d]", field, j), "must not be negative in a pie chart")
+				}
+			}
+		}
+	}
+	if c.Kind == ChartKindPie && len(c.Series) > 1 {
+		v.Add("series", "a pie chart has exactly one series")
+	}
+
+	c.XAxis.validate(&v, points, c.Kind)
+	return v.Err()
+}
+
+// validate checks the axis labels against the number of points in each series
+func (x XAxis) validate(v *Validator, points int, chartKind string) {
+	v.OneOf("x_axis.kind", x.Kind, AxisKinds)
+	switch x.Kind {
+	case AxisKindCategory, "":
+		if len(x.Timestamps) > 0 {
+			v.Add("x_axis.timestamps", "only allowed on a time axis")
+		}
+		if len(x.Categories) > 0 && points >= 0 && len(x.Categories) != points {
+			v.Add("x_axis.categories", fmt.Sprintf("must have one label per value (%d)", points))
+		}
+		for i, c := range x.Categories {
+			field := fmt.Sprintf("x_axis.categories[%d]", i)
+			v.Required(field, c)
+			v.MaxLength(field, c, MaxCategoryLength)
+		}
+	case AxisKindTime:
+		if chartKind == ChartKindPie {
+			v.Add("x_axis.kind", "a pie chart must use a category axis")
+		}
+		if len(x.Categories) > 0 {
+			v.Add("x_axis.categories", "only allowed on a category axis")
+		}
+		if points >= 0 && len(x.Timestamps) != points {
+			v.Add("x_axis.timestamps", fmt.Sprintf("must have one timestamp per value (%d)", points))
+		}
+		for i := 1; i < len(x.Timestamps); i++ {
+			if !x.Timestamps[i].After(x.Timestamps[i-1]) {
+				v.Add(fmt.Sprintf("x_axis.timestamps[%d]", i), "must be after the previous timestamp")
+			}
+		}
+	}
+}
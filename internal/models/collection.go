@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// MaxCollectionNameLength limits collection names
+const MaxCollectionNameLength = 100
+
+// Collection is a user-defined folder of favorites, such as "Q3 pitch".
+// A favorite can belong to several collections.
+// swagger:model Collection
+type Collection struct {
+	ID            int       `json:"id"`
+	Name          string    `json:"name"`
+	Description   string    `json:"description"`
+	Position      int       `json:"position"` // zero-based order among the user's collections
+	FavoriteCount int       `json:"favorite_count"`
+	Version       int       `json:"version"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// CollectionUpdate is a partial update of a collection; nil fields are left unchanged
+type CollectionUpdate struct {
+	Name        *string `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+	// Position moves the collection, shifting the ones in between
+	Position *int `json:"position,omitempty"`
+}
+
+func (c *Collection) Validate() error {
+	var v Validator
+	v.Required("name", c.Name)
+	v.MaxLength("name", c.Name, MaxCollectionNameLength)
+	v.MaxLength("description", c.Description, MaxDescriptionLength)
+	return v.Err()
+}
+
+// Empty reports whether the update changes nothing
+func (u *CollectionUpdate) Empty() bool {
+	return u.Name == nil && u.Description == nil && u.Position == nil
+}
+
+func (u *CollectionUpdate) Validate() error {
+	var v Validator
+	if u.Name != nil {
+		v.Required("name", *u.Name)
+		v.MaxLength("name", *u.Name, MaxCollectionNameLength)
+	}
+	if u.Description != nil {
+		v.MaxLength("description", *u.Description, MaxDescriptionLength)
+	}
+	if u.Position != nil {
+		v.NonNegative("position", *u.Position)
+	}
+	return v.Err()
+}
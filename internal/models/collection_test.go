@@ -0,0 +1,27 @@
+package models
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCollection_Validate(t *testing.T) {
+	c := &Collection{Name: " ", Description: strings.Repeat("d", MaxDescriptionLength+1)}
+	if got := fields(t, c.Validate()); !reflect.DeepEqual(got, []string{"name", "description"}) {
+		t.Errorf("unexpected fields %v", got)
+	}
+
+	long, blank, pos := strings.Repeat("n", MaxCollectionNameLength+1), "", -1
+	u := &CollectionUpdate{Name: &long, Position: &pos}
+	if got := fields(t, u.Validate()); !reflect.DeepEqual(got, []string{"name", "position"}) {
+		t.Errorf("unexpected fields %v", got)
+	}
+	u = &CollectionUpdate{Description: &blank}
+	if u.Empty() || u.Validate() != nil {
+		t.Error("expected clearing the description to be a valid update")
+	}
+	if !(&CollectionUpdate{}).Empty() {
+		t.Error("expected an update without fields to be empty")
+	}
+}
@@ -0,0 +1,279 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Criteria operators
+const (
+	OpEq  = "eq"
+	OpNe  = "ne"
+	OpIn  = "in"
+	OpGt  = "gt"
+	OpGte = "gte"
+	OpLt  = "lt"
+	OpLte = "lte"
+)
+
+// Audience attributes that criteria can filter on
+const (
+	AttrGender             = "gender"
+	AttrBirthCountry       = "birth_country"
+	AttrAgeGroup           = "age_group"
+	AttrHoursOnSocial      = "hours_on_social"
+	AttrPurchasesLastMonth = "purchases_last_month"
+)
+
+// Limits for criteria expressions
+const (
+	MaxCriteriaDepth  = 8
+	MaxCriteriaNodes  = 100
+	MaxCriteriaValues = 50
+)
+
+var (
+	stringOps  = []string{OpEq, OpNe, OpIn}
+	numericOps = []string{OpEq, OpNe, OpGt, OpGte, OpLt, OpLte}
+
+	opPhrases = map[string]string{
+		OpEq:  "is",
+		OpNe:  "is not",
+		OpIn:  "is one of",
+		OpGt:  "is more than",
+		OpGte: "is at least",
+		OpLt:  "is less than",
+		OpLte: "is at most",
+	}
+)
+
+// attribute describes how predicates on an audience attribute are typed and checked
+type attribute struct {
+	label   string
+	numeric bool
+	max     float64 // upper bound for numeric attributes; 0 means unbounded
+	check   func(v *Validator, field, value string)
+}
+
+var criteriaAttributes = map[string]attribute{
+	AttrGender: {label: "gender", check: func(v *Validator, field, value string) {
+		v.OneOf(field, value, Genders)
+	}},
+	AttrBirthCountry: {label: "birth country", check: func(v *Validator, field, value string) {
+		v.CountryCode(field, value)
+	}},
+	AttrAgeGroup: {label: "age group", check: func(v *Validator, field, value string) {
+		v.OneOf(field, value, AgeGroups)
+	}},
+	AttrHoursOnSocial:      {label: "hours on social", numeric: true, max: MaxHoursOnSocial},
+	AttrPurchasesLastMonth: {label: "purchases last month", numeric: true},
+}
+
+// CriteriaAttributes returns the attribute names criteria can filter on, sorted
+func CriteriaAttributes() []string {
+	names := make([]string, 0, len(criteriaAttributes))
+	for name := range criteriaAttributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Criteria is a boolean expression over audience attributes, stored as JSON.
+// Each node sets exactly one of And, Or, Not or a predicate made of
+// Attribute, Op and Value, for example
+//
+//	{"and": [{"attribute": "gender", "op": "eq", "value": "female"},
+//	         {"attribute": "birth_country", "op": "in", "value": ["GB", "IE"]}]}
+type Criteria struct {
+	And       []Criteria `json:"and,omitempty"`
+	Or        []Criteria `json:"or,omitempty"`
+	Not       *Criteria  `json:"not,omitempty"`
+	Attribute string     `json:"attribute,omitempty"`
+	Op        string     `json:"op,omitempty"`
+	// Value is a string or number, or a list of strings for the "in" operator
+	Value any `json:"value,omitempty"`
+}
+
+func (c *Criteria) isPredicate() bool {
+	return c.Attribute != "" || c.Op != "" || c.Value != nil
+}
+
+// Summary renders the criteria for people, e.g.
+// "gender is female AND (birth country is one of GB, IE OR hours on social is at least 4)"
+func (c *Criteria) Summary() string {
+	switch {
+	case c.And != nil:
+		return renderGroup(c.And, " AND ")
+	case c.Or != nil:
+		return renderGroup(c.Or, " OR ")
+	case c.Not != nil:
+		return "NOT (" + c.Not.Summary() + ")"
+	default:
+		return c.renderPredicate()
+	}
+}
+
+// renderGroup joins conditions, parenthesizing nested groups of more than one condition
+func renderGroup(cs []Criteria, sep string) string {
+	parts := make([]string, len(cs))
+	for i := range cs {
+		parts[i] = cs[i].Summary()
+		if (len(cs[i].And) > 1 || len(cs[i].Or) > 1) && len(cs) > 1 {
+			parts[i] = "(" + parts[i] + ")"
+		}
+	}
+	return strings.Join(parts, sep)
+}
+
+func (c *Criteria) renderPredicate() string {
+	label := c.Attribute
+	if attr, ok := criteriaAttributes[c.Attribute]; ok {
+		label = attr.label
+	}
+	phrase, ok := opPhrases[c.Op]
+	if !ok {
+		phrase = c.Op
+	}
+	var value string
+	if n, ok := numberValue(c.Value); ok {
+		value = strconv.FormatFloat(n, 'f', -1, 64)
+	} else if list, ok := stringValues(c.Value); ok {
+		value = strings.Join(list, ", ")
+	} else {
+		value = fmt.Sprint(c.Value)
+	}
+	return label + " " + phrase + " " + value
+}
+
+// validate reports every problem in the expression, prefixing fields with field
+func (c *Criteria) validate(v *Validator, field string) {
+	nodes := 0
+	c.validateNode(v, field, 1, &nodes)
+	if nodes > MaxCriteriaNodes {
+		v.Add(field, fmt.Sprintf("must have at most %d conditions", MaxCriteriaNodes))
+	}
+}
+
+func (c *Criteria) validateNode(v *Validator, field string, depth int, nodes *int) {
+	*nodes++
+	if depth > MaxCriteriaDepth {
+		v.Add(field, fmt.Sprintf("must be nested at most %d levels deep", MaxCriteriaDepth))
+		return
+	}
+	set := 0
+	for _, ok := range []bool{c.And != nil, c.Or != nil, c.Not != nil, c.isPredicate()} {
+		if ok {
+			set++
+		}
+	}
+	if set != 1 {
+		v.Add(field, "must set exactly one of and, or, not or attribute")
+		return
+	}
+	switch {
+	case c.And != nil:
+		validateGroup(v, field+".and", c.And, depth, nodes)
+	case c.Or != nil:
+		validateGroup(v, field+".or", c.Or, depth, nodes)
+	case c.Not != nil:
+		c.Not.validateNode(v, field+".not", depth+1, nodes)
+	default:
+		c.validatePredicate(v, field)
+	}
+}
+
+func validateGroup(v *Validator, field string, cs []Criteria, depth int, nodes *int) {
+	if len(cs) == 0 {
+		v.Add(field, "must have at least one condition")
+	}
+	for i := range cs {
+		cs[i].validateNode(v, fmt.Sprintf("%s[%d]", field, i), depth+1, nodes)
+	}
+}
+
+func (c *Criteria) validatePredicate(v *Validator, field string) {
+	attr, ok := criteriaAttributes[c.Attribute]
+	if !ok {
+		v.Add(field+".attribute", "must be one of "+strings.Join(CriteriaAttributes(), ", "))
+		return
+	}
+	ops := stringOps
+	if attr.numeric {
+		ops = numericOps
+	}
+	v.Required(field+".op", c.Op)
+	v.OneOf(field+".op", c.Op, ops)
+
+	if attr.numeric {
+		n, ok := numberValue(c.Value)
+		switch {
+		case !ok:
+			v.Add(field+".value", "must be a number")
+		case n < 0:
+			v.Add(field+".value", "must not be negative")
+		case attr.max > 0 && n > attr.max:
+			v.Add(field+".value", fmt.Sprintf("must be at most %s", strconv.FormatFloat(attr.max, 'f', -1, 64)))
+		}
+		return
+	}
+
+	values, ok := stringValues(c.Value)
+	_, isString := c.Value.(string)
+	switch {
+	case !ok || (c.Op == OpIn) == isString:
+		if c.Op == OpIn {
+			v.Add(field+".value", "must be a list of strings")
+		} else {
+			v.Add(field+".value", "must be a string")
+		}
+		return
+	case len(values) == 0:
+		v.Add(field+".value", "must not be empty")
+	}
+	v.MaxItems(field+".value", len(values), MaxCriteriaValues)
+	for i, s := range values {
+		f := field + ".value"
+		if !isString {
+			f = fmt.Sprintf("%s[%d]", f, i)
+		}
+		v.Required(f, s)
+		attr.check(v, f, s)
+	}
+}
+
+// numberValue returns a numeric predicate value, as decoded from JSON or set in Go
+func numberValue(value any) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	default:
+		return 0, false
+	}
+}
+
+// stringValues returns a string predicate value, or the strings of a list value
+func stringValues(value any) ([]string, bool) {
+	switch s := value.(type) {
+	case string:
+		return []string{s}, true
+	case []string:
+		return s, true
+	case []any:
+		out := make([]string, len(s))
+		for i, e := range s {
+			str, ok := e.(string)
+			if !ok {
+				return nil, false
+			}
+			out[i] = str
+		}
+		return out, true
+	default:
+		return nil, false
+	}
+}
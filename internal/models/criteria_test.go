@@ -0,0 +1,153 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCriteria_Summary(t *testing.T) {
+	c := &Criteria{And: []Criteria{
+		{Attribute: AttrGender, Op: OpEq, Value: "female"},
+		{Or: []Criteria{
+			{Attribute: AttrBirthCountry, Op: OpEq, Value: "GB"},
+			{Attribute: AttrBirthCountry, Op: OpEq, Value: "IE"},
+		}},
+		{Attribute: AttrAgeGroup, Op: OpIn, Value: []any{"18-24", "25-34"}},
+		{Attribute: AttrHoursOnSocial, Op: OpGte, Value: 4.0},
+		{Not: &Criteria{Attribute: AttrPurchasesLastMonth, Op: OpEq, Value: 0.0}},
+	}}
+	want := "gender is female AND (birth country is GB OR birth country is IE) AND age group is one of 18-24, 25-34" +
+		" AND hours on social is at least 4 AND NOT (purchases last month is 0)"
+	if got := c.Summary(); got != want {
+		t.Errorf("unexpected summary:\n got %s\nwant %s", got, want)
+	}
+	var v Validator
+	c.validate(&v, "criteria")
+	if err := v.Err(); err != nil {
+		t.Errorf("expected valid criteria, got %v", err)
+	}
+}
+
+func TestCriteria_Validate(t *testing.T) {
+	deep := Criteria{Attribute: AttrGender, Op: OpEq, Value: "male"}
+	for i := 0; i < MaxCriteriaDepth; i++ {
+		deep = Criteria{Not: &deep}
+	}
+	cases := []struct {
+		name     string
+		criteria Criteria
+		want     []string
+	}{
+		{
+			name:     "empty node",
+			criteria: Criteria{},
+			want:     []string{"criteria"},
+		},
+		{
+			name:     "two kinds in one node",
+			criteria: Criteria{And: []Criteria{}, Attribute: AttrGender},
+			want:     []string{"criteria"},
+		},
+		{
+			name:     "empty group",
+			criteria: Criteria{Or: []Criteria{}},
+			want:     []string{"criteria.or"},
+		},
+		{
+			name: "bad predicates",
+			criteria: Criteria{And: []Criteria{
+				{Attribute: "income", Op: OpEq, Value: "high"},
+				{Attribute: AttrGender, Op: OpGt, Value: "female"},
+				{Attribute: AttrBirthCountry, Op: OpIn, Value: []any{"GB", "UK", 3.0}},
+				{Attribute: AttrBirthCountry, Op: OpIn, Value: []any{"GB", "UK"}},
+				{Attribute: AttrAgeGroup, Op: OpEq, Value: []any{"18-24"}},
+				{Attribute: AttrHoursOnSocial, Op: OpIn, Value: 30.0},
+				{Attribute: AttrPurchasesLastMonth, Op: OpLt, Value: "2"},
+			}},
+			want: []string{
+				"criteria.and[0].attribute",
+				"criteria.and[1].op",
+				"criteria.and[2].value",
+				"criteria.and[3].value[1]",
+				"criteria.and[4].value",
+				"criteria.and[5].op", "criteria.and[5].value",
+				"criteria.and[6].value",
+			},
+		},
+		{
+			name:     "too deep",
+			criteria: deep,
+			want:     []string{"criteria" + strings.Repeat(".not", MaxCriteriaDepth)},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var v Validator
+			c.criteria.validate(&v, "criteria")
+			if got := fields(t, v.Err()); !reflect.DeepEqual(got, c.want) {
+				t.Errorf("expected %v, got %v (%v)", c.want, got, v.Err())
+			}
+		})
+	}
+}
+
+func TestAudience_FlatFieldsStayCompatible(t *testing.T) {
+	legacy := &Audience{ExternalID: "a1", Gender: "female", BirthCountry: "GB", AgeGroups: []string{"18-24"}, HoursOnSocial: 4, Type: "audience"}
+	if err := legacy.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	want := "gender is female AND birth country is GB AND age group is one of 18-24 AND hours on social is 4 AND purchases last month is 0"
+	if got := legacy.Summary(); got != want {
+		t.Errorf("unexpected summary: %s", got)
+	}
+
+	// A listed legacy audience can be sent back as is, with its derived criteria
+	out, err := json.Marshal(legacy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var body map[string]any
+	json.Unmarshal(out, &body)
+	if body["gender"] != "female" || body["summary"] != want || body["criteria"] == nil {
+		t.Fatalf("unexpected legacy audience JSON: %s", out)
+	}
+	delete(body, "id")
+	delete(body, "summary")
+	again, _ := json.Marshal(body)
+	a, err := DecodeAsset(again)
+	if err != nil {
+		t.Fatalf("re-decoding %s: %v", again, err)
+	}
+	if err := a.Validate(); err != nil {
+		t.Errorf("expected round-tripped legacy audience to validate, got %v", err)
+	}
+
+	// Criteria that disagree with the flat fields are rejected
+	a.(*Audience).HoursOnSocial = 5
+	if got := fields(t, a.Validate()); !reflect.DeepEqual(got, []string{"criteria"}) {
+		t.Errorf("expected a criteria mismatch, got %v", got)
+	}
+}
+
+func TestAudience_CriteriaOnlyJSON(t *testing.T) {
+	a, err := DecodeAsset([]byte(`{"type":"audience","external_id":"a1","criteria":{"and":[
+		{"attribute":"gender","op":"eq","value":"female"},
+		{"attribute":"birth_country","op":"in","value":["GB","IE"]}]}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	out, _ := json.Marshal(a)
+	var body map[string]any
+	json.Unmarshal(out, &body)
+	if _, ok := body["gender"]; ok {
+		t.Errorf("expected flat fields to be omitted, got %s", out)
+	}
+	if body["summary"] != "gender is female AND birth country is one of GB, IE" {
+		t.Errorf("unexpected summary in %s", out)
+	}
+}
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CSVListSeparator joins the items of list values, such as tags, within one CSV field
+const CSVListSeparator = ";"
+
+// csvCommonColumns are the CSV columns every favorite has, whatever its type
+var csvCommonColumns = []string{"type", "external_id", "description", "tags", "pinned"}
+
+// CSVHeader returns the columns of a CSV export: the common ones, then every
+// registered type's own columns prefixed with the type, e.g. "chart.title"
+func CSVHeader() []string {
+	header := append([]string{}, csvCommonColumns...)
+	for _, spec := range Types() {
+		for _, c := range csvColumnsOf(spec) {
+			header = append(header, string(spec.Type)+"."+c)
+		}
+	}
+	return header
+}
+
+// CSVRecord returns a's row of a CSV export, matching CSVHeader. The columns
+// of the other types are left empty.
+func CSVRecord(a Asset) ([]string, error) {
+	record := []string{
+		string(a.GetType()),
+		a.GetID(),
+		a.GetDescription(),
+		strings.Join(a.GetTags(), CSVListSeparator),
+		strconv.FormatBool(a.GetPinned()),
+	}
+	for _, spec := range Types() {
+		if spec.Type != a.GetType() {
+			record = append(record, make([]string, len(csvColumnsOf(spec)))...)
+			continue
+		}
+		values, err := csvValuesOf(spec, a)
+		if err != nil {
+			return nil, err
+		}
+		record = append(record, values...)
+	}
+	return record, nil
+}
+
+func csvColumnsOf(spec TypeSpec) []string {
+	if spec.CSVValues == nil {
+		return []string{"json"}
+	}
+	return spec.CSVColumns
+}
+
+func csvValuesOf(spec TypeSpec, a Asset) ([]string, error) {
+	if spec.CSVValues == nil {
+		v, err := csvJSON(a)
+		return []string{v}, err
+	}
+	return spec.CSVValues(a)
+}
+
+// csvJSON renders nested values that have no flat form as JSON
+func csvJSON(v any) (string, error) {
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+func csvTimes(ts []time.Time) string {
+	s := make([]string, len(ts))
+	for i, t := range ts {
+		s[i] = t.Format(time.RFC3339)
+	}
+	return strings.Join(s, CSVListSeparator)
+}
+
+// AssetList is a list of favorites or catalog assets. It encodes to JSON like
+// []Asset and to CSV in the export layout.
+type AssetList []Asset
+
+// CSVRecords returns the CSV header and a row per asset
+func (l AssetList) CSVRecords() ([][]string, error) {
+	records := [][]string{CSVHeader()}
+	for _, a := range l {
+		record, err := CSVRecord(a)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
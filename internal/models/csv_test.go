@@ -0,0 +1,120 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCSVRecord(t *testing.T) {
+	header := CSVHeader()
+	tests := []struct {
+		name  string
+		asset Asset
+		want  map[string]string
+	}{
+		{
+			name: "chart",
+			asset: &Chart{ExternalID: "sales", Title: "Sales", Kind: ChartKindBar, Type: "chart",
+				XAxis:  XAxis{Kind: AxisKindTime, Timestamps: []time.Time{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)}},
+				Series: []Series{{Name: "EU", Values: []float64{1, 2}}},
+				Tags:   []string{"q1", "sales"}, Pinned: true},
+			want: map[string]string{
+				"type": "chart", "external_id": "sales", "tags": "q1;sales", "pinned": "true",
+				"chart.title": "Sales", "chart.kind": "bar", "chart.x_axis_kind": "time",
+				"chart.x_axis_timestamps": "2024-01-01T00:00:00Z;2024-02-01T00:00:00Z",
+				"chart.series":            `[{"name":"EU","values":[1,2]}]`,
+			},
+		},
+		{
+			name:  "insight",
+			asset: &Insight{ExternalID: "churn", Text: "Churn is down", Description: "weekly", Type: "insight"},
+			want: map[string]string{
+				"type": "insight", "external_id": "churn", "description": "weekly", "pinned": "false",
+				"insight.text": "Churn is down",
+			},
+		},
+		{
+			name: "flat audience",
+			asset: &Audience{ExternalID: "gr", Gender: "female", BirthCountry: "GR", AgeGroups: []string{"18-24", "25-34"},
+				HoursOnSocial: 3, Type: "audience"},
+			want: map[string]string{
+				"type": "audience", "external_id": "gr", "pinned": "false",
+				"audience.gender": "female", "audience.birth_country": "GR", "audience.age_groups": "18-24;25-34",
+				"audience.hours_on_social": "3", "audience.purchases_last_month": "0",
+				"audience.criteria": `{"and":[{"attribute":"gender","op":"eq","value":"female"},{"attribute":"birth_country","op":"eq","value":"GR"},` +
+					`{"attribute":"age_group","op":"in","value":["18-24","25-34"]},{"attribute":"hours_on_social","op":"eq","value":3},` +
+					`{"attribute":"purchases_last_month","op":"eq","value":0}]}`,
+			},
+		},
+		{
+			name: "dashboard",
+			asset: &Dashboard{ExternalID: "ops", Title: "Ops", Layout: "grid", Type: "dashboard",
+				Members: []AssetRef{{Type: AssetTypeChart, ExternalID: "sales"}, {Type: AssetTypeInsight, ExternalID: "churn"}}},
+			want: map[string]string{
+				"type": "dashboard", "external_id": "ops", "pinned": "false",
+				"dashboard.title": "Ops", "dashboard.layout": "grid", "dashboard.members": "chart:sales;insight:churn",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record, err := CSVRecord(tt.asset)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(record) != len(header) {
+				t.Fatalf("expected %d fields, got %d", len(header), len(record))
+			}
+			got := map[string]string{}
+			for i, v := range record {
+				if v != "" {
+					got[header[i]] = v
+				}
+			}
+			// The summary is tested with criteria; only check it is filled in
+			if _, ok := got["audience.summary"]; ok != (tt.asset.GetType() == AssetTypeAudience) {
+				t.Errorf("audience.summary set = %v", ok)
+			}
+			delete(got, "audience.summary")
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCSVRecord_CriteriaAudienceLeavesFlatFieldsEmpty(t *testing.T) {
+	a := &Audience{ExternalID: "young", Type: "audience",
+		Criteria: &Criteria{Attribute: AttrAgeGroup, Op: OpIn, Value: []string{"18-24"}}}
+	record, err := CSVRecord(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, c := range CSVHeader() {
+		switch c {
+		case "audience.gender", "audience.hours_on_social", "audience.purchases_last_month":
+			if record[i] != "" {
+				t.Errorf("expected %s to be empty, got %q", c, record[i])
+			}
+		case "audience.criteria":
+			if want := `{"attribute":"age_group","op":"in","value":["18-24"]}`; record[i] != want {
+				t.Errorf("expected criteria %s, got %s", want, record[i])
+			}
+		}
+	}
+}
+
+func TestCSVHeader_FallsBackToJSON(t *testing.T) {
+	spec, _ := Lookup(AssetTypeInsight)
+	if got := csvColumnsOf(TypeSpec{Type: "video"}); !reflect.DeepEqual(got, []string{"json"}) {
+		t.Errorf("expected a json column for types without CSV columns, got %v", got)
+	}
+	values, err := csvValuesOf(TypeSpec{Type: spec.Type}, &Insight{ExternalID: "churn", Text: "Churn is down", Type: "insight"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"id":0,"external_id":"churn","text":"Churn is down","description":"","type":"insight"}`; values[0] != want {
+		t.Errorf("expected %s, got %s", want, values[0])
+	}
+}
@@ -0,0 +1,114 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+const AssetTypeDashboard AssetType = "dashboard"
+
+// Limits and allowed values for dashboards
+const MaxDashboardMembers = 50
+
+var DashboardLayouts = []string{"grid", "rows", "columns"}
+
+func init() {
+	Register(TypeSpec{
+		Type:     AssetTypeDashboard,
+		New:      func() Asset { return &Dashboard{Type: string(AssetTypeDashboard)} },
+		Required: []string{"external_id", "title", "layout", "members"},
+		Table:    "dashboards",
+		Columns:  []string{"id", "external_id", "title", "layout"},
+		ScanFields: func(a Asset) []any {
+			d := a.(*Dashboard)
+			return []any{&d.ID, &d.ExternalID, &d.Title, &d.Layout}
+		},
+		CSVColumns: []string{"title", "layout", "members"},
+		CSVValues: func(a Asset) ([]string, error) {
+			d := a.(*Dashboard)
+			members := make([]string, len(d.Members))
+			for i, m := range d.Members {
+				members[i] = string(m.Type) + ":" + m.ExternalID
+			}
+			return []string{d.Title, d.Layout, strings.Join(members, CSVListSeparator)}, nil
+		},
+	})
+}
+
+// Composite is implemented by assets made up of other assets. The store keeps
+// their members in order and checks that requested members exist and match them.
+type Composite interface {
+	Asset
+	CatalogID() int
+	MemberRefs() []AssetRef
+	SetMembers(members []AssetRef)
+}
+
+// AssetRef points at another asset by type and external ID
+type AssetRef struct {
+	Type       AssetType `json:"type"`
+	ExternalID string    `json:"external_id"`
+	Asset      Asset     `json:"asset,omitempty"` // full member, set when members are expanded
+}
+
+// Dashboard Asset groups charts, insights and audiences in a fixed order
+// swagger:model Dashboard
+type Dashboard struct {
+	ID          int        `json:"id"`
+	ExternalID  string     `json:"external_id"`
+	Title       string     `json:"title"`
+	Layout      string     `json:"layout"`
+	Members     []AssetRef `json:"members"`
+	Description string     `json:"description"`
+	Tags        []string   `json:"tags,omitempty"`   // favorite tags, lower-case and sorted
+	Pinned      bool       `json:"pinned,omitempty"` // pinned to the top of the favorites
+	Type        string     `json:"type"`
+	Version     int        `json:"version,omitempty"` // favorite version, used as its ETag
+}
+
+func (d *Dashboard) GetID() string                 { return d.ExternalID }
+func (d *Dashboard) GetType() AssetType            { return AssetTypeDashboard }
+func (d *Dashboard) GetDescription() string        { return d.Description }
+func (d *Dashboard) SetDescription(desc string)    { d.Description = desc }
+func (d *Dashboard) GetVersion() int               { return d.Version }
+func (d *Dashboard) SetVersion(v int)              { d.Version = v }
+func (d *Dashboard) GetTags() []string             { return d.Tags }
+func (d *Dashboard) SetTags(tags []string)         { d.Tags = tags }
+func (d *Dashboard) GetPinned() bool               { return d.Pinned }
+func (d *Dashboard) SetPinned(p bool)              { d.Pinned = p }
+func (d *Dashboard) CatalogID() int                { return d.ID }
+func (d *Dashboard) MemberRefs() []AssetRef        { return d.Members }
+func (d *Dashboard) SetMembers(members []AssetRef) { d.Members = members }
+func (d *Dashboard) Validate() error {
+	var v Validator
+	v.externalID(d.ExternalID)
+	v.Required("title", d.Title)
+	v.MaxLength("title", d.Title, MaxTitleLength)
+	v.Required("layout", d.Layout)
+	v.OneOf("layout", d.Layout, DashboardLayouts)
+	if len(d.Members) == 0 {
+		v.Add("members", "must have at least one member")
+	}
+	v.MaxItems("members", len(d.Members), MaxDashboardMembers)
+	seen := map[AssetRef]bool{}
+	for i, m := range d.Members {
+		field := fmt.Sprintf("members[%d]", i)
+		switch _, ok := Lookup(m.Type); {
+		case m.Type == "":
+			v.Add(field+".type", "is required")
+		case !ok:
+			v.Add(field+".type", "is not a known asset type")
+		case m.Type == AssetTypeDashboard:
+			v.Add(field+".type", "dashboards cannot contain other dashboards")
+		}
+		v.Required(field+".external_id", m.ExternalID)
+		key := AssetRef{Type: m.Type, ExternalID: m.ExternalID}
+		if seen[key] {
+			v.Add(field, "is a duplicate")
+		}
+		seen[key] = true
+	}
+	v.MaxLength("description", d.Description, MaxDescriptionLength)
+	v.Tags("tags", d.Tags)
+	return v.Err()
+}
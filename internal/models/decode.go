@@ -0,0 +1,194 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// maxJSONDepth bounds object and array nesting in request bodies
+const maxJSONDepth = 32
+
+// DecodeError reports where and why a JSON payload was rejected
+type DecodeError struct {
+	Field  string // dotted path of the offending field, empty when unknown
+	Offset int64  // byte offset into the payload
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e *DecodeError) Error() string {
+	var b strings.Builder
+	if e.Line > 0 {
+		fmt.Fprintf(&b, "line %d, column %d: ", e.Line, e.Column)
+	}
+	if e.Field != "" {
+		b.WriteString(e.Field + ": ")
+	}
+	b.WriteString(e.Msg)
+	return b.String()
+}
+
+// DecodeStrict decodes a single JSON value into v. Unlike json.Unmarshal it rejects
+// duplicate keys, unknown fields and trailing data, and reports error positions.
+func DecodeStrict(data []byte, v any) error {
+	if err := checkJSON(data); err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return decodeError(data, dec.InputOffset(), err)
+	}
+	return nil
+}
+
+// checkJSON walks every token so syntax errors, duplicate keys, excessive
+// nesting and trailing data are caught before decoding into a struct
+func checkJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var walk func(path string, depth int) error
+	walk = func(path string, depth int) error {
+		if depth > maxJSONDepth {
+			return &DecodeError{Field: path, Offset: dec.InputOffset(), Msg: "nesting is too deep"}
+		}
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'):
+			seen := map[string]bool{}
+			for dec.More() {
+				keyTok, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				key := keyTok.(string)
+				field := joinPath(path, key)
+				if seen[key] {
+					return &DecodeError{Field: field, Offset: dec.InputOffset(), Msg: "duplicate key"}
+				}
+				seen[key] = true
+				if err := walk(field, depth+1); err != nil {
+					return err
+				}
+			}
+			_, err = dec.Token()
+			return err
+		case json.Delim('['):
+			for i := 0; dec.More(); i++ {
+				if err := walk(fmt.Sprintf("%s[%d]", path, i), depth+1); err != nil {
+					return err
+				}
+			}
+			_, err = dec.Token()
+			return err
+		}
+		return nil
+	}
+
+	if err := walk("", 0); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return decodeError(data, dec.InputOffset(), err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return decodeError(data, dec.InputOffset(), errors.New("unexpected data after top-level value"))
+	}
+	return nil
+}
+
+// decodeError converts encoding/json errors into a DecodeError with a line and column
+func decodeError(data []byte, offset int64, err error) error {
+	de := &DecodeError{Offset: offset, Msg: err.Error()}
+
+	var existing *DecodeError
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &existing):
+		de = existing
+	case errors.As(err, &syntaxErr):
+		de.Offset = syntaxErr.Offset
+		de.Msg = strings.TrimPrefix(syntaxErr.Error(), "json: ")
+	case errors.As(err, &typeErr):
+		de.Offset = typeErr.Offset
+		de.Field = indexPath(typeErr.Field)
+		de.Msg = "must be " + jsonTypeName(typeErr.Type.Kind().String()) + ", got " + typeErr.Value
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		de.Offset = int64(len(data))
+		de.Msg = "unexpected end of JSON input"
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		de.Field, _ = strconv.Unquote(strings.TrimPrefix(err.Error(), "json: unknown field "))
+		de.Msg = "unknown field"
+	default:
+		de.Msg = strings.TrimPrefix(err.Error(), "json: ")
+	}
+	de.Line, de.Column = position(data, de.Offset)
+	return de
+}
+
+// position converts a byte offset into a 1-based line and column
+func position(data []byte, offset int64) (int, int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	line, col := 1, 1
+	for _, b := range data[:offset] {
+		if b == '\n' {
+			line++
+			col = 1
+		} else {
+			col++
+		}
+	}
+	return line, col
+}
+
+func jsonTypeName(kind string) string {
+	switch {
+	case strings.HasPrefix(kind, "int"), strings.HasPrefix(kind, "uint"), strings.HasPrefix(kind, "float"):
+		return "a number"
+	case kind == "string":
+		return "a string"
+	case kind == "bool":
+		return "a boolean"
+	case kind == "slice", kind == "array":
+		return "an array"
+	default:
+		return "an object"
+	}
+}
+
+// indexPath rewrites encoding/json field paths such as "data.0" as "data[0]"
+func indexPath(field string) string {
+	parts := strings.Split(field, ".")
+	var b strings.Builder
+	for i, p := range parts {
+		if _, err := strconv.Atoi(p); err == nil && i > 0 {
+			b.WriteString("[" + p + "]")
+			continue
+		}
+		if i > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(p)
+	}
+	return b.String()
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
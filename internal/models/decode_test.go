@@ -0,0 +1,166 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecodeAsset(t *testing.T) {
+	cases := []struct {
+		name      string
+		body      string
+		wantType  AssetType
+		wantField string
+		wantLine  int
+	}{
+		{name: "chart", body: `{"type":"chart","external_id":"c1","title":"t","data":[1,2]}`, wantType: AssetTypeChart},
+		{name: "audience", body: `{"type":"audience","external_id":"a1","gender":"female","birth_country":"GB","age_groups":["18-24"],"hours_on_social":0,"purchases_last_month":0}`, wantType: AssetTypeAudience},
+		{name: "chart series", body: `{"type":"chart","external_id":"c1","title":"t","kind":"bar","x_axis":{"kind":"category","categories":["Jan"]},"series":[{"name":"s","unit":"%","values":[2.5]}]}`, wantType: AssetTypeChart},
+		{name: "chart series unknown field", body: `{"type":"chart","external_id":"c1","title":"t","series":[{"name":"s","points":[1]}]}`, wantField: "points"},
+		{name: "dashboard", body: `{"type":"dashboard","external_id":"d1","title":"t","layout":"grid","members":[{"type":"chart","external_id":"c1"}]}`, wantType: AssetTypeDashboard},
+		{name: "dashboard member unknown field", body: `{"type":"dashboard","external_id":"d1","title":"t","layout":"grid","members":[{"type":"chart","id":"c1"}]}`, wantField: "id"},
+		{name: "unknown field", body: `{"type":"insight","external_id":"i1","text":"t","colour":"red"}`, wantField: "colour"},
+		{name: "duplicate key", body: "{\"type\":\"chart\",\n\"title\":\"a\",\n\"title\":\"b\"}", wantField: "title", wantLine: 3},
+		{name: "nested duplicate", body: `{"type":"chart","external_id":"c","title":"t","data":[1],"x":{"a":1,"a":2}}`, wantField: "x.a"},
+		{name: "client id", body: `{"type":"chart","id":7,"external_id":"c1","title":"t"}`, wantField: "id"},
+		{name: "null", body: `{"type":"chart","external_id":"c1","title":null}`, wantField: "title"},
+		{name: "missing count", body: `{"type":"audience","external_id":"a1","gender":"male","birth_country":"GR","hours_on_social":2}`, wantField: "purchases_last_month"},
+		{name: "audience criteria", body: `{"type":"audience","external_id":"a1","criteria":{"or":[{"attribute":"hours_on_social","op":"gte","value":4}]}}`, wantType: AssetTypeAudience},
+		{name: "audience without definition", body: `{"type":"audience","external_id":"a1"}`, wantField: "criteria"},
+		{name: "criteria unknown field", body: `{"type":"audience","external_id":"a1","criteria":{"attr":"gender"}}`, wantField: "attr"},
+		{name: "wrong type", body: "{\"type\":\"chart\",\"external_id\":\"c1\",\"title\":\"t\",\n\"data\":[\"x\"]}", wantField: "data", wantLine: 2},
+		{name: "trailing data", body: `{"type":"chart","external_id":"c1","title":"t"} {}`},
+		{name: "syntax", body: "{\"type\":\"chart\",\n\"title\" \"t\"}", wantLine: 2},
+		{name: "truncated", body: `{"type":"chart"`},
+		{name: "not an object", body: `[1,2]`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			a, err := DecodeAsset([]byte(c.body))
+			if c.wantType != "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if a.GetType() != c.wantType {
+					t.Errorf("expected %s, got %s", c.wantType, a.GetType())
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			var de *DecodeError
+			if !errors.As(err, &de) {
+				if errors.Is(err, ErrUnknownAssetType) && c.wantField == "" {
+					return
+				}
+				t.Fatalf("expected DecodeError, got %T: %v", err, err)
+			}
+			// Older Go releases omit the element index from type errors, so match on prefix
+			if (c.wantField == "" && de.Field != "") || !strings.HasPrefix(de.Field, c.wantField) {
+				t.Errorf("expected field %q, got %q (%v)", c.wantField, de.Field, err)
+			}
+			if c.wantLine != 0 && de.Line != c.wantLine {
+				t.Errorf("expected line %d, got %d (%v)", c.wantLine, de.Line, err)
+			}
+		})
+	}
+}
+
+func TestDecodeAsset_ChartRoundTrip(t *testing.T) {
+	a, err := DecodeAsset([]byte(`{"type":"chart","external_id":"c1","title":"t","y_axis_title":"Engagement","data":[85,92]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := a.(*Chart)
+	want := []Series{{Name: "Engagement", Values: []float64{85, 92}}}
+	if c.Kind != ChartKindLine || c.XAxis.Kind != AxisKindCategory || c.Data != nil || !reflect.DeepEqual(c.Series, want) {
+		t.Fatalf("legacy data not normalized: %+v", c)
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	c.XAxis = XAxis{Kind: AxisKindTime, Timestamps: []time.Time{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)}}
+	c.Series = append(c.Series, Series{Name: "Rate", Unit: "%", Values: []float64{2.1, 3.75}})
+	out, _ := json.Marshal(c)
+	var fields map[string]any
+	json.Unmarshal(out, &fields)
+	delete(fields, "id")
+	again, _ := json.Marshal(fields)
+	b, err := DecodeAsset(again)
+	if err != nil {
+		t.Fatalf("re-decoding %s: %v", again, err)
+	}
+	if !reflect.DeepEqual(b, c) {
+		t.Fatalf("round trip changed chart:\n%+v\n%+v", c, b)
+	}
+	if err := b.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDecodeAsset_UnknownType(t *testing.T) {
+	for _, body := range []string{`{"external_id":"x"}`, `{"type":"video"}`, `{"type":3}`} {
+		if _, err := DecodeAsset([]byte(body)); !errors.Is(err, ErrUnknownAssetType) {
+			t.Errorf("%s: expected ErrUnknownAssetType, got %v", body, err)
+		}
+	}
+}
+
+func FuzzDecodeAsset(f *testing.F) {
+	for _, seed := range []string{
+		`{"type":"chart","external_id":"c1","title":"t","data":[1,2,3]}`,
+		`{"type":"chart","external_id":"c1","title":"t","kind":"line","x_axis":{"kind":"time","timestamps":["2024-01-01T00:00:00Z"]},"series":[{"name":"s","values":[1.5]}]}`,
+		`{"type":"insight","external_id":"i1","text":"t","description":"d"}`,
+		`{"type":"audience","external_id":"a1","gender":"female","birth_country":"GB","age_groups":["18-24"],"hours_on_social":1,"purchases_last_month":2}`,
+		`{"type":"audience","external_id":"a1","criteria":{"and":[{"attribute":"gender","op":"eq","value":"female"},{"not":{"attribute":"age_group","op":"in","value":["16-17"]}}]}}`,
+		`{"type":"dashboard","external_id":"d1","title":"t","layout":"grid","members":[{"type":"chart","external_id":"c1"}]}`,
+		`{"type":"chart","title":"a","title":"b"}`,
+		`{"type":"chart","data":[[[[[]]]]]}`,
+		`{"type":"chart"`,
+		`null`,
+		``,
+	} {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		a, err := DecodeAsset(data)
+		if err != nil {
+			var de *DecodeError
+			if errors.As(err, &de) && (de.Line < 0 || de.Offset > int64(len(data))) {
+				t.Fatalf("error position out of range: %+v", de)
+			}
+			return
+		}
+		// Anything accepted must survive a round trip as the same type
+		a.Validate()
+		out, err := json.Marshal(a)
+		if err != nil {
+			t.Fatalf("marshal accepted asset: %v", err)
+		}
+		var fields map[string]any
+		json.Unmarshal(out, &fields)
+		delete(fields, "id")
+		delete(fields, "version")
+		delete(fields, "summary")
+		for k, v := range fields {
+			if v == nil {
+				delete(fields, k) // nil slices marshal as null
+			}
+		}
+		fields["type"] = string(a.GetType())
+		again, _ := json.Marshal(fields)
+		b, err := DecodeAsset(again)
+		if err != nil {
+			t.Fatalf("re-decoding %s: %v", again, err)
+		}
+		if b.GetType() != a.GetType() || b.GetID() != a.GetID() {
+			t.Fatalf("round trip changed asset: %+v vs %+v", a, b)
+		}
+	})
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// IdentityFields are kept in every sparse fieldset, so each asset can still be told apart
+var IdentityFields = []string{"type", "external_id"}
+
+// FieldNames returns every JSON field assets of the registered types are returned
+// with, sorted
+func FieldNames() []string {
+	var names []string
+	for _, spec := range Types() {
+		t := reflect.TypeOf(spec.New()).Elem()
+		for i := range t.NumField() {
+			name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+			if name != "" && name != "-" && !slices.Contains(spec.RequestOnly, name) {
+				names = append(names, name)
+			}
+		}
+		for name := range spec.FieldColumns {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+// SelectColumns returns the indexes of the Columns needed to encode the given JSON
+// fields: every column when fields is empty, otherwise id, external_id and the
+// columns the fields are stored in or built from
+func (s TypeSpec) SelectColumns(fields []string) []int {
+	var indexes []int
+	for i, c := range s.Columns {
+		if len(fields) == 0 || c == "id" || c == "external_id" || slices.Contains(fields, c) ||
+			slices.ContainsFunc(fields, func(f string) bool { return slices.Contains(s.FieldColumns[f], c) }) {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+// SparseAsset encodes an asset with only the requested JSON fields and IdentityFields
+type SparseAsset struct {
+	Asset  Asset
+	Fields []string
+}
+
+func (s SparseAsset) MarshalJSON() ([]byte, error) {
+	b, err := json.Marshal(s.Asset)
+	if err != nil {
+		return nil, err
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(b, &all); err != nil {
+		return nil, err
+	}
+	kept := map[string]json.RawMessage{}
+	for _, f := range append(slices.Clone(IdentityFields), s.Fields...) {
+		if v, ok := all[f]; ok {
+			kept[f] = v
+		}
+	}
+	return json.Marshal(kept)
+}
+
+// SparseAssets wraps each asset to encode with only the given fields
+func SparseAssets(assets []Asset, fields []string) []SparseAsset {
+	sparse := make([]SparseAsset, len(assets))
+	for i, a := range assets {
+		sparse[i] = SparseAsset{Asset: a, Fields: fields}
+	}
+	return sparse
+}
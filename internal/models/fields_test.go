@@ -0,0 +1,65 @@
+package models
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestSelectColumns(t *testing.T) {
+	columns := func(typ AssetType, fields ...string) []string {
+		spec, _ := Lookup(typ)
+		var names []string
+		for _, i := range spec.SelectColumns(fields) {
+			names = append(names, spec.Columns[i])
+		}
+		return names
+	}
+	chart, _ := Lookup(AssetTypeChart)
+	if got := columns(AssetTypeChart); !slices.Equal(got, chart.Columns) {
+		t.Errorf("expected every column without fields, got %v", got)
+	}
+	if got := columns(AssetTypeChart, "external_id", "title", "type"); !slices.Equal(got, []string{"id", "external_id", "title"}) {
+		t.Errorf("expected chart series to be left out, got %v", got)
+	}
+	if got := columns(AssetTypeInsight, "title"); !slices.Equal(got, []string{"id", "external_id"}) {
+		t.Errorf("expected only identifying columns for fields of other types, got %v", got)
+	}
+	if got := columns(AssetTypeAudience, "gender"); !slices.Equal(got, []string{"id", "external_id", "gender", "criteria"}) {
+		t.Errorf("expected criteria with flat audience fields, got %v", got)
+	}
+	if got := columns(AssetTypeAudience, "summary"); len(got) != len(audienceColumns)+2 {
+		t.Errorf("expected the summary to need every audience column, got %v", got)
+	}
+}
+
+func TestSparseAsset(t *testing.T) {
+	chart := &Chart{ExternalID: "q3", Type: "chart", Title: "Q3", Series: []Series{{Name: "revenue", Values: []float64{1, 2}}}, Description: "mine"}
+	b, err := json.Marshal(SparseAssets([]Asset{chart}, []string{"title", "text"}))
+	if err != nil || string(b) != `[{"external_id":"q3","title":"Q3","type":"chart"}]` {
+		t.Errorf("unexpected sparse encoding %s (%v)", b, err)
+	}
+
+	audience := &Audience{ExternalID: "a", Type: "audience", Gender: "Male", BirthCountry: "GR"}
+	b, _ = json.Marshal(SparseAsset{Asset: audience, Fields: []string{"summary"}})
+	var got map[string]any
+	json.Unmarshal(b, &got)
+	if len(got) != 3 || got["summary"] == "" {
+		t.Errorf("expected the derived summary, got %s", b)
+	}
+}
+
+func TestFieldNames(t *testing.T) {
+	names := FieldNames()
+	for _, want := range []string{"external_id", "title", "series", "text", "summary", "members", "tags", "version"} {
+		if !slices.Contains(names, want) {
+			t.Errorf("expected %q among %v", want, names)
+		}
+	}
+	if slices.Contains(names, "data") {
+		t.Errorf("expected the request-only chart data to be left out, got %v", names)
+	}
+	if slices.Contains(names, "") || !slices.IsSorted(names) {
+		t.Errorf("expected sorted field names, got %v", names)
+	}
+}
@@ -0,0 +1,215 @@
+package models
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// File formats of favorite exports and imports
+const (
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+	FormatCSV    = "csv"
+)
+
+var FileFormats = []string{FormatJSON, FormatNDJSON, FormatCSV}
+
+// MaxImportRows bounds the favorites in one import, which is applied in one transaction
+const MaxImportRows = 1000
+
+// What an import does with rows already in the user's favorites
+const (
+	ImportSkip      = "skip"
+	ImportOverwrite = "overwrite"
+	ImportFail      = "fail"
+)
+
+var ImportConflictPolicies = []string{ImportSkip, ImportOverwrite, ImportFail}
+
+// Outcomes of an imported row
+const (
+	ImportAdded        = "added"
+	ImportOverwritten  = "overwritten"
+	ImportDuplicate    = "duplicate" // already a favorite, and skipped or failing the import
+	ImportUnknownAsset = "unknown_asset"
+	ImportInvalid      = "invalid"
+)
+
+// ImportRow is one favorite of an imported file. The asset itself comes from the
+// catalog, so other fields, such as the asset payload of an export, are ignored.
+type ImportRow struct {
+	Type        string   `json:"type"`
+	ExternalID  string   `json:"external_id"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
+}
+
+// Validate checks the row identifies an asset; the asset's own Validate checks the rest
+func (r ImportRow) Validate() error {
+	var types []string
+	for _, spec := range Types() {
+		types = append(types, string(spec.Type))
+	}
+	var v Validator
+	v.Required("type", r.Type)
+	v.OneOf("type", r.Type, types)
+	v.externalID(r.ExternalID)
+	return v.Err()
+}
+
+// ImportReport lists what an import did, or would do on a dry run, with every row
+// swagger:model ImportReport
+type ImportReport struct {
+	DryRun        bool              `json:"dry_run"`
+	Added         int               `json:"added"`
+	Overwritten   int               `json:"overwritten"`
+	Duplicates    int               `json:"duplicates"`
+	UnknownAssets int               `json:"unknown_assets"`
+	Invalid       int               `json:"invalid"`
+	Rows          []ImportRowResult `json:"rows"`
+}
+
+// ImportRowResult is the outcome of one row. Index counts rows from 0, after the CSV header.
+type ImportRowResult struct {
+	Index      int              `json:"index"`
+	Type       string           `json:"type"`
+	ExternalID string           `json:"external_id"`
+	Status     string           `json:"status"`
+	Errors     ValidationErrors `json:"errors,omitempty"`
+}
+
+// Add records a row's outcome and counts it
+func (r *ImportReport) Add(row ImportRowResult) {
+	switch row.Status {
+	case ImportAdded:
+		r.Added++
+	case ImportOverwritten:
+		r.Overwritten++
+	case ImportDuplicate:
+		r.Duplicates++
+	case ImportUnknownAsset:
+		r.UnknownAssets++
+	case ImportInvalid:
+		r.Invalid++
+	}
+	r.Rows = append(r.Rows, row)
+}
+
+// ParseImport reads the rows of an imported file. JSON files hold an array of
+// favorites, NDJSON files one per line and CSV files a header naming at least the
+// type and external_id columns, with tags joined by CSVListSeparator. JSON errors
+// are DecodeErrors.
+func ParseImport(format string, data []byte) ([]ImportRow, error) {
+	var rows []ImportRow
+	var err error
+	switch format {
+	case FormatJSON:
+		rows, err = parseImportJSON(data)
+	case FormatNDJSON:
+		rows, err = parseImportNDJSON(data)
+	case FormatCSV:
+		rows, err = parseImportCSV(data)
+	default:
+		return nil, fmt.Errorf("unknown import format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) > MaxImportRows {
+		return nil, fmt.Errorf("an import may have at most %d rows, got %d", MaxImportRows, len(rows))
+	}
+	return rows, nil
+}
+
+func parseImportJSON(data []byte) ([]ImportRow, error) {
+	if err := checkJSON(data); err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	var rows []ImportRow
+	if err := dec.Decode(&rows); err != nil {
+		return nil, decodeError(data, dec.InputOffset(), err)
+	}
+	return rows, nil
+}
+
+func parseImportNDJSON(data []byte) ([]ImportRow, error) {
+	var rows []ImportRow
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		var row ImportRow
+		err := checkJSON(text)
+		if err == nil {
+			if err = json.Unmarshal(text, &row); err != nil {
+				err = decodeError(text, 0, err)
+			}
+		}
+		if err != nil {
+			// Positions are within the line; report the line of the file instead
+			var de *DecodeError
+			if errors.As(err, &de) {
+				de.Line = line
+			}
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+	return rows, scanner.Err()
+}
+
+func parseImportCSV(data []byte) ([]ImportRow, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	header, err := r.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Spreadsheet apps may start the file with a byte order mark
+	col := map[string]int{}
+	for i, name := range header {
+		col[strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))] = i
+	}
+	for _, required := range []string{"type", "external_id"} {
+		if _, ok := col[required]; !ok {
+			return nil, fmt.Errorf("the CSV header has no %s column", required)
+		}
+	}
+
+	var rows []ImportRow
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		field := func(name string) string {
+			if i, ok := col[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		row := ImportRow{Type: field("type"), ExternalID: field("external_id"), Description: field("description")}
+		for _, t := range strings.Split(field("tags"), CSVListSeparator) {
+			if t = strings.TrimSpace(t); t != "" {
+				row.Tags = append(row.Tags, t)
+			}
+		}
+		rows = append(rows, row)
+	}
+}
@@ -0,0 +1,92 @@
+package models
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseImport(t *testing.T) {
+	want := []ImportRow{
+		{Type: "chart", ExternalID: "sales", Description: "Q1", Tags: []string{"kpi", "q1"}},
+		{Type: "insight", ExternalID: "churn"},
+	}
+	tests := []struct {
+		name   string
+		format string
+		data   string
+	}{
+		{"json", FormatJSON, `[{"type": "chart", "external_id": "sales", "description": "Q1", "tags": ["kpi", "q1"], "title": "ignored"},
+			{"type": "insight", "external_id": "churn"}]`},
+		{"ndjson", FormatNDJSON, "{\"type\": \"chart\", \"external_id\": \"sales\", \"description\": \"Q1\", \"tags\": [\"kpi\", \"q1\"]}\n\n{\"type\": \"insight\", \"external_id\": \"churn\"}\n"},
+		{"csv", FormatCSV, "\ufefftype,external_id,description,tags,chart.title\nchart,sales,Q1,kpi; q1,Sales\ninsight,churn,,,\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := ParseImport(tt.format, []byte(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(rows, want) {
+				t.Errorf("expected %+v, got %+v", want, rows)
+			}
+		})
+	}
+}
+
+func TestParseImport_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		data   string
+		want   string
+	}{
+		{"json syntax", FormatJSON, `[{"type": "chart",}]`, "line 1"},
+		{"json wrong type", FormatJSON, `[{"type": "chart", "tags": "kpi"}]`, "tags"},
+		{"ndjson line", FormatNDJSON, "{\"type\": \"chart\"}\n{\"type\": }\n", "line 2"},
+		{"csv header", FormatCSV, "kind,external_id\nchart,sales\n", "no type column"},
+		{"too many rows", FormatNDJSON, strings.Repeat("{}\n", MaxImportRows+1), "at most"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseImport(tt.format, []byte(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected an error mentioning %q, got %v", tt.want, err)
+			}
+		})
+	}
+	var de *DecodeError
+	if _, err := ParseImport(FormatJSON, []byte(`{}`)); !errors.As(err, &de) {
+		t.Errorf("expected a DecodeError for a JSON object, got %v", err)
+	}
+}
+
+func TestImportRow_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		row    ImportRow
+		fields []string
+	}{
+		{"valid", ImportRow{Type: "insight", ExternalID: "churn"}, nil},
+		{"missing", ImportRow{}, []string{"type", "external_id"}},
+		{"unknown type", ImportRow{Type: "video", ExternalID: "v1"}, []string{"type"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fields(t, tt.row.Validate()); !reflect.DeepEqual(got, tt.fields) {
+				t.Errorf("expected fields %v, got %v", tt.fields, got)
+			}
+		})
+	}
+}
+
+func TestImportReport_Add(t *testing.T) {
+	var r ImportReport
+	for _, status := range []string{ImportAdded, ImportAdded, ImportDuplicate, ImportUnknownAsset, ImportInvalid, ImportOverwritten} {
+		r.Add(ImportRowResult{Status: status})
+	}
+	if r.Added != 2 || r.Duplicates != 1 || r.UnknownAssets != 1 || r.Invalid != 1 || r.Overwritten != 1 || len(r.Rows) != 6 {
+		t.Errorf("unexpected counts %+v", r)
+	}
+}
@@ -0,0 +1,52 @@
+package models
+
+func init() {
+	Register(TypeSpec{
+		Type:     AssetTypeInsight,
+		New:      func() Asset { return &Insight{Type: string(AssetTypeInsight)} },
+		Required: []string{"external_id", "text"},
+		Table:    "insights",
+		Columns:  []string{"id", "external_id", "text"},
+		ScanFields: func(a Asset) []any {
+			i := a.(*Insight)
+			return []any{&i.ID, &i.ExternalID, &i.Text}
+		},
+		CSVColumns: []string{"text"},
+		CSVValues: func(a Asset) ([]string, error) {
+			return []string{a.(*Insight).Text}, nil
+		},
+	})
+}
+
+// Insight Asset
+// swagger:model Insight
+type Insight struct {
+	ID          int      `json:"id"`
+	ExternalID  string   `json:"external_id"`
+	Text        string   `json:"text"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags,omitempty"`   // favorite tags, lower-case and sorted
+	Pinned      bool     `json:"pinned,omitempty"` // pinned to the top of the favorites
+	Type        string   `json:"type"`
+	Version     int      `json:"version,omitempty"` // favorite version, used as its ETag
+}
+
+func (i *Insight) GetID() string              { return i.ExternalID }
+func (i *Insight) GetType() AssetType         { return AssetTypeInsight }
+func (i *Insight) GetDescription() string     { return i.Description }
+func (i *Insight) SetDescription(desc string) { i.Description = desc }
+func (i *Insight) GetVersion() int            { return i.Version }
+func (i *Insight) SetVersion(v int)           { i.Version = v }
+func (i *Insight) GetTags() []string          { return i.Tags }
+func (i *Insight) SetTags(tags []string)      { i.Tags = tags }
+func (i *Insight) GetPinned() bool            { return i.Pinned }
+func (i *Insight) SetPinned(p bool)           { i.Pinned = p }
+func (i *Insight) Validate() error {
+	var v Validator
+	v.externalID(i.ExternalID)
+	v.Required("text", i.Text)
+	v.MaxLength("text", i.Text, MaxInsightTextLength)
+	v.MaxLength("description", i.Description, MaxDescriptionLength)
+	v.Tags("tags", i.Tags)
+	return v.Err()
+}
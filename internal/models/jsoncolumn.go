@@ -0,0 +1,43 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+)
+
+// jsonColumn scans a JSON or JSONB column into the value it points to
+type jsonColumn struct{ v any }
+
+// JSONColumn returns a scanner that unmarshals a JSON column into v, for use
+// in TypeSpec.ScanFields. NULL leaves v unchanged.
+func JSONColumn(v any) sql.Scanner { return jsonColumn{v} }
+
+func (j jsonColumn) Scan(src any) error {
+	switch s := src.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(s, j.v)
+	case string:
+		return json.Unmarshal([]byte(s), j.v)
+	default:
+		return fmt.Errorf("models: cannot scan %T into a JSON column", src)
+	}
+}
+
+// nullAsZero scans a nullable column, leaving the destination's zero value for NULL
+type nullAsZero[T any] struct{ dest *T }
+
+// NullAsZero returns a scanner that stores the zero value of T for NULL, for
+// optional columns scanned into plain fields in TypeSpec.ScanFields
+func NullAsZero[T any](dest *T) sql.Scanner { return nullAsZero[T]{dest} }
+
+func (n nullAsZero[T]) Scan(src any) error {
+	var v sql.Null[T]
+	if err := v.Scan(src); err != nil {
+		return err
+	}
+	*n.dest = v.V
+	return nil
+}
@@ -0,0 +1,43 @@
+package models
+
+// FavoriteRef identifies one of the user's favorites by asset type and external ID
+type FavoriteRef struct {
+	Type       AssetType `json:"type"`
+	ExternalID string    `json:"external_id"`
+}
+
+// FavoriteMove places a favorite directly before or after another one.
+// Exactly one of Before and After is set.
+type FavoriteMove struct {
+	Before *FavoriteRef `json:"before,omitempty"`
+	After  *FavoriteRef `json:"after,omitempty"`
+}
+
+// Anchor returns the favorite to move next to and whether the move goes after it
+func (m *FavoriteMove) Anchor() (FavoriteRef, bool) {
+	if m.After != nil {
+		return *m.After, true
+	}
+	return *m.Before, false
+}
+
+func (m *FavoriteMove) Validate() error {
+	var v Validator
+	switch {
+	case m.Before == nil && m.After == nil:
+		v.Add("before", "either before or after is required")
+	case m.Before != nil && m.After != nil:
+		v.Add("after", "cannot be combined with before")
+	default:
+		field := "before"
+		if m.After != nil {
+			field = "after"
+		}
+		ref, _ := m.Anchor()
+		if _, ok := Lookup(ref.Type); !ok {
+			v.Add(field+".type", "is not a known asset type")
+		}
+		v.Required(field+".external_id", ref.ExternalID)
+	}
+	return v.Err()
+}
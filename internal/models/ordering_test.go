@@ -0,0 +1,39 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFavoriteMove_Validate(t *testing.T) {
+	ref := &FavoriteRef{Type: AssetTypeChart, ExternalID: "c1"}
+	tests := []struct {
+		name   string
+		move   FavoriteMove
+		fields []string
+	}{
+		{"before", FavoriteMove{Before: ref}, nil},
+		{"after", FavoriteMove{After: ref}, nil},
+		{"neither", FavoriteMove{}, []string{"before"}},
+		{"both", FavoriteMove{Before: ref, After: ref}, []string{"after"}},
+		{"bad anchor", FavoriteMove{After: &FavoriteRef{Type: "video"}}, []string{"after.type", "after.external_id"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.move.Validate()
+			if tt.fields == nil {
+				if err != nil {
+					t.Fatalf("expected a valid move, got %v", err)
+				}
+				return
+			}
+			if got := fields(t, err); !reflect.DeepEqual(got, tt.fields) {
+				t.Errorf("expected fields %v, got %v", tt.fields, got)
+			}
+		})
+	}
+
+	if anchor, after := (&FavoriteMove{After: ref}).Anchor(); anchor != *ref || !after {
+		t.Errorf("unexpected anchor %v after=%v", anchor, after)
+	}
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// UserDataTable holds a user's rows of one table for a subject access report
+type UserDataTable struct {
+	Table string
+	Count int
+	// Rows is a JSON array of the rows with every column
+	Rows json.RawMessage
+}
+
+// ErasureReport confirms that everything a tenant stored about a user was erased.
+// Deleted counts the rows removed from each table; audit events are kept with the
+// user ID replaced by a pseudonym and their snapshots removed.
+// swagger:model ErasureReport
+type ErasureReport struct {
+	UserID                   string           `json:"user_id"`
+	TenantID                 string           `json:"tenant_id"`
+	Deleted                  map[string]int64 `json:"deleted"`
+	PseudonymizedAuditEvents int64            `json:"pseudonymized_audit_events"`
+	ErasedBy                 string           `json:"erased_by"`
+	RequestID                string           `json:"request_id,omitempty"`
+	CompletedAt              time.Time        `json:"completed_at"`
+	// Signature is "sha256=" and the hex HMAC-SHA256 of the report's JSON with an
+	// empty signature, keyed with the server's report signing key
+	Signature string `json:"signature"`
+}
+
+// Sign sets the report's signature
+func (r *ErasureReport) Sign(key []byte) error {
+	sig, err := r.signature(key)
+	r.Signature = sig
+	return err
+}
+
+// Verify reports whether the report is unchanged since it was signed with key
+func (r *ErasureReport) Verify(key []byte) bool {
+	sig, err := r.signature(key)
+	return err == nil && hmac.Equal([]byte(sig), []byte(r.Signature))
+}
+
+func (r *ErasureReport) signature(key []byte) (string, error) {
+	unsigned := *r
+	unsigned.Signature = ""
+	body, err := json.Marshal(unsigned)
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil)), nil
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestErasureReport_SignAndVerify(t *testing.T) {
+	key := []byte("report-key")
+	r := &ErasureReport{UserID: "11111111-1111-1111-1111-111111111111", TenantID: "default",
+		Deleted: map[string]int64{"favorites": 3}, CompletedAt: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)}
+	if err := r.Sign(key); err != nil {
+		t.Fatal(err)
+	}
+	if !r.Verify(key) {
+		t.Fatal("expected the signed report to verify")
+	}
+
+	// The signature survives a round trip through JSON, as clients receive it
+	body, _ := json.Marshal(r)
+	var received ErasureReport
+	json.Unmarshal(body, &received)
+	if !received.Verify(key) {
+		t.Error("expected the received report to verify")
+	}
+	if received.Verify([]byte("other-key")) {
+		t.Error("expected another key to fail")
+	}
+	received.Deleted["favorites"] = 0
+	if received.Verify(key) {
+		t.Error("expected a changed report to fail")
+	}
+}
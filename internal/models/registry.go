@@ -0,0 +1,77 @@
+package models
+
+import (
+	"fmt"
+	"sync"
+)
+
+// TypeSpec describes everything the API needs to support an asset type.
+// Each type registers its spec from an init function in its own file, so
+// adding a type does not require touching the handlers or the store.
+type TypeSpec struct {
+	Type AssetType
+	// New returns an empty asset, used as the target for decoding and scanning
+	New func() Asset
+	// Required lists JSON fields that must be present in request bodies
+	Required []string
+	// RequiredAlternatives lists alternative sets of JSON fields for types
+	// accepting more than one form; bodies must contain every field of one set
+	RequiredAlternatives [][]string
+	// Table is the catalog table holding assets of this type
+	Table string
+	// Columns are the catalog columns returned when listing favorites
+	Columns []string
+	// ScanFields returns pointers into an asset from New matching Columns, in order
+	ScanFields func(a Asset) []any
+	// FieldColumns lists the Columns that JSON fields not stored in a column of their
+	// own name are built from, for sparse fieldsets
+	FieldColumns map[string][]string
+	// RequestOnly lists JSON fields accepted in request bodies but never returned,
+	// so they cannot be asked for in sparse fieldsets
+	RequestOnly []string
+	// CSVColumns name the type's own columns in CSV exports and CSVValues returns
+	// an asset's values for them, in order. Types without them are exported as a
+	// single JSON column.
+	CSVColumns []string
+	CSVValues  func(a Asset) ([]string, error)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[AssetType]TypeSpec{}
+	typeOrder  []AssetType
+)
+
+// Register adds an asset type to the registry. It panics on duplicate or
+// incomplete registrations, which are programming errors.
+func Register(spec TypeSpec) {
+	if spec.Type == "" || spec.New == nil || spec.Table == "" || len(spec.Columns) == 0 || spec.ScanFields == nil {
+		panic(fmt.Sprintf("models: incomplete registration for asset type %q", spec.Type))
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, dup := registry[spec.Type]; dup {
+		panic(fmt.Sprintf("models: asset type %q registered twice", spec.Type))
+	}
+	registry[spec.Type] = spec
+	typeOrder = append(typeOrder, spec.Type)
+}
+
+// Lookup returns the spec registered for t
+func Lookup(t AssetType) (TypeSpec, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	spec, ok := registry[t]
+	return spec, ok
+}
+
+// Types returns every registered spec in registration order
+func Types() []TypeSpec {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	specs := make([]TypeSpec, len(typeOrder))
+	for i, t := range typeOrder {
+		specs[i] = registry[t]
+	}
+	return specs
+}
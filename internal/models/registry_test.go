@@ -0,0 +1,35 @@
+package models
+
+import "testing"
+
+func TestRegistry_BuiltinTypes(t *testing.T) {
+	for _, typ := range []AssetType{AssetTypeChart, AssetTypeInsight, AssetTypeAudience} {
+		spec, ok := Lookup(typ)
+		if !ok {
+			t.Fatalf("%s is not registered", typ)
+		}
+		a := spec.New()
+		if a.GetType() != typ {
+			t.Errorf("%s: New returned %s", typ, a.GetType())
+		}
+		if got := len(spec.ScanFields(a)); got != len(spec.Columns) {
+			t.Errorf("%s: %d scan fields for %d columns", typ, got, len(spec.Columns))
+		}
+		if values, err := spec.CSVValues(a); err != nil || len(values) != len(spec.CSVColumns) {
+			t.Errorf("%s: %d CSV values for %d CSV columns (%v)", typ, len(values), len(spec.CSVColumns), err)
+		}
+	}
+	if _, ok := Lookup("video"); ok {
+		t.Error("expected unknown type to be missing")
+	}
+}
+
+func TestRegister_RejectsDuplicates(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected duplicate registration to panic")
+		}
+	}()
+	spec, _ := Lookup(AssetTypeChart)
+	Register(spec)
+}
@@ -0,0 +1,134 @@
+package models
+
+import (
+	"regexp"
+	"time"
+)
+
+// What a share gives access to
+const (
+	ShareKindFavorite   = "favorite"
+	ShareKindCollection = "collection"
+)
+
+// Share permissions. Editors can change a shared favorite's description and a
+// shared collection's name and description; nobody but the owner can delete.
+const (
+	PermissionView = "view"
+	PermissionEdit = "edit"
+)
+
+// Share link lifetimes
+const (
+	DefaultShareLinkTTL = 7 * 24 * time.Hour
+	MaxShareLinkTTL     = 90 * 24 * time.Hour
+)
+
+var SharePermissions = []string{PermissionView, PermissionEdit}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// IsUUID reports whether s is a UUID, the format of user IDs
+func IsUUID(s string) bool {
+	return uuidPattern.MatchString(s)
+}
+
+// Share grants another user, or anyone holding a link token, access to one of
+// the owner's favorites or collections.
+// swagger:model Share
+type Share struct {
+	ID           int          `json:"id"`
+	Kind         string       `json:"kind"` // favorite or collection
+	OwnerID      string       `json:"owner_id"`
+	UserID       string       `json:"user_id,omitempty"` // the user shared with; empty for links
+	Permission   string       `json:"permission"`
+	Favorite     *FavoriteRef `json:"favorite,omitempty"`
+	CollectionID int          `json:"collection_id,omitempty"`
+	// Token opens a share link. It is only returned when the link is created.
+	Token     string     `json:"token,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// ShareRequest shares with a user, or creates a view-only link when Link is set.
+// Sharing again with the same user replaces the permission and expiry.
+type ShareRequest struct {
+	UserID     string     `json:"user_id,omitempty"`
+	Link       bool       `json:"link,omitempty"`
+	Permission string     `json:"permission"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"` // defaults to 7 days from now for links
+}
+
+// Normalize gives links the default expiry
+func (r *ShareRequest) Normalize() {
+	if r.Link && r.ExpiresAt == nil {
+		expires := time.Now().Add(DefaultShareLinkTTL).UTC().Truncate(time.Second)
+		r.ExpiresAt = &expires
+	}
+}
+
+// Validate checks the request of an owner sharing one of their items
+func (r *ShareRequest) Validate(ownerID string) error {
+	var v Validator
+	switch {
+	case r.UserID == "" && !r.Link:
+		v.Add("user_id", "either user_id or link is required")
+	case r.UserID != "" && r.Link:
+		v.Add("link", "cannot be combined with user_id")
+	case r.Link && r.Permission == PermissionEdit:
+		v.Add("permission", "share links are view-only")
+	case r.UserID != "" && !IsUUID(r.UserID):
+		v.Add("user_id", "must be a UUID")
+	case r.UserID == ownerID:
+		v.Add("user_id", "cannot share with yourself")
+	}
+	v.Required("permission", r.Permission)
+	v.OneOf("permission", r.Permission, SharePermissions)
+	if r.ExpiresAt != nil {
+		now := time.Now()
+		switch {
+		case !r.ExpiresAt.After(now):
+			v.Add("expires_at", "must be in the future")
+		case r.Link && r.ExpiresAt.After(now.Add(MaxShareLinkTTL)):
+			v.Add("expires_at", "must be at most 90 days away for links")
+		}
+	}
+	return v.Err()
+}
+
+// SharedItem is what a share gives access to: a favorite's asset, or a
+// collection with a page of its favorites.
+type SharedItem struct {
+	Share      Share       `json:"share"`
+	Asset      Asset       `json:"asset,omitempty"`
+	Collection *Collection `json:"collection,omitempty"`
+	Favorites  []Asset     `json:"favorites,omitempty"`
+}
+
+// SharedItemUpdate is an editor's partial update of a shared item; nil fields are left unchanged
+type SharedItemUpdate struct {
+	Name        *string `json:"name,omitempty"` // collections only
+	Description *string `json:"description,omitempty"`
+}
+
+// Empty reports whether the update changes nothing
+func (u *SharedItemUpdate) Empty() bool {
+	return u.Name == nil && u.Description == nil
+}
+
+// Validate checks the update against the kind of item being edited
+func (u *SharedItemUpdate) Validate(kind string) error {
+	var v Validator
+	if u.Name != nil {
+		if kind == ShareKindFavorite {
+			v.Add("name", "favorites have no name")
+		} else {
+			v.Required("name", *u.Name)
+			v.MaxLength("name", *u.Name, MaxCollectionNameLength)
+		}
+	}
+	if u.Description != nil {
+		v.MaxLength("description", *u.Description, MaxDescriptionLength)
+	}
+	return v.Err()
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestShareRequest_Validate(t *testing.T) {
+	owner := "11111111-1111-1111-1111-111111111111"
+	colleague := "22222222-2222-2222-2222-222222222222"
+	past, far := time.Now().Add(-time.Hour), time.Now().Add(MaxShareLinkTTL+time.Hour)
+	tests := []struct {
+		name   string
+		req    ShareRequest
+		fields []string
+	}{
+		{"user", ShareRequest{UserID: colleague, Permission: PermissionEdit}, nil},
+		{"link", ShareRequest{Link: true, Permission: PermissionView}, nil},
+		{"far user share", ShareRequest{UserID: colleague, Permission: PermissionView, ExpiresAt: &far}, nil},
+		{"nobody", ShareRequest{Permission: PermissionView}, []string{"user_id"}},
+		{"both", ShareRequest{UserID: colleague, Link: true, Permission: PermissionView}, []string{"link"}},
+		{"editable link", ShareRequest{Link: true, Permission: PermissionEdit}, []string{"permission"}},
+		{"not a uuid", ShareRequest{UserID: "bob", Permission: PermissionView}, []string{"user_id"}},
+		{"self", ShareRequest{UserID: owner, Permission: PermissionView}, []string{"user_id"}},
+		{"bad permission", ShareRequest{UserID: colleague, Permission: "admin"}, []string{"permission"}},
+		{"missing permission", ShareRequest{UserID: colleague}, []string{"permission"}},
+		{"expired", ShareRequest{UserID: colleague, Permission: PermissionView, ExpiresAt: &past}, []string{"expires_at"}},
+		{"far link", ShareRequest{Link: true, Permission: PermissionView, ExpiresAt: &far}, []string{"expires_at"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate(owner)
+			if tt.fields == nil {
+				if err != nil {
+					t.Fatalf("expected a valid request, got %v", err)
+				}
+				return
+			}
+			if got := fields(t, err); !reflect.DeepEqual(got, tt.fields) {
+				t.Errorf("expected fields %v, got %v", tt.fields, got)
+			}
+		})
+	}
+}
+
+func TestShareRequest_NormalizeDefaultsLinkExpiry(t *testing.T) {
+	link := ShareRequest{Link: true, Permission: PermissionView}
+	link.Normalize()
+	if link.ExpiresAt == nil || link.ExpiresAt.Sub(time.Now()) > DefaultShareLinkTTL {
+		t.Errorf("expected a link to expire within %v, got %v", DefaultShareLinkTTL, link.ExpiresAt)
+	}
+	user := ShareRequest{UserID: "22222222-2222-2222-2222-222222222222", Permission: PermissionView}
+	user.Normalize()
+	if user.ExpiresAt != nil {
+		t.Errorf("expected user shares not to expire by default, got %v", user.ExpiresAt)
+	}
+}
+
+func TestSharedItemUpdate_Validate(t *testing.T) {
+	name, blank := "Renamed", ""
+	if got := fields(t, (&SharedItemUpdate{Name: &name}).Validate(ShareKindFavorite)); !reflect.DeepEqual(got, []string{"name"}) {
+		t.Errorf("expected favorites to reject a name, got %v", got)
+	}
+	if got := fields(t, (&SharedItemUpdate{Name: &blank}).Validate(ShareKindCollection)); !reflect.DeepEqual(got, []string{"name"}) {
+		t.Errorf("expected collections to reject a blank name, got %v", got)
+	}
+	if err := (&SharedItemUpdate{Name: &name, Description: &blank}).Validate(ShareKindCollection); err != nil {
+		t.Errorf("expected a valid collection update, got %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Limits for favorite tags
+const (
+	MaxTagLength       = 50
+	MaxTagsPerFavorite = 20
+)
+
+// TagCount is the number of a user's favorites carrying a tag
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// NormalizeTag trims and lower-cases a tag, so "Client-X " and "client-x" are the same tag
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// NormalizeTags normalizes, de-duplicates and sorts tags
+func NormalizeTags(tags []string) []string {
+	seen := map[string]bool{}
+	out := []string{}
+	for _, t := range tags {
+		if t = NormalizeTag(t); !seen[t] {
+			seen[t] = true
+			out = append(out, t)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// validTag reports whether a normalized tag is made of letters, digits and - _ . :
+func validTag(tag string) bool {
+	for _, r := range tag {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("-_.:", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// Tags records a violation for every invalid tag and for more than
+// MaxTagsPerFavorite distinct tags. Tags are checked after normalization.
+func (v *Validator) Tags(field string, tags []string) {
+	for i, t := range tags {
+		f := fmt.Sprintf("%s[%d]", field, i)
+		t = NormalizeTag(t)
+		switch {
+		case t == "":
+			v.Add(f, "is required")
+		case utf8.RuneCountInString(t) > MaxTagLength:
+			v.Add(f, fmt.Sprintf("must be at most %d characters", MaxTagLength))
+		case !validTag(t):
+			v.Add(f, "may only contain letters, digits, '-', '_', '.' and ':'")
+		}
+	}
+	v.MaxItems(field, len(NormalizeTags(tags)), MaxTagsPerFavorite)
+}
+
+// ValidateTags checks tags sent to be added to a favorite
+func ValidateTags(tags []string) error {
+	var v Validator
+	if len(tags) == 0 {
+		v.Add("tags", "must have at least one tag")
+	}
+	v.Tags("tags", tags)
+	return v.Err()
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	got := NormalizeTags([]string{" Urgent", "client-x", "urgent ", "CLIENT-X"})
+	if !reflect.DeepEqual(got, []string{"client-x", "urgent"}) {
+		t.Errorf("unexpected tags %v", got)
+	}
+}
+
+func TestValidateTags(t *testing.T) {
+	if err := ValidateTags([]string{"q3", "Client-X", "region:emea", "v1.2_final"}); err != nil {
+		t.Errorf("expected valid tags, got %v", err)
+	}
+
+	bad := []string{" ", "has space", strings.Repeat("t", MaxTagLength+1), "ok"}
+	if got := fields(t, ValidateTags(bad)); !reflect.DeepEqual(got, []string{"tags[0]", "tags[1]", "tags[2]"}) {
+		t.Errorf("unexpected fields %v", got)
+	}
+	if got := fields(t, ValidateTags(nil)); !reflect.DeepEqual(got, []string{"tags"}) {
+		t.Errorf("unexpected fields %v", got)
+	}
+
+	many := make([]string, MaxTagsPerFavorite+1)
+	for i := range many {
+		many[i] = strings.Repeat("a", i+1)
+	}
+	if got := fields(t, ValidateTags(many)); !reflect.DeepEqual(got, []string{"tags"}) {
+		t.Errorf("unexpected fields %v", got)
+	}
+	// Duplicates collapse before the count is checked
+	dupes := append(many[:MaxTagsPerFavorite:MaxTagsPerFavorite], "A")
+	if err := ValidateTags(dupes); err != nil {
+		t.Errorf("expected duplicates not to count twice, got %v", err)
+	}
+}
@@ -0,0 +1,9 @@
+package models
+
+import "time"
+
+// TrashedFavorite is a removed favorite kept in the trash until it is restored or purged
+type TrashedFavorite struct {
+	Favorite  Asset     `json:"favorite"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
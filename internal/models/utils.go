@@ -3,42 +3,99 @@ package models
 import (
 	"encoding/json"
 	"errors"
+	"sort"
+	"strings"
 )
 
-func DecodeAsset(raw map[string]interface{}) (Asset, error) {
-	t, ok := raw["type"].(string)
-	if !ok {
-		return nil, errors.New("missing or invalid asset type")
-	}
+// ErrUnknownAssetType is returned by DecodeAsset when the type field is missing or not a known asset type
+var ErrUnknownAssetType = errors.New("missing or unknown asset type")
 
-	data, err := json.Marshal(raw)
-	if err != nil {
+// readOnlyFields are assigned by the server and rejected in request bodies
+var readOnlyFields = []string{"id", "version"}
+
+// DecodeAsset strictly decodes a JSON asset, dispatching on its "type" field.
+// Unknown, duplicate, read-only and null fields are rejected, as are missing
+// required fields whose zero value would otherwise be indistinguishable.
+func DecodeAsset(data []byte) (Asset, error) {
+	if err := checkJSON(data); err != nil {
 		return nil, err
 	}
 
-	var a Asset
-	switch AssetType(t) {
-	case AssetTypeChart:
-		var chart Chart
-		if err := json.Unmarshal(data, &chart); err != nil {
-			return nil, err
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, decodeError(data, 0, errors.New("asset must be a JSON object"))
+	}
+	var t string
+	if err := json.Unmarshal(fields["type"], &t); err != nil {
+		return nil, ErrUnknownAssetType
+	}
+
+	spec, ok := Lookup(AssetType(t))
+	if !ok {
+		return nil, ErrUnknownAssetType
+	}
+
+	for _, f := range readOnlyFields {
+		if _, ok := fields[f]; ok {
+			return nil, &DecodeError{Field: f, Msg: "is assigned by the server and must not be sent"}
 		}
-		a = &chart
-	case AssetTypeInsight:
-		var insight Insight
-		if err := json.Unmarshal(data, &insight); err != nil {
-			return nil, err
+	}
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if string(fields[name]) == "null" {
+			return nil, &DecodeError{Field: name, Msg: "must not be null"}
 		}
-		a = &insight
-	case AssetTypeAudience:
-		var audience Audience
-		if err := json.Unmarshal(data, &audience); err != nil {
-			return nil, err
+	}
+	for _, f := range spec.Required {
+		if _, ok := fields[f]; !ok {
+			return nil, &DecodeError{Field: f, Msg: "is required"}
 		}
-		a = &audience
-	default:
-		return nil, errors.New("unknown asset type")
+	}
+	if err := checkAlternatives(fields, spec.RequiredAlternatives); err != nil {
+		return nil, err
 	}
 
+	a := spec.New()
+	if err := DecodeStrict(data, a); err != nil {
+		return nil, err
+	}
+	if n, ok := a.(Normalizer); ok {
+		n.Normalize()
+	}
 	return a, nil
 }
+
+// checkAlternatives requires every field of at least one alternative set,
+// reporting the first missing field of the set the body has most fields of
+func checkAlternatives(fields map[string]json.RawMessage, sets [][]string) error {
+	if len(sets) == 0 {
+		return nil
+	}
+	var missing string
+	best := -1
+	for _, set := range sets {
+		present, firstMissing := 0, ""
+		for _, f := range set {
+			if _, ok := fields[f]; ok {
+				present++
+			} else if firstMissing == "" {
+				firstMissing = f
+			}
+		}
+		if firstMissing == "" {
+			return nil
+		}
+		if present > best {
+			best, missing = present, firstMissing
+		}
+	}
+	alternatives := make([]string, len(sets))
+	for i, set := range sets {
+		alternatives[i] = strings.Join(set, ", ")
+	}
+	return &DecodeError{Field: missing, Msg: "is required; send all of either " + strings.Join(alternatives, " or ")}
+}
@@ -0,0 +1,126 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Field limits shared by every asset type
+const (
+	MaxExternalIDLength  = 100
+	MaxTitleLength       = 255
+	MaxDescriptionLength = 1000
+	MaxInsightTextLength = 2000
+	MaxChartDataPoints   = 1000
+	MaxHoursOnSocial     = 24
+)
+
+// Allowed values for audience attributes
+var (
+	Genders   = []string{"male", "female", "non_binary", "other"}
+	AgeGroups = []string{"16-17", "18-24", "25-34", "35-44", "45-54", "55-64", "65+"}
+)
+
+// ValidationError describes why a single field of an asset is invalid
+type ValidationError struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+// ValidationErrors collects every invalid field of an asset
+type ValidationErrors []ValidationError
+
+func (v ValidationErrors) Error() string {
+	parts := make([]string, len(v))
+	for i, e := range v {
+		parts[i] = e.Field + ": " + e.Reason
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Validator accumulates field violations so every failing field is reported at once
+type Validator struct {
+	errs ValidationErrors
+}
+
+// Add records a violation for field
+func (v *Validator) Add(field, reason string) {
+	v.errs = append(v.errs, ValidationError{Field: field, Reason: reason})
+}
+
+// Required records a violation when value is empty
+func (v *Validator) Required(field, value string) {
+	if strings.TrimSpace(value) == "" {
+		v.Add(field, "is required")
+	}
+}
+
+// MaxLength records a violation when value is longer than max characters
+func (v *Validator) MaxLength(field, value string, max int) {
+	if utf8.RuneCountInString(value) > max {
+		v.Add(field, fmt.Sprintf("must be at most %d characters", max))
+	}
+}
+
+// OneOf records a violation when a non-empty value is not in allowed
+func (v *Validator) OneOf(field, value string, allowed []string) {
+	if value == "" {
+		return
+	}
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	v.Add(field, "must be one of "+strings.Join(allowed, ", "))
+}
+
+// CountryCode records a violation when a non-empty value is not an ISO 3166-1 alpha-2 code
+func (v *Validator) CountryCode(field, value string) {
+	if value != "" && !IsCountryCode(value) {
+		v.Add(field, "must be an ISO 3166-1 alpha-2 country code such as GB")
+	}
+}
+
+// Range records a violation when n is outside [min, max]
+func (v *Validator) Range(field string, n, min, max int) {
+	if n < min || n > max {
+		v.Add(field, fmt.Sprintf("must be between %d and %d", min, max))
+	}
+}
+
+// NonNegative records a violation when n is below zero
+func (v *Validator) NonNegative(field string, n int) {
+	if n < 0 {
+		v.Add(field, "must not be negative")
+	}
+}
+
+// MaxItems records a violation when a list holds more than max items
+func (v *Validator) MaxItems(field string, n, max int) {
+	if n > max {
+		v.Add(field, fmt.Sprintf("must have at most %d items", max))
+	}
+}
+
+// Err returns the collected violations, or nil when there are none
+func (v *Validator) Err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+// externalID applies the rules shared by every asset's external_id
+func (v *Validator) externalID(value string) {
+	v.Required("external_id", value)
+	v.MaxLength("external_id", value, MaxExternalIDLength)
+}
+
+// ValidateDescription checks a favorite's user-supplied description
+func ValidateDescription(desc string) error {
+	var v Validator
+	v.MaxLength("description", desc, MaxDescriptionLength)
+	return v.Err()
+}
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"net/url"
+	"time"
+)
+
+// Favorite change events published through the outbox to webhooks
+const (
+	EventFavoriteAdded    = "favorite.added"
+	EventFavoriteRemoved  = "favorite.removed"
+	EventFavoriteUpdated  = "favorite.updated"
+	EventFavoriteRestored = "favorite.restored"
+)
+
+var WebhookEventTypes = []string{EventFavoriteAdded, EventFavoriteRemoved, EventFavoriteUpdated, EventFavoriteRestored}
+
+// Webhook delivery states. Deliveries are retried with backoff while pending and
+// dead-lettered once their attempts run out.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead"
+)
+
+var DeliveryStatuses = []string{DeliveryPending, DeliveryDelivered, DeliveryDead}
+
+// Webhook limits
+const (
+	MaxWebhookURLLength = 2048
+)
+
+// Webhook is a URL that receives the tenant's favorite change events.
+// swagger:model Webhook
+type Webhook struct {
+	ID     int      `json:"id"`
+	URL    string   `json:"url"`
+	Events []string `json:"events"` // empty means every event
+	// Secret signs every delivery. It is only returned when the webhook is created.
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WebhookRequest registers a webhook
+type WebhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events,omitempty"`
+}
+
+// Validate checks the URL is absolute http(s) and every event type is known
+func (r *WebhookRequest) Validate() error {
+	var v Validator
+	v.Required("url", r.URL)
+	v.MaxLength("url", r.URL, MaxWebhookURLLength)
+	if r.URL != "" {
+		u, err := url.Parse(r.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			v.Add("url", "must be an absolute http or https URL")
+		}
+	}
+	v.MaxItems("events", len(r.Events), len(WebhookEventTypes))
+	for _, e := range r.Events {
+		v.OneOf("events", e, WebhookEventTypes)
+	}
+	return v.Err()
+}
+
+// FavoriteEvent is the JSON body POSTed to webhooks. ID is the same for every
+// delivery of the event, so receivers can drop duplicates.
+// swagger:model FavoriteEvent
+type FavoriteEvent struct {
+	ID         int64             `json:"id"`
+	Type       string            `json:"type"`
+	UserID     string            `json:"user_id"`
+	OccurredAt time.Time         `json:"occurred_at"`
+	Data       FavoriteEventData `json:"data"`
+}
+
+// FavoriteEventData is the favorite as it was after the change, or before it for removals
+type FavoriteEventData struct {
+	AssetType   string   `json:"asset_type"`
+	ExternalID  string   `json:"external_id"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
+}
+
+// WebhookDelivery is the state of one event's delivery to one webhook.
+// swagger:model WebhookDelivery
+type WebhookDelivery struct {
+	ID            int64      `json:"id"`
+	WebhookID     int        `json:"webhook_id"`
+	EventID       int64      `json:"event_id"`
+	EventType     string     `json:"event_type"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"` // pending deliveries only
+	LastError     string     `json:"last_error,omitempty"`
+	DeliveredAt   *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWebhookRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		req    WebhookRequest
+		fields []string
+	}{
+		{"every event", WebhookRequest{URL: "https://crm.example.com/hooks/favorites"}, nil},
+		{"some events", WebhookRequest{URL: "http://localhost:9000/", Events: []string{EventFavoriteAdded, EventFavoriteRemoved}}, nil},
+		{"missing url", WebhookRequest{}, []string{"url"}},
+		{"relative url", WebhookRequest{URL: "/hooks"}, []string{"url"}},
+		{"other scheme", WebhookRequest{URL: "ftp://example.com/hooks"}, []string{"url"}},
+		{"unknown event", WebhookRequest{URL: "https://example.com", Events: []string{"favorite.liked"}}, []string{"events"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if got := fields(t, err); !reflect.DeepEqual(got, tt.fields) {
+				t.Errorf("expected fields %v, got %v", tt.fields, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gitvam/platform-go-challenge/internal/models"
+	"github.com/lib/pq"
+)
+
+// auditEvent is a change to record in the audit trail. before and after are
+// marshalled to JSON; nil means the entity did not exist on that side.
+type auditEvent struct {
+	userID     string // owner of the changed data
+	action     string
+	entityType string
+	entityID   string
+	before     any
+	after      any
+}
+
+// favoriteState is what the audit trail records about a favorite
+type favoriteState struct {
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
+	Pinned      bool     `json:"pinned"`
+	Position    float64  `json:"position"`
+	userID      string
+}
+
+// webhookState is what the audit trail records about a webhook, leaving out its secret
+type webhookState struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+}
+
+// collectionMembership is the audited state of a favorite's place in a collection
+type collectionMembership struct {
+	Favorite string `json:"favorite"`
+}
+
+func loadFavoriteState(tx *sql.Tx, favoriteID int) (*favoriteState, error) {
+	var s favoriteState
+	var tags pq.StringArray
+	err := tx.QueryRow(`SELECT description, tags, pinned, position, user_id FROM favorites WHERE id = $1`, favoriteID).
+		Scan(&s.Description, &tags, &s.Pinned, &s.Position, &s.userID)
+	if err != nil {
+		return nil, err
+	}
+	s.Tags = tags
+	return &s, nil
+}
+
+// favoriteEntityIDOf returns the audit EntityID of a favorite known only by its row ID
+func favoriteEntityIDOf(tx *sql.Tx, favoriteID int) (string, error) {
+	assetType, externalID, err := favoriteRefOf(tx, favoriteID)
+	return models.FavoriteEntityID(assetType, externalID), err
+}
+
+// favoriteRefOf returns the type and external ID of a favorite known only by its row ID
+func favoriteRefOf(tx *sql.Tx, favoriteID int) (string, string, error) {
+	var assetType string
+	var assetID int
+	if err := tx.QueryRow(`SELECT asset_type, asset_id FROM favorites WHERE id = $1`, favoriteID).Scan(&assetType, &assetID); err != nil {
+		return "", "", err
+	}
+	spec, ok := models.Lookup(models.AssetType(assetType))
+	if !ok {
+		return "", "", fmt.Errorf("%w %q", ErrInvalidType, assetType)
+	}
+	var externalID string
+	err := tx.QueryRow(fmt.Sprintf(`SELECT external_id FROM %s WHERE id = $1`, pq.QuoteIdentifier(spec.Table)), assetID).Scan(&externalID)
+	return assetType, externalID, err
+}
+
+// auditFavorite records a change to a live favorite made in tx, reading its state after the change
+func (ps *PostgresStore) auditFavorite(tx *sql.Tx, callerID string, favoriteID int, entityID, action string, before *favoriteState) error {
+	after, err := loadFavoriteState(tx, favoriteID)
+	if err != nil {
+		return err
+	}
+	return ps.audit(tx, callerID, auditEvent{userID: after.userID, action: action,
+		entityType: models.AuditEntityFavorite, entityID: entityID, before: before, after: after})
+}
+
+// audit appends e to the audit trail in tx, so the event is kept only if the change is.
+// The actor is the one set with AuditAs, falling back to callerID, the user making the change.
+func (ps *PostgresStore) audit(tx *sql.Tx, callerID string, e auditEvent) error {
+	actorID := ps.actorID
+	if actorID == "" {
+		actorID = callerID
+	}
+	before, err := auditJSON(e.before)
+	if err != nil {
+		return err
+	}
+	after, err := auditJSON(e.after)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`
+		INSERT INTO audit_events (tenant_id, user_id, actor_id, action, entity_type, entity_id, before, after, request_id)
+		VALUES ($1, $2, NULLIF($3, '')::uuid, $4, $5, $6, $7, $8, $9)`,
+		ps.tenantID, e.userID, actorID, e.action, e.entityType, e.entityID, before, after, ps.requestID)
+	return err
+}
+
+// auditJSON marshals an audited state, keeping nil values, including nil pointers, as SQL NULL
+func auditJSON(v any) ([]byte, error) {
+	if v == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil || string(b) == "null" {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (ps *PostgresStore) ListAuditEvents(filter AuditFilter) ([]models.AuditEvent, error) {
+	var since, until *time.Time
+	if !filter.Since.IsZero() {
+		since = &filter.Since
+	}
+	if !filter.Until.IsZero() {
+		until = &filter.Until
+	}
+	query := `
+		SELECT id, user_id, COALESCE(actor_id::text, ''), action, entity_type, entity_id, before, after, request_id, created_at
+		FROM audit_events
+		WHERE tenant_id = $1
+		  AND ($2 = '' OR user_id = NULLIF($2, '')::uuid)
+		  AND ($3 = '' OR actor_id = NULLIF($3, '')::uuid)
+		  AND ($4 = '' OR action = $4)
+		  AND ($5 = '' OR entity_type = $5)
+		  AND ($6 = '' OR entity_id = $6)
+		  AND ($7::timestamptz IS NULL OR created_at >= $7)
+		  AND ($8::timestamptz IS NULL OR created_at < $8)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $9 OFFSET $10`
+	rows, err := ps.db.Query(query, ps.tenantID, filter.UserID, filter.ActorID, filter.Action, filter.EntityType, filter.EntityID,
+		since, until, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.AuditEvent{}
+	for rows.Next() {
+		var e models.AuditEvent
+		var before, after []byte
+		if err := rows.Scan(&e.ID, &e.UserID, &e.ActorID, &e.Action, &e.EntityType, &e.EntityID, &before, &after, &e.RequestID, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		e.Before, e.After = before, after
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
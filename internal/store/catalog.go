@@ -0,0 +1,74 @@
+package store
+
+import (
+	"fmt"
+
+	"github.com/gitvam/platform-go-challenge/internal/models"
+	"github.com/lib/pq"
+)
+
+func (ps *PostgresStore) ListCatalog(assetType string, opts ListOptions) ([]models.Asset, error) {
+	spec, ok := models.Lookup(models.AssetType(assetType))
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrInvalidType, assetType)
+	}
+	// A tenant's asset shadows a global one with the same external ID, as in resolveAssetID
+	query := fmt.Sprintf(`
+		SELECT id FROM (
+			SELECT DISTINCT ON (external_id) id, external_id FROM %s
+			WHERE tenant_id IS NULL OR tenant_id = $1
+			ORDER BY external_id, tenant_id NULLS LAST
+		) visible
+		ORDER BY external_id
+		LIMIT $2 OFFSET $3`, pq.QuoteIdentifier(spec.Table))
+	rows, err := ps.db.Query(query, ps.tenantID, opts.Limit, opts.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return ps.catalogAssetsInOrder(spec.Type, ids, opts.ExpandMembers)
+}
+
+func (ps *PostgresStore) GetCatalogAsset(assetType, externalID string, expandMembers bool) (models.Asset, error) {
+	id, err := ps.resolveAssetID(assetType, externalID)
+	if err != nil {
+		return nil, err
+	}
+	assets, err := ps.catalogAssetsInOrder(models.AssetType(assetType), []int64{int64(id)}, expandMembers)
+	if err != nil {
+		return nil, err
+	}
+	if len(assets) == 0 {
+		return nil, fmt.Errorf("%s %q: %w", assetType, externalID, ErrNotFound)
+	}
+	return assets[0], nil
+}
+
+// catalogAssetsInOrder loads catalog assets of one type in the order of ids, with their members
+func (ps *PostgresStore) catalogAssetsInOrder(t models.AssetType, ids []int64, expandMembers bool) ([]models.Asset, error) {
+	byID, err := ps.catalogAssets(ps.db, t, ids)
+	if err != nil {
+		return nil, err
+	}
+	assets := []models.Asset{}
+	for _, id := range ids {
+		if a, ok := byID[int(id)]; ok {
+			assets = append(assets, a)
+		}
+	}
+	if err := ps.loadMembers(ps.db, assets, expandMembers); err != nil {
+		return nil, err
+	}
+	return assets, nil
+}
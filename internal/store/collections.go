@@ -0,0 +1,300 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/gitvam/platform-go-challenge/internal/models"
+	"github.com/lib/pq"
+)
+
+// collectionColumns are selected, in scanCollection order, from collections aliased as c
+const collectionColumns = `
+	c.id, c.name, c.description, c.position, c.version, c.created_at, c.updated_at,
+	(SELECT count(*) FROM collection_favorites cf JOIN favorites f ON f.id = cf.favorite_id
+	 WHERE cf.collection_id = c.id AND f.deleted_at IS NULL)`
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanCollection(row rowScanner) (*models.Collection, error) {
+	var c models.Collection
+	err := row.Scan(&c.ID, &c.Name, &c.Description, &c.Position, &c.Version, &c.CreatedAt, &c.UpdatedAt, &c.FavoriteCount)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (ps *PostgresStore) ListCollections(userID string) ([]models.Collection, error) {
+	rows, err := ps.db.Query(`SELECT `+collectionColumns+` FROM collections c WHERE c.tenant_id = $1 AND c.user_id = $2 ORDER BY c.position, c.id`, ps.tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	collections := []models.Collection{}
+	for rows.Next() {
+		c, err := scanCollection(rows)
+		if err != nil {
+			return nil, err
+		}
+		collections = append(collections, *c)
+	}
+	return collections, rows.Err()
+}
+
+func (ps *PostgresStore) GetCollection(userID string, collectionID int) (*models.Collection, error) {
+	row := ps.db.QueryRow(`SELECT `+collectionColumns+` FROM collections c WHERE c.tenant_id = $1 AND c.user_id = $2 AND c.id = $3`, ps.tenantID, userID, collectionID)
+	c, err := scanCollection(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("collection %d: %w", collectionID, ErrNotFound)
+	}
+	return c, err
+}
+
+func (ps *PostgresStore) CreateCollection(userID string, c *models.Collection) error {
+	if err := c.Validate(); err != nil {
+		return fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+
+	tx, err := ps.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	insert := `
+		INSERT INTO collections (tenant_id, user_id, name, description, position)
+		SELECT $4, $1, $2, $3, COALESCE(MAX(position) + 1, 0) FROM collections WHERE tenant_id = $4 AND user_id = $1
+		RETURNING id, position, version, created_at, updated_at`
+	err = tx.QueryRow(insert, userID, c.Name, c.Description, ps.tenantID).Scan(&c.ID, &c.Position, &c.Version, &c.CreatedAt, &c.UpdatedAt)
+	if isUniqueViolation(err) {
+		return fmt.Errorf("collection %q %w", c.Name, ErrAlreadyExists)
+	}
+	if err != nil {
+		return err
+	}
+	err = ps.audit(tx, userID, auditEvent{userID: userID, action: models.ActionCollectionCreated,
+		entityType: models.AuditEntityCollection, entityID: strconv.Itoa(c.ID), after: c})
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (ps *PostgresStore) UpdateCollection(userID string, collectionID int, update models.CollectionUpdate, expectedVersion int) (*models.Collection, error) {
+	if err := update.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+
+	tx, err := ps.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Lock the user's collections so concurrent moves see a consistent order
+	rows, err := tx.Query(`SELECT id, version FROM collections WHERE tenant_id = $1 AND user_id = $2 ORDER BY position, id FOR UPDATE`, ps.tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	var order []int64
+	index, version := -1, 0
+	for rows.Next() {
+		var id int64
+		var v int
+		if err := rows.Scan(&id, &v); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if id == int64(collectionID) {
+			index, version = len(order), v
+		}
+		order = append(order, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if index < 0 {
+		return nil, fmt.Errorf("collection %d: %w", collectionID, ErrNotFound)
+	}
+	if expectedVersion != 0 && version != expectedVersion {
+		return nil, ErrVersionConflict
+	}
+	before, err := scanCollection(tx.QueryRow(`SELECT `+collectionColumns+` FROM collections c WHERE c.id = $1`, collectionID))
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		UPDATE collections
+		SET name = COALESCE($1, name), description = COALESCE($2, description), version = version + 1, updated_at = now()
+		WHERE id = $3`
+	if _, err := tx.Exec(query, update.Name, update.Description, collectionID); err != nil {
+		if isUniqueViolation(err) {
+			return nil, fmt.Errorf("collection %q %w", *update.Name, ErrAlreadyExists)
+		}
+		return nil, err
+	}
+
+	if update.Position != nil {
+		to := min(*update.Position, len(order)-1)
+		id := order[index]
+		order = append(order[:index], order[index+1:]...)
+		order = append(order[:to], append([]int64{id}, order[to:]...)...)
+		renumber := `
+			UPDATE collections c SET position = o.ord - 1
+			FROM unnest($1::int[]) WITH ORDINALITY AS o(id, ord)
+			WHERE c.id = o.id AND c.position <> o.ord - 1`
+		if _, err := tx.Exec(renumber, pq.Array(order)); err != nil {
+			return nil, err
+		}
+	}
+
+	c, err := scanCollection(tx.QueryRow(`SELECT `+collectionColumns+` FROM collections c WHERE c.id = $1`, collectionID))
+	if err != nil {
+		return nil, err
+	}
+	err = ps.audit(tx, userID, auditEvent{userID: userID, action: models.ActionCollectionUpdated,
+		entityType: models.AuditEntityCollection, entityID: strconv.Itoa(collectionID), before: before, after: c})
+	if err != nil {
+		return nil, err
+	}
+	return c, tx.Commit()
+}
+
+func (ps *PostgresStore) DeleteCollection(userID string, collectionID int) error {
+	tx, err := ps.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	before, err := scanCollection(tx.QueryRow(`SELECT `+collectionColumns+` FROM collections c WHERE c.tenant_id = $1 AND c.user_id = $2 AND c.id = $3 FOR UPDATE`,
+		ps.tenantID, userID, collectionID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("collection %d: %w", collectionID, ErrNotFound)
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM collections WHERE id = $1`, collectionID); err != nil {
+		return err
+	}
+	position := before.Position
+	// Close the gap so positions stay contiguous
+	if _, err := tx.Exec(`UPDATE collections SET position = position - 1 WHERE tenant_id = $1 AND user_id = $2 AND position > $3`, ps.tenantID, userID, position); err != nil {
+		return err
+	}
+	err = ps.audit(tx, userID, auditEvent{userID: userID, action: models.ActionCollectionDeleted,
+		entityType: models.AuditEntityCollection, entityID: strconv.Itoa(collectionID), before: before})
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (ps *PostgresStore) AddToCollection(userID string, collectionID int, assetType, externalID string) error {
+	favoriteID, err := ps.collectionFavoriteID(userID, collectionID, assetType, externalID)
+	if err != nil {
+		return err
+	}
+	err = ps.changeMembership(userID, collectionID, assetType, externalID, models.ActionCollectionFavoriteAdded, `
+		INSERT INTO collection_favorites (collection_id, favorite_id) VALUES ($1, $2)
+		ON CONFLICT (collection_id, favorite_id) DO NOTHING`, favoriteID)
+	// Adding a favorite that is already in the collection changes nothing
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	return err
+}
+
+func (ps *PostgresStore) RemoveFromCollection(userID string, collectionID int, assetType, externalID string) error {
+	favoriteID, err := ps.collectionFavoriteID(userID, collectionID, assetType, externalID)
+	if err != nil {
+		return err
+	}
+	err = ps.changeMembership(userID, collectionID, assetType, externalID, models.ActionCollectionFavoriteRemoved,
+		`DELETE FROM collection_favorites WHERE collection_id = $1 AND favorite_id = $2`, favoriteID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("favorite %s %q in collection %d: %w", assetType, externalID, collectionID, ErrNotFound)
+	}
+	return err
+}
+
+// changeMembership runs statement to add a favorite to a collection or remove it, auditing
+// the change. It returns sql.ErrNoRows when the statement changed nothing.
+func (ps *PostgresStore) changeMembership(userID string, collectionID int, assetType, externalID, action, statement string, favoriteID int) error {
+	tx, err := ps.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(statement, collectionID, favoriteID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	e := auditEvent{userID: userID, action: action, entityType: models.AuditEntityCollection, entityID: strconv.Itoa(collectionID)}
+	membership := collectionMembership{Favorite: models.FavoriteEntityID(assetType, externalID)}
+	if action == models.ActionCollectionFavoriteAdded {
+		e.after = membership
+	} else {
+		e.before = membership
+	}
+	if err := ps.audit(tx, userID, e); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (ps *PostgresStore) ListCollectionFavorites(userID string, collectionID int, opts ListOptions) ([]models.Asset, error) {
+	if err := ps.checkCollection(userID, collectionID); err != nil {
+		return nil, err
+	}
+	return ps.listFavorites(favoriteFilter{userID: userID, collectionID: collectionID}, opts)
+}
+
+// checkCollection returns ErrNotFound unless the user owns the collection
+func (ps *PostgresStore) checkCollection(userID string, collectionID int) error {
+	var exists bool
+	err := ps.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM collections WHERE tenant_id = $1 AND user_id = $2 AND id = $3)`, ps.tenantID, userID, collectionID).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("collection %d: %w", collectionID, ErrNotFound)
+	}
+	return nil
+}
+
+// collectionFavoriteID checks the user owns the collection and returns the ID of their favorite
+func (ps *PostgresStore) collectionFavoriteID(userID string, collectionID int, assetType, externalID string) (int, error) {
+	if err := ps.checkCollection(userID, collectionID); err != nil {
+		return 0, err
+	}
+	return ps.favoriteID(userID, assetType, externalID)
+}
+
+// favoriteID returns the ID of the user's favorite of an asset
+func (ps *PostgresStore) favoriteID(userID, assetType, externalID string) (int, error) {
+	assetID, err := ps.resolveAssetID(assetType, externalID)
+	if err != nil {
+		return 0, err
+	}
+	var favoriteID int
+	err = ps.db.QueryRow(`SELECT id FROM favorites WHERE tenant_id = $1 AND user_id = $2 AND asset_type = $3 AND asset_id = $4 AND deleted_at IS NULL`, ps.tenantID, userID, assetType, assetID).Scan(&favoriteID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("favorite %s %q: %w", assetType, externalID, ErrNotFound)
+	}
+	return favoriteID, err
+}
@@ -0,0 +1,25 @@
+package store
+
+import "errors"
+
+// Sentinel errors returned by Store implementations. They are wrapped with
+// context, so callers should match them with errors.Is.
+var (
+	// ErrNotFound means the asset, favorite, collection or share does not exist, or is not visible to the user
+	ErrNotFound = errors.New("not found")
+	// ErrAlreadyExists means the asset is already in the user's favorites, or
+	// the user already has a collection with the same name
+	ErrAlreadyExists = errors.New("already exists")
+	// ErrInvalidType means the asset type is not one the store knows about
+	ErrInvalidType = errors.New("invalid asset type")
+	// ErrValidation means the asset or collection failed validation; the wrapped error holds the details
+	ErrValidation = errors.New("validation failed")
+	// ErrForbidden means the user can see a shared item but lacks the permission for the change
+	ErrForbidden = errors.New("forbidden")
+	// ErrVersionConflict is returned when a conditional update loses to a concurrent change
+	ErrVersionConflict = errors.New("resource was modified by another request")
+	// ErrEventsExpired means events after the requested one may have been purged from the event log
+	ErrEventsExpired = errors.New("events expired")
+	// ErrReservationLost means an idempotency key's lease ran out and another request took it over
+	ErrReservationLost = errors.New("idempotency key reservation lost")
+)
@@ -0,0 +1,230 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gitvam/platform-go-challenge/internal/models"
+	"github.com/lib/pq"
+)
+
+// favoriteEventsChannel is the Postgres NOTIFY channel announcing new outbox events
+const favoriteEventsChannel = "favorite_events"
+
+// favoriteNotification is the NOTIFY payload; listeners read the events themselves
+type favoriteNotification struct {
+	TenantID string `json:"tenant_id"`
+	UserID   string `json:"user_id"`
+}
+
+// Subscription wakes its holder when one user's favorites may have changed. C
+// holds at most one pending wake-up, so several changes can arrive as one; read
+// the event log after the last event seen to get them all.
+type Subscription struct {
+	C     <-chan struct{}
+	close func()
+}
+
+// Close stops the wake-ups
+func (s *Subscription) Close() {
+	s.close()
+}
+
+// notify announces an event written in tx to every replica. Postgres delivers
+// the notification when tx commits and drops it on rollback.
+func (ps *PostgresStore) notify(tx *sql.Tx, userID string) error {
+	payload, err := json.Marshal(favoriteNotification{TenantID: ps.tenantID, UserID: userID})
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`SELECT pg_notify($1, $2)`, favoriteEventsChannel, string(payload))
+	return err
+}
+
+func (ps *PostgresStore) SubscribeFavoriteEvents(userID string) (*Subscription, error) {
+	return ps.events.subscribe(ps.tenantID, userID)
+}
+
+func (ps *PostgresStore) ListFavoriteEvents(userID string, afterID int64, limit int) ([]models.FavoriteEvent, error) {
+	// The event the client saw last must still be in the log, or events after it may have been purged
+	afterXact := "0"
+	if afterID != 0 {
+		err := ps.db.QueryRow(`SELECT xact_id FROM outbox_events WHERE tenant_id = $1 AND user_id = $2 AND id = $3`,
+			ps.tenantID, userID, afterID).Scan(&afterXact)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("event %d: %w", afterID, ErrEventsExpired)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// IDs are taken before commit, so a transaction still running may yet add an event below
+	// the ID of one already committed. Events are listed in the order of the transactions that
+	// wrote them, and only once every transaction older than theirs has ended, so a later
+	// call never finds an event that sorts before one already returned.
+	rows, err := ps.db.Query(`
+		SELECT id, event_type, user_id, created_at, data FROM outbox_events
+		WHERE tenant_id = $1 AND user_id = $2 AND (xact_id, id) > ($3::xid8, $4)
+		  AND xact_id < pg_snapshot_xmin(pg_current_snapshot())
+		ORDER BY xact_id, id LIMIT $5`, ps.tenantID, userID, afterXact, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.FavoriteEvent{}
+	for rows.Next() {
+		var e models.FavoriteEvent
+		var data []byte
+		if err := rows.Scan(&e.ID, &e.Type, &e.UserID, &e.OccurredAt, &data); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &e.Data); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+func (ps *PostgresStore) LastFavoriteEventID(userID string) (int64, error) {
+	// The latest event ListFavoriteEvents could have returned, so none listed after it is missed
+	var id int64
+	err := ps.db.QueryRow(`
+		SELECT id FROM outbox_events
+		WHERE tenant_id = $1 AND user_id = $2 AND xact_id < pg_snapshot_xmin(pg_current_snapshot())
+		ORDER BY xact_id DESC, id DESC LIMIT 1`,
+		ps.tenantID, userID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return id, err
+}
+
+func (ps *PostgresStore) PurgeFavoriteEvents(retention time.Duration) (int64, error) {
+	// Events still being delivered to a webhook are kept until the delivery ends
+	res, err := ps.db.Exec(`
+		DELETE FROM outbox_events e
+		WHERE e.created_at < $1 AND e.dispatched_at IS NOT NULL
+		  AND NOT EXISTS (SELECT 1 FROM webhook_deliveries d WHERE d.event_id = e.id AND d.status = 'pending')`,
+		time.Now().Add(-retention))
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// eventHub listens for favorite event notifications on one connection per process
+// and fans them out to the subscriptions of the notified user
+type eventHub struct {
+	connStr string
+
+	startMu sync.Mutex
+	started bool
+
+	mu   sync.Mutex
+	subs map[favoriteNotification]map[chan struct{}]struct{}
+}
+
+func newEventHub(connStr string) *eventHub {
+	return &eventHub{connStr: connStr, subs: map[favoriteNotification]map[chan struct{}]struct{}{}}
+}
+
+func (h *eventHub) subscribe(tenantID, userID string) (*Subscription, error) {
+	if err := h.start(); err != nil {
+		return nil, err
+	}
+	key := favoriteNotification{TenantID: tenantID, UserID: userID}
+	c := make(chan struct{}, 1)
+
+	h.mu.Lock()
+	if h.subs[key] == nil {
+		h.subs[key] = map[chan struct{}]struct{}{}
+	}
+	h.subs[key][c] = struct{}{}
+	h.mu.Unlock()
+
+	return &Subscription{C: c, close: func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(h.subs[key], c)
+		if len(h.subs[key]) == 0 {
+			delete(h.subs, key)
+		}
+	}}, nil
+}
+
+// start opens the listening connection the first time anyone subscribes. When that
+// fails the subscription fails too, and the next one tries again.
+func (h *eventHub) start() error {
+	h.startMu.Lock()
+	defer h.startMu.Unlock()
+	if h.started {
+		return nil
+	}
+	listener := pq.NewListener(h.connStr, time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("[ERROR] favorite events listener: %v", err)
+		}
+	})
+	if err := listener.Listen(favoriteEventsChannel); err != nil && !errors.Is(err, pq.ErrChannelAlreadyOpen) {
+		listener.Close()
+		return err
+	}
+	h.started = true
+	go h.run(listener)
+	return nil
+}
+
+func (h *eventHub) run(listener *pq.Listener) {
+	ping := time.NewTicker(90 * time.Second)
+	defer ping.Stop()
+	for {
+		select {
+		case n := <-listener.Notify:
+			// A nil notification follows a reconnect, when notifications may have been missed
+			if n == nil {
+				h.wakeAll()
+				continue
+			}
+			var key favoriteNotification
+			if err := json.Unmarshal([]byte(n.Extra), &key); err != nil {
+				log.Printf("[ERROR] favorite events notification %q: %v", n.Extra, err)
+				continue
+			}
+			h.wake(key)
+		case <-ping.C:
+			go listener.Ping()
+		}
+	}
+}
+
+func (h *eventHub) wake(key favoriteNotification) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for c := range h.subs[key] {
+		select {
+		case c <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (h *eventHub) wakeAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, subs := range h.subs {
+		for c := range subs {
+			select {
+			case c <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
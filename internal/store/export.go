@@ -0,0 +1,69 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/gitvam/platform-go-challenge/internal/models"
+)
+
+// exportBatchSize is how many favorites are fetched from the export cursor at a time
+const exportBatchSize = 200
+
+func (ps *PostgresStore) ExportFavorites(userID string, each func(models.Asset) error) error {
+	// One read-only snapshot keeps the export consistent across the per-type queries
+	tx, err := ps.db.BeginTx(context.Background(), &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	filter := favoriteFilter{userID: userID}
+	for _, spec := range models.Types() {
+		query, args := ps.favoritesOfTypeQuery(spec, filter, ListOptions{}, nil)
+		if _, err := tx.Exec(`DECLARE export_favorites NO SCROLL CURSOR FOR `+query, args...); err != nil {
+			return err
+		}
+		for {
+			batch, err := fetchFavorites(tx, spec)
+			if err != nil {
+				return err
+			}
+			if err := ps.loadMembers(tx, batch, false); err != nil {
+				return err
+			}
+			for _, a := range batch {
+				if err := each(a); err != nil {
+					return err
+				}
+			}
+			if len(batch) < exportBatchSize {
+				break
+			}
+		}
+		if _, err := tx.Exec(`CLOSE export_favorites`); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// fetchFavorites reads the next batch of favorites from the export cursor
+func fetchFavorites(tx *sql.Tx, spec models.TypeSpec) ([]models.Asset, error) {
+	rows, err := tx.Query(fmt.Sprintf(`FETCH %d FROM export_favorites`, exportBatchSize))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var batch []models.Asset
+	for rows.Next() {
+		f, err := scanFavorite(spec, nil, rows)
+		if err != nil {
+			return nil, err
+		}
+		batch = append(batch, f.asset)
+	}
+	return batch, rows.Err()
+}
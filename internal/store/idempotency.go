@@ -0,0 +1,102 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ReserveIdempotencyKey claims key for userID. It returns the reservation to complete
+// or release when the caller now owns the key and should process the request, or the
+// existing record when the key has already been used and has not expired. A reservation
+// not completed within lease is taken to belong to a request that crashed, and may be
+// claimed again.
+func (ps *PostgresStore) ReserveIdempotencyKey(userID, key, requestHash string, ttl, lease time.Duration) (string, *IdempotencyRecord, error) {
+	// Expired keys and stale reservations may be reused
+	_, err := ps.db.Exec(`
+		DELETE FROM idempotency_keys
+		WHERE tenant_id = $1 AND user_id = $2 AND key = $3
+		  AND (expires_at <= now() OR (status_code = 0 AND lease_expires_at <= now()))`, ps.tenantID, userID, key)
+	if err != nil {
+		return "", nil, err
+	}
+
+	insert := `
+		INSERT INTO idempotency_keys (tenant_id, user_id, key, request_hash, expires_at, lease_expires_at)
+		VALUES ($5, $1, $2, $3, now() + make_interval(secs => $4), now() + make_interval(secs => $6))
+		ON CONFLICT (tenant_id, user_id, key) DO NOTHING
+		RETURNING reservation`
+	var reservation string
+	err = ps.db.QueryRow(insert, userID, key, requestHash, ttl.Seconds(), ps.tenantID, lease.Seconds()).Scan(&reservation)
+	if err == nil {
+		return reservation, nil, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return "", nil, err
+	}
+
+	rec := IdempotencyRecord{UserID: userID, Key: key}
+	var headers []byte
+	query := `
+		SELECT request_hash, status_code, content_type, response_headers, response_body, expires_at
+		FROM idempotency_keys
+		WHERE tenant_id = $3 AND user_id = $1 AND key = $2`
+	err = ps.db.QueryRow(query, userID, key, ps.tenantID).Scan(&rec.RequestHash, &rec.StatusCode, &rec.ContentType, &headers, &rec.Body, &rec.ExpiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		// The holder released the key between our insert and select
+		return ps.ReserveIdempotencyKey(userID, key, requestHash, ttl, lease)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	if err := json.Unmarshal(headers, &rec.Headers); err != nil {
+		return "", nil, err
+	}
+	return "", &rec, nil
+}
+
+// CompleteIdempotencyKey stores the response for a reserved key, with the headers to
+// send again, so retries can replay it. It fails with ErrReservationLost, storing
+// nothing, when the reservation is no longer the one holding the key.
+func (ps *PostgresStore) CompleteIdempotencyKey(userID, key, reservation string, statusCode int, contentType string, headers map[string]string, body []byte) error {
+	if headers == nil {
+		headers = map[string]string{}
+	}
+	encoded, err := json.Marshal(headers)
+	if err != nil {
+		return err
+	}
+	update := `
+		UPDATE idempotency_keys
+		SET status_code = $1, content_type = $2, response_headers = $7, response_body = $3
+		WHERE tenant_id = $6 AND user_id = $4 AND key = $5 AND reservation = $8 AND status_code = 0`
+	res, err := ps.db.Exec(update, statusCode, contentType, body, userID, key, ps.tenantID, encoded, reservation)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("idempotency key %q: %w", key, ErrReservationLost)
+	}
+	return nil
+}
+
+// ReleaseIdempotencyKey drops a reservation so the request can be retried with the same key.
+// A reservation another request has since taken over is left alone.
+func (ps *PostgresStore) ReleaseIdempotencyKey(userID, key, reservation string) error {
+	_, err := ps.db.Exec(`
+		DELETE FROM idempotency_keys
+		WHERE tenant_id = $1 AND user_id = $2 AND key = $3 AND reservation = $4 AND status_code = 0`,
+		ps.tenantID, userID, key, reservation)
+	return err
+}
+
+// PurgeExpiredIdempotencyKeys deletes every record past its TTL, in every tenant, and returns how many were removed
+func (ps *PostgresStore) PurgeExpiredIdempotencyKeys() (int64, error) {
+	res, err := ps.db.Exec(`DELETE FROM idempotency_keys WHERE expires_at <= now()`)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
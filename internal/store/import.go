@@ -0,0 +1,237 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/gitvam/platform-go-challenge/internal/models"
+	"github.com/lib/pq"
+)
+
+// importedFavorite is a row of an import ready to be written
+type importedFavorite struct {
+	asset      models.Asset
+	internalID int
+	// favoriteID is the favorite the row overwrites, or 0 for a new one
+	favoriteID int
+}
+
+func (ps *PostgresStore) ImportFavorites(userID string, rows []models.ImportRow, opts ImportOptions) (*models.ImportReport, error) {
+	tx, err := ps.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	catalog, err := ps.loadImportCatalog(tx, rows)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := ps.lockImportedFavorites(tx, userID, catalog)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.ImportReport{DryRun: opts.DryRun, Rows: []models.ImportRowResult{}}
+	var writes []importedFavorite
+	var invalid, duplicates models.ValidationErrors
+	seen := map[string]int{}
+	for i, row := range rows {
+		result := models.ImportRowResult{Index: i, Type: row.Type, ExternalID: row.ExternalID}
+		field := fmt.Sprintf("rows[%d]", i)
+		entityID := models.FavoriteEntityID(row.Type, row.ExternalID)
+		asset, internalID, err := catalog.lookup(row)
+		// Repeats are caught before setting the row's fields, since rows for the same
+		// asset share its catalog copy
+		if j, repeated := seen[entityID]; err == nil && repeated {
+			err = models.ValidationErrors{{Field: "external_id", Reason: fmt.Sprintf("repeats rows[%d]", j)}}
+		}
+		if err == nil {
+			err = setImportedFields(asset, row)
+		}
+		var verrs models.ValidationErrors
+		switch {
+		case errors.As(err, &verrs):
+			result.Status, result.Errors = models.ImportInvalid, verrs
+		case errors.Is(err, ErrNotFound):
+			result.Status = models.ImportUnknownAsset
+			result.Errors = models.ValidationErrors{{Field: "external_id", Reason: fmt.Sprintf("no %s asset has this ID", row.Type)}}
+		case err != nil:
+			return nil, err
+		}
+		if result.Status != "" {
+			for _, e := range result.Errors {
+				invalid = append(invalid, models.ValidationError{Field: field + "." + e.Field, Reason: e.Reason})
+			}
+			report.Add(result)
+			continue
+		}
+
+		seen[entityID] = i
+
+		favoriteID, exists := existing[asset.GetType()][internalID]
+		switch {
+		case !exists:
+			result.Status = models.ImportAdded
+			writes = append(writes, importedFavorite{asset: asset, internalID: internalID})
+		case opts.OnConflict == models.ImportOverwrite:
+			result.Status = models.ImportOverwritten
+			writes = append(writes, importedFavorite{asset: asset, internalID: internalID, favoriteID: favoriteID})
+		default:
+			result.Status = models.ImportDuplicate
+			duplicates = append(duplicates, models.ValidationError{Field: field, Reason: fmt.Sprintf("%s is already a favorite", entityID)})
+		}
+		report.Add(result)
+	}
+
+	switch {
+	case opts.DryRun:
+		return report, nil
+	case len(invalid) > 0:
+		return report, fmt.Errorf("%w: %w", ErrValidation, invalid)
+	case opts.OnConflict == models.ImportFail && len(duplicates) > 0:
+		return report, fmt.Errorf("%w: %w", ErrAlreadyExists, duplicates)
+	}
+	for _, f := range writes {
+		if f.favoriteID == 0 {
+			err = ps.insertFavorite(tx, userID, f.internalID, f.asset)
+		} else {
+			err = ps.replaceFavorite(tx, userID, f.favoriteID, f.asset)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return report, tx.Commit()
+}
+
+// importCatalog holds the catalog assets an import's rows refer to, by type and row ID,
+// and their row IDs by external ID
+type importCatalog struct {
+	ids    map[models.AssetType]map[string]int
+	assets map[models.AssetType]map[int]models.Asset
+}
+
+// loadImportCatalog loads the catalog assets of every valid row in tx, with one query per
+// asset type for their IDs and another for the assets, then their members
+func (ps *PostgresStore) loadImportCatalog(tx *sql.Tx, rows []models.ImportRow) (importCatalog, error) {
+	externalIDs := map[models.AssetType][]string{}
+	for _, row := range rows {
+		if row.Validate() == nil {
+			t := models.AssetType(row.Type)
+			externalIDs[t] = append(externalIDs[t], row.ExternalID)
+		}
+	}
+
+	catalog := importCatalog{ids: map[models.AssetType]map[string]int{}, assets: map[models.AssetType]map[int]models.Asset{}}
+	var all []models.Asset
+	for t, externalIDs := range externalIDs {
+		ids, err := ps.resolveAssetIDs(tx, t, externalIDs)
+		if err != nil {
+			return catalog, err
+		}
+		internalIDs := make([]int64, 0, len(ids))
+		for _, id := range ids {
+			internalIDs = append(internalIDs, int64(id))
+		}
+		assets, err := ps.catalogAssets(tx, t, internalIDs)
+		if err != nil {
+			return catalog, err
+		}
+		catalog.ids[t], catalog.assets[t] = ids, assets
+		for _, a := range assets {
+			all = append(all, a)
+		}
+	}
+	return catalog, ps.loadMembers(tx, all, false)
+}
+
+// lockImportedFavorites finds and locks the user's favorites of every asset in the
+// catalog in one query, returning their IDs by asset type and catalog row ID
+func (ps *PostgresStore) lockImportedFavorites(tx *sql.Tx, userID string, catalog importCatalog) (map[models.AssetType]map[int]int, error) {
+	var types []string
+	var assetIDs []int64
+	for t, assets := range catalog.assets {
+		for id := range assets {
+			types = append(types, string(t))
+			assetIDs = append(assetIDs, int64(id))
+		}
+	}
+	existing := map[models.AssetType]map[int]int{}
+	if len(assetIDs) == 0 {
+		return existing, nil
+	}
+
+	rows, err := tx.Query(`
+		SELECT f.asset_type, f.asset_id, f.id
+		FROM favorites f
+		JOIN unnest($3::text[], $4::int[]) AS a(asset_type, asset_id) USING (asset_type, asset_id)
+		WHERE f.tenant_id = $1 AND f.user_id = $2 AND f.deleted_at IS NULL
+		FOR UPDATE OF f`,
+		ps.tenantID, userID, pq.Array(types), pq.Array(assetIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var t models.AssetType
+		var assetID, favoriteID int
+		if err := rows.Scan(&t, &assetID, &favoriteID); err != nil {
+			return nil, err
+		}
+		if existing[t] == nil {
+			existing[t] = map[int]int{}
+		}
+		existing[t][assetID] = favoriteID
+	}
+	return existing, rows.Err()
+}
+
+// lookup returns the catalog asset a row refers to and its row ID, returning
+// ValidationErrors for invalid rows and ErrNotFound for unknown assets
+func (c importCatalog) lookup(row models.ImportRow) (models.Asset, int, error) {
+	if err := row.Validate(); err != nil {
+		return nil, 0, err
+	}
+	t := models.AssetType(row.Type)
+	internalID, ok := c.ids[t][row.ExternalID]
+	if !ok {
+		return nil, 0, fmt.Errorf("%s %q: %w", row.Type, row.ExternalID, ErrNotFound)
+	}
+	asset, ok := c.assets[t][internalID]
+	if !ok {
+		return nil, 0, fmt.Errorf("%s %q: %w", row.Type, row.ExternalID, ErrNotFound)
+	}
+	return asset, internalID, nil
+}
+
+// setImportedFields gives a catalog asset a row's description and tags, returning
+// ValidationErrors when they are invalid
+func setImportedFields(asset models.Asset, row models.ImportRow) error {
+	asset.SetDescription(row.Description)
+	asset.SetTags(row.Tags)
+	if err := asset.Validate(); err != nil {
+		return err
+	}
+	asset.SetTags(models.NormalizeTags(row.Tags))
+	return nil
+}
+
+// replaceFavorite overwrites a favorite's description and tags with an imported asset's in tx
+func (ps *PostgresStore) replaceFavorite(tx *sql.Tx, userID string, favoriteID int, asset models.Asset) error {
+	before, err := loadFavoriteState(tx, favoriteID)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`UPDATE favorites SET description = $1, tags = $2, version = version + 1, updated_at = now() WHERE id = $3`,
+		asset.GetDescription(), pq.Array(asset.GetTags()), favoriteID)
+	if err != nil {
+		return err
+	}
+	entityID := models.FavoriteEntityID(string(asset.GetType()), asset.GetID())
+	if err := ps.auditFavorite(tx, userID, favoriteID, entityID, models.ActionFavoriteReplaced, before); err != nil {
+		return err
+	}
+	return ps.publishFavorite(tx, models.EventFavoriteUpdated, favoriteID)
+}
@@ -0,0 +1,178 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gitvam/platform-go-challenge/internal/models"
+	"github.com/lib/pq"
+)
+
+// checkMembers reports every member of a composite asset missing from the catalog, and
+// rejects members that differ from those the catalog keeps for the asset with row ID
+// internalID, since favorites are always returned with the catalog's members. Members
+// are resolved with one query per member type. Like the fields of any other asset, the
+// composite's own fields (a dashboard's title and layout) are not compared: they are
+// returned from the catalog too, so what the request says about them is ignored.
+func (ps *PostgresStore) checkMembers(c models.Composite, internalID int) error {
+	refs := c.MemberRefs()
+	externalIDs := map[models.AssetType][]string{}
+	for _, m := range refs {
+		externalIDs[m.Type] = append(externalIDs[m.Type], m.ExternalID)
+	}
+	ids := map[models.AssetType]map[string]int{}
+	for t, externalIDs := range externalIDs {
+		resolved, err := ps.resolveAssetIDs(ps.db, t, externalIDs)
+		if err != nil && !errors.Is(err, ErrInvalidType) {
+			return err
+		}
+		ids[t] = resolved
+	}
+
+	var missing models.ValidationErrors
+	requested := make([]memberRow, 0, len(refs))
+	for i, m := range refs {
+		id, ok := ids[m.Type][m.ExternalID]
+		if !ok {
+			missing = append(missing, models.ValidationError{Field: fmt.Sprintf("members[%d]", i), Reason: "does not exist"})
+			continue
+		}
+		requested = append(requested, memberRow{parentID: internalID, memberType: m.Type, memberID: id})
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %w", ErrValidation, missing)
+	}
+
+	stored, err := ps.memberRows(ps.db, c.GetType(), []int64{int64(internalID)})
+	if err != nil {
+		return err
+	}
+	if !slices.Equal(requested, stored) {
+		return fmt.Errorf("%w: %w", ErrValidation, models.ValidationErrors{{Field: "members", Reason: fmt.Sprintf("must match the members of %s %q in the catalog, in order", c.GetType(), c.GetID())}})
+	}
+	return nil
+}
+
+// memberRow is one stored member of a composite asset
+type memberRow struct {
+	parentID   int
+	memberType models.AssetType
+	memberID   int
+}
+
+// querier runs queries on the database or inside a transaction
+type querier interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// loadMembers fills in the ordered members of every composite asset in assets,
+// reading through q. With expand set, each member carries its full catalog asset.
+func (ps *PostgresStore) loadMembers(q querier, assets []models.Asset, expand bool) error {
+	parents := map[models.AssetType]map[int]models.Composite{}
+	for _, a := range assets {
+		if c, ok := a.(models.Composite); ok {
+			if parents[c.GetType()] == nil {
+				parents[c.GetType()] = map[int]models.Composite{}
+			}
+			parents[c.GetType()][c.CatalogID()] = c
+		}
+	}
+
+	for parentType, byID := range parents {
+		ids := make([]int64, 0, len(byID))
+		for id := range byID {
+			ids = append(ids, int64(id))
+		}
+		rows, err := ps.memberRows(q, parentType, ids)
+		if err != nil {
+			return err
+		}
+
+		memberIDs := map[models.AssetType][]int64{}
+		for _, r := range rows {
+			memberIDs[r.memberType] = append(memberIDs[r.memberType], int64(r.memberID))
+		}
+		members := map[models.AssetType]map[int]models.Asset{}
+		for t, ids := range memberIDs {
+			if members[t], err = ps.catalogAssets(q, t, ids); err != nil {
+				return err
+			}
+		}
+
+		refs := map[int][]models.AssetRef{}
+		for _, r := range rows {
+			m, ok := members[r.memberType][r.memberID]
+			if !ok {
+				continue
+			}
+			ref := models.AssetRef{Type: r.memberType, ExternalID: m.GetID()}
+			if expand {
+				ref.Asset = m
+			}
+			refs[r.parentID] = append(refs[r.parentID], ref)
+		}
+		for id, c := range byID {
+			c.SetMembers(refs[id])
+		}
+	}
+	return nil
+}
+
+func (ps *PostgresStore) memberRows(q querier, parentType models.AssetType, parentIDs []int64) ([]memberRow, error) {
+	query := `
+		SELECT parent_id, member_type, member_id
+		FROM asset_members
+		WHERE parent_type = $1 AND parent_id = ANY($2)
+		ORDER BY parent_id, position`
+	rows, err := q.Query(query, string(parentType), pq.Array(parentIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []memberRow
+	for rows.Next() {
+		var r memberRow
+		if err := rows.Scan(&r.parentID, &r.memberType, &r.memberID); err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// catalogAssets loads catalog assets of one type by row ID through q, with their catalog descriptions
+func (ps *PostgresStore) catalogAssets(q querier, t models.AssetType, ids []int64) (map[int]models.Asset, error) {
+	spec, ok := models.Lookup(t)
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrInvalidType, t)
+	}
+	cols := make([]string, len(spec.Columns))
+	for i, c := range spec.Columns {
+		cols[i] = "a." + pq.QuoteIdentifier(c)
+	}
+	query := fmt.Sprintf(`SELECT a.id, %s, COALESCE(a.description, '') FROM %s a WHERE a.id = ANY($1) AND (a.tenant_id IS NULL OR a.tenant_id = $2)`,
+		strings.Join(cols, ", "), pq.QuoteIdentifier(spec.Table))
+	rows, err := q.Query(query, pq.Array(ids), ps.tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := map[int]models.Asset{}
+	for rows.Next() {
+		a := spec.New()
+		var id int
+		var desc string
+		if err := rows.Scan(append(append([]any{&id}, spec.ScanFields(a)...), &desc)...); err != nil {
+			return nil, err
+		}
+		a.SetDescription(desc)
+		out[id] = a
+	}
+	return out, rows.Err()
+}
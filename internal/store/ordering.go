@@ -0,0 +1,211 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+
+	"github.com/gitvam/platform-go-challenge/internal/models"
+	"github.com/lib/pq"
+)
+
+// orderSlot is one favorite's place in a user's manual order
+type orderSlot struct {
+	id        int
+	assetType string
+	assetID   int
+	pinned    bool
+	position  float64
+	version   int
+}
+
+// lockOrder locks the user's favorites and returns them in manual order, so
+// concurrent moves never compute positions from a stale order
+func lockOrder(tx *sql.Tx, tenantID, userID string) ([]orderSlot, error) {
+	rows, err := tx.Query(`
+		SELECT id, asset_type, asset_id, pinned, position, version
+		FROM favorites WHERE tenant_id = $1 AND user_id = $2 AND deleted_at IS NULL
+		ORDER BY pinned DESC, position, id
+		FOR UPDATE`, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var order []orderSlot
+	for rows.Next() {
+		var s orderSlot
+		if err := rows.Scan(&s.id, &s.assetType, &s.assetID, &s.pinned, &s.position, &s.version); err != nil {
+			return nil, err
+		}
+		order = append(order, s)
+	}
+	return order, rows.Err()
+}
+
+// indexOf returns the index of a favorite in order, or -1
+func indexOf(order []orderSlot, assetType string, assetID int) int {
+	return slices.IndexFunc(order, func(s orderSlot) bool {
+		return s.assetType == assetType && s.assetID == assetID
+	})
+}
+
+func (ps *PostgresStore) SetFavoritePinned(userID, assetType, externalID string, pinned bool, expectedVersion int) (int, error) {
+	assetID, err := ps.resolveAssetID(assetType, externalID)
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := ps.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	order, err := lockOrder(tx, ps.tenantID, userID)
+	if err != nil {
+		return 0, err
+	}
+	i := indexOf(order, assetType, assetID)
+	if i < 0 {
+		return 0, fmt.Errorf("favorite %s %q: %w", assetType, externalID, ErrNotFound)
+	}
+	slot := order[i]
+	if expectedVersion != 0 && slot.version != expectedVersion {
+		return 0, ErrVersionConflict
+	}
+	if slot.pinned == pinned {
+		return slot.version, tx.Commit()
+	}
+
+	// Go to the top of the new group: pinning brings the favorite to the very top,
+	// unpinning leaves it just below the favorites that are still pinned
+	position := slot.position
+	if top := slices.IndexFunc(order, func(s orderSlot) bool { return s.pinned == pinned }); top >= 0 {
+		position = order[top].position - 1
+	}
+	before, err := loadFavoriteState(tx, slot.id)
+	if err != nil {
+		return 0, err
+	}
+	var version int
+	err = tx.QueryRow(`UPDATE favorites SET pinned = $1, position = $2, version = version + 1, updated_at = now() WHERE id = $3 RETURNING version`,
+		pinned, position, slot.id).Scan(&version)
+	if err != nil {
+		return 0, err
+	}
+	action := models.ActionFavoriteUnpinned
+	if pinned {
+		action = models.ActionFavoritePinned
+	}
+	if err := ps.auditFavorite(tx, userID, slot.id, models.FavoriteEntityID(assetType, externalID), action, before); err != nil {
+		return 0, err
+	}
+	if err := ps.publishFavorite(tx, models.EventFavoriteUpdated, slot.id); err != nil {
+		return 0, err
+	}
+	return version, tx.Commit()
+}
+
+func (ps *PostgresStore) MoveFavorite(userID, assetType, externalID string, move models.FavoriteMove, expectedVersion int) (bool, int, error) {
+	if err := move.Validate(); err != nil {
+		return false, 0, fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+	ref, after := move.Anchor()
+	assetID, err := ps.resolveAssetID(assetType, externalID)
+	if err != nil {
+		return false, 0, err
+	}
+	anchorID, err := ps.resolveAssetID(string(ref.Type), ref.ExternalID)
+	if err != nil {
+		return false, 0, err
+	}
+	if string(ref.Type) == assetType && anchorID == assetID {
+		return false, 0, fmt.Errorf("%w: cannot move a favorite next to itself", ErrValidation)
+	}
+
+	tx, err := ps.db.Begin()
+	if err != nil {
+		return false, 0, err
+	}
+	defer tx.Rollback()
+
+	order, err := lockOrder(tx, ps.tenantID, userID)
+	if err != nil {
+		return false, 0, err
+	}
+	i := indexOf(order, assetType, assetID)
+	if i < 0 {
+		return false, 0, fmt.Errorf("favorite %s %q: %w", assetType, externalID, ErrNotFound)
+	}
+	slot := order[i]
+	if expectedVersion != 0 && slot.version != expectedVersion {
+		return false, 0, ErrVersionConflict
+	}
+	before, err := loadFavoriteState(tx, slot.id)
+	if err != nil {
+		return false, 0, err
+	}
+	order = slices.Delete(order, i, i+1)
+	to := indexOf(order, string(ref.Type), anchorID)
+	if to < 0 {
+		return false, 0, fmt.Errorf("favorite %s %q: %w", ref.Type, ref.ExternalID, ErrNotFound)
+	}
+	slot.pinned = order[to].pinned
+	if after {
+		to++
+	}
+	order = slices.Insert(order, to, slot)
+
+	// Take the midpoint between the new neighbours in the same group. When
+	// repeated moves exhaust float precision, renumber the user's favorites.
+	var ok bool
+	if slot.position, ok = placeBetween(order, to); !ok {
+		ids := make([]int64, len(order))
+		for j, s := range order {
+			ids[j] = int64(s.id)
+		}
+		renumber := `
+			UPDATE favorites f SET position = o.ord
+			FROM unnest($1::int[]) WITH ORDINALITY AS o(id, ord)
+			WHERE f.id = o.id AND f.position <> o.ord`
+		if _, err := tx.Exec(renumber, pq.Array(ids)); err != nil {
+			return false, 0, err
+		}
+		slot.position = float64(to + 1)
+	}
+
+	var version int
+	err = tx.QueryRow(`UPDATE favorites SET pinned = $1, position = $2, version = version + 1, updated_at = now() WHERE id = $3 RETURNING version`,
+		slot.pinned, slot.position, slot.id).Scan(&version)
+	if err != nil {
+		return false, 0, err
+	}
+	if err := ps.auditFavorite(tx, userID, slot.id, models.FavoriteEntityID(assetType, externalID), models.ActionFavoriteMoved, before); err != nil {
+		return false, 0, err
+	}
+	if err := ps.publishFavorite(tx, models.EventFavoriteUpdated, slot.id); err != nil {
+		return false, 0, err
+	}
+	return slot.pinned, version, tx.Commit()
+}
+
+// placeBetween returns a position for order[i] between its neighbours in the same
+// pinned group. It reports false when no float fits strictly between them.
+func placeBetween(order []orderSlot, i int) (float64, bool) {
+	pinned := order[i].pinned
+	hasPrev := i > 0 && order[i-1].pinned == pinned
+	hasNext := i+1 < len(order) && order[i+1].pinned == pinned
+	switch {
+	case hasPrev && hasNext:
+		prev, next := order[i-1].position, order[i+1].position
+		mid := prev + (next-prev)/2
+		return mid, prev < mid && mid < next
+	case hasPrev:
+		return order[i-1].position + 1, true
+	case hasNext:
+		return order[i+1].position - 1, true
+	default:
+		return order[i].position, true
+	}
+}
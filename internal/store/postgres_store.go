@@ -1,17 +1,31 @@
 package store
 
 import (
+	"cmp"
 	"database/sql"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/gitvam/platform-go-challenge/internal/models"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
+// DefaultTenant holds the data of tokens without an org claim
+const DefaultTenant = "default"
+
+// PostgresStore keeps every tenant's data in one database. Each store value is
+// bound to one tenant and every query it runs is filtered to that tenant; use
+// ForTenant to get a store for another tenant. Every change to favorites,
+// collections and shares is recorded in the audit trail in the same transaction.
 type PostgresStore struct {
-	db *sql.DB
+	db        *sql.DB
+	tenantID  string
+	actorID   string
+	requestID string
+	events    *eventHub
 }
 
 func (ps *PostgresStore) DB() *sql.DB {
@@ -26,180 +40,546 @@ func NewPostgresStore(connStr string) (*PostgresStore, error) {
 	if err := db.Ping(); err != nil {
 		return nil, err
 	}
-	return &PostgresStore{db: db}, nil
+	ps := &PostgresStore{db: db, tenantID: DefaultTenant, events: newEventHub(connStr)}
+	if err := ps.registerAssetTypes(); err != nil {
+		return nil, err
+	}
+	return ps, nil
 }
 
-func (ps *PostgresStore) ListFavorites(userID string, limit, offset int) ([]models.Asset, error){
-	var results []models.Asset
+func (ps *PostgresStore) ForTenant(tenantID string) Store {
+	scoped := *ps
+	scoped.tenantID = tenantID
+	return &scoped
+}
 
-	// Charts
-	chartQuery := `
-		SELECT c.id, c.external_id, c.title, c.x_axis_title, c.y_axis_title, c.data, c.description
-		FROM favorites f
-		JOIN charts c ON f.asset_type = 'chart' AND f.asset_id = c.id
-		WHERE f.user_id = $1
-		LIMIT $2 OFFSET $3
-	`
-	chartRows, err := ps.db.Query(chartQuery, userID, limit, offset)
+func (ps *PostgresStore) AuditAs(actorID, requestID string) Store {
+	scoped := *ps
+	scoped.actorID, scoped.requestID = actorID, requestID
+	return &scoped
+}
+
+func (ps *PostgresStore) ListFavorites(userID string, opts ListOptions) ([]models.Asset, error) {
+	return ps.listFavorites(favoriteFilter{userID: userID}, opts)
+}
+
+func (ps *PostgresStore) ListKeyedFavorites(userID string, opts ListOptions) ([]KeyedFavorite, error) {
+	opts.ManualOrder = true
+	favorites, err := ps.listOrderedFavorites(favoriteFilter{userID: userID}, opts)
 	if err != nil {
 		return nil, err
 	}
-	defer chartRows.Close()
-	for chartRows.Next() {
-		var c models.Chart
-		if err := chartRows.Scan(&c.ID, &c.ExternalID, &c.Title, &c.XAxisTitle, &c.YAxisTitle, &c.Data, &c.Description); err != nil {
-			return nil, err
-		}
-		c.Type = "chart"
-		results = append(results, &c)
+	keyed := []KeyedFavorite{}
+	for _, f := range favorites {
+		keyed = append(keyed, KeyedFavorite{Asset: f.asset, Key: FavoriteKey{Pinned: f.asset.GetPinned(), Position: f.position, ID: f.id}})
 	}
+	return keyed, nil
+}
 
-	// Insights
-	insightQuery := `
-		SELECT i.id, i.external_id, i.text, i.description
-		FROM favorites f
-		JOIN insights i ON f.asset_type = 'insight' AND f.asset_id = i.id
-		WHERE f.user_id = $1
-		LIMIT $2 OFFSET $3
-	`
-	insightRows, err := ps.db.Query(insightQuery, userID, limit, offset)
+func (ps *PostgresStore) GetFavorite(userID, assetType, externalID string, expandMembers bool) (models.Asset, error) {
+	favoriteID, err := ps.favoriteID(userID, assetType, externalID)
+	if err != nil {
+		return nil, err
+	}
+	assets, err := ps.listFavorites(favoriteFilter{userID: userID, favoriteID: favoriteID}, ListOptions{Limit: 1, ExpandMembers: expandMembers})
 	if err != nil {
 		return nil, err
 	}
-	defer insightRows.Close()
-	for insightRows.Next() {
-		var i models.Insight
-		if err := insightRows.Scan(&i.ID, &i.ExternalID, &i.Text, &i.Description); err != nil {
+	if len(assets) == 0 {
+		return nil, fmt.Errorf("favorite %s %q: %w", assetType, externalID, ErrNotFound)
+	}
+	return assets[0], nil
+}
+
+func (ps *PostgresStore) ListTrash(userID string, opts ListOptions) ([]models.TrashedFavorite, error) {
+	favorites, err := ps.listOrderedFavorites(favoriteFilter{userID: userID, trashed: true}, opts)
+	if err != nil {
+		return nil, err
+	}
+	trash := []models.TrashedFavorite{}
+	for _, f := range favorites {
+		trash = append(trash, models.TrashedFavorite{Favorite: f.asset, DeletedAt: *f.deletedAt})
+	}
+	return trash, nil
+}
+
+// favoriteFilter selects whose favorites are listed and narrows them to a collection or a
+// single favorite. Trashed favorites are only listed, and then exclusively, when trashed is set.
+type favoriteFilter struct {
+	userID       string
+	collectionID int
+	favoriteID   int
+	trashed      bool
+}
+
+// listFavorites lists a user's favorites of every type that match filter
+func (ps *PostgresStore) listFavorites(filter favoriteFilter, opts ListOptions) ([]models.Asset, error) {
+	favorites, err := ps.listOrderedFavorites(filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	var results []models.Asset
+	for _, f := range favorites {
+		results = append(results, f.asset)
+	}
+	return results, nil
+}
+
+func (ps *PostgresStore) listOrderedFavorites(filter favoriteFilter, opts ListOptions) ([]orderedFavorite, error) {
+	// In manual order, and in the trash, the page spans every type, so take each
+	// type's first offset+limit favorites and page the merged list. Listing after a
+	// key already skips to the page in every type.
+	merged := opts.ManualOrder || filter.trashed
+	// Callers validate paging, but a negative limit or offset must not reach SQL or the slice below
+	opts.Limit, opts.Offset = max(opts.Limit, 0), max(opts.Offset, 0)
+	limit, offset := opts.Limit, opts.Offset
+	if opts.After != nil && opts.ManualOrder && !filter.trashed {
+		offset = 0
+	}
+	if merged {
+		opts.Limit, opts.Offset = offset+limit, 0
+	}
+	var favorites []orderedFavorite
+	for _, spec := range models.Types() {
+		if len(opts.Types) > 0 && !slices.Contains(opts.Types, spec.Type) {
+			continue
+		}
+		ofType, err := ps.listFavoritesOfType(spec, filter, opts)
+		if err != nil {
 			return nil, err
 		}
-		i.Type = "insight"
-		results = append(results, &i)
+		favorites = append(favorites, ofType...)
+	}
+	if merged {
+		if filter.trashed {
+			slices.SortFunc(favorites, compareDeletedAt)
+		} else {
+			slices.SortFunc(favorites, compareManualOrder)
+		}
+		favorites = favorites[min(offset, len(favorites)):min(offset+limit, len(favorites))]
 	}
 
-	// Audiences
-	audienceQuery := `
-		SELECT a.id, a.external_id, a.gender, a.birth_country, a.age_groups, a.hours_on_social, a.purchases_last_month, a.description
-		FROM favorites f
-		JOIN audiences a ON f.asset_type = 'audience' AND f.asset_id = a.id
-		WHERE f.user_id = $1
-		LIMIT $2 OFFSET $3
-	`
-	audienceRows, err := ps.db.Query(audienceQuery, userID, limit, offset)
+	assets := make([]models.Asset, len(favorites))
+	for i, f := range favorites {
+		assets[i] = f.asset
+	}
+	if len(opts.Fields) == 0 || slices.Contains(opts.Fields, "members") {
+		if err := ps.loadMembers(ps.db, assets, opts.ExpandMembers); err != nil {
+			return nil, err
+		}
+	}
+	return favorites, nil
+}
+
+// orderedFavorite is a listed favorite with the keys it is sorted by in manual order and in the trash
+type orderedFavorite struct {
+	asset     models.Asset
+	position  float64
+	id        int
+	deletedAt *time.Time
+}
+
+// compareManualOrder puts pinned favorites first, then orders by position.
+// It matches the ORDER BY used for ListOptions.ManualOrder.
+func compareManualOrder(a, b orderedFavorite) int {
+	if a.asset.GetPinned() != b.asset.GetPinned() {
+		if a.asset.GetPinned() {
+			return -1
+		}
+		return 1
+	}
+	if c := cmp.Compare(a.position, b.position); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.id, b.id)
+}
+
+// compareDeletedAt puts the most recently trashed favorites first, matching the ORDER BY used for the trash
+func compareDeletedAt(a, b orderedFavorite) int {
+	if c := b.deletedAt.Compare(*a.deletedAt); c != 0 {
+		return c
+	}
+	return cmp.Compare(b.id, a.id)
+}
+
+// listFavoritesOfType selects a user's favorites from one catalog table using its registered mapping
+func (ps *PostgresStore) listFavoritesOfType(spec models.TypeSpec, filter favoriteFilter, opts ListOptions) ([]orderedFavorite, error) {
+	query, args := ps.favoritesOfTypeQuery(spec, filter, opts, opts.Limit)
+	rows, err := ps.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
-	defer audienceRows.Close()
-	for audienceRows.Next() {
-		var a models.Audience
-		if err := audienceRows.Scan(&a.ID, &a.ExternalID, &a.Gender, &a.BirthCountry, &a.AgeGroups, &a.HoursOnSocial, &a.PurchasesLastMonth, &a.Description); err != nil {
+	defer rows.Close()
+
+	var results []orderedFavorite
+	for rows.Next() {
+		f, err := scanFavorite(spec, opts.Fields, rows)
+		if err != nil {
 			return nil, err
 		}
-		a.Type = "audience"
-		results = append(results, &a)
+		results = append(results, f)
 	}
+	return results, rows.Err()
+}
 
-	return results, nil
+// favoritesOfTypeQuery builds the query listing a user's favorites of one type and its
+// arguments. A nil limit selects every favorite after opts.Offset, and in manual order
+// opts.After keeps only the favorites sorting after that key.
+func (ps *PostgresStore) favoritesOfTypeQuery(spec models.TypeSpec, filter favoriteFilter, opts ListOptions, limit any) (string, []any) {
+	var cols []string
+	for _, i := range spec.SelectColumns(opts.Fields) {
+		cols = append(cols, "a."+pq.QuoteIdentifier(spec.Columns[i]))
+	}
+	order := "f.id"
+	switch {
+	case filter.trashed:
+		order = "f.deleted_at DESC, f.id DESC"
+	case opts.ManualOrder:
+		order = "f.pinned DESC, f.position, f.id"
+	}
+	query := fmt.Sprintf(`
+		SELECT %s, f.description, f.tags, f.pinned, f.version, f.position, f.id, f.deleted_at
+		FROM favorites f
+		JOIN %s a ON f.asset_type = $1 AND f.asset_id = a.id AND (a.tenant_id IS NULL OR a.tenant_id = $9)
+		WHERE f.tenant_id = $9 AND f.user_id = $2 AND (f.deleted_at IS NOT NULL) = $10
+		  AND ($5 = 0 OR EXISTS (
+			SELECT 1 FROM collection_favorites cf WHERE cf.favorite_id = f.id AND cf.collection_id = $5))
+		  AND (COALESCE(cardinality($6::text[]), 0) = 0 OR CASE WHEN $7 THEN f.tags @> $6 ELSE f.tags && $6 END)
+		  AND ($8 = 0 OR f.id = $8)
+		  AND (NOT $11 OR f.pinned < $12::boolean
+			OR (f.pinned = $12::boolean AND (f.position, f.id) > ($13::double precision, $14::integer)))
+		ORDER BY %s
+		LIMIT $3 OFFSET $4
+	`, strings.Join(cols, ", "), pq.QuoteIdentifier(spec.Table), order)
+	var after FavoriteKey
+	keyed := opts.After != nil && opts.ManualOrder && !filter.trashed
+	if keyed {
+		after = *opts.After
+	}
+	return query, []any{string(spec.Type), filter.userID, limit, opts.Offset, filter.collectionID,
+		pq.Array(opts.Tags), opts.MatchAllTags, filter.favoriteID, ps.tenantID, filter.trashed,
+		keyed, after.Pinned, after.Position, after.ID}
+}
+
+// scanFavorite reads a row selected by favoritesOfTypeQuery with the same fields
+func scanFavorite(spec models.TypeSpec, fields []string, row rowScanner) (orderedFavorite, error) {
+	f := orderedFavorite{asset: spec.New()}
+	all := spec.ScanFields(f.asset)
+	var dest []any
+	for _, i := range spec.SelectColumns(fields) {
+		dest = append(dest, all[i])
+	}
+	var desc string
+	var tags pq.StringArray
+	var pinned bool
+	var version int
+	if err := row.Scan(append(dest, &desc, &tags, &pinned, &version, &f.position, &f.id, &f.deletedAt)...); err != nil {
+		return f, err
+	}
+	f.asset.SetDescription(desc)
+	f.asset.SetTags(tags)
+	f.asset.SetPinned(pinned)
+	f.asset.SetVersion(version)
+	return f, nil
 }
 
 func (ps *PostgresStore) AddFavorite(userID string, asset models.Asset) error {
 	if err := asset.Validate(); err != nil {
-		return err
+		return fmt.Errorf("%w: %w", ErrValidation, err)
 	}
 
 	assetType := string(asset.GetType())
 	externalID := asset.GetID()
-	var internalID int
-	var query string
-
-	switch assetType {
-	case "chart":
-		query = `SELECT id FROM charts WHERE external_id = $1`
-	case "insight":
-		query = `SELECT id FROM insights WHERE external_id = $1`
-	case "audience":
-		query = `SELECT id FROM audiences WHERE external_id = $1`
-	default:
-		return errors.New("unknown asset type")
+	internalID, err := ps.resolveAssetID(assetType, externalID)
+	if err != nil {
+		return err
 	}
+	if c, ok := asset.(models.Composite); ok {
+		if err := ps.checkMembers(c, internalID); err != nil {
+			return err
+		}
+	}
+
+	asset.SetTags(models.NormalizeTags(asset.GetTags()))
 
-	err := ps.db.QueryRow(query, externalID).Scan(&internalID)
+	tx, err := ps.db.Begin()
 	if err != nil {
-		return fmt.Errorf("could not resolve asset ID: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+	if err := ps.insertFavorite(tx, userID, internalID, asset); err != nil {
+		return err
 	}
+	return tx.Commit()
+}
 
-	insert := `
-		INSERT INTO favorites (user_id, asset_id, asset_type, description)
-		VALUES ($1, $2, $3, $4)
-	`
-	_, err = ps.db.Exec(insert, userID, internalID, assetType, asset.GetDescription())
+// insertFavorite adds a validated asset with normalized tags to the user's favorites in tx
+func (ps *PostgresStore) insertFavorite(tx *sql.Tx, userID string, internalID int, asset models.Asset) error {
+	assetType := string(asset.GetType())
+	externalID := asset.GetID()
 
+	// Adding a trashed favorite again starts afresh instead of restoring it
+	entityID := models.FavoriteEntityID(assetType, externalID)
+	var trashed favoriteState
+	var trashedTags pq.StringArray
+	err := tx.QueryRow(`
+		DELETE FROM favorites WHERE tenant_id = $1 AND user_id = $2 AND asset_type = $3 AND asset_id = $4 AND deleted_at IS NOT NULL
+		RETURNING description, tags, pinned, position`,
+		ps.tenantID, userID, assetType, internalID).Scan(&trashed.Description, &trashedTags, &trashed.Pinned, &trashed.Position)
+	switch {
+	case err == nil:
+		trashed.Tags = trashedTags
+		err = ps.audit(tx, userID, auditEvent{userID: userID, action: models.ActionFavoritePurged,
+			entityType: models.AuditEntityFavorite, entityID: entityID, before: trashed})
+		if err != nil {
+			return err
+		}
+	case !errors.Is(err, sql.ErrNoRows):
+		return err
+	}
+	// New favorites go to the end of the manual order
+	insert := `
+		INSERT INTO favorites (tenant_id, user_id, asset_id, asset_type, description, tags, pinned, position)
+		SELECT $7, $1, $2, $3, $4, $5, $6, COALESCE(MAX(position) + 1, 1) FROM favorites WHERE tenant_id = $7 AND user_id = $1
+		RETURNING id`
+	var favoriteID int
+	err = tx.QueryRow(insert, userID, internalID, assetType, asset.GetDescription(), pq.Array(asset.GetTags()), asset.GetPinned(), ps.tenantID).
+		Scan(&favoriteID)
 	if err != nil {
-		if strings.Contains(err.Error(), "duplicate key") {
-			return fmt.Errorf("asset already in favorites")
+		if isUniqueViolation(err) {
+			return fmt.Errorf("%s %q is %w in favorites", assetType, externalID, ErrAlreadyExists)
 		}
 		return err
 	}
-	return nil
+	if err := ps.auditFavorite(tx, userID, favoriteID, entityID, models.ActionFavoriteAdded, nil); err != nil {
+		return err
+	}
+	return ps.publishFavorite(tx, models.EventFavoriteAdded, favoriteID)
 }
 
 func (ps *PostgresStore) RemoveFavorite(userID, assetType, externalID string) error {
-	var assetID int
-	var query string
-
-	switch assetType {
-	case "chart":
-		query = `SELECT id FROM charts WHERE external_id = $1`
-	case "insight":
-		query = `SELECT id FROM insights WHERE external_id = $1`
-	case "audience":
-		query = `SELECT id FROM audiences WHERE external_id = $1`
-	default:
-		return errors.New("unknown asset type")
+	assetID, err := ps.resolveAssetID(assetType, externalID)
+	if err != nil {
+		return err
 	}
 
-	if err := ps.db.QueryRow(query, externalID).Scan(&assetID); err != nil {
-		return fmt.Errorf("could not resolve asset ID: %v", err)
+	tx, err := ps.db.Begin()
+	if err != nil {
+		return err
 	}
+	defer tx.Rollback()
 
-	res, err := ps.db.Exec(`DELETE FROM favorites WHERE user_id = $1 AND asset_type = $2 AND asset_id = $3`, userID, assetType, assetID)
+	// Removed favorites go to the trash, keeping their description, tags and collections until purged
+	var favoriteID int
+	err = tx.QueryRow(`
+		UPDATE favorites SET deleted_at = now(), version = version + 1, updated_at = now()
+		WHERE tenant_id = $1 AND user_id = $2 AND asset_type = $3 AND asset_id = $4 AND deleted_at IS NULL
+		RETURNING id`,
+		ps.tenantID, userID, assetType, assetID).Scan(&favoriteID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("favorite %s %q: %w", assetType, externalID, ErrNotFound)
+	}
+	if err != nil {
+		return err
+	}
+	before, err := loadFavoriteState(tx, favoriteID)
 	if err != nil {
 		return err
 	}
-	if n, _ := res.RowsAffected(); n == 0 {
-		return errors.New("asset not found")
+	err = ps.audit(tx, userID, auditEvent{userID: userID, action: models.ActionFavoriteRemoved,
+		entityType: models.AuditEntityFavorite, entityID: models.FavoriteEntityID(assetType, externalID), before: before})
+	if err != nil {
+		return err
 	}
-	return nil
+	if err := ps.publishFavorite(tx, models.EventFavoriteRemoved, favoriteID); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
-func (ps *PostgresStore) EditFavoriteDescription(userID, assetType, externalID, desc string) error {
-	var assetID int
-	var query string
+func (ps *PostgresStore) RestoreFavorite(userID, assetType, externalID string) (int, error) {
+	assetID, err := ps.resolveAssetID(assetType, externalID)
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := ps.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
 
-	switch assetType {
-	case "chart":
-		query = `SELECT id FROM charts WHERE external_id = $1`
-	case "insight":
-		query = `SELECT id FROM insights WHERE external_id = $1`
-	case "audience":
-		query = `SELECT id FROM audiences WHERE external_id = $1`
-	default:
-		return errors.New("unknown asset type")
+	var favoriteID, version int
+	err = tx.QueryRow(`
+		UPDATE favorites SET deleted_at = NULL, version = version + 1, updated_at = now()
+		WHERE tenant_id = $1 AND user_id = $2 AND asset_type = $3 AND asset_id = $4 AND deleted_at IS NOT NULL
+		RETURNING id, version`, ps.tenantID, userID, assetType, assetID).Scan(&favoriteID, &version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("trashed favorite %s %q: %w", assetType, externalID, ErrNotFound)
+	}
+	if err != nil {
+		return 0, err
+	}
+	err = ps.auditFavorite(tx, userID, favoriteID, models.FavoriteEntityID(assetType, externalID), models.ActionFavoriteRestored, nil)
+	if err != nil {
+		return 0, err
+	}
+	if err := ps.publishFavorite(tx, models.EventFavoriteRestored, favoriteID); err != nil {
+		return 0, err
 	}
+	return version, tx.Commit()
+}
 
-	if err := ps.db.QueryRow(query, externalID).Scan(&assetID); err != nil {
-		return fmt.Errorf("could not resolve asset ID: %v", err)
+func (ps *PostgresStore) PurgeTrash(retention time.Duration) (int64, error) {
+	cutoff := time.Now().Add(-retention)
+	var purged int64
+	for _, spec := range models.Types() {
+		n, err := ps.purgeTrashOfType(spec, cutoff)
+		if err != nil {
+			return purged, err
+		}
+		purged += n
 	}
+	return purged, nil
+}
 
-	update := `
-		UPDATE favorites
-		SET description = $1
-		WHERE user_id = $2 AND asset_type = $3 AND asset_id = $4`
-	res, err := ps.db.Exec(update, desc, userID, assetType, assetID)
+// purgeTrashOfType permanently deletes one type's favorites trashed before the cutoff,
+// recording a maintenance event without an actor for each
+func (ps *PostgresStore) purgeTrashOfType(spec models.TypeSpec, cutoff time.Time) (int64, error) {
+	query := fmt.Sprintf(`
+		WITH purged AS (
+			DELETE FROM favorites f USING %s a
+			WHERE f.asset_type = $1 AND f.asset_id = a.id AND f.deleted_at < $2
+			RETURNING f.tenant_id, f.user_id, a.external_id, f.description, f.tags, f.pinned, f.position
+		)
+		INSERT INTO audit_events (tenant_id, user_id, action, entity_type, entity_id, before)
+		SELECT tenant_id, user_id, $3, $4, $1 || ':' || external_id,
+			jsonb_build_object('description', description, 'tags', to_jsonb(tags), 'pinned', pinned, 'position', position)
+		FROM purged`, pq.QuoteIdentifier(spec.Table))
+	res, err := ps.db.Exec(query, string(spec.Type), cutoff, models.ActionFavoritePurged, models.AuditEntityFavorite)
 	if err != nil {
-		return err
+		return 0, err
 	}
-	if n, _ := res.RowsAffected(); n == 0 {
-		return errors.New("asset not found")
+	return res.RowsAffected()
+}
+
+func (ps *PostgresStore) EditFavoriteDescription(userID, assetType, externalID, desc string, expectedVersion int) (int, error) {
+	if err := models.ValidateDescription(desc); err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+
+	assetID, err := ps.resolveAssetID(assetType, externalID)
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := ps.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// Compare-and-swap on version when the caller holds an ETag
+	var favoriteID, version int
+	err = tx.QueryRow(`SELECT id, version FROM favorites WHERE tenant_id = $1 AND user_id = $2 AND asset_type = $3 AND asset_id = $4 AND deleted_at IS NULL FOR UPDATE`,
+		ps.tenantID, userID, assetType, assetID).Scan(&favoriteID, &version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("favorite %s %q: %w", assetType, externalID, ErrNotFound)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if expectedVersion != 0 && version != expectedVersion {
+		return 0, ErrVersionConflict
+	}
+	before, err := loadFavoriteState(tx, favoriteID)
+	if err != nil {
+		return 0, err
+	}
+	err = tx.QueryRow(`UPDATE favorites SET description = $1, version = version + 1, updated_at = now() WHERE id = $2 RETURNING version`,
+		desc, favoriteID).Scan(&version)
+	if err != nil {
+		return 0, err
+	}
+	err = ps.auditFavorite(tx, userID, favoriteID, models.FavoriteEntityID(assetType, externalID), models.ActionFavoriteDescribed, before)
+	if err != nil {
+		return 0, err
+	}
+	if err := ps.publishFavorite(tx, models.EventFavoriteUpdated, favoriteID); err != nil {
+		return 0, err
+	}
+	return version, tx.Commit()
+}
+
+// resolveAssetID maps an asset's external ID to its catalog row ID. Only the
+// tenant's own assets and the global catalog are visible, and a tenant's asset
+// shadows a global one with the same external ID.
+func (ps *PostgresStore) resolveAssetID(assetType, externalID string) (int, error) {
+	spec, ok := models.Lookup(models.AssetType(assetType))
+	if !ok {
+		return 0, fmt.Errorf("%w %q", ErrInvalidType, assetType)
+	}
+
+	var id int
+	query := fmt.Sprintf(`
+		SELECT id FROM %s
+		WHERE external_id = $1 AND (tenant_id IS NULL OR tenant_id = $2)
+		ORDER BY tenant_id NULLS LAST
+		LIMIT 1`, pq.QuoteIdentifier(spec.Table))
+	err := ps.db.QueryRow(query, externalID, ps.tenantID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("%s %q: %w", assetType, externalID, ErrNotFound)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// resolveAssetIDs maps external IDs of one asset type to catalog row IDs through q in
+// a single query, with the same visibility and shadowing as resolveAssetID. Unknown
+// IDs are left out.
+func (ps *PostgresStore) resolveAssetIDs(q querier, assetType models.AssetType, externalIDs []string) (map[string]int, error) {
+	spec, ok := models.Lookup(assetType)
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrInvalidType, assetType)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT DISTINCT ON (external_id) external_id, id FROM %s
+		WHERE external_id = ANY($1) AND (tenant_id IS NULL OR tenant_id = $2)
+		ORDER BY external_id, tenant_id NULLS LAST`, pq.QuoteIdentifier(spec.Table))
+	rows, err := q.Query(query, pq.Array(externalIDs), ps.tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := map[string]int{}
+	for rows.Next() {
+		var externalID string
+		var id int
+		if err := rows.Scan(&externalID, &id); err != nil {
+			return nil, err
+		}
+		ids[externalID] = id
+	}
+	return ids, rows.Err()
+}
+
+// registerAssetTypes records every registered asset type so favorites can reference it.
+// Asset types are shared by every tenant.
+func (ps *PostgresStore) registerAssetTypes() error {
+	for _, spec := range models.Types() {
+		if _, err := ps.db.Exec(`INSERT INTO asset_types (name, catalog_table) VALUES ($1, $2) ON CONFLICT (name) DO NOTHING`, string(spec.Type), spec.Table); err != nil {
+			return fmt.Errorf("registering asset type %q: %w", spec.Type, err)
+		}
 	}
 	return nil
 }
+
+// isUniqueViolation reports whether err is a Postgres unique constraint violation
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
@@ -0,0 +1,108 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/gitvam/platform-go-challenge/internal/models"
+)
+
+// userDataTables lists every table holding a user's data, with the condition selecting
+// their rows ($1 is the tenant, $2 the user), in the order erasure deletes them.
+// Together with tablesWithoutUserData it names every table in the schema, which the
+// store tests check, so a new table cannot be left out of erasure and access reports.
+var userDataTables = []struct {
+	table string
+	where string
+}{
+	{"shares", "tenant_id = $1 AND (owner_id = $2 OR grantee_id = $2)"},
+	{"collection_favorites", "collection_id IN (SELECT id FROM collections WHERE tenant_id = $1 AND user_id = $2)"},
+	{"collections", "tenant_id = $1 AND user_id = $2"},
+	{"favorites", "tenant_id = $1 AND user_id = $2"},
+	{"idempotency_keys", "tenant_id = $1 AND user_id = $2"},
+	{"webhook_deliveries", "event_id IN (SELECT id FROM outbox_events WHERE tenant_id = $1 AND user_id = $2)"},
+	{"outbox_events", "tenant_id = $1 AND user_id = $2"},
+	// Pseudonymized instead of deleted
+	{"audit_events", "tenant_id = $1 AND (user_id = $2 OR actor_id = $2)"},
+}
+
+// tablesWithoutUserData hold the catalog and tenant settings
+var tablesWithoutUserData = []string{"charts", "insights", "audiences", "dashboards", "asset_types", "asset_members", "webhooks"}
+
+func (ps *PostgresStore) UserData(userID string) ([]models.UserDataTable, error) {
+	// One snapshot keeps the tables consistent with each other
+	tx, err := ps.db.BeginTx(context.Background(), &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var tables []models.UserDataTable
+	for _, t := range userDataTables {
+		d := models.UserDataTable{Table: t.table}
+		query := fmt.Sprintf(`SELECT count(*), COALESCE(json_agg(t), '[]') FROM %s t WHERE %s`, t.table, t.where)
+		if err := tx.QueryRow(query, ps.tenantID, userID).Scan(&d.Count, &d.Rows); err != nil {
+			return nil, err
+		}
+		tables = append(tables, d)
+	}
+	return tables, tx.Commit()
+}
+
+func (ps *PostgresStore) EraseUser(userID string) (*models.ErasureReport, error) {
+	tx, err := ps.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	report := &models.ErasureReport{UserID: userID, TenantID: ps.tenantID, Deleted: map[string]int64{},
+		ErasedBy: ps.actorID, RequestID: ps.requestID}
+	for _, t := range userDataTables {
+		if t.table == "audit_events" {
+			if report.PseudonymizedAuditEvents, err = ps.pseudonymizeAuditEvents(tx, userID); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		res, err := tx.Exec(fmt.Sprintf(`DELETE FROM %s WHERE %s`, t.table, t.where), ps.tenantID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if report.Deleted[t.table], err = res.RowsAffected(); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	report.CompletedAt = time.Now().UTC()
+	return report, nil
+}
+
+// pseudonymizeAuditEvents replaces the user's ID in the audit trail with a random
+// pseudonym, so the trail still shows what one person did without saying who. The
+// snapshots of the user's own events hold their content and are removed; other
+// users' snapshots only lose the user's ID.
+func (ps *PostgresStore) pseudonymizeAuditEvents(tx *sql.Tx, userID string) (int64, error) {
+	if _, err := tx.Exec(`SET LOCAL audit.pseudonymize = 'on'`); err != nil {
+		return 0, err
+	}
+	res, err := tx.Exec(`
+		UPDATE audit_events a SET
+			user_id = CASE WHEN a.user_id = u.id THEN u.pseudonym ELSE a.user_id END,
+			actor_id = CASE WHEN a.actor_id = u.id THEN u.pseudonym ELSE a.actor_id END,
+			before = CASE WHEN a.user_id = u.id THEN NULL ELSE replace(a.before::text, u.id::text, u.pseudonym::text)::jsonb END,
+			after = CASE WHEN a.user_id = u.id THEN NULL ELSE replace(a.after::text, u.id::text, u.pseudonym::text)::jsonb END,
+			request_id = CASE WHEN a.user_id = u.id THEN '' ELSE a.request_id END
+		FROM (SELECT $2::uuid AS id, gen_random_uuid() AS pseudonym) u
+		WHERE a.tenant_id = $1 AND (a.user_id = u.id OR a.actor_id = u.id
+			OR strpos(a.before::text, u.id::text) > 0 OR strpos(a.after::text, u.id::text) > 0)`,
+		ps.tenantID, userID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
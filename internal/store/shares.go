@@ -0,0 +1,351 @@
+package store
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/gitvam/platform-go-challenge/internal/models"
+)
+
+// shareColumns are selected, in scanShare order, from shares aliased as s
+const shareColumns = `
+	s.id, s.owner_id, COALESCE(s.grantee_id::text, ''), s.permission, s.expires_at, s.created_at,
+	COALESCE(s.favorite_id, 0), COALESCE(s.collection_id, 0)`
+
+// activeShare excludes expired shares and shares of trashed favorites; revoked shares are deleted
+const activeShare = `(s.expires_at IS NULL OR s.expires_at > now())
+	AND (s.favorite_id IS NULL OR EXISTS (SELECT 1 FROM favorites f WHERE f.id = s.favorite_id AND f.deleted_at IS NULL))`
+
+// storedShare is a share with the row IDs of the item it shares
+type storedShare struct {
+	models.Share
+	favoriteID int
+}
+
+func scanShare(row rowScanner) (*storedShare, error) {
+	var s storedShare
+	err := row.Scan(&s.ID, &s.OwnerID, &s.UserID, &s.Permission, &s.ExpiresAt, &s.CreatedAt, &s.favoriteID, &s.CollectionID)
+	if err != nil {
+		return nil, err
+	}
+	s.Kind = models.ShareKindCollection
+	if s.favoriteID != 0 {
+		s.Kind = models.ShareKindFavorite
+	}
+	return &s, nil
+}
+
+// hashShareToken is what is stored for a link token, so a leaked database does not leak working links
+func hashShareToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func newShareToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func (ps *PostgresStore) ShareFavorite(ownerID, assetType, externalID string, req models.ShareRequest) (*models.Share, error) {
+	req.Normalize()
+	if err := req.Validate(ownerID); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+	favoriteID, err := ps.favoriteID(ownerID, assetType, externalID)
+	if err != nil {
+		return nil, err
+	}
+	return ps.createShare(ownerID, favoriteID, &models.FavoriteRef{Type: models.AssetType(assetType), ExternalID: externalID}, 0, req)
+}
+
+func (ps *PostgresStore) ShareCollection(ownerID string, collectionID int, req models.ShareRequest) (*models.Share, error) {
+	req.Normalize()
+	if err := req.Validate(ownerID); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+	if err := ps.checkCollection(ownerID, collectionID); err != nil {
+		return nil, err
+	}
+	return ps.createShare(ownerID, 0, nil, collectionID, req)
+}
+
+// createShare stores a share of a favorite or a collection, generating a token for links
+func (ps *PostgresStore) createShare(ownerID string, favoriteID int, favorite *models.FavoriteRef, collectionID int, req models.ShareRequest) (*models.Share, error) {
+	s := &models.Share{
+		Kind:         models.ShareKindFavorite,
+		OwnerID:      ownerID,
+		UserID:       req.UserID,
+		Permission:   req.Permission,
+		Favorite:     favorite,
+		CollectionID: collectionID,
+		ExpiresAt:    req.ExpiresAt,
+	}
+	if collectionID != 0 {
+		s.Kind = models.ShareKindCollection
+	}
+	var tokenHash string
+	if req.Link {
+		token, err := newShareToken()
+		if err != nil {
+			return nil, err
+		}
+		s.Token, tokenHash = token, hashShareToken(token)
+	}
+
+	insert := `
+		INSERT INTO shares (tenant_id, owner_id, favorite_id, collection_id, grantee_id, token_hash, permission, expires_at)
+		VALUES ($8, $1, NULLIF($2, 0), NULLIF($3, 0), NULLIF($4, '')::uuid, NULLIF($5, ''), $6, $7)
+		ON CONFLICT (COALESCE(favorite_id, 0), COALESCE(collection_id, 0), grantee_id)
+		DO UPDATE SET permission = EXCLUDED.permission, expires_at = EXCLUDED.expires_at
+		RETURNING id, created_at`
+	tx, err := ps.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(insert, ownerID, favoriteID, collectionID, req.UserID, tokenHash, req.Permission, req.ExpiresAt, ps.tenantID).
+		Scan(&s.ID, &s.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	// Link tokens never go into the audit trail
+	audited := *s
+	audited.Token = ""
+	err = ps.audit(tx, ownerID, auditEvent{userID: ownerID, action: models.ActionShareCreated,
+		entityType: models.AuditEntityShare, entityID: strconv.Itoa(s.ID), after: audited})
+	if err != nil {
+		return nil, err
+	}
+	return s, tx.Commit()
+}
+
+func (ps *PostgresStore) ListFavoriteShares(ownerID, assetType, externalID string) ([]models.Share, error) {
+	favoriteID, err := ps.favoriteID(ownerID, assetType, externalID)
+	if err != nil {
+		return nil, err
+	}
+	shares, err := ps.queryShares(`WHERE s.tenant_id = $1 AND s.favorite_id = $2 AND `+activeShare, ps.tenantID, favoriteID)
+	for i := range shares {
+		shares[i].Favorite = &models.FavoriteRef{Type: models.AssetType(assetType), ExternalID: externalID}
+	}
+	return shares, err
+}
+
+func (ps *PostgresStore) ListCollectionShares(ownerID string, collectionID int) ([]models.Share, error) {
+	if err := ps.checkCollection(ownerID, collectionID); err != nil {
+		return nil, err
+	}
+	return ps.queryShares(`WHERE s.tenant_id = $1 AND s.collection_id = $2 AND `+activeShare, ps.tenantID, collectionID)
+}
+
+// queryShares lists the shares matching where, oldest first
+func (ps *PostgresStore) queryShares(where string, args ...any) ([]models.Share, error) {
+	rows, err := ps.db.Query(`SELECT `+shareColumns+` FROM shares s `+where+` ORDER BY s.created_at, s.id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	shares := []models.Share{}
+	for rows.Next() {
+		s, err := scanShare(rows)
+		if err != nil {
+			return nil, err
+		}
+		shares = append(shares, s.Share)
+	}
+	return shares, rows.Err()
+}
+
+func (ps *PostgresStore) RevokeShare(ownerID string, shareID int) error {
+	tx, err := ps.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	s, err := scanShare(tx.QueryRow(`DELETE FROM shares s WHERE s.tenant_id = $1 AND s.owner_id = $2 AND s.id = $3 RETURNING `+shareColumns,
+		ps.tenantID, ownerID, shareID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("share %d: %w", shareID, ErrNotFound)
+	}
+	if err != nil {
+		return err
+	}
+	err = ps.audit(tx, ownerID, auditEvent{userID: ownerID, action: models.ActionShareRevoked,
+		entityType: models.AuditEntityShare, entityID: strconv.Itoa(shareID), before: s.Share})
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (ps *PostgresStore) ListSharedWithMe(userID string) ([]models.SharedItem, error) {
+	rows, err := ps.db.Query(`SELECT `+shareColumns+` FROM shares s WHERE s.tenant_id = $1 AND s.grantee_id = $2 AND `+activeShare+` ORDER BY s.created_at DESC, s.id DESC`, ps.tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	var shares []*storedShare
+	for rows.Next() {
+		s, err := scanShare(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		shares = append(shares, s)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	items := []models.SharedItem{}
+	for _, s := range shares {
+		item, err := ps.sharedItem(s, nil)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, *item)
+	}
+	return items, nil
+}
+
+func (ps *PostgresStore) GetSharedItem(userID string, shareID int, opts ListOptions) (*models.SharedItem, error) {
+	s, err := scanShare(ps.db.QueryRow(`SELECT `+shareColumns+` FROM shares s WHERE s.tenant_id = $1 AND s.id = $2 AND s.grantee_id = $3 AND `+activeShare, ps.tenantID, shareID, userID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("share %d: %w", shareID, ErrNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ps.sharedItem(s, &opts)
+}
+
+func (ps *PostgresStore) OpenShareLink(token string, opts ListOptions) (*models.SharedItem, error) {
+	s, err := scanShare(ps.db.QueryRow(`SELECT `+shareColumns+` FROM shares s WHERE s.tenant_id = $1 AND s.token_hash = $2 AND `+activeShare, ps.tenantID, hashShareToken(token)))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("share link: %w", ErrNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ps.sharedItem(s, &opts)
+}
+
+// sharedItem loads the item behind a share as its owner sees it. A page of a
+// shared collection's favorites is included when opts is not nil.
+func (ps *PostgresStore) sharedItem(s *storedShare, opts *ListOptions) (*models.SharedItem, error) {
+	item := &models.SharedItem{Share: s.Share}
+	if s.Kind == models.ShareKindFavorite {
+		assets, err := ps.listFavorites(favoriteFilter{userID: s.OwnerID, favoriteID: s.favoriteID}, ListOptions{Limit: 1})
+		if err != nil {
+			return nil, err
+		}
+		if len(assets) == 0 {
+			return nil, fmt.Errorf("share %d: %w", s.ID, ErrNotFound)
+		}
+		item.Asset = assets[0]
+		item.Share.Favorite = &models.FavoriteRef{Type: item.Asset.GetType(), ExternalID: item.Asset.GetID()}
+		return item, nil
+	}
+
+	c, err := ps.GetCollection(s.OwnerID, s.CollectionID)
+	if err != nil {
+		return nil, err
+	}
+	item.Collection = c
+	if opts != nil {
+		if item.Favorites, err = ps.listFavorites(favoriteFilter{userID: s.OwnerID, collectionID: s.CollectionID}, *opts); err != nil {
+			return nil, err
+		}
+	}
+	return item, nil
+}
+
+func (ps *PostgresStore) EditSharedItem(userID string, shareID int, update models.SharedItemUpdate, expectedVersion int) (*models.SharedItem, error) {
+	tx, err := ps.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Lock the share so it cannot be revoked or downgraded while the edit is applied
+	s, err := scanShare(tx.QueryRow(`SELECT `+shareColumns+` FROM shares s WHERE s.tenant_id = $1 AND s.id = $2 AND s.grantee_id = $3 AND `+activeShare+` FOR UPDATE`, ps.tenantID, shareID, userID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("share %d: %w", shareID, ErrNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if s.Permission != models.PermissionEdit {
+		return nil, fmt.Errorf("share %d is view-only: %w", shareID, ErrForbidden)
+	}
+	if err := update.Validate(s.Kind); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+
+	var res sql.Result
+	var before any
+	if s.Kind == models.ShareKindFavorite {
+		if before, err = loadFavoriteState(tx, s.favoriteID); err != nil {
+			return nil, err
+		}
+		res, err = tx.Exec(`
+			UPDATE favorites SET description = COALESCE($1, description), version = version + 1, updated_at = now()
+			WHERE id = $2 AND ($3 = 0 OR version = $3)`, update.Description, s.favoriteID, expectedVersion)
+	} else {
+		if before, err = scanCollection(tx.QueryRow(`SELECT `+collectionColumns+` FROM collections c WHERE c.id = $1`, s.CollectionID)); err != nil {
+			return nil, err
+		}
+		res, err = tx.Exec(`
+			UPDATE collections SET name = COALESCE($1, name), description = COALESCE($2, description), version = version + 1, updated_at = now()
+			WHERE id = $3 AND ($4 = 0 OR version = $4)`, update.Name, update.Description, s.CollectionID, expectedVersion)
+		if isUniqueViolation(err) {
+			return nil, fmt.Errorf("collection %q %w", *update.Name, ErrAlreadyExists)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	// The share's foreign keys guarantee the item exists, so no match means the version moved on
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, ErrVersionConflict
+	}
+	if err := ps.auditSharedEdit(tx, userID, s, before); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return ps.sharedItem(s, nil)
+}
+
+// auditSharedEdit records an editor's change to the owner's shared item, with the editor as the actor
+func (ps *PostgresStore) auditSharedEdit(tx *sql.Tx, editorID string, s *storedShare, before any) error {
+	if s.Kind == models.ShareKindFavorite {
+		entityID, err := favoriteEntityIDOf(tx, s.favoriteID)
+		if err != nil {
+			return err
+		}
+		if err := ps.auditFavorite(tx, editorID, s.favoriteID, entityID, models.ActionFavoriteDescribed, before.(*favoriteState)); err != nil {
+			return err
+		}
+		return ps.publishFavorite(tx, models.EventFavoriteUpdated, s.favoriteID)
+	}
+	after, err := scanCollection(tx.QueryRow(`SELECT `+collectionColumns+` FROM collections c WHERE c.id = $1`, s.CollectionID))
+	if err != nil {
+		return err
+	}
+	return ps.audit(tx, editorID, auditEvent{userID: s.OwnerID, action: models.ActionCollectionUpdated,
+		entityType: models.AuditEntityCollection, entityID: strconv.Itoa(s.CollectionID), before: before, after: after})
+}
@@ -1,10 +1,228 @@
 package store
 
-import "github.com/gitvam/platform-go-challenge/internal/models"
+import (
+	"time"
+
+	"github.com/gitvam/platform-go-challenge/internal/models"
+)
 
 type Store interface {
-	ListFavorites(userID string, limit, offset int) ([]models.Asset, error)
+	// ForTenant returns a store whose reads and writes are limited to one tenant's favorites,
+	// collections, shares and idempotency keys, and to the global catalog plus that tenant's assets
+	ForTenant(tenantID string) Store
+	// AuditAs returns a store that records actorID and requestID on the audit events of its
+	// changes. Without it the user whose data changes is recorded as the actor.
+	AuditAs(actorID, requestID string) Store
+	// ListAuditEvents lists the tenant's audit events matching filter, newest first
+	ListAuditEvents(filter AuditFilter) ([]models.AuditEvent, error)
+	// UserData returns every row the tenant stores about a user, one table at a time, for
+	// subject access requests
+	UserData(userID string) ([]models.UserDataTable, error)
+	// EraseUser permanently deletes the tenant's data about a user: favorites, tags,
+	// collections, shares made or received, idempotency records and favorite events. Their
+	// audit events are kept but pseudonymized. The report is not signed.
+	EraseUser(userID string) (*models.ErasureReport, error)
+
+	// ListCatalog lists the catalog assets of one type the tenant can favorite, by external ID,
+	// paged and expanded like ListFavorites. GetCatalogAsset returns one of them.
+	ListCatalog(assetType string, opts ListOptions) ([]models.Asset, error)
+	GetCatalogAsset(assetType, externalID string, expandMembers bool) (models.Asset, error)
+
+	ListFavorites(userID string, opts ListOptions) ([]models.Asset, error)
+	// ListKeyedFavorites lists favorites in manual order like ListFavorites, each with the
+	// key that ListOptions.After takes to list the favorites following it
+	ListKeyedFavorites(userID string, opts ListOptions) ([]KeyedFavorite, error)
+	// GetFavorite returns one of the user's favorites, with the full asset of each member when
+	// expandMembers is set
+	GetFavorite(userID, assetType, externalID string, expandMembers bool) (models.Asset, error)
+	// ExportFavorites calls each with every one of the user's favorites, including their members,
+	// as of one snapshot. It reads them in batches instead of holding them all, and stops at the
+	// first error from each.
+	ExportFavorites(userID string, each func(models.Asset) error) error
 	AddFavorite(userID string, asset models.Asset) error
+	// ImportFavorites adds the catalog assets of rows to the user's favorites in one transaction
+	// and reports the outcome of every row. Unless it is a dry run, rows that are invalid or name
+	// unknown assets fail the whole import with ErrValidation, as do rows already in the favorites
+	// with ErrAlreadyExists when opts.OnConflict is models.ImportFail; the report is returned either way.
+	ImportFavorites(userID string, rows []models.ImportRow, opts ImportOptions) (*models.ImportReport, error)
+	// RemoveFavorite moves a favorite to the trash. Trashed favorites are left out of every
+	// listing, collection, tag count and share until restored, and adding one again replaces it.
 	RemoveFavorite(userID, assetType, externalID string) error
-	EditFavoriteDescription(userID, assetType, externalID, desc string) error
+	// ListTrash lists the user's trashed favorites, most recently removed first
+	ListTrash(userID string, opts ListOptions) ([]models.TrashedFavorite, error)
+	// RestoreFavorite brings a trashed favorite back where it was and returns its new version
+	RestoreFavorite(userID, assetType, externalID string) (int, error)
+	// PurgeTrash is maintenance and permanently deletes, in every tenant, favorites trashed
+	// longer than retention ago
+	PurgeTrash(retention time.Duration) (int64, error)
+	// EditFavoriteDescription updates the description and returns the favorite's new version.
+	// A non-zero expectedVersion makes the update conditional and fails with ErrVersionConflict
+	// when the stored version differs.
+	EditFavoriteDescription(userID, assetType, externalID, desc string, expectedVersion int) (int, error)
+	// AddFavoriteTags adds tags to a favorite and RemoveFavoriteTag removes one, both returning the
+	// favorite's resulting tags and version. expectedVersion works as for EditFavoriteDescription.
+	AddFavoriteTags(userID, assetType, externalID string, tags []string, expectedVersion int) ([]string, int, error)
+	RemoveFavoriteTag(userID, assetType, externalID, tag string, expectedVersion int) ([]string, int, error)
+	// SetFavoritePinned pins or unpins a favorite, placing it at the top of its new group, and
+	// returns its version. Pinning a pinned favorite changes nothing.
+	SetFavoritePinned(userID, assetType, externalID string, pinned bool, expectedVersion int) (int, error)
+	// MoveFavorite places a favorite next to another one in the user's manual order. The moved
+	// favorite takes the anchor's pinned state; its resulting pinned state and version are returned.
+	MoveFavorite(userID, assetType, externalID string, move models.FavoriteMove, expectedVersion int) (bool, int, error)
+	// ListTags returns every tag the user has used with the number of favorites carrying it
+	ListTags(userID string) ([]models.TagCount, error)
+
+	ListCollections(userID string) ([]models.Collection, error)
+	GetCollection(userID string, collectionID int) (*models.Collection, error)
+	// CreateCollection stores c at the end of the user's collections and fills in its ID, position,
+	// version and timestamps
+	CreateCollection(userID string, c *models.Collection) error
+	// UpdateCollection applies a partial update. A non-zero expectedVersion makes it conditional,
+	// as for EditFavoriteDescription.
+	UpdateCollection(userID string, collectionID int, update models.CollectionUpdate, expectedVersion int) (*models.Collection, error)
+	DeleteCollection(userID string, collectionID int) error
+	// AddToCollection adds one of the user's favorites to a collection; adding it twice is a no-op
+	AddToCollection(userID string, collectionID int, assetType, externalID string) error
+	RemoveFromCollection(userID string, collectionID int, assetType, externalID string) error
+	// ListCollectionFavorites lists the favorites in a collection, paged like ListFavorites
+	ListCollectionFavorites(userID string, collectionID int, opts ListOptions) ([]models.Asset, error)
+
+	// ShareFavorite and ShareCollection share one of the owner's items with another user or
+	// create a share link. Sharing again with the same user replaces the earlier share.
+	ShareFavorite(ownerID, assetType, externalID string, req models.ShareRequest) (*models.Share, error)
+	ShareCollection(ownerID string, collectionID int, req models.ShareRequest) (*models.Share, error)
+	ListFavoriteShares(ownerID, assetType, externalID string) ([]models.Share, error)
+	ListCollectionShares(ownerID string, collectionID int) ([]models.Share, error)
+	// RevokeShare deletes one of the owner's shares, invalidating its link token if it has one
+	RevokeShare(ownerID string, shareID int) error
+	// ListSharedWithMe lists the unexpired shares granted to the user with the items they share.
+	// Collections are listed without their favorites.
+	ListSharedWithMe(userID string) ([]models.SharedItem, error)
+	// GetSharedItem returns an item shared with the user, with a page of favorites for collections.
+	// Shares the user was not granted are reported as ErrNotFound.
+	GetSharedItem(userID string, shareID int, opts ListOptions) (*models.SharedItem, error)
+	// EditSharedItem applies an editor's update to a shared item, failing with ErrForbidden for
+	// view-only shares. A non-zero expectedVersion makes it conditional on the item's version.
+	EditSharedItem(userID string, shareID int, update models.SharedItemUpdate, expectedVersion int) (*models.SharedItem, error)
+	// OpenShareLink returns the item behind an unexpired share link token
+	OpenShareLink(token string, opts ListOptions) (*models.SharedItem, error)
+
+	// SubscribeFavoriteEvents wakes the subscriber whenever an event is written for the user on any
+	// replica; ListFavoriteEvents then reads up to limit events after afterID, oldest first. Events
+	// are ordered by the transaction that wrote them rather than by ID, and are held back until
+	// every transaction begun before theirs has ended. It fails with ErrEventsExpired when afterID
+	// is no longer in the log, as later events may be gone too.
+	SubscribeFavoriteEvents(userID string) (*Subscription, error)
+	ListFavoriteEvents(userID string, afterID int64, limit int) ([]models.FavoriteEvent, error)
+	// LastFavoriteEventID returns the ID of the user's latest event ListFavoriteEvents has let
+	// through, or 0 if there is none
+	LastFavoriteEventID(userID string) (int64, error)
+	// PurgeFavoriteEvents is maintenance and deletes, in every tenant, events older than retention
+	// that no webhook is still trying to deliver
+	PurgeFavoriteEvents(retention time.Duration) (int64, error)
+
+	// CreateWebhook registers a URL for the tenant's favorite change events, generating the
+	// secret that signs its deliveries. The secret is only returned here.
+	CreateWebhook(adminID string, req models.WebhookRequest) (*models.Webhook, error)
+	ListWebhooks() ([]models.Webhook, error)
+	// DeleteWebhook removes a webhook with its pending and past deliveries
+	DeleteWebhook(adminID string, webhookID int) error
+	// ListWebhookDeliveries lists a webhook's deliveries newest first, optionally with one status
+	ListWebhookDeliveries(webhookID int, status string, opts ListOptions) ([]models.WebhookDelivery, error)
+	// ReplayWebhookDelivery queues a delivery to be sent again with a fresh set of attempts,
+	// whether it was dead-lettered or already delivered
+	ReplayWebhookDelivery(webhookID int, deliveryID int64) (*models.WebhookDelivery, error)
+
+	// EnqueueWebhookDeliveries is maintenance and fans up to limit undispatched outbox events
+	// of every tenant out to the webhooks subscribed to them
+	EnqueueWebhookDeliveries(limit int) (int64, error)
+	// ClaimWebhookDeliveries takes up to limit due deliveries of every tenant and counts an
+	// attempt for each. Claimed deliveries are not due again until lease has passed, so one
+	// that is never completed, retried or dead-lettered is retried after the lease.
+	ClaimWebhookDeliveries(limit int, lease time.Duration) ([]PendingDelivery, error)
+	CompleteWebhookDelivery(deliveryID int64) error
+	RetryWebhookDelivery(deliveryID int64, lastErr string, at time.Time) error
+	DeadLetterWebhookDelivery(deliveryID int64, lastErr string) error
+
+	ReserveIdempotencyKey(userID, key, requestHash string, ttl, lease time.Duration) (string, *IdempotencyRecord, error)
+	CompleteIdempotencyKey(userID, key, reservation string, statusCode int, contentType string, headers map[string]string, body []byte) error
+	ReleaseIdempotencyKey(userID, key, reservation string) error
+	// PurgeExpiredIdempotencyKeys is maintenance and purges expired keys of every tenant
+	PurgeExpiredIdempotencyKeys() (int64, error)
+}
+
+// ListOptions controls paging and expansion when listing favorites
+type ListOptions struct {
+	Limit  int
+	Offset int
+	// ExpandMembers loads the full asset of every member of composite assets such as dashboards
+	ExpandMembers bool
+	// Tags keeps only favorites carrying any of the normalized tags, or all of them with MatchAllTags
+	Tags         []string
+	MatchAllTags bool
+	// Types keeps only favorites of the given asset types
+	Types []models.AssetType
+	// ManualOrder lists pinned favorites first and then follows the user's own order,
+	// instead of grouping favorites by type
+	ManualOrder bool
+	// After, in manual order, starts the list after the favorite with this key instead of at Offset
+	After *FavoriteKey
+	// Fields selects only the catalog columns needed for these JSON fields of each asset,
+	// leaving the others zero, and skips loading members unless "members" is among them.
+	// Empty loads whole assets.
+	Fields []string
+}
+
+// FavoriteKey is where a favorite sorts in manual order: pinned first, then by position and ID
+type FavoriteKey struct {
+	Pinned   bool
+	Position float64
+	ID       int
+}
+
+// KeyedFavorite is a favorite listed with its FavoriteKey
+type KeyedFavorite struct {
+	Asset models.Asset
+	Key   FavoriteKey
+}
+
+// ImportOptions controls how favorites are imported
+type ImportOptions struct {
+	// OnConflict is what happens to rows already in the favorites: models.ImportSkip,
+	// ImportOverwrite or ImportFail
+	OnConflict string
+	// DryRun reports what the import would do without changing anything
+	DryRun bool
+}
+
+// AuditFilter selects audit events; zero fields match every event
+type AuditFilter struct {
+	UserID     string
+	ActorID    string
+	Action     string
+	EntityType string
+	EntityID   string
+	Since      time.Time // inclusive
+	Until      time.Time // exclusive
+	Limit      int
+	Offset     int
+}
+
+// IdempotencyRecord is the stored outcome of a request sent with an Idempotency-Key.
+// A StatusCode of 0 means the original request has not finished yet.
+type IdempotencyRecord struct {
+	UserID      string
+	Key         string
+	RequestHash string
+	StatusCode  int
+	ContentType string
+	// Headers holds the response headers replayed along with the body, such as ETag and Location
+	Headers   map[string]string
+	Body      []byte
+	ExpiresAt time.Time
+}
+
+// InProgress reports whether the original request is still being processed
+func (r *IdempotencyRecord) InProgress() bool {
+	return r.StatusCode == 0
 }
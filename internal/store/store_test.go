@@ -2,26 +2,50 @@ package store
 
 import (
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
+
 	"github.com/gitvam/platform-go-challenge/internal/models"
-	"github.com/lib/pq"
 )
 
 func getTestConnStr() string {
+	return getTestConnStrFor("favorites")
+}
+
+func getTestConnStrFor(database string) string {
 	host := os.Getenv("DB_HOST")
 	if host == "" {
 		host = "localhost"
 	}
-	return fmt.Sprintf("postgres://gwi:password@%s:5432/favorites?sslmode=disable", host)
+	return fmt.Sprintf("postgres://gwi:password@%s:5432/%s?sslmode=disable", host, database)
 }
 
 func resetTestDB(db *sql.DB) {
+	db.Exec("DELETE FROM shares")
+	db.Exec("DELETE FROM collection_favorites")
+	db.Exec("DELETE FROM collections")
 	db.Exec("DELETE FROM favorites")
 	db.Exec("DELETE FROM charts")
 	db.Exec("DELETE FROM insights")
 	db.Exec("DELETE FROM audiences")
+	db.Exec("DELETE FROM asset_members")
+	db.Exec("DELETE FROM dashboards")
+	db.Exec("DELETE FROM idempotency_keys")
+	db.Exec("DELETE FROM webhook_deliveries")
+	db.Exec("DELETE FROM webhooks")
+	db.Exec("DELETE FROM outbox_events")
+	// TRUNCATE bypasses the trigger that keeps audit_events append-only
+	db.Exec("TRUNCATE audit_events")
 }
 
 func TestAddFavorite_Success(t *testing.T) {
@@ -32,8 +56,10 @@ func TestAddFavorite_Success(t *testing.T) {
 	resetTestDB(s.db)
 
 	_, err = s.db.Exec(`
-		INSERT INTO charts (external_id, title, x_axis_title, y_axis_title, data, description)
-		VALUES ('test_chart_extid', 'Chart Title', 'X', 'Y', ARRAY[1,2,3], 'desc')`)
+		INSERT INTO charts (external_id, title, kind, x_axis_title, y_axis_title, x_axis, series, description)
+		VALUES ('test_chart_extid', 'Chart Title', 'bar', 'X', 'Y',
+			'{"kind": "category", "categories": ["Jan", "Feb", "Mar"]}',
+			'[{"name": "Reach", "unit": "%", "values": [1.5, 2, 3.25]}, {"name": "Clicks", "values": [4, 5, 6]}]', 'desc')`)
 	if err != nil {
 		t.Fatalf("failed to insert test chart: %v", err)
 	}
@@ -43,7 +69,7 @@ func TestAddFavorite_Success(t *testing.T) {
 		Title:       "Chart Title",
 		XAxisTitle:  "X",
 		YAxisTitle:  "Y",
-		Data:        pq.Int64Array{1, 2, 3},
+		Series:      []models.Series{{Name: "Reach", Values: []float64{1.5, 2, 3.25}}},
 		Description: "desc",
 		Type:        "chart",
 	}
@@ -53,7 +79,7 @@ func TestAddFavorite_Success(t *testing.T) {
 		t.Fatalf("AddFavorite failed: %v", err)
 	}
 
-	favs, err := s.ListFavorites("11111111-1111-1111-1111-111111111111", 10, 0)
+	favs, err := s.ListFavorites("11111111-1111-1111-1111-111111111111", ListOptions{Limit: 10})
 	if err != nil {
 		t.Fatalf("ListFavorites failed: %v", err)
 	}
@@ -64,6 +90,13 @@ func TestAddFavorite_Success(t *testing.T) {
 	if favs[0].GetType() != "chart" {
 		t.Errorf("expected type 'chart', got %q", favs[0].GetType())
 	}
+	got := favs[0].(*models.Chart)
+	if got.Kind != models.ChartKindBar || strings.Join(got.XAxis.Categories, ",") != "Jan,Feb,Mar" {
+		t.Errorf("unexpected chart axis: kind %q, x_axis %+v", got.Kind, got.XAxis)
+	}
+	if len(got.Series) != 2 || got.Series[0].Unit != "%" || got.Series[0].Values[2] != 3.25 || got.Series[1].Name != "Clicks" {
+		t.Errorf("unexpected chart series: %+v", got.Series)
+	}
 }
 
 func TestAddFavorite_Duplicate(t *testing.T) {
@@ -86,8 +119,8 @@ func TestAddFavorite_Duplicate(t *testing.T) {
 		t.Fatal(err)
 	}
 	err = s.AddFavorite("11111111-1111-1111-1111-111111111111", insight)
-	if err == nil {
-		t.Fatal("expected duplicate error, got nil")
+	if !errors.Is(err, ErrAlreadyExists) {
+		t.Fatalf("expected ErrAlreadyExists, got %v", err)
 	}
 }
 
@@ -98,8 +131,37 @@ func TestAddFavorite_Invalid(t *testing.T) {
 	}
 	invalid := &models.Chart{ExternalID: "", Title: ""}
 	err = s.AddFavorite("11111111-1111-1111-1111-111111111111", invalid)
-	if err == nil {
-		t.Fatal("expected validation error, got nil")
+	if !errors.Is(err, ErrValidation) {
+		t.Fatalf("expected ErrValidation, got %v", err)
+	}
+	var verrs models.ValidationErrors
+	if !errors.As(err, &verrs) || len(verrs) != 2 {
+		t.Errorf("expected two field errors, got %v", err)
+	}
+}
+
+func TestFavoriteErrors_NotFoundAndInvalidType(t *testing.T) {
+	s, err := NewPostgresStore(getTestConnStr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resetTestDB(s.db)
+	userID := "11111111-1111-1111-1111-111111111111"
+
+	err = s.AddFavorite(userID, &models.Insight{ExternalID: "missing_insight", Text: "t"})
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for unknown asset, got %v", err)
+	}
+	if strings.Contains(err.Error(), "sql:") {
+		t.Errorf("expected no database text in error, got %q", err.Error())
+	}
+
+	s.db.Exec(`INSERT INTO insights (external_id, text, description) VALUES ('not_favorited', 't', 'd')`)
+	if err := s.RemoveFavorite(userID, "insight", "not_favorited"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for missing favorite, got %v", err)
+	}
+	if err := s.RemoveFavorite(userID, "video", "x"); !errors.Is(err, ErrInvalidType) {
+		t.Errorf("expected ErrInvalidType, got %v", err)
 	}
 }
 
@@ -109,7 +171,7 @@ func TestListFavorites_EmptyUser(t *testing.T) {
 		t.Fatal(err)
 	}
 	resetTestDB(s.db)
-	favs, err := s.ListFavorites("33333333-3333-3333-3333-333333333333", 10, 0)
+	favs, err := s.ListFavorites("33333333-3333-3333-3333-333333333333", ListOptions{Limit: 10})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -125,14 +187,14 @@ func TestAddAndListDifferentAssets(t *testing.T) {
 	}
 	resetTestDB(s.db)
 
-	s.db.Exec(`INSERT INTO charts (external_id, title, x_axis_title, y_axis_title, data, description) VALUES ('chart_c1', 't', 'x', 'y', ARRAY[1], 'd')`)
+	s.db.Exec(`INSERT INTO charts (external_id, title, x_axis_title, y_axis_title, series, description) VALUES ('chart_c1', 't', 'x', 'y', '[{"name": "s", "values": [1]}]', 'd')`)
 	s.db.Exec(`INSERT INTO insights (external_id, text, description) VALUES ('insight_i1', 't', 'd')`)
-	s.db.Exec(`INSERT INTO audiences (external_id, gender, birth_country, age_groups, hours_on_social, purchases_last_month, description) VALUES ('audience_a1', 'f', 'GR', ARRAY['18-24'], 2, 1, 'd')`)
+	s.db.Exec(`INSERT INTO audiences (external_id, gender, birth_country, age_groups, hours_on_social, purchases_last_month, description) VALUES ('audience_a1', 'female', 'GR', ARRAY['18-24'], 2, 1, 'd')`)
 
 	assets := []models.Asset{
-		&models.Chart{ExternalID: "chart_c1", Title: "t", XAxisTitle: "x", YAxisTitle: "y", Data: pq.Int64Array{1, 2, 3}, Description: "d", Type: "chart"},
+		&models.Chart{ExternalID: "chart_c1", Title: "t", XAxisTitle: "x", YAxisTitle: "y", Series: []models.Series{{Name: "s", Values: []float64{1}}}, Description: "d", Type: "chart"},
 		&models.Insight{ExternalID: "insight_i1", Text: "t", Description: "d", Type: "insight"},
-		&models.Audience{ExternalID: "audience_a1", Gender: "f", BirthCountry: "GR", AgeGroups: []string{"18-24"}, HoursOnSocial: 2, PurchasesLastMonth: 1, Description: "d", Type: "audience"},
+		&models.Audience{ExternalID: "audience_a1", Gender: "female", BirthCountry: "GR", AgeGroups: []string{"18-24"}, HoursOnSocial: 2, PurchasesLastMonth: 1, Description: "d", Type: "audience"},
 	}
 
 	for _, asset := range assets {
@@ -141,7 +203,7 @@ func TestAddAndListDifferentAssets(t *testing.T) {
 		}
 	}
 
-	favs, err := s.ListFavorites("22222222-2222-2222-2222-222222222222", 10, 0)
+	favs, err := s.ListFavorites("22222222-2222-2222-2222-222222222222", ListOptions{Limit: 10})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -149,3 +211,1511 @@ func TestAddAndListDifferentAssets(t *testing.T) {
 		t.Errorf("expected 3 favorites, got %d", len(favs))
 	}
 }
+
+func TestListFavorites_AudienceCriteria(t *testing.T) {
+	s, err := NewPostgresStore(getTestConnStr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resetTestDB(s.db)
+	userID := "22222222-2222-2222-2222-222222222222"
+
+	s.db.Exec(`INSERT INTO audiences (external_id, criteria, description) VALUES ('aud_criteria',
+		'{"or": [{"attribute": "birth_country", "op": "eq", "value": "GB"}, {"attribute": "hours_on_social", "op": "gte", "value": 4}]}', 'd')`)
+	criteria := &models.Criteria{Or: []models.Criteria{
+		{Attribute: models.AttrBirthCountry, Op: models.OpEq, Value: "GB"},
+		{Attribute: models.AttrHoursOnSocial, Op: models.OpGte, Value: 4.0},
+	}}
+	if err := s.AddFavorite(userID, &models.Audience{ExternalID: "aud_criteria", Criteria: criteria, Type: "audience"}); err != nil {
+		t.Fatal(err)
+	}
+
+	favs, err := s.ListFavorites(userID, ListOptions{Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(favs) != 1 {
+		t.Fatalf("expected 1 favorite, got %d", len(favs))
+	}
+	got := favs[0].(*models.Audience)
+	if got.Gender != "" || got.Summary() != "birth country is GB OR hours on social is at least 4" {
+		t.Errorf("unexpected audience: %+v (%s)", got, got.Summary())
+	}
+}
+
+func TestListFavorites_SparseFields(t *testing.T) {
+	s, err := NewPostgresStore(getTestConnStr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resetTestDB(s.db)
+	userID := "22222222-2222-2222-2222-222222222222"
+
+	s.db.Exec(`INSERT INTO charts (external_id, title, x_axis_title, y_axis_title, series, description) VALUES ('chart_c1', 't', 'x', 'y', '[{"name": "s", "values": [1]}]', 'd')`)
+	chart := &models.Chart{ExternalID: "chart_c1", Title: "t", XAxisTitle: "x", YAxisTitle: "y", Series: []models.Series{{Name: "s", Values: []float64{1}}}, Description: "mine", Type: "chart"}
+	if err := s.AddFavorite(userID, chart); err != nil {
+		t.Fatal(err)
+	}
+
+	favs, err := s.ListFavorites(userID, ListOptions{Limit: 10, Fields: []string{"title", "description"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(favs) != 1 {
+		t.Fatalf("expected 1 favorite, got %d", len(favs))
+	}
+	got := favs[0].(*models.Chart)
+	if got.ExternalID != "chart_c1" || got.Title != "t" || got.Description != "mine" || got.Series != nil || got.XAxisTitle != "" {
+		t.Errorf("expected only the requested columns, got %+v", got)
+	}
+}
+
+func TestDashboard_MembersMustMatchCatalogAndExpand(t *testing.T) {
+	s, err := NewPostgresStore(getTestConnStr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resetTestDB(s.db)
+	userID := "22222222-2222-2222-2222-222222222222"
+
+	var chartID, insightID, dashID int
+	s.db.QueryRow(`INSERT INTO charts (external_id, title, x_axis_title, y_axis_title, series, description) VALUES ('dash_chart', 'c', 'x', 'y', '[{"name": "s", "values": [1, 2]}]', 'chart desc') RETURNING id`).Scan(&chartID)
+	s.db.QueryRow(`INSERT INTO insights (external_id, text, description) VALUES ('dash_insight', 'i', 'insight desc') RETURNING id`).Scan(&insightID)
+	s.db.QueryRow(`INSERT INTO dashboards (external_id, title, layout, description) VALUES ('dash_1', 'Dash', 'grid', 'd') RETURNING id`).Scan(&dashID)
+	s.db.Exec(`INSERT INTO asset_members (parent_type, parent_id, position, member_type, member_id) VALUES ('dashboard', $1, 0, 'insight', $2), ('dashboard', $1, 1, 'chart', $3)`, dashID, insightID, chartID)
+
+	missing := &models.Dashboard{ExternalID: "dash_1", Title: "Dash", Layout: "grid", Members: []models.AssetRef{
+		{Type: models.AssetTypeChart, ExternalID: "dash_chart"},
+		{Type: models.AssetTypeInsight, ExternalID: "no_such_insight"},
+	}}
+	err = s.AddFavorite(userID, missing)
+	var verrs models.ValidationErrors
+	if !errors.Is(err, ErrValidation) || !errors.As(err, &verrs) || len(verrs) != 1 || verrs[0].Field != "members[1]" {
+		t.Fatalf("expected members[1] to be reported missing, got %v", err)
+	}
+
+	reordered := &models.Dashboard{ExternalID: "dash_1", Title: "Dash", Layout: "grid", Members: []models.AssetRef{
+		{Type: models.AssetTypeChart, ExternalID: "dash_chart"},
+		{Type: models.AssetTypeInsight, ExternalID: "dash_insight"},
+	}}
+	err = s.AddFavorite(userID, reordered)
+	if !errors.Is(err, ErrValidation) || !errors.As(err, &verrs) || len(verrs) != 1 || verrs[0].Field != "members" {
+		t.Fatalf("expected members differing from the catalog to be rejected, got %v", err)
+	}
+
+	// The title and layout sent are ignored in favor of the catalog's
+	dash := &models.Dashboard{ExternalID: "dash_1", Title: "Renamed", Layout: "rows", Members: []models.AssetRef{
+		{Type: models.AssetTypeInsight, ExternalID: "dash_insight"},
+		{Type: models.AssetTypeChart, ExternalID: "dash_chart"},
+	}}
+	if err := s.AddFavorite(userID, dash); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expand := range []bool{false, true} {
+		favs, err := s.ListFavorites(userID, ListOptions{Limit: 10, ExpandMembers: expand})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(favs) != 1 {
+			t.Fatalf("expected 1 favorite, got %d", len(favs))
+		}
+		if d := favs[0].(*models.Dashboard); d.Title != "Dash" || d.Layout != "grid" {
+			t.Errorf("expected the catalog's title and layout, got %q and %q", d.Title, d.Layout)
+		}
+		members := favs[0].(*models.Dashboard).Members
+		if len(members) != 2 || members[0].ExternalID != "dash_insight" || members[1].ExternalID != "dash_chart" {
+			t.Fatalf("expected members in stored order, got %+v", members)
+		}
+		if expanded := members[1].Asset != nil; expanded != expand {
+			t.Errorf("expand=%v: member asset present=%v", expand, expanded)
+		}
+		if expand && members[1].Asset.(*models.Chart).Title != "c" {
+			t.Errorf("expected expanded chart, got %+v", members[1].Asset)
+		}
+	}
+}
+
+func TestGetFavoriteAndTypeFilter(t *testing.T) {
+	s, err := NewPostgresStore(getTestConnStr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resetTestDB(s.db)
+	userID := "22222222-2222-2222-2222-222222222222"
+
+	var insightID, dashID int
+	s.db.Exec(`INSERT INTO charts (external_id, title, x_axis_title, y_axis_title, series, description) VALUES ('get_chart', 'c', 'x', 'y', '[]', 'd')`)
+	s.db.QueryRow(`INSERT INTO insights (external_id, text, description) VALUES ('get_insight', 'i', 'd') RETURNING id`).Scan(&insightID)
+	s.db.QueryRow(`INSERT INTO dashboards (external_id, title, layout, description) VALUES ('get_dash', 'Dash', 'grid', 'd') RETURNING id`).Scan(&dashID)
+	s.db.Exec(`INSERT INTO asset_members (parent_type, parent_id, position, member_type, member_id) VALUES ('dashboard', $1, 0, 'insight', $2)`, dashID, insightID)
+	s.AddFavorite(userID, &models.Chart{ExternalID: "get_chart", Title: "c", Description: "mine"})
+	s.AddFavorite(userID, &models.Insight{ExternalID: "get_insight", Text: "i"})
+	s.AddFavorite(userID, &models.Dashboard{ExternalID: "get_dash", Title: "Dash", Layout: "grid", Members: []models.AssetRef{{Type: models.AssetTypeInsight, ExternalID: "get_insight"}}})
+
+	chart, err := s.GetFavorite(userID, "chart", "get_chart", false)
+	if err != nil || chart.GetDescription() != "mine" || chart.GetVersion() != 1 {
+		t.Fatalf("expected the chart favorite, got %+v err=%v", chart, err)
+	}
+	dash, err := s.GetFavorite(userID, "dashboard", "get_dash", true)
+	if err != nil || dash.(*models.Dashboard).Members[0].Asset == nil {
+		t.Errorf("expected the dashboard with its expanded member, got %+v err=%v", dash, err)
+	}
+	if _, err := s.GetFavorite("33333333-3333-3333-3333-333333333333", "chart", "get_chart", false); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for another user, got %v", err)
+	}
+	s.RemoveFavorite(userID, "chart", "get_chart")
+	if _, err := s.GetFavorite(userID, "chart", "get_chart", false); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a trashed favorite, got %v", err)
+	}
+
+	favs, err := s.ListFavorites(userID, ListOptions{Limit: 10, ManualOrder: true, Types: []models.AssetType{models.AssetTypeInsight, models.AssetTypeDashboard}})
+	if err != nil || len(favs) != 2 {
+		t.Fatalf("expected the insight and dashboard, got %d err=%v", len(favs), err)
+	}
+	for _, f := range favs {
+		if f.GetType() == models.AssetTypeChart {
+			t.Errorf("expected charts to be filtered out, got %+v", f)
+		}
+	}
+}
+
+func TestCatalog_TenantShadowsGlobalAssets(t *testing.T) {
+	s, err := NewPostgresStore(getTestConnStr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resetTestDB(s.db)
+	s.db.Exec(`INSERT INTO insights (tenant_id, external_id, text, description) VALUES (NULL, 'cat_i1', 'global', 'd'), ('acme', 'cat_i1', 'acme', 'd'), (NULL, 'cat_i2', 'global', 'd'), ('other', 'cat_i3', 'other', 'd')`)
+	acme := s.ForTenant("acme")
+
+	assets, err := acme.ListCatalog("insight", ListOptions{Limit: 10})
+	if err != nil || len(assets) != 2 {
+		t.Fatalf("expected 2 insights visible to acme, got %d err=%v", len(assets), err)
+	}
+	if i := assets[0].(*models.Insight); i.ExternalID != "cat_i1" || i.Text != "acme" {
+		t.Errorf("expected acme's cat_i1 to shadow the global one, got %+v", i)
+	}
+	if page, _ := acme.ListCatalog("insight", ListOptions{Limit: 1, Offset: 1}); len(page) != 1 || page[0].GetID() != "cat_i2" {
+		t.Errorf("expected cat_i2 on the second page, got %+v", page)
+	}
+	if _, err := acme.ListCatalog("bogus", ListOptions{Limit: 10}); !errors.Is(err, ErrInvalidType) {
+		t.Errorf("expected ErrInvalidType, got %v", err)
+	}
+
+	if a, err := acme.GetCatalogAsset("insight", "cat_i1", false); err != nil || a.(*models.Insight).Text != "acme" {
+		t.Errorf("expected acme's cat_i1, got %+v err=%v", a, err)
+	}
+	if _, err := acme.GetCatalogAsset("insight", "cat_i3", false); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected another tenant's asset to be hidden, got %v", err)
+	}
+}
+
+func TestCollections_CRUDAndOrdering(t *testing.T) {
+	s, err := NewPostgresStore(getTestConnStr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resetTestDB(s.db)
+	userID := "11111111-1111-1111-1111-111111111111"
+
+	var ids []int
+	for _, name := range []string{"Q3 pitch", "Competitor research", "Archive"} {
+		c := &models.Collection{Name: name}
+		if err := s.CreateCollection(userID, c); err != nil {
+			t.Fatal(err)
+		}
+		if c.Position != len(ids) || c.Version != 1 {
+			t.Errorf("%s: expected position %d and version 1, got %+v", name, len(ids), c)
+		}
+		ids = append(ids, c.ID)
+	}
+	if err := s.CreateCollection(userID, &models.Collection{Name: "q3 PITCH"}); !errors.Is(err, ErrAlreadyExists) {
+		t.Errorf("expected ErrAlreadyExists for a duplicate name, got %v", err)
+	}
+	if err := s.CreateCollection(userID, &models.Collection{}); !errors.Is(err, ErrValidation) {
+		t.Errorf("expected ErrValidation for a missing name, got %v", err)
+	}
+
+	// Move "Archive" to the front and rename it
+	pos, name := 0, "Old work"
+	c, err := s.UpdateCollection(userID, ids[2], models.CollectionUpdate{Name: &name, Position: &pos}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Name != name || c.Position != 0 || c.Version != 2 {
+		t.Errorf("unexpected updated collection: %+v", c)
+	}
+	if _, err := s.UpdateCollection(userID, ids[2], models.CollectionUpdate{Name: &name}, 1); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("expected ErrVersionConflict for a stale version, got %v", err)
+	}
+	if _, err := s.UpdateCollection("22222222-2222-2222-2222-222222222222", ids[2], models.CollectionUpdate{Name: &name}, 0); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for another user's collection, got %v", err)
+	}
+
+	if err := s.DeleteCollection(userID, ids[0]); err != nil {
+		t.Fatal(err)
+	}
+	list, err := s.ListCollections(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for i, c := range list {
+		if c.Position != i {
+			t.Errorf("expected contiguous positions, got %d at %d", c.Position, i)
+		}
+		got = append(got, c.Name)
+	}
+	if strings.Join(got, ",") != "Old work,Competitor research" {
+		t.Errorf("unexpected order %v", got)
+	}
+}
+
+func TestCollections_Favorites(t *testing.T) {
+	s, err := NewPostgresStore(getTestConnStr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resetTestDB(s.db)
+	userID := "11111111-1111-1111-1111-111111111111"
+
+	s.db.Exec(`INSERT INTO insights (external_id, text, description) VALUES ('coll_i1', 't', 'd'), ('coll_i2', 't', 'd')`)
+	for _, id := range []string{"coll_i1", "coll_i2"} {
+		if err := s.AddFavorite(userID, &models.Insight{ExternalID: id, Text: "t", Type: "insight"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pitch, research := &models.Collection{Name: "pitch"}, &models.Collection{Name: "research"}
+	s.CreateCollection(userID, pitch)
+	s.CreateCollection(userID, research)
+
+	// One favorite in two collections; adding twice is a no-op
+	for _, cid := range []int{pitch.ID, research.ID, pitch.ID} {
+		if err := s.AddToCollection(userID, cid, "insight", "coll_i1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.AddToCollection(userID, pitch.ID, "insight", "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a missing favorite, got %v", err)
+	}
+	if err := s.AddToCollection("22222222-2222-2222-2222-222222222222", pitch.ID, "insight", "coll_i1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for another user's collection, got %v", err)
+	}
+
+	favs, err := s.ListCollectionFavorites(userID, pitch.ID, ListOptions{Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(favs) != 1 || favs[0].GetID() != "coll_i1" {
+		t.Errorf("expected only coll_i1 in the collection, got %v", favs)
+	}
+	c, _ := s.GetCollection(userID, research.ID)
+	if c.FavoriteCount != 1 {
+		t.Errorf("expected 1 favorite in research, got %d", c.FavoriteCount)
+	}
+
+	if err := s.RemoveFromCollection(userID, pitch.ID, "insight", "coll_i1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RemoveFromCollection(userID, pitch.ID, "insight", "coll_i1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound when removing twice, got %v", err)
+	}
+
+	// Trashing the favorite takes it out of every collection
+	if err := s.RemoveFavorite(userID, "insight", "coll_i1"); err != nil {
+		t.Fatal(err)
+	}
+	c, _ = s.GetCollection(userID, research.ID)
+	if c.FavoriteCount != 0 {
+		t.Errorf("expected research to be empty, got %d", c.FavoriteCount)
+	}
+}
+
+func TestFavoriteTags(t *testing.T) {
+	s, err := NewPostgresStore(getTestConnStr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resetTestDB(s.db)
+	userID := "11111111-1111-1111-1111-111111111111"
+
+	s.db.Exec(`INSERT INTO insights (external_id, text, description) VALUES ('tag_i1', 't', 'd'), ('tag_i2', 't', 'd')`)
+	if err := s.AddFavorite(userID, &models.Insight{ExternalID: "tag_i1", Text: "t", Tags: []string{"Urgent", "client-x"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddFavorite(userID, &models.Insight{ExternalID: "tag_i2", Text: "t"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tags, v, err := s.AddFavoriteTags(userID, "insight", "tag_i2", []string{"urgent", "URGENT", "q3"}, 1)
+	if err != nil || v != 2 || strings.Join(tags, ",") != "q3,urgent" {
+		t.Fatalf("expected [q3 urgent] at version 2, got %v %d err=%v", tags, v, err)
+	}
+	if _, _, err := s.AddFavoriteTags(userID, "insight", "tag_i2", []string{"late"}, 1); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("expected ErrVersionConflict, got %v", err)
+	}
+	if _, _, err := s.AddFavoriteTags(userID, "insight", "tag_i2", []string{"has space"}, 0); !errors.Is(err, ErrValidation) {
+		t.Errorf("expected ErrValidation, got %v", err)
+	}
+
+	ids := func(opts ListOptions) string {
+		opts.Limit = 10
+		favs, err := s.ListFavorites(userID, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out []string
+		for _, f := range favs {
+			out = append(out, f.GetID())
+		}
+		sort.Strings(out)
+		return strings.Join(out, ",")
+	}
+	if got := ids(ListOptions{Tags: []string{"client-x", "q3"}}); got != "tag_i1,tag_i2" {
+		t.Errorf("expected both favorites matching any tag, got %s", got)
+	}
+	if got := ids(ListOptions{Tags: []string{"urgent", "q3"}, MatchAllTags: true}); got != "tag_i2" {
+		t.Errorf("expected only tag_i2 matching all tags, got %s", got)
+	}
+
+	counts, err := s.ListTags(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(counts) != 3 || counts[0] != (models.TagCount{Tag: "urgent", Count: 2}) {
+		t.Errorf("unexpected tag counts %+v", counts)
+	}
+
+	if _, _, err := s.RemoveFavoriteTag(userID, "insight", "tag_i1", " Urgent ", 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.RemoveFavoriteTag(userID, "insight", "tag_i1", "urgent", 0); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound removing a missing tag, got %v", err)
+	}
+}
+
+func TestFavoriteOrder_PinAndMove(t *testing.T) {
+	s, err := NewPostgresStore(getTestConnStr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resetTestDB(s.db)
+	userID := "11111111-1111-1111-1111-111111111111"
+
+	s.db.Exec(`INSERT INTO insights (external_id, text, description) VALUES ('ord_i1', 't', 'd'), ('ord_i2', 't', 'd'), ('ord_i3', 't', 'd')`)
+	s.db.Exec(`INSERT INTO charts (external_id, title, x_axis_title, y_axis_title, description) VALUES ('ord_c1', 't', 'x', 'y', 'd')`)
+	for _, id := range []string{"ord_i1", "ord_c1", "ord_i2", "ord_i3"} {
+		var a models.Asset = &models.Insight{ExternalID: id, Text: "t"}
+		if id == "ord_c1" {
+			a = &models.Chart{ExternalID: id, Title: "t"}
+		}
+		if err := s.AddFavorite(userID, a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	order := func(offset, limit int) string {
+		favs, err := s.ListFavorites(userID, ListOptions{Limit: limit, Offset: offset, ManualOrder: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out []string
+		for _, f := range favs {
+			id := f.GetID()
+			if f.GetPinned() {
+				id += "*"
+			}
+			out = append(out, id)
+		}
+		return strings.Join(out, ",")
+	}
+	if got := order(0, 10); got != "ord_i1,ord_c1,ord_i2,ord_i3" {
+		t.Fatalf("expected insertion order, got %s", got)
+	}
+
+	if _, _, err := s.MoveFavorite(userID, "insight", "ord_i3", models.FavoriteMove{Before: &models.FavoriteRef{Type: "insight", ExternalID: "ord_i1"}}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.SetFavoritePinned(userID, "insight", "ord_i2", true, 0); err != nil {
+		t.Fatal(err)
+	}
+	if got := order(0, 10); got != "ord_i2*,ord_i3,ord_i1,ord_c1" {
+		t.Fatalf("unexpected order after move and pin: %s", got)
+	}
+	if got := order(1, 2); got != "ord_i3,ord_i1" {
+		t.Errorf("expected a page across types, got %s", got)
+	}
+	keyed, err := s.ListKeyedFavorites(userID, ListOptions{Limit: 1})
+	if err != nil || len(keyed) != 1 || keyed[0].Asset.GetID() != "ord_i2" || !keyed[0].Key.Pinned {
+		t.Fatalf("expected the pinned favorite first, got %+v err=%v", keyed, err)
+	}
+	keyed, err = s.ListKeyedFavorites(userID, ListOptions{Limit: 2, After: &keyed[0].Key})
+	if err != nil || len(keyed) != 2 || keyed[0].Asset.GetID() != "ord_i3" || keyed[1].Asset.GetID() != "ord_i1" {
+		t.Errorf("expected the page after the pinned favorite to cross into the unpinned ones, got %+v err=%v", keyed, err)
+	}
+
+	// Moving next to a pinned favorite pins the moved one
+	pinned, version, err := s.MoveFavorite(userID, "chart", "ord_c1", models.FavoriteMove{After: &models.FavoriteRef{Type: "insight", ExternalID: "ord_i2"}}, 1)
+	if err != nil || !pinned || version != 2 {
+		t.Fatalf("expected chart pinned at version 2, got %v %d err=%v", pinned, version, err)
+	}
+	if _, _, err := s.MoveFavorite(userID, "chart", "ord_c1", models.FavoriteMove{After: &models.FavoriteRef{Type: "insight", ExternalID: "ord_i3"}}, 1); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("expected ErrVersionConflict, got %v", err)
+	}
+	if _, _, err := s.MoveFavorite(userID, "chart", "ord_c1", models.FavoriteMove{After: &models.FavoriteRef{Type: "insight", ExternalID: "missing"}}, 0); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a missing anchor, got %v", err)
+	}
+	if _, err := s.SetFavoritePinned(userID, "insight", "ord_i2", false, 0); err != nil {
+		t.Fatal(err)
+	}
+	if got := order(0, 10); got != "ord_c1*,ord_i2,ord_i3,ord_i1" {
+		t.Errorf("expected unpinned favorite below the pins, got %s", got)
+	}
+}
+
+func TestPlaceBetween(t *testing.T) {
+	order := []orderSlot{{pinned: true, position: 5}, {position: 1}, {position: 2}, {position: 2}}
+	if p, ok := placeBetween(order, 1); !ok || p != 1 {
+		t.Errorf("expected to keep the only position in the group, got %v %v", p, ok)
+	}
+	order = []orderSlot{{pinned: true, position: 5}, {position: 1}, {position: 0}, {position: 2}}
+	if p, ok := placeBetween(order, 2); !ok || p != 1.5 {
+		t.Errorf("expected the midpoint, got %v %v", p, ok)
+	}
+	if p, ok := placeBetween(order, 1); !ok || p != -1 {
+		t.Errorf("expected one before the next favorite in the group, got %v %v", p, ok)
+	}
+	order = []orderSlot{{position: 2}, {position: 0}, {position: 2}}
+	if _, ok := placeBetween(order, 1); ok {
+		t.Error("expected no room between equal positions")
+	}
+}
+
+func TestShares_AccessAndRevocation(t *testing.T) {
+	s, err := NewPostgresStore(getTestConnStr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resetTestDB(s.db)
+	owner := "11111111-1111-1111-1111-111111111111"
+	viewer := "22222222-2222-2222-2222-222222222222"
+	editor := "33333333-3333-3333-3333-333333333333"
+
+	s.db.Exec(`INSERT INTO insights (external_id, text, description) VALUES ('share_i1', 't', 'd')`)
+	if err := s.AddFavorite(owner, &models.Insight{ExternalID: "share_i1", Text: "t", Description: "mine"}); err != nil {
+		t.Fatal(err)
+	}
+	c := &models.Collection{Name: "pitch"}
+	s.CreateCollection(owner, c)
+	s.AddToCollection(owner, c.ID, "insight", "share_i1")
+
+	view, err := s.ShareFavorite(owner, "insight", "share_i1", models.ShareRequest{UserID: viewer, Permission: models.PermissionView})
+	if err != nil {
+		t.Fatal(err)
+	}
+	edit, err := s.ShareCollection(owner, c.ID, models.ShareRequest{UserID: editor, Permission: models.PermissionEdit})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ShareCollection(viewer, c.ID, models.ShareRequest{UserID: editor, Permission: models.PermissionEdit}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound sharing someone else's collection, got %v", err)
+	}
+
+	items, err := s.ListSharedWithMe(viewer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].Asset.GetID() != "share_i1" || items[0].Share.OwnerID != owner {
+		t.Fatalf("unexpected shared items %+v", items)
+	}
+	if _, err := s.GetSharedItem(editor, view.ID, ListOptions{Limit: 10}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a share granted to someone else, got %v", err)
+	}
+	item, err := s.GetSharedItem(editor, edit.ID, ListOptions{Limit: 10})
+	if err != nil || item.Collection.Name != "pitch" || len(item.Favorites) != 1 {
+		t.Fatalf("unexpected shared collection %+v err=%v", item, err)
+	}
+
+	desc, name := "theirs", "Q3 pitch"
+	if _, err := s.EditSharedItem(viewer, view.ID, models.SharedItemUpdate{Description: &desc}, 0); !errors.Is(err, ErrForbidden) {
+		t.Errorf("expected ErrForbidden for a viewer, got %v", err)
+	}
+	item, err = s.EditSharedItem(editor, edit.ID, models.SharedItemUpdate{Name: &name}, 1)
+	if err != nil || item.Collection.Name != "Q3 pitch" || item.Collection.Version != 2 {
+		t.Fatalf("expected renamed collection at version 2, got %+v err=%v", item, err)
+	}
+	if _, err := s.EditSharedItem(editor, edit.ID, models.SharedItemUpdate{Name: &name}, 1); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("expected ErrVersionConflict, got %v", err)
+	}
+
+	// Re-sharing upgrades the existing share instead of adding another
+	again, err := s.ShareFavorite(owner, "insight", "share_i1", models.ShareRequest{UserID: viewer, Permission: models.PermissionEdit})
+	if err != nil || again.ID != view.ID {
+		t.Fatalf("expected share %d to be updated, got %+v err=%v", view.ID, again, err)
+	}
+	if _, err := s.EditSharedItem(viewer, view.ID, models.SharedItemUpdate{Description: &desc}, 0); err != nil {
+		t.Errorf("expected the upgraded viewer to edit, got %v", err)
+	}
+
+	if err := s.RevokeShare(viewer, view.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected only the owner to revoke, got %v", err)
+	}
+	if err := s.RevokeShare(owner, view.ID); err != nil {
+		t.Fatal(err)
+	}
+	if items, _ := s.ListSharedWithMe(viewer); len(items) != 0 {
+		t.Errorf("expected nothing shared after revoking, got %+v", items)
+	}
+}
+
+func TestShares_Links(t *testing.T) {
+	s, err := NewPostgresStore(getTestConnStr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resetTestDB(s.db)
+	owner := "11111111-1111-1111-1111-111111111111"
+
+	s.db.Exec(`INSERT INTO insights (external_id, text, description) VALUES ('link_i1', 't', 'd')`)
+	if err := s.AddFavorite(owner, &models.Insight{ExternalID: "link_i1", Text: "t"}); err != nil {
+		t.Fatal(err)
+	}
+	link, err := s.ShareFavorite(owner, "insight", "link_i1", models.ShareRequest{Link: true, Permission: models.PermissionView})
+	if err != nil || link.Token == "" || link.ExpiresAt == nil {
+		t.Fatalf("expected a link with a token and expiry, got %+v err=%v", link, err)
+	}
+	item, err := s.OpenShareLink(link.Token, ListOptions{Limit: 10})
+	if err != nil || item.Asset.GetID() != "link_i1" {
+		t.Fatalf("expected the shared favorite, got %+v err=%v", item, err)
+	}
+	if _, err := s.OpenShareLink("not-a-token", ListOptions{Limit: 10}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for an unknown token, got %v", err)
+	}
+
+	shares, err := s.ListFavoriteShares(owner, "insight", "link_i1")
+	if err != nil || len(shares) != 1 || shares[0].Token != "" {
+		t.Errorf("expected one listed link without its token, got %+v err=%v", shares, err)
+	}
+
+	s.db.Exec(`UPDATE shares SET expires_at = now() - interval '1 minute' WHERE id = $1`, link.ID)
+	if _, err := s.OpenShareLink(link.Token, ListOptions{Limit: 10}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for an expired link, got %v", err)
+	}
+}
+
+func TestTrash_RemoveRestoreAndPurge(t *testing.T) {
+	s, err := NewPostgresStore(getTestConnStr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resetTestDB(s.db)
+	userID := "11111111-1111-1111-1111-111111111111"
+
+	s.db.Exec(`INSERT INTO insights (external_id, text, description) VALUES ('trash_i1', 't', 'd'), ('trash_i2', 't', 'd')`)
+	for _, id := range []string{"trash_i1", "trash_i2"} {
+		if err := s.AddFavorite(userID, &models.Insight{ExternalID: id, Text: "t", Description: "mine", Tags: []string{"keep"}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.RemoveFavorite(userID, "insight", "trash_i1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RemoveFavorite(userID, "insight", "trash_i1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound removing a trashed favorite, got %v", err)
+	}
+	if favs, _ := s.ListFavorites(userID, ListOptions{Limit: 10}); len(favs) != 1 {
+		t.Errorf("expected the trashed favorite to be left out, got %d favorites", len(favs))
+	}
+	if counts, _ := s.ListTags(userID); len(counts) != 1 || counts[0].Count != 1 {
+		t.Errorf("expected tag counts without the trashed favorite, got %+v", counts)
+	}
+	trash, err := s.ListTrash(userID, ListOptions{Limit: 10})
+	if err != nil || len(trash) != 1 || trash[0].Favorite.GetID() != "trash_i1" || trash[0].DeletedAt.IsZero() {
+		t.Fatalf("expected trash_i1 in the trash, got %+v err=%v", trash, err)
+	}
+
+	if _, err := s.RestoreFavorite(userID, "insight", "trash_i2"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound restoring a favorite that is not trashed, got %v", err)
+	}
+	if _, err := s.RestoreFavorite(userID, "insight", "trash_i1"); err != nil {
+		t.Fatal(err)
+	}
+	favs, _ := s.ListFavorites(userID, ListOptions{Limit: 10})
+	if len(favs) != 2 || favs[0].GetDescription() != "mine" {
+		t.Errorf("expected the restored favorite with its description, got %+v", favs)
+	}
+
+	// Adding a trashed favorite again replaces it rather than conflicting
+	s.RemoveFavorite(userID, "insight", "trash_i1")
+	if err := s.AddFavorite(userID, &models.Insight{ExternalID: "trash_i1", Text: "t", Description: "fresh"}); err != nil {
+		t.Fatalf("expected re-adding a trashed favorite to succeed, got %v", err)
+	}
+	if trash, _ := s.ListTrash(userID, ListOptions{Limit: 10}); len(trash) != 0 {
+		t.Errorf("expected the replaced favorite to leave the trash, got %d", len(trash))
+	}
+
+	s.RemoveFavorite(userID, "insight", "trash_i2")
+	s.db.Exec(`UPDATE favorites SET deleted_at = now() - interval '31 days' WHERE deleted_at IS NOT NULL`)
+	if n, err := s.PurgeTrash(30 * 24 * time.Hour); err != nil || n != 1 {
+		t.Errorf("expected 1 purged favorite, got %d err=%v", n, err)
+	}
+	if _, err := s.RestoreFavorite(userID, "insight", "trash_i2"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound restoring a purged favorite, got %v", err)
+	}
+}
+
+func TestAuditTrail_RecordsFavoriteChanges(t *testing.T) {
+	s, err := NewPostgresStore(getTestConnStr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resetTestDB(s.db)
+	userID := "11111111-1111-1111-1111-111111111111"
+	adminID := "99999999-9999-9999-9999-999999999999"
+
+	s.db.Exec(`INSERT INTO insights (external_id, text, description) VALUES ('audit_i1', 't', 'd')`)
+	if err := s.AddFavorite(userID, &models.Insight{ExternalID: "audit_i1", Text: "t", Description: "first"}); err != nil {
+		t.Fatal(err)
+	}
+	asAdmin := s.AuditAs(adminID, "req-1")
+	if _, err := asAdmin.EditFavoriteDescription(userID, "insight", "audit_i1", "second", 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RemoveFavorite(userID, "insight", "audit_i1"); err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := s.ListAuditEvents(AuditFilter{UserID: userID, EntityID: models.FavoriteEntityID("insight", "audit_i1"), Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{models.ActionFavoriteRemoved, models.ActionFavoriteDescribed, models.ActionFavoriteAdded}
+	if len(events) != len(want) {
+		t.Fatalf("expected %d events, got %+v", len(want), events)
+	}
+	for i, e := range events {
+		if e.Action != want[i] {
+			t.Errorf("event %d: expected %s, got %s", i, want[i], e.Action)
+		}
+	}
+
+	described := events[1]
+	if described.ActorID != adminID || described.RequestID != "req-1" || described.UserID != userID {
+		t.Errorf("expected the admin as actor of the user's event, got %+v", described)
+	}
+	var before, after struct{ Description string }
+	json.Unmarshal(described.Before, &before)
+	json.Unmarshal(described.After, &after)
+	if before.Description != "first" || after.Description != "second" {
+		t.Errorf("expected before/after descriptions, got %s -> %s", described.Before, described.After)
+	}
+	if events[0].ActorID != userID {
+		t.Errorf("expected the owner as actor without AuditAs, got %q", events[0].ActorID)
+	}
+
+	if n, _ := s.ListAuditEvents(AuditFilter{ActorID: adminID, Limit: 10}); len(n) != 1 {
+		t.Errorf("expected 1 event by the admin, got %d", len(n))
+	}
+	if other, _ := s.ForTenant("acme").ListAuditEvents(AuditFilter{Limit: 10}); len(other) != 0 {
+		t.Errorf("expected no events in another tenant, got %d", len(other))
+	}
+
+	if _, err := s.db.Exec(`DELETE FROM audit_events`); err == nil {
+		t.Error("expected audit events to be append-only")
+	}
+}
+
+func TestWebhooks_OutboxAndDeliveries(t *testing.T) {
+	s, err := NewPostgresStore(getTestConnStr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resetTestDB(s.db)
+	userID := "11111111-1111-1111-1111-111111111111"
+	adminID := "33333333-3333-3333-3333-333333333333"
+
+	all, err := s.CreateWebhook(adminID, models.WebhookRequest{URL: "https://all.example.com/hook"})
+	if err != nil || all.Secret == "" {
+		t.Fatalf("expected a webhook with a secret, got %+v err=%v", all, err)
+	}
+	s.CreateWebhook(adminID, models.WebhookRequest{URL: "https://removals.example.com/hook", Events: []string{models.EventFavoriteRemoved}})
+	s.ForTenant("acme").CreateWebhook(adminID, models.WebhookRequest{URL: "https://acme.example.com/hook"})
+	if _, err := s.CreateWebhook(adminID, models.WebhookRequest{URL: "not a url"}); !errors.Is(err, ErrValidation) {
+		t.Errorf("expected ErrValidation, got %v", err)
+	}
+
+	s.db.Exec(`INSERT INTO insights (external_id, text, description) VALUES ('hook_i1', 't', 'd')`)
+	if err := s.AddFavorite(userID, &models.Insight{ExternalID: "hook_i1", Text: "t", Description: "first"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.EditFavoriteDescription(userID, "insight", "hook_i1", "second", 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.EditFavoriteDescription(userID, "insight", "hook_i1", "stale", 1); !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("expected ErrVersionConflict, got %v", err)
+	}
+	if err := s.RemoveFavorite(userID, "insight", "hook_i1"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Three events: one to both webhooks, two to the one taking every event, none to acme's
+	if n, err := s.EnqueueWebhookDeliveries(100); err != nil || n != 4 {
+		t.Fatalf("expected 4 deliveries, got %d err=%v", n, err)
+	}
+	if n, _ := s.EnqueueWebhookDeliveries(100); n != 0 {
+		t.Errorf("expected events to be enqueued once, got %d more", n)
+	}
+	claimed, err := s.ClaimWebhookDeliveries(100, time.Minute)
+	if err != nil || len(claimed) != 4 {
+		t.Fatalf("expected 4 claimed deliveries, got %d err=%v", len(claimed), err)
+	}
+	types := map[string]int{}
+	var removal PendingDelivery
+	for _, p := range claimed {
+		types[p.Event.Type]++
+		if p.Attempts != 1 || p.Event.UserID != userID || p.Event.Data.ExternalID != "hook_i1" {
+			t.Errorf("unexpected delivery %+v", p)
+		}
+		if p.URL == "https://removals.example.com/hook" {
+			removal = p
+		}
+	}
+	if types[models.EventFavoriteAdded] != 1 || types[models.EventFavoriteUpdated] != 1 || types[models.EventFavoriteRemoved] != 2 {
+		t.Errorf("unexpected event types %v", types)
+	}
+	if again, _ := s.ClaimWebhookDeliveries(100, time.Minute); len(again) != 0 {
+		t.Errorf("expected leased deliveries to stay hidden, got %d", len(again))
+	}
+
+	s.DeadLetterWebhookDelivery(removal.ID, "receiver responded 500")
+	webhooks, _ := s.ListWebhooks()
+	if len(webhooks) != 2 || webhooks[0].Secret != "" {
+		t.Fatalf("expected 2 webhooks without secrets, got %+v", webhooks)
+	}
+	removals := webhooks[1].ID
+	dead, err := s.ListWebhookDeliveries(removals, models.DeliveryDead, ListOptions{Limit: 10})
+	if err != nil || len(dead) != 1 || dead[0].LastError != "receiver responded 500" || dead[0].EventType != models.EventFavoriteRemoved {
+		t.Fatalf("expected the dead-lettered delivery, got %+v err=%v", dead, err)
+	}
+	if _, err := s.ForTenant("acme").ReplayWebhookDelivery(removals, removal.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected another tenant's webhook to be hidden, got %v", err)
+	}
+	replayed, err := s.ReplayWebhookDelivery(removals, removal.ID)
+	if err != nil || replayed.Status != models.DeliveryPending || replayed.Attempts != 0 || replayed.NextAttemptAt == nil {
+		t.Fatalf("expected a pending delivery, got %+v err=%v", replayed, err)
+	}
+	if again, _ := s.ClaimWebhookDeliveries(100, time.Minute); len(again) != 1 || again[0].ID != removal.ID {
+		t.Errorf("expected the replayed delivery to be due, got %+v", again)
+	}
+
+	if err := s.DeleteWebhook(adminID, removals); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ListWebhookDeliveries(removals, "", ListOptions{Limit: 10}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a deleted webhook, got %v", err)
+	}
+	if err := s.DeleteWebhook(adminID, removals); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound deleting twice, got %v", err)
+	}
+
+	// Creating and deleting webhooks is audited under the admin, without the secret
+	audited, err := s.ListAuditEvents(AuditFilter{EntityType: models.AuditEntityWebhook, Limit: 10})
+	if err != nil || len(audited) != 3 {
+		t.Fatalf("expected 2 creations and a deletion, got %+v err=%v", audited, err)
+	}
+	deleted := audited[0]
+	if deleted.Action != models.ActionWebhookDeleted || deleted.UserID != adminID || deleted.ActorID != adminID ||
+		deleted.EntityID != strconv.Itoa(removals) || deleted.After != nil || !strings.Contains(string(deleted.Before), "removals.example.com") {
+		t.Errorf("unexpected deletion event %+v", deleted)
+	}
+	if created := audited[2]; created.Action != models.ActionWebhookCreated || strings.Contains(string(created.After), all.Secret) {
+		t.Errorf("unexpected creation event %+v", created)
+	}
+}
+
+func TestFavoriteEvents_NotifyAndResume(t *testing.T) {
+	s, err := NewPostgresStore(getTestConnStr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resetTestDB(s.db)
+	userID := "11111111-1111-1111-1111-111111111111"
+	otherID := "22222222-2222-2222-2222-222222222222"
+
+	sub, err := s.SubscribeFavoriteEvents(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+	other, _ := s.SubscribeFavoriteEvents(otherID)
+	defer other.Close()
+	head, _ := s.LastFavoriteEventID(userID)
+
+	s.db.Exec(`INSERT INTO insights (external_id, text, description) VALUES ('sse_i1', 't', 'd')`)
+	if err := s.AddFavorite(userID, &models.Insight{ExternalID: "sse_i1", Text: "t", Description: "first"}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-sub.C:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected a wake-up after adding a favorite")
+	}
+	select {
+	case <-other.C:
+		t.Error("expected another user's subscription to stay quiet")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	s.EditFavoriteDescription(userID, "insight", "sse_i1", "second", 0)
+	events, err := s.ListFavoriteEvents(userID, head, 10)
+	if err != nil || len(events) != 2 {
+		t.Fatalf("expected 2 events after the head, got %+v err=%v", events, err)
+	}
+	if events[0].Type != models.EventFavoriteAdded || events[1].Type != models.EventFavoriteUpdated || events[1].Data.Description != "second" {
+		t.Errorf("unexpected events %+v", events)
+	}
+	if rest, _ := s.ListFavoriteEvents(userID, events[0].ID, 10); len(rest) != 1 || rest[0].ID != events[1].ID {
+		t.Errorf("expected to resume after the first event, got %+v", rest)
+	}
+	if last, _ := s.LastFavoriteEventID(userID); last != events[1].ID {
+		t.Errorf("expected the last event ID %d, got %d", events[1].ID, last)
+	}
+	if _, err := s.ForTenant("acme").ListFavoriteEvents(userID, events[0].ID, 10); !errors.Is(err, ErrEventsExpired) {
+		t.Errorf("expected another tenant's event to be unknown, got %v", err)
+	}
+
+	// An event of a transaction still open holds back the events committed after it
+	tx, err := s.db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Exec(`INSERT INTO outbox_events (tenant_id, user_id, event_type, data) VALUES ($1, $2, $3, '{}')`,
+		s.tenantID, userID, string(models.EventFavoriteUpdated)); err != nil {
+		t.Fatal(err)
+	}
+	s.EditFavoriteDescription(userID, "insight", "sse_i1", "third", 0)
+	if held, err := s.ListFavoriteEvents(userID, events[1].ID, 10); err != nil || len(held) != 0 {
+		t.Errorf("expected the committed event to wait for the open transaction, got %+v err=%v", held, err)
+	}
+	if last, _ := s.LastFavoriteEventID(userID); last != events[1].ID {
+		t.Errorf("expected the last event ID to stay %d, got %d", events[1].ID, last)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if late, err := s.ListFavoriteEvents(userID, events[1].ID, 10); err != nil || len(late) != 2 || late[0].ID > late[1].ID {
+		t.Errorf("expected the late event and then the held one, got %+v err=%v", late, err)
+	}
+
+	// Purging only takes events already handed to webhooks
+	if n, _ := s.PurgeFavoriteEvents(0); n != 0 {
+		t.Errorf("expected undispatched events to be kept, purged %d", n)
+	}
+	s.EnqueueWebhookDeliveries(100)
+	if n, err := s.PurgeFavoriteEvents(0); err != nil || n < 2 {
+		t.Errorf("expected both events to be purged, got %d err=%v", n, err)
+	}
+	if _, err := s.ListFavoriteEvents(userID, events[0].ID, 10); !errors.Is(err, ErrEventsExpired) {
+		t.Errorf("expected ErrEventsExpired resuming after a purged event, got %v", err)
+	}
+}
+
+func TestFavoriteEvents_TagPinAndMovePublish(t *testing.T) {
+	s, err := NewPostgresStore(getTestConnStr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resetTestDB(s.db)
+	userID := "11111111-1111-1111-1111-111111111111"
+
+	s.db.Exec(`INSERT INTO insights (external_id, text, description) VALUES ('pub_i1', 't', 'd'), ('pub_i2', 't', 'd')`)
+	for _, id := range []string{"pub_i1", "pub_i2"} {
+		if err := s.AddFavorite(userID, &models.Insight{ExternalID: id, Text: "t"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	head, _ := s.LastFavoriteEventID(userID)
+	sub, err := s.SubscribeFavoriteEvents(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+
+	if _, _, err := s.AddFavoriteTags(userID, "insight", "pub_i1", []string{"kpi"}, 0); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-sub.C:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected a wake-up after tagging a favorite")
+	}
+	var outbox int
+	s.db.QueryRow(`SELECT count(*) FROM outbox_events WHERE user_id = $1 AND event_type = $2 AND data->'tags' ? 'kpi'`,
+		userID, models.EventFavoriteUpdated).Scan(&outbox)
+	if outbox != 1 {
+		t.Errorf("expected an outbox row for the tag change, got %d", outbox)
+	}
+
+	s.SetFavoritePinned(userID, "insight", "pub_i2", true, 0)
+	s.MoveFavorite(userID, "insight", "pub_i1", models.FavoriteMove{Before: &models.FavoriteRef{Type: "insight", ExternalID: "pub_i2"}}, 0)
+	events, err := s.ListFavoriteEvents(userID, head, 10)
+	if err != nil || len(events) != 3 {
+		t.Fatalf("expected tag, pin and move events, got %+v err=%v", events, err)
+	}
+	for _, e := range events {
+		if e.Type != models.EventFavoriteUpdated {
+			t.Errorf("expected %s, got %+v", models.EventFavoriteUpdated, e)
+		}
+	}
+	if events[0].Data.ExternalID != "pub_i1" || len(events[0].Data.Tags) != 1 || events[1].Data.ExternalID != "pub_i2" {
+		t.Errorf("unexpected events %+v", events)
+	}
+}
+
+func TestTenants_Isolation(t *testing.T) {
+	s, err := NewPostgresStore(getTestConnStr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resetTestDB(s.db)
+	user := "11111111-1111-1111-1111-111111111111"
+	acme, globex := s.ForTenant("acme"), s.ForTenant("globex")
+
+	s.db.Exec(`INSERT INTO insights (tenant_id, external_id, text, description) VALUES (NULL, 'tenant_i1', 'global', 'd'), ('acme', 'tenant_i1', 'acme', 'd'), ('acme', 'tenant_i2', 'acme', 'd')`)
+	for _, id := range []string{"tenant_i1", "tenant_i2"} {
+		if err := acme.AddFavorite(user, &models.Insight{ExternalID: id, Text: "t"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	favs, err := acme.ListFavorites(user, ListOptions{Limit: 10})
+	if err != nil || len(favs) != 2 {
+		t.Fatalf("expected acme's 2 favorites, got %d err=%v", len(favs), err)
+	}
+	for _, f := range favs {
+		if text := f.(*models.Insight).Text; text != "acme" {
+			t.Errorf("expected the tenant's own assets over the global one, got %q", text)
+		}
+	}
+
+	if favs, _ := globex.ListFavorites(user, ListOptions{Limit: 10}); len(favs) != 0 {
+		t.Errorf("expected another tenant to see no favorites, got %d", len(favs))
+	}
+	if err := globex.AddFavorite(user, &models.Insight{ExternalID: "tenant_i2", Text: "t"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound favoriting another tenant's asset, got %v", err)
+	}
+	if err := globex.RemoveFavorite(user, "insight", "tenant_i1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound removing another tenant's favorite, got %v", err)
+	}
+	if err := globex.AddFavorite(user, &models.Insight{ExternalID: "tenant_i1", Text: "t"}); err != nil {
+		t.Errorf("expected the global catalog to be visible to every tenant, got %v", err)
+	}
+	if favs, _ := s.ListFavorites(user, ListOptions{Limit: 10}); len(favs) != 0 {
+		t.Errorf("expected the default tenant to see no favorites, got %d", len(favs))
+	}
+}
+
+func TestEditFavoriteDescription_CompareAndSwap(t *testing.T) {
+	s, err := NewPostgresStore(getTestConnStr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resetTestDB(s.db)
+	userID := "11111111-1111-1111-1111-111111111111"
+
+	s.db.Exec(`INSERT INTO insights (external_id, text, description) VALUES ('cas_insight', 'some text', 'desc')`)
+	if err := s.AddFavorite(userID, &models.Insight{ExternalID: "cas_insight", Text: "some text", Description: "desc"}); err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := s.EditFavoriteDescription(userID, "insight", "cas_insight", "first tab", 1)
+	if err != nil || v != 2 {
+		t.Fatalf("expected version 2, got %d err=%v", v, err)
+	}
+
+	// A second tab still holding version 1 must lose
+	if _, err := s.EditFavoriteDescription(userID, "insight", "cas_insight", "second tab", 1); !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("expected ErrVersionConflict, got %v", err)
+	}
+
+	v, err = s.EditFavoriteDescription(userID, "insight", "cas_insight", "unconditional", 0)
+	if err != nil || v != 3 {
+		t.Fatalf("expected version 3, got %d err=%v", v, err)
+	}
+
+	favs, err := s.ListFavorites(userID, ListOptions{Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(favs) != 1 || favs[0].GetDescription() != "unconditional" {
+		t.Errorf("expected edited description in list, got %+v", favs)
+	}
+
+	if _, err := s.EditFavoriteDescription("33333333-3333-3333-3333-333333333333", "insight", "cas_insight", "x", 1); err == nil || errors.Is(err, ErrVersionConflict) {
+		t.Errorf("expected not found for another user's favorite, got %v", err)
+	}
+}
+
+func TestIdempotencyKey_ReserveCompleteReplay(t *testing.T) {
+	s, err := NewPostgresStore(getTestConnStr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resetTestDB(s.db)
+	userID := "11111111-1111-1111-1111-111111111111"
+
+	reservation, rec, err := s.ReserveIdempotencyKey(userID, "key-1", "hash-a", time.Hour, time.Minute)
+	if err != nil || rec != nil || reservation == "" {
+		t.Fatalf("expected fresh reservation, got rec=%v err=%v", rec, err)
+	}
+
+	_, rec, err = s.ReserveIdempotencyKey(userID, "key-1", "hash-a", time.Hour, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec == nil || !rec.InProgress() {
+		t.Fatalf("expected in-progress record, got %+v", rec)
+	}
+
+	headers := map[string]string{"ETag": `"1"`, "Location": "/v1/users/" + userID + "/favorites/chart_1"}
+	if err := s.CompleteIdempotencyKey(userID, "key-1", reservation, 201, "application/json", headers, []byte(`{"status":"success"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.CompleteIdempotencyKey(userID, "key-1", reservation, 200, "application/json", nil, []byte(`{}`)); !errors.Is(err, ErrReservationLost) {
+		t.Errorf("expected a completed key not to be completed again, got %v", err)
+	}
+	_, rec, err = s.ReserveIdempotencyKey(userID, "key-1", "hash-b", time.Hour, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.StatusCode != 201 || rec.RequestHash != "hash-a" || string(rec.Body) != `{"status":"success"}` || !reflect.DeepEqual(rec.Headers, headers) {
+		t.Errorf("unexpected stored record: %+v", rec)
+	}
+}
+
+func TestIdempotencyKey_StaleReservationCanBeReused(t *testing.T) {
+	s, err := NewPostgresStore(getTestConnStr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resetTestDB(s.db)
+	userID := "11111111-1111-1111-1111-111111111111"
+
+	stale, _, err := s.ReserveIdempotencyKey(userID, "key-3", "hash-a", time.Hour, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, rec, err := s.ReserveIdempotencyKey(userID, "key-3", "hash-a", time.Hour, time.Minute); err != nil || rec == nil || !rec.InProgress() {
+		t.Fatalf("expected the reservation to hold within its lease, got rec=%+v err=%v", rec, err)
+	}
+	// The request holding the key crashed, or is slow, and its lease ran out
+	s.db.Exec(`UPDATE idempotency_keys SET lease_expires_at = now() - interval '1 second'`)
+	reservation, rec, err := s.ReserveIdempotencyKey(userID, "key-3", "hash-a", time.Hour, time.Minute)
+	if err != nil || rec != nil || reservation == stale {
+		t.Fatalf("expected a stale reservation to be reusable, got rec=%+v err=%v", rec, err)
+	}
+	// The original request finishing late neither stores its response nor frees the key
+	if err := s.CompleteIdempotencyKey(userID, "key-3", stale, 202, "application/json", nil, []byte(`{}`)); !errors.Is(err, ErrReservationLost) {
+		t.Errorf("expected ErrReservationLost for a reservation taken over, got %v", err)
+	}
+	s.ReleaseIdempotencyKey(userID, "key-3", stale)
+
+	// Completed responses outlive the lease
+	if err := s.CompleteIdempotencyKey(userID, "key-3", reservation, 201, "application/json", nil, []byte(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+	s.db.Exec(`UPDATE idempotency_keys SET lease_expires_at = now() - interval '1 second'`)
+	if _, rec, err := s.ReserveIdempotencyKey(userID, "key-3", "hash-a", time.Hour, time.Minute); err != nil || rec == nil || rec.StatusCode != 201 {
+		t.Errorf("expected the stored response, got rec=%+v err=%v", rec, err)
+	}
+}
+
+func TestIdempotencyKey_ExpiredKeyCanBeReused(t *testing.T) {
+	s, err := NewPostgresStore(getTestConnStr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resetTestDB(s.db)
+	userID := "11111111-1111-1111-1111-111111111111"
+
+	if _, _, err := s.ReserveIdempotencyKey(userID, "key-2", "hash-a", time.Hour, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	s.db.Exec(`UPDATE idempotency_keys SET expires_at = now() - interval '1 minute'`)
+
+	reservation, rec, err := s.ReserveIdempotencyKey(userID, "key-2", "hash-b", time.Hour, time.Minute)
+	if err != nil || rec != nil {
+		t.Fatalf("expected expired key to be reusable, got rec=%v err=%v", rec, err)
+	}
+
+	if err := s.ReleaseIdempotencyKey(userID, "key-2", reservation); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := s.PurgeExpiredIdempotencyKeys(); err != nil || n != 0 {
+		t.Errorf("expected nothing left to purge, got n=%d err=%v", n, err)
+	}
+}
+
+func TestExportFavorites(t *testing.T) {
+	s, err := NewPostgresStore(getTestConnStr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resetTestDB(s.db)
+	userID := "11111111-1111-1111-1111-111111111111"
+
+	// More insights than one cursor batch holds
+	s.db.Exec(`INSERT INTO insights (external_id, text, description) SELECT 'export_i' || n, 't', 'd' FROM generate_series(1, 250) n`)
+	for n := 1; n <= 250; n++ {
+		if err := s.AddFavorite(userID, &models.Insight{ExternalID: fmt.Sprintf("export_i%d", n), Text: "t"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var dashID, insightID int
+	s.db.QueryRow(`SELECT id FROM insights WHERE external_id = 'export_i1'`).Scan(&insightID)
+	s.db.QueryRow(`INSERT INTO dashboards (external_id, title, layout, description) VALUES ('export_d1', 'Dash', 'grid', 'd') RETURNING id`).Scan(&dashID)
+	s.db.Exec(`INSERT INTO asset_members (parent_type, parent_id, position, member_type, member_id) VALUES ('dashboard', $1, 0, 'insight', $2)`, dashID, insightID)
+	dash := &models.Dashboard{ExternalID: "export_d1", Title: "Dash", Layout: "grid", Members: []models.AssetRef{{Type: models.AssetTypeInsight, ExternalID: "export_i1"}}}
+	if err := s.AddFavorite(userID, dash); err != nil {
+		t.Fatal(err)
+	}
+	// Trashed favorites and other users' favorites are left out
+	s.RemoveFavorite(userID, "insight", "export_i250")
+	s.AddFavorite("22222222-2222-2222-2222-222222222222", &models.Insight{ExternalID: "export_i1", Text: "t"})
+
+	var exported []models.Asset
+	err = s.ExportFavorites(userID, func(a models.Asset) error {
+		exported = append(exported, a)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(exported) != 250 {
+		t.Fatalf("expected 249 insights and a dashboard, got %d favorites", len(exported))
+	}
+	var dashboards int
+	for _, a := range exported {
+		if d, ok := a.(*models.Dashboard); ok {
+			dashboards++
+			if len(d.Members) != 1 || d.Members[0].ExternalID != "export_i1" {
+				t.Errorf("expected the dashboard with its member, got %+v", d)
+			}
+		}
+	}
+	if dashboards != 1 {
+		t.Errorf("expected one dashboard, got %d", dashboards)
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+	err = s.ExportFavorites(userID, func(models.Asset) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("expected the export to stop at the first error, got %v after %d calls", err, calls)
+	}
+}
+
+func TestImportFavorites(t *testing.T) {
+	s, err := NewPostgresStore(getTestConnStr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resetTestDB(s.db)
+	userID := "11111111-1111-1111-1111-111111111111"
+	s.db.Exec(`INSERT INTO insights (external_id, text, description) VALUES ('import_i1', 't', 'd'), ('import_i2', 't', 'd')`)
+	if err := s.AddFavorite(userID, &models.Insight{ExternalID: "import_i1", Text: "t", Description: "mine"}); err != nil {
+		t.Fatal(err)
+	}
+
+	rows := []models.ImportRow{
+		{Type: "insight", ExternalID: "import_i2", Description: "new", Tags: []string{"Q1"}},
+		{Type: "insight", ExternalID: "import_i1", Description: "imported"},
+		{Type: "insight", ExternalID: "no_such_insight"},
+		{Type: "insight", ExternalID: "import_i2", Tags: []string{"no spaces"}},
+		{Type: "video", ExternalID: "v1"},
+	}
+	report, err := s.ImportFavorites(userID, rows, ImportOptions{OnConflict: models.ImportSkip, DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var statuses []string
+	for _, r := range report.Rows {
+		statuses = append(statuses, r.Status)
+	}
+	want := []string{models.ImportAdded, models.ImportDuplicate, models.ImportUnknownAsset, models.ImportInvalid, models.ImportInvalid}
+	if !reflect.DeepEqual(statuses, want) || report.Added != 1 || report.Invalid != 2 {
+		t.Errorf("expected statuses %v, got %+v", want, report)
+	}
+	if favs, _ := s.ListFavorites(userID, ListOptions{Limit: 10}); len(favs) != 1 {
+		t.Errorf("expected a dry run to change nothing, got %d favorites", len(favs))
+	}
+
+	// Without a dry run, any bad row rejects the whole import
+	_, err = s.ImportFavorites(userID, rows, ImportOptions{OnConflict: models.ImportSkip})
+	var verrs models.ValidationErrors
+	if !errors.Is(err, ErrValidation) || !errors.As(err, &verrs) || len(verrs) != 3 || verrs[0].Field != "rows[2].external_id" {
+		t.Fatalf("expected rows 2 to 4 to be reported, got %v", err)
+	}
+	if favs, _ := s.ListFavorites(userID, ListOptions{Limit: 10}); len(favs) != 1 {
+		t.Errorf("expected a rejected import to change nothing, got %d favorites", len(favs))
+	}
+
+	rows = rows[:2]
+	if _, err := s.ImportFavorites(userID, rows, ImportOptions{OnConflict: models.ImportFail}); !errors.Is(err, ErrAlreadyExists) {
+		t.Errorf("expected ErrAlreadyExists failing on conflicts, got %v", err)
+	}
+	report, err = s.ImportFavorites(userID, rows, ImportOptions{OnConflict: models.ImportSkip})
+	if err != nil || report.Added != 1 || report.Duplicates != 1 {
+		t.Fatalf("expected one added and one skipped, got %+v err=%v", report, err)
+	}
+	report, err = s.ImportFavorites(userID, rows, ImportOptions{OnConflict: models.ImportOverwrite})
+	if err != nil || report.Overwritten != 2 {
+		t.Fatalf("expected both overwritten, got %+v err=%v", report, err)
+	}
+	favs, _ := s.ListFavorites(userID, ListOptions{Limit: 10})
+	for _, f := range favs {
+		if f.GetID() == "import_i1" && f.GetDescription() != "imported" {
+			t.Errorf("expected the overwritten description, got %q", f.GetDescription())
+		}
+		if f.GetID() == "import_i2" && !reflect.DeepEqual(f.GetTags(), []string{"q1"}) {
+			t.Errorf("expected normalized tags, got %v", f.GetTags())
+		}
+	}
+	events, _ := s.ListAuditEvents(AuditFilter{Action: models.ActionFavoriteReplaced, Limit: 10})
+	if len(events) != 2 {
+		t.Errorf("expected 2 favorite.replaced audit events, got %d", len(events))
+	}
+
+	// A tenant's asset shadows the global one with the same ID, and other tenants' stay hidden
+	s.db.Exec(`INSERT INTO insights (tenant_id, external_id, text, description) VALUES ('acme', 'import_i1', 'acme', 'd'), ('other', 'import_i3', 'other', 'd')`)
+	acme := s.ForTenant("acme")
+	report, err = acme.ImportFavorites(userID, []models.ImportRow{
+		{Type: "insight", ExternalID: "import_i1"},
+		{Type: "insight", ExternalID: "import_i2"},
+		{Type: "insight", ExternalID: "import_i3"},
+	}, ImportOptions{OnConflict: models.ImportSkip, DryRun: true})
+	if err != nil || report.Added != 2 || report.UnknownAssets != 1 {
+		t.Fatalf("expected two added and one unknown asset, got %+v err=%v", report, err)
+	}
+	acme.ImportFavorites(userID, []models.ImportRow{{Type: "insight", ExternalID: "import_i1"}}, ImportOptions{OnConflict: models.ImportSkip})
+	if favs, _ := acme.ListFavorites(userID, ListOptions{Limit: 10}); len(favs) != 1 || favs[0].(*models.Insight).Text != "acme" {
+		t.Errorf("expected the tenant's own insight, got %+v", favs)
+	}
+}
+
+func TestEraseUser_CoversEveryTable(t *testing.T) {
+	s, err := NewPostgresStore(getTestConnStr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resetTestDB(s.db)
+	userID := "11111111-1111-1111-1111-111111111111"
+	other := "22222222-2222-2222-2222-222222222222"
+	admin := "99999999-9999-9999-9999-999999999999"
+
+	// Every table is either erased or known to hold no user data
+	known := map[string]bool{}
+	for _, t := range userDataTables {
+		known[t.table] = true
+	}
+	for _, table := range tablesWithoutUserData {
+		known[table] = true
+	}
+	rows, err := s.db.Query(`SELECT table_name FROM information_schema.tables WHERE table_schema = 'public'`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tables []string
+	for rows.Next() {
+		var table string
+		rows.Scan(&table)
+		tables = append(tables, table)
+		if !known[table] {
+			t.Errorf("table %s is missing from userDataTables and tablesWithoutUserData", table)
+		}
+	}
+	rows.Close()
+	if len(tables) != len(known) {
+		t.Errorf("expected %d tables, found %v", len(known), tables)
+	}
+
+	s.CreateWebhook("33333333-3333-3333-3333-333333333333", models.WebhookRequest{URL: "https://all.example.com/hook"})
+	s.db.Exec(`INSERT INTO insights (external_id, text, description) VALUES ('erase_i1', 't', 'd'), ('erase_i2', 't', 'd')`)
+	for _, id := range []string{userID, other} {
+		if err := s.AddFavorite(id, &models.Insight{ExternalID: "erase_i1", Text: "t", Description: "mine", Tags: []string{"q3"}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s.AddFavorite(userID, &models.Insight{ExternalID: "erase_i2", Text: "t", Description: "second"})
+	c := &models.Collection{Name: "pitch"}
+	s.CreateCollection(userID, c)
+	s.AddToCollection(userID, c.ID, "insight", "erase_i1")
+	if _, err := s.ShareFavorite(userID, "insight", "erase_i1", models.ShareRequest{Link: true, Permission: models.PermissionView}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ShareFavorite(other, "insight", "erase_i1", models.ShareRequest{UserID: userID, Permission: models.PermissionEdit}); err != nil {
+		t.Fatal(err)
+	}
+	// The user editing someone else's favorite puts their ID in that user's audit trail
+	if _, err := s.AuditAs(userID, "req-edit").EditFavoriteDescription(other, "insight", "erase_i1", "edited", 0); err != nil {
+		t.Fatal(err)
+	}
+	s.ReserveIdempotencyKey(userID, "key-1", "hash-a", time.Hour, time.Minute)
+	if n, err := s.EnqueueWebhookDeliveries(100); err != nil || n == 0 {
+		t.Fatalf("expected webhook deliveries, got %d err=%v", n, err)
+	}
+	s.ForTenant("acme").ReserveIdempotencyKey(userID, "key-1", "hash-a", time.Hour, time.Minute)
+
+	data, err := s.UserData(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != len(userDataTables) {
+		t.Fatalf("expected %d tables of user data, got %d", len(userDataTables), len(data))
+	}
+	for _, d := range data {
+		var rows []json.RawMessage
+		if err := json.Unmarshal(d.Rows, &rows); err != nil || len(rows) != d.Count {
+			t.Errorf("%s: %d rows for count %d (%v)", d.Table, len(rows), d.Count, err)
+		}
+		if d.Count == 0 {
+			t.Errorf("%s: expected the test to store user data", d.Table)
+		}
+	}
+
+	var auditEvents, otherFavorites int
+	s.db.QueryRow(`SELECT count(*) FROM audit_events`).Scan(&auditEvents)
+	report, err := s.AuditAs(admin, "req-erase").EraseUser(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.UserID != userID || report.ErasedBy != admin || report.RequestID != "req-erase" || report.CompletedAt.IsZero() {
+		t.Errorf("unexpected report %+v", report)
+	}
+	if report.Deleted["favorites"] != 2 || report.Deleted["shares"] != 2 || report.PseudonymizedAuditEvents == 0 {
+		t.Errorf("unexpected counts %+v", report)
+	}
+
+	// Nothing in the tenant mentions the user any more
+	for _, table := range tables {
+		var n int
+		err := s.db.QueryRow(fmt.Sprintf(`SELECT count(*) FROM %s t WHERE tenant_id = $1 AND t::text LIKE '%%' || $2 || '%%'`, table),
+			s.tenantID, userID).Scan(&n)
+		if err != nil {
+			// Tables without a tenant column hold no user data either
+			err = s.db.QueryRow(fmt.Sprintf(`SELECT count(*) FROM %s t WHERE t::text LIKE '%%' || $1 || '%%'`, table), userID).Scan(&n)
+		}
+		if err != nil || n != 0 {
+			t.Errorf("%s: %d rows still mention the user (%v)", table, n, err)
+		}
+	}
+	var n int
+	s.db.QueryRow(`SELECT count(*) FROM audit_events`).Scan(&n)
+	if n != auditEvents {
+		t.Errorf("expected audit events to be kept, had %d and now %d", auditEvents, n)
+	}
+	s.db.QueryRow(`SELECT count(*) FROM favorites WHERE user_id = $1`, other).Scan(&otherFavorites)
+	if otherFavorites != 1 {
+		t.Errorf("expected other users' favorites to be kept, got %d", otherFavorites)
+	}
+	if _, rec, err := s.ForTenant("acme").ReserveIdempotencyKey(userID, "key-1", "hash-a", time.Hour, time.Minute); err != nil || rec == nil {
+		t.Errorf("expected erasure to leave other tenants alone, got rec=%v err=%v", rec, err)
+	}
+	if again, err := s.EraseUser(userID); err != nil || again.PseudonymizedAuditEvents != 0 || again.Deleted["favorites"] != 0 {
+		t.Errorf("expected erasing again to change nothing, got %+v err=%v", again, err)
+	}
+}
+
+// TestMigrations_UpgradeBaseline applies every migration in order to a database created
+// from the original init.sql and checks it ends up with the tables, columns and indexes
+// of one created from the current init.sql
+func TestMigrations_UpgradeBaseline(t *testing.T) {
+	admin, err := sql.Open("postgres", getTestConnStr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer admin.Close()
+
+	migrated := createTestDatabase(t, admin, "favorites_migrated")
+	execSQLFile(t, migrated, "testdata/baseline_init.sql")
+	files, err := filepath.Glob("../../migrations/*.sql")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no migrations found (%v)", err)
+	}
+	sort.Strings(files)
+	for _, f := range files {
+		execSQLFile(t, migrated, f)
+	}
+
+	fresh := createTestDatabase(t, admin, "favorites_fresh")
+	execSQLFile(t, fresh, "../../init.sql")
+
+	got, want := schemaOf(t, migrated), schemaOf(t, fresh)
+	for _, item := range want {
+		if !slices.Contains(got, item) {
+			t.Errorf("migrated database lacks %s", item)
+		}
+	}
+	for _, item := range got {
+		if !slices.Contains(want, item) {
+			t.Errorf("migrated database has %s, which init.sql does not", item)
+		}
+	}
+}
+
+// createTestDatabase creates an empty database, dropped when the test ends
+func createTestDatabase(t *testing.T, admin *sql.DB, name string) *sql.DB {
+	t.Helper()
+	admin.Exec(`DROP DATABASE IF EXISTS ` + name)
+	if _, err := admin.Exec(`CREATE DATABASE ` + name); err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open("postgres", getTestConnStrFor(name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+		admin.Exec(`DROP DATABASE IF EXISTS ` + name)
+	})
+	return db
+}
+
+func execSQLFile(t *testing.T, db *sql.DB, path string) {
+	t.Helper()
+	script, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(string(script)); err != nil {
+		t.Fatalf("%s: %v", path, err)
+	}
+}
+
+// schemaOf lists the columns, with their types and nullability, and the indexes of a database
+func schemaOf(t *testing.T, db *sql.DB) []string {
+	t.Helper()
+	rows, err := db.Query(`
+		SELECT 'column ' || table_name || '.' || column_name || ' ' || data_type || ' ' || is_nullable
+		FROM information_schema.columns WHERE table_schema = 'public'
+		UNION ALL
+		SELECT 'index ' || indexname || ': ' || indexdef FROM pg_indexes WHERE schemaname = 'public'`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var item string
+		if err := rows.Scan(&item); err != nil {
+			t.Fatal(err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return items
+}
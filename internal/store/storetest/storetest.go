@@ -0,0 +1,133 @@
+// Package storetest provides an in-memory store.Store for testing the API layers
+// built on the store, such as GraphQL and gRPC, without a database.
+package storetest
+
+import (
+	"fmt"
+
+	"github.com/gitvam/platform-go-challenge/internal/models"
+	"github.com/gitvam/platform-go-challenge/internal/store"
+)
+
+// Users the fixed favorites are listed for, the caller and someone else
+const (
+	CallerID = "11111111-1111-1111-1111-111111111111"
+	OtherID  = "22222222-2222-2222-2222-222222222222"
+)
+
+// Store serves a fixed list of favorites and records how it was called. Store
+// methods no API layer uses are left to the nil embedded interface.
+type Store struct {
+	store.Store
+	Favorites []models.Asset
+	Added     []models.Asset
+	Lists     []store.ListOptions
+	Users     []string
+	Tenant    string
+	Actor     string
+	RequestID string
+	// Err is returned by every call once it has been recorded
+	Err error
+}
+
+// New returns a Store with a chart, an insight, an audience and two dashboards of
+// insights as favorites, in that order
+func New() *Store {
+	return &Store{Favorites: []models.Asset{
+		&models.Chart{ExternalID: "c1", Title: "Reach", Kind: "bar", Series: []models.Series{{Name: "Reach", Values: []float64{1, 2}}}, Type: "chart", Version: 2},
+		&models.Insight{ExternalID: "i1", Text: "t", Tags: []string{"kpi"}, Type: "insight", Version: 1},
+		&models.Audience{ExternalID: "a1", Gender: "female", BirthCountry: "GB", Type: "audience", Version: 1},
+		&models.Dashboard{ExternalID: "d1", Title: "Q3", Layout: "grid", Type: "dashboard", Version: 1, Members: []models.AssetRef{
+			{Type: models.AssetTypeInsight, ExternalID: "i1"}, {Type: models.AssetTypeInsight, ExternalID: "i2"}}},
+		&models.Dashboard{ExternalID: "d2", Title: "Q4", Layout: "rows", Type: "dashboard", Version: 1, Members: []models.AssetRef{
+			{Type: models.AssetTypeInsight, ExternalID: "i3"}}},
+	}}
+}
+
+func (s *Store) ForTenant(tenantID string) store.Store {
+	s.Tenant = tenantID
+	return s
+}
+
+func (s *Store) AuditAs(actorID, requestID string) store.Store {
+	s.Actor, s.RequestID = actorID, requestID
+	return s
+}
+
+func (s *Store) ListFavorites(userID string, opts store.ListOptions) ([]models.Asset, error) {
+	s.Lists = append(s.Lists, opts)
+	s.Users = append(s.Users, userID)
+	return s.page(opts), s.Err
+}
+
+// ListKeyedFavorites keys each favorite by its place in Favorites, counting from 1
+func (s *Store) ListKeyedFavorites(userID string, opts store.ListOptions) ([]store.KeyedFavorite, error) {
+	s.Lists = append(s.Lists, opts)
+	s.Users = append(s.Users, userID)
+	if opts.After != nil {
+		opts.Offset = opts.After.ID
+	}
+	keyed := []store.KeyedFavorite{}
+	for i, a := range s.page(opts) {
+		n := opts.Offset + i + 1
+		keyed = append(keyed, store.KeyedFavorite{Asset: a, Key: store.FavoriteKey{Position: float64(n), ID: n}})
+	}
+	return keyed, s.Err
+}
+
+func (s *Store) GetFavorite(userID, assetType, externalID string, expandMembers bool) (models.Asset, error) {
+	s.Lists = append(s.Lists, store.ListOptions{ExpandMembers: expandMembers})
+	s.Users = append(s.Users, userID)
+	for _, a := range s.Favorites {
+		if string(a.GetType()) == assetType && a.GetID() == externalID {
+			return expand(a, expandMembers), s.Err
+		}
+	}
+	return nil, fmt.Errorf("favorite %s %q: %w", assetType, externalID, store.ErrNotFound)
+}
+
+func (s *Store) AddFavorite(userID string, asset models.Asset) error {
+	s.Users = append(s.Users, userID)
+	if err := asset.Validate(); err != nil {
+		return fmt.Errorf("%w: %w", store.ErrValidation, err)
+	}
+	s.Added = append(s.Added, asset)
+	return s.Err
+}
+
+func (s *Store) EditFavoriteDescription(userID, assetType, externalID, desc string, expectedVersion int) (int, error) {
+	return expectedVersion + 1, s.Err
+}
+
+func (s *Store) ListCatalog(assetType string, opts store.ListOptions) ([]models.Asset, error) {
+	s.Lists = append(s.Lists, opts)
+	return s.page(opts), s.Err
+}
+
+// page returns the favorites opts.Offset and opts.Limit select
+func (s *Store) page(opts store.ListOptions) []models.Asset {
+	var page []models.Asset
+	for _, a := range s.Favorites[min(opts.Offset, len(s.Favorites)):] {
+		if len(page) == opts.Limit {
+			break
+		}
+		page = append(page, expand(a, opts.ExpandMembers))
+	}
+	return page
+}
+
+// expand sets the asset of every member to an insight, as the store does when
+// loading members
+func expand(a models.Asset, expand bool) models.Asset {
+	d, ok := a.(*models.Dashboard)
+	if !ok || !expand {
+		return a
+	}
+	expanded := *d
+	expanded.Members = nil
+	for _, m := range d.Members {
+		m.Asset = &models.Insight{ExternalID: m.ExternalID, Text: "member " + m.ExternalID, Type: string(m.Type)}
+		expanded.Members = append(expanded.Members, m)
+	}
+	return &expanded
+}
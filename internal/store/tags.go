@@ -0,0 +1,110 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/gitvam/platform-go-challenge/internal/models"
+	"github.com/lib/pq"
+)
+
+func (ps *PostgresStore) AddFavoriteTags(userID, assetType, externalID string, tags []string, expectedVersion int) ([]string, int, error) {
+	if err := models.ValidateTags(tags); err != nil {
+		return nil, 0, fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+	return ps.changeFavoriteTags(userID, assetType, externalID, expectedVersion, models.ActionFavoriteTagged, func(current []string) ([]string, error) {
+		next := models.NormalizeTags(append(current, tags...))
+		var v models.Validator
+		v.Tags("tags", next)
+		if err := v.Err(); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrValidation, err)
+		}
+		return next, nil
+	})
+}
+
+func (ps *PostgresStore) RemoveFavoriteTag(userID, assetType, externalID, tag string, expectedVersion int) ([]string, int, error) {
+	tag = models.NormalizeTag(tag)
+	return ps.changeFavoriteTags(userID, assetType, externalID, expectedVersion, models.ActionFavoriteUntagged, func(current []string) ([]string, error) {
+		i := slices.Index(current, tag)
+		if i < 0 {
+			return nil, fmt.Errorf("tag %q on %s %q: %w", tag, assetType, externalID, ErrNotFound)
+		}
+		return slices.Delete(current, i, i+1), nil
+	})
+}
+
+// changeFavoriteTags locks a favorite, applies change to its tags, bumps its version and audits it as action
+func (ps *PostgresStore) changeFavoriteTags(userID, assetType, externalID string, expectedVersion int, action string, change func([]string) ([]string, error)) ([]string, int, error) {
+	assetID, err := ps.resolveAssetID(assetType, externalID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	tx, err := ps.db.Begin()
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	var id, version int
+	var tags pq.StringArray
+	err = tx.QueryRow(`SELECT id, tags, version FROM favorites WHERE tenant_id = $1 AND user_id = $2 AND asset_type = $3 AND asset_id = $4 AND deleted_at IS NULL FOR UPDATE`,
+		ps.tenantID, userID, assetType, assetID).Scan(&id, &tags, &version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, 0, fmt.Errorf("favorite %s %q: %w", assetType, externalID, ErrNotFound)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	if expectedVersion != 0 && version != expectedVersion {
+		return nil, 0, ErrVersionConflict
+	}
+
+	before, err := loadFavoriteState(tx, id)
+	if err != nil {
+		return nil, 0, err
+	}
+	next, err := change(tags)
+	if err != nil {
+		return nil, 0, err
+	}
+	err = tx.QueryRow(`UPDATE favorites SET tags = $1, version = version + 1, updated_at = now() WHERE id = $2 RETURNING version`,
+		pq.Array(next), id).Scan(&version)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := ps.auditFavorite(tx, userID, id, models.FavoriteEntityID(assetType, externalID), action, before); err != nil {
+		return nil, 0, err
+	}
+	if err := ps.publishFavorite(tx, models.EventFavoriteUpdated, id); err != nil {
+		return nil, 0, err
+	}
+	return next, version, tx.Commit()
+}
+
+func (ps *PostgresStore) ListTags(userID string) ([]models.TagCount, error) {
+	query := `
+		SELECT tag, count(*)
+		FROM favorites, unnest(tags) AS tag
+		WHERE tenant_id = $1 AND user_id = $2 AND deleted_at IS NULL
+		GROUP BY tag
+		ORDER BY count(*) DESC, tag`
+	rows, err := ps.db.Query(query, ps.tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := []models.TagCount{}
+	for rows.Next() {
+		var c models.TagCount
+		if err := rows.Scan(&c.Tag, &c.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, c)
+	}
+	return counts, rows.Err()
+}
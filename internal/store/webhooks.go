@@ -0,0 +1,279 @@
+package store
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gitvam/platform-go-challenge/internal/models"
+	"github.com/lib/pq"
+)
+
+// PendingDelivery is a claimed webhook delivery, ready to send
+type PendingDelivery struct {
+	ID     int64
+	URL    string
+	Secret string
+	// Attempts counts this attempt, which is made when the delivery is claimed
+	Attempts int
+	Event    models.FavoriteEvent
+}
+
+const deliveryColumns = `d.id, d.webhook_id, d.event_id, e.event_type, d.status, d.attempts, d.next_attempt_at, d.last_error, d.delivered_at, d.created_at`
+
+// publishFavorite writes a favorite change event to the outbox in tx, so it is
+// delivered to webhooks and event streams only if the change is committed
+func (ps *PostgresStore) publishFavorite(tx *sql.Tx, eventType string, favoriteID int) error {
+	assetType, externalID, err := favoriteRefOf(tx, favoriteID)
+	if err != nil {
+		return err
+	}
+	state, err := loadFavoriteState(tx, favoriteID)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(models.FavoriteEventData{
+		AssetType:   assetType,
+		ExternalID:  externalID,
+		Description: state.Description,
+		Tags:        state.Tags,
+	})
+	if err != nil {
+		return err
+	}
+	// Serializing a user's events until commit makes their IDs follow commit order, so a
+	// stream resuming after an event cannot miss one committed later with a lower ID
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext($1 || '/' || $2))`, ps.tenantID, state.userID); err != nil {
+		return err
+	}
+	_, err = tx.Exec(`INSERT INTO outbox_events (tenant_id, user_id, event_type, data) VALUES ($1, $2, $3, $4)`,
+		ps.tenantID, state.userID, eventType, data)
+	if err != nil {
+		return err
+	}
+	return ps.notify(tx, state.userID)
+}
+
+func (ps *PostgresStore) CreateWebhook(adminID string, req models.WebhookRequest) (*models.Webhook, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+	wh := &models.Webhook{URL: req.URL, Events: req.Events, Secret: secret}
+	if wh.Events == nil {
+		wh.Events = []string{}
+	}
+	tx, err := ps.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	err = tx.QueryRow(`INSERT INTO webhooks (tenant_id, url, events, secret) VALUES ($1, $2, $3, $4) RETURNING id, created_at`,
+		ps.tenantID, wh.URL, pq.Array(wh.Events), secret).Scan(&wh.ID, &wh.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	err = ps.audit(tx, adminID, auditEvent{userID: adminID, action: models.ActionWebhookCreated,
+		entityType: models.AuditEntityWebhook, entityID: strconv.Itoa(wh.ID), after: webhookState{URL: wh.URL, Events: wh.Events}})
+	if err != nil {
+		return nil, err
+	}
+	return wh, tx.Commit()
+}
+
+func (ps *PostgresStore) ListWebhooks() ([]models.Webhook, error) {
+	rows, err := ps.db.Query(`SELECT id, url, events, created_at FROM webhooks WHERE tenant_id = $1 ORDER BY id`, ps.tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := []models.Webhook{}
+	for rows.Next() {
+		var wh models.Webhook
+		var events pq.StringArray
+		if err := rows.Scan(&wh.ID, &wh.URL, &events, &wh.CreatedAt); err != nil {
+			return nil, err
+		}
+		wh.Events = events
+		webhooks = append(webhooks, wh)
+	}
+	return webhooks, rows.Err()
+}
+
+func (ps *PostgresStore) DeleteWebhook(adminID string, webhookID int) error {
+	tx, err := ps.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	var before webhookState
+	var events pq.StringArray
+	err = tx.QueryRow(`DELETE FROM webhooks WHERE tenant_id = $1 AND id = $2 RETURNING url, events`, ps.tenantID, webhookID).
+		Scan(&before.URL, &events)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("webhook %d: %w", webhookID, ErrNotFound)
+	}
+	if err != nil {
+		return err
+	}
+	before.Events = events
+	err = ps.audit(tx, adminID, auditEvent{userID: adminID, action: models.ActionWebhookDeleted,
+		entityType: models.AuditEntityWebhook, entityID: strconv.Itoa(webhookID), before: before})
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (ps *PostgresStore) ListWebhookDeliveries(webhookID int, status string, opts ListOptions) ([]models.WebhookDelivery, error) {
+	if err := ps.checkWebhook(webhookID); err != nil {
+		return nil, err
+	}
+	rows, err := ps.db.Query(`
+		SELECT `+deliveryColumns+`
+		FROM webhook_deliveries d JOIN outbox_events e ON e.id = d.event_id
+		WHERE d.webhook_id = $1 AND ($2 = '' OR d.status = $2)
+		ORDER BY d.id DESC
+		LIMIT $3 OFFSET $4`, webhookID, status, opts.Limit, opts.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []models.WebhookDelivery{}
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, *d)
+	}
+	return deliveries, rows.Err()
+}
+
+func (ps *PostgresStore) ReplayWebhookDelivery(webhookID int, deliveryID int64) (*models.WebhookDelivery, error) {
+	if err := ps.checkWebhook(webhookID); err != nil {
+		return nil, err
+	}
+	d, err := scanDelivery(ps.db.QueryRow(`
+		UPDATE webhook_deliveries d
+		SET status = 'pending', attempts = 0, next_attempt_at = now(), last_error = '', delivered_at = NULL
+		FROM outbox_events e
+		WHERE d.id = $1 AND d.webhook_id = $2 AND e.id = d.event_id
+		RETURNING `+deliveryColumns, deliveryID, webhookID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("delivery %d: %w", deliveryID, ErrNotFound)
+	}
+	return d, err
+}
+
+func (ps *PostgresStore) checkWebhook(webhookID int) error {
+	var exists bool
+	err := ps.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM webhooks WHERE tenant_id = $1 AND id = $2)`, ps.tenantID, webhookID).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("webhook %d: %w", webhookID, ErrNotFound)
+	}
+	return nil
+}
+
+func (ps *PostgresStore) EnqueueWebhookDeliveries(limit int) (int64, error) {
+	res, err := ps.db.Exec(`
+		WITH events AS (
+			UPDATE outbox_events SET dispatched_at = now()
+			WHERE id IN (
+				SELECT id FROM outbox_events WHERE dispatched_at IS NULL
+				ORDER BY id LIMIT $1 FOR UPDATE SKIP LOCKED
+			)
+			RETURNING id, tenant_id, event_type
+		)
+		INSERT INTO webhook_deliveries (webhook_id, event_id)
+		SELECT w.id, e.id FROM events e
+		JOIN webhooks w ON w.tenant_id = e.tenant_id AND (cardinality(w.events) = 0 OR e.event_type = ANY(w.events))
+		ON CONFLICT DO NOTHING`, limit)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (ps *PostgresStore) ClaimWebhookDeliveries(limit int, lease time.Duration) ([]PendingDelivery, error) {
+	// Pushing next_attempt_at past the lease hides claimed deliveries from other
+	// dispatchers, and retries them if this one dies before reporting back
+	rows, err := ps.db.Query(`
+		UPDATE webhook_deliveries d
+		SET attempts = d.attempts + 1, next_attempt_at = now() + make_interval(secs => $2)
+		FROM webhooks w, outbox_events e
+		WHERE d.id IN (
+			SELECT id FROM webhook_deliveries WHERE status = 'pending' AND next_attempt_at <= now()
+			ORDER BY next_attempt_at, id LIMIT $1 FOR UPDATE SKIP LOCKED
+		) AND w.id = d.webhook_id AND e.id = d.event_id
+		RETURNING d.id, d.attempts, w.url, w.secret, e.id, e.event_type, e.user_id, e.created_at, e.data`,
+		limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var claimed []PendingDelivery
+	for rows.Next() {
+		var p PendingDelivery
+		var data []byte
+		err := rows.Scan(&p.ID, &p.Attempts, &p.URL, &p.Secret, &p.Event.ID, &p.Event.Type, &p.Event.UserID, &p.Event.OccurredAt, &data)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &p.Event.Data); err != nil {
+			return nil, err
+		}
+		claimed = append(claimed, p)
+	}
+	return claimed, rows.Err()
+}
+
+func (ps *PostgresStore) CompleteWebhookDelivery(deliveryID int64) error {
+	_, err := ps.db.Exec(`UPDATE webhook_deliveries SET status = 'delivered', delivered_at = now(), last_error = '' WHERE id = $1`, deliveryID)
+	return err
+}
+
+func (ps *PostgresStore) RetryWebhookDelivery(deliveryID int64, lastErr string, at time.Time) error {
+	_, err := ps.db.Exec(`UPDATE webhook_deliveries SET next_attempt_at = $2, last_error = $3 WHERE id = $1`, deliveryID, at, lastErr)
+	return err
+}
+
+func (ps *PostgresStore) DeadLetterWebhookDelivery(deliveryID int64, lastErr string) error {
+	_, err := ps.db.Exec(`UPDATE webhook_deliveries SET status = 'dead', last_error = $2 WHERE id = $1`, deliveryID, lastErr)
+	return err
+}
+
+func scanDelivery(row rowScanner) (*models.WebhookDelivery, error) {
+	var d models.WebhookDelivery
+	var next time.Time
+	err := row.Scan(&d.ID, &d.WebhookID, &d.EventID, &d.EventType, &d.Status, &d.Attempts, &next, &d.LastError, &d.DeliveredAt, &d.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if d.Status == models.DeliveryPending {
+		d.NextAttemptAt = &next
+	}
+	return &d, nil
+}
+
+func newWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,140 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// CSVRecorder is implemented by response data with a CSV layout of its own, such as
+// lists of favorites, which are written like exports. The first record is the header.
+type CSVRecorder interface {
+	CSVRecords() ([][]string, error)
+}
+
+// csvListSeparator joins list items within one field, as in exports
+const csvListSeparator = ";"
+
+// encodeCSV writes the data of a response as CSV. Unless it is a CSVRecorder, a list
+// becomes a row per item and anything else a single row, with a column for each JSON
+// field in the order first seen. Lists of strings are joined with ";" and other nested
+// values are written as JSON.
+func encodeCSV(w io.Writer, payload any) error {
+	if resp, ok := payload.(SuccessResponse); ok {
+		payload = resp.Data
+	}
+	var records [][]string
+	var err error
+	if r, ok := payload.(CSVRecorder); ok {
+		records, err = r.CSVRecords()
+	} else {
+		records, err = csvRecords(payload)
+	}
+	if err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	return cw.WriteAll(records)
+}
+
+func csvRecords(payload any) ([][]string, error) {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	var items []json.RawMessage
+	switch b[0] {
+	case 'n':
+	case '[':
+		if err := json.Unmarshal(b, &items); err != nil {
+			return nil, err
+		}
+	default:
+		items = []json.RawMessage{b}
+	}
+
+	var header []string
+	columns := map[string]int{}
+	rows := make([]map[string]string, len(items))
+	for i, item := range items {
+		keys, values, ok := objectFields(item)
+		if !ok {
+			keys, values = []string{"value"}, map[string]json.RawMessage{"value": item}
+		}
+		rows[i] = map[string]string{}
+		for _, k := range keys {
+			if _, seen := columns[k]; !seen {
+				columns[k] = len(header)
+				header = append(header, k)
+			}
+			if rows[i][k], err = csvField(values[k]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(header) == 0 {
+		return nil, nil
+	}
+	records := [][]string{header}
+	for _, row := range rows {
+		record := make([]string, len(header))
+		for k, v := range row {
+			record[columns[k]] = v
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// objectFields returns the keys of a JSON object in order with their raw values, or
+// false when raw is not an object
+func objectFields(raw json.RawMessage) ([]string, map[string]json.RawMessage, bool) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, nil, false
+	}
+	var keys []string
+	values := map[string]json.RawMessage{}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, nil, false
+		}
+		key := tok.(string)
+		var v json.RawMessage
+		if err := dec.Decode(&v); err != nil {
+			return nil, nil, false
+		}
+		if _, dup := values[key]; !dup {
+			keys = append(keys, key)
+		}
+		values[key] = v
+	}
+	return keys, values, true
+}
+
+// csvField renders one JSON value as a CSV field
+func csvField(raw json.RawMessage) (string, error) {
+	switch raw[0] {
+	case 'n':
+		return "", nil
+	case '"':
+		var s string
+		err := json.Unmarshal(raw, &s)
+		return s, err
+	case '[':
+		var list []string
+		if json.Unmarshal(raw, &list) == nil {
+			return strings.Join(list, csvListSeparator), nil
+		}
+		fallthrough
+	case '{':
+		var buf bytes.Buffer
+		err := json.Compact(&buf, raw)
+		return buf.String(), err
+	default:
+		return string(raw), nil
+	}
+}
@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// WeakETag builds a weak entity tag from the hash of a response body
+func WeakETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `W/"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// VersionETag builds a strong entity tag from a row version
+func VersionETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// EncodedETag marks a strong tag as belonging to a representation compressed with
+// encoding, e.g. "3" becomes "3-gzip", since strong tags must differ between encodings.
+// Weak tags are returned as they are.
+func EncodedETag(tag, encoding string) string {
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return tag
+	}
+	return tag[:len(tag)-1] + "-" + encoding + `"`
+}
+
+// ParseVersionETag extracts the version from a strong tag made by VersionETag,
+// whether or not EncodedETag marked it with an encoding
+func ParseVersionETag(tag string) (int, bool) {
+	tag = strings.TrimSpace(tag)
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return 0, false
+	}
+	version, encoding, encoded := strings.Cut(tag[1:len(tag)-1], "-")
+	if encoded && (encoding == "" || strings.Trim(encoding, "abcdefghijklmnopqrstuvwxyz") != "") {
+		return 0, false
+	}
+	v, err := strconv.Atoi(version)
+	if err != nil || v <= 0 {
+		return 0, false
+	}
+	return v, true
+}
+
+// ETagMatches reports whether an If-None-Match style header matches etag using weak comparison
+func ETagMatches(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+func writeWithETag(w http.ResponseWriter, r *http.Request, status int, payload any, c Codec) {
+	var buf bytes.Buffer
+	if err := c.Encode(&buf, payload); err != nil {
+		WriteProblem(w, http.StatusInternalServerError, CodeInternal, "could not encode response")
+		return
+	}
+	etag := WeakETag(buf.Bytes())
+	w.Header().Set("ETag", etag)
+	if ETagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", c.ContentType)
+	w.WriteHeader(status)
+	w.Write(buf.Bytes())
+}
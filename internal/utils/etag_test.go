@@ -0,0 +1,40 @@
+package utils
+
+import "testing"
+
+func TestETagMatches(t *testing.T) {
+	etag := WeakETag([]byte(`{"status":"success"}`))
+	cases := []struct {
+		header string
+		want   bool
+	}{
+		{"", false},
+		{"*", true},
+		{etag, true},
+		{`"other", ` + etag, true},
+		{etag[2:], true}, // weak comparison ignores the W/ prefix
+		{`W/"other"`, false},
+	}
+	for _, c := range cases {
+		if got := ETagMatches(c.header, etag); got != c.want {
+			t.Errorf("ETagMatches(%q) = %v, want %v", c.header, got, c.want)
+		}
+	}
+}
+
+func TestParseVersionETag(t *testing.T) {
+	if v, ok := ParseVersionETag(VersionETag(7)); !ok || v != 7 {
+		t.Errorf("expected round trip of version 7, got %d %v", v, ok)
+	}
+	if v, ok := ParseVersionETag(EncodedETag(VersionETag(7), "gzip")); !ok || v != 7 {
+		t.Errorf("expected the encoding to be stripped, got %d %v", v, ok)
+	}
+	if tag := EncodedETag(`W/"abc"`, "gzip"); tag != `W/"abc"` {
+		t.Errorf("expected weak tags to be left as they are, got %s", tag)
+	}
+	for _, bad := range []string{"", "7", `W/"7"`, `"abc"`, `"0"`, `"1", "2"`, `"7-"`, `"7-gz1p"`, `"-gzip"`} {
+		if _, ok := ParseVersionETag(bad); ok {
+			t.Errorf("expected %q to be rejected", bad)
+		}
+	}
+}
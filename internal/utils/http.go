@@ -6,24 +6,75 @@ import (
 	"net/http"
 )
 
+// RequestIDHeader carries the request ID set by middleware.RequestID
+const RequestIDHeader = "X-Request-ID"
+
+// Stable machine-readable error codes returned in Problem.Code
+const (
+	CodeBadRequest            = "bad_request"
+	CodeInvalidJSON           = "invalid_json"
+	CodeInvalidAssetType      = "invalid_asset_type"
+	CodeValidationFailed      = "validation_failed"
+	CodeUnauthorized          = "unauthorized"
+	CodeForbidden             = "forbidden"
+	CodeNotFound              = "not_found"
+	CodeAlreadyExists         = "already_exists"
+	CodePreconditionFailed    = "precondition_failed"
+	CodeIdempotencyKeyReused  = "idempotency_key_reused"
+	CodeIdempotencyInProgress = "idempotency_key_in_progress"
+	CodePayloadTooLarge       = "payload_too_large"
+	CodeNotAcceptable         = "not_acceptable"
+	CodeUnsupportedMediaType  = "unsupported_media_type"
+	CodeRateLimited           = "rate_limited"
+	CodeInternal              = "internal_error"
+)
+
 // swagger:model
 type SuccessResponse struct {
 	Status string      `json:"status"` 
 	Data   interface{} `json:"data"`   
 }
 
+// Problem is an RFC 7807 problem details error response
 // swagger:model
-type ErrorResponse struct {
-	Status  string `json:"status"`  
-	Message string `json:"message"` 
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Code      string       `json:"code"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError is a single field-level validation failure
+type FieldError struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+// WriteProblem writes an application/problem+json response with a stable error code
+func WriteProblem(w http.ResponseWriter, status int, code, detail string) {
+	WriteValidationProblem(w, status, code, detail, nil)
 }
 
-func WriteJSONError(w http.ResponseWriter, msg string, status int) {
-	log.Printf("[ERROR] %d - %s", status, msg)
-	w.Header().Set("Content-Type", "application/json")
+// WriteValidationProblem writes a problem response listing every invalid field
+func WriteValidationProblem(w http.ResponseWriter, status int, code, detail string, fields []FieldError) {
+	requestID := w.Header().Get(RequestIDHeader)
+	level := "[WARN]"
+	if status >= http.StatusInternalServerError {
+		level = "[ERROR]"
+	}
+	log.Printf("%s %d %s request_id=%s - %s", level, status, code, requestID, detail)
+	w.Header().Set("Content-Type", "application/problem+json")
 	w.WriteHeader(status)
-	_ = json.NewEncoder(w).Encode(ErrorResponse{
-		Status:  "error",
-		Message: msg,
+	_ = json.NewEncoder(w).Encode(Problem{
+		Type:      "/problems/" + code,
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		Code:      code,
+		RequestID: requestID,
+		Errors:    fields,
 	})
 }
@@ -0,0 +1,304 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Media types the API negotiates for responses and request bodies
+const (
+	MediaTypeJSON    = "application/json"
+	MediaTypeMsgPack = "application/msgpack"
+	MediaTypeCSV     = "text/csv"
+)
+
+// Codec encodes responses in one media type and, unless response-only, converts
+// request bodies in it to JSON for the strict JSON decoders
+type Codec struct {
+	MediaType   string
+	ContentType string
+	Encode      func(w io.Writer, payload any) error
+	// ToJSON is nil for types only offered for responses
+	ToJSON func(body []byte) ([]byte, error)
+}
+
+var (
+	JSONCodec = Codec{
+		MediaType:   MediaTypeJSON,
+		ContentType: MediaTypeJSON,
+		Encode:      func(w io.Writer, payload any) error { return json.NewEncoder(w).Encode(payload) },
+		ToJSON:      func(body []byte) ([]byte, error) { return body, nil },
+	}
+	MsgPackCodec = Codec{
+		MediaType:   MediaTypeMsgPack,
+		ContentType: MediaTypeMsgPack,
+		Encode:      encodeMsgPack,
+		ToJSON:      msgPackToJSON,
+	}
+	CSVCodec = Codec{
+		MediaType:   MediaTypeCSV,
+		ContentType: MediaTypeCSV + "; charset=utf-8",
+		Encode:      encodeCSV,
+	}
+)
+
+// Codecs lists the negotiable codecs, JSON first as the default
+var Codecs = []Codec{JSONCodec, MsgPackCodec, CSVCodec}
+
+// mediaTypeAliases maps other names in use for a media type to the one the API answers with
+var mediaTypeAliases = map[string]string{
+	"application/x-msgpack":   MediaTypeMsgPack,
+	"application/vnd.msgpack": MediaTypeMsgPack,
+}
+
+func codecFor(mediaType string) (Codec, bool) {
+	mediaType = strings.ToLower(mediaType)
+	if alias, ok := mediaTypeAliases[mediaType]; ok {
+		mediaType = alias
+	}
+	for _, c := range Codecs {
+		if c.MediaType == mediaType {
+			return c, true
+		}
+	}
+	return Codec{}, false
+}
+
+// ResponseCodec picks the codec for a request's Accept header: the supported type with
+// the highest quality, preferring earlier Codecs on ties and JSON when Accept is absent.
+// It reports false when the header accepts none of them.
+func ResponseCodec(r *http.Request) (Codec, bool) {
+	accept := r.Header.Values("Accept")
+	if len(accept) == 0 {
+		return JSONCodec, true
+	}
+	best, bestQ := Codec{}, 0.0
+	for _, c := range Codecs {
+		if q := acceptQuality(accept, c.MediaType); q > bestQ {
+			best, bestQ = c, q
+		}
+	}
+	return best, bestQ > 0
+}
+
+// acceptQuality returns the quality an Accept header gives a media type, taken from
+// its most specific matching range: the type itself, then type/*, then */*
+func acceptQuality(accept []string, mediaType string) float64 {
+	kind, _, _ := strings.Cut(mediaType, "/")
+	q, specificity := 0.0, -1
+	for _, h := range accept {
+		for _, part := range strings.Split(h, ",") {
+			rng, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+			if err != nil {
+				continue
+			}
+			rng = strings.ToLower(rng)
+			s := -1
+			switch {
+			case rng == mediaType, mediaTypeAliases[rng] == mediaType:
+				s = 2
+			case rng == kind+"/*":
+				s = 1
+			case rng == "*/*":
+				s = 0
+			}
+			if s > specificity {
+				q, specificity = quality(params), s
+			}
+		}
+	}
+	return q
+}
+
+func quality(params map[string]string) float64 {
+	raw, ok := params["q"]
+	if !ok {
+		return 1
+	}
+	q, err := strconv.ParseFloat(raw, 64)
+	if err != nil || q < 0 || q > 1 {
+		return 0
+	}
+	return q
+}
+
+// RequestCodec returns the codec of a request body's Content-Type, JSON when it has
+// none. It reports false for types that cannot be sent as request bodies.
+func RequestCodec(r *http.Request) (Codec, bool) {
+	contentType := r.Header.Get("Content-Type")
+	if contentType == "" {
+		return JSONCodec, true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return Codec{}, false
+	}
+	c, ok := codecFor(mediaType)
+	if !ok || c.ToJSON == nil {
+		return Codec{}, false
+	}
+	return c, true
+}
+
+// RequestMediaTypes lists the media types accepted for request bodies
+func RequestMediaTypes() []string {
+	var types []string
+	for _, c := range Codecs {
+		if c.ToJSON != nil {
+			types = append(types, c.MediaType)
+		}
+	}
+	return types
+}
+
+// ResponseMediaTypes lists the media types responses can be negotiated to
+func ResponseMediaTypes() []string {
+	types := make([]string, len(Codecs))
+	for i, c := range Codecs {
+		types[i] = c.MediaType
+	}
+	return types
+}
+
+// BodyToJSON converts a request body to JSON according to its Content-Type
+func BodyToJSON(r *http.Request, body []byte) ([]byte, error) {
+	c, ok := RequestCodec(r)
+	if !ok {
+		return nil, fmt.Errorf("unsupported Content-Type %q", r.Header.Get("Content-Type"))
+	}
+	return c.ToJSON(body)
+}
+
+// Write writes payload in the format negotiated from the request's Accept header.
+// Requests are expected to have passed middleware.Negotiate, so anything not
+// acceptable falls back to JSON.
+func Write(w http.ResponseWriter, r *http.Request, status int, payload any) {
+	c, ok := ResponseCodec(r)
+	if !ok {
+		c = JSONCodec
+	}
+	var buf bytes.Buffer
+	if err := c.Encode(&buf, payload); err != nil {
+		WriteProblem(w, http.StatusInternalServerError, CodeInternal, "could not encode response")
+		return
+	}
+	w.Header().Add("Vary", "Accept")
+	w.Header().Set("Content-Type", c.ContentType)
+	w.WriteHeader(status)
+	w.Write(buf.Bytes())
+}
+
+// WriteWithETag is Write with a weak ETag of the encoded response. It answers 304 Not
+// Modified when the request's If-None-Match already matches.
+func WriteWithETag(w http.ResponseWriter, r *http.Request, status int, payload any) {
+	c, ok := ResponseCodec(r)
+	if !ok {
+		c = JSONCodec
+	}
+	w.Header().Add("Vary", "Accept")
+	writeWithETag(w, r, status, payload, c)
+}
+
+// encodeMsgPack encodes payload as MessagePack with the structure its JSON has, so
+// field names, omitted fields and custom JSON encodings are the same in both
+func encodeMsgPack(w io.Writer, payload any) error {
+	v, err := toGeneric(payload)
+	if err != nil {
+		return err
+	}
+	enc := msgpack.NewEncoder(w)
+	// Sorted keys keep the encoding, and so its ETag, stable
+	enc.SetSortMapKeys(true)
+	return enc.Encode(v)
+}
+
+// toGeneric round-trips payload through JSON into maps, slices and scalars, keeping
+// integers as integers
+func toGeneric(payload any) (any, error) {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return numbers(v), nil
+}
+
+func numbers(v any) any {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case []any:
+		for i := range v {
+			v[i] = numbers(v[i])
+		}
+	case map[string]any:
+		for k := range v {
+			v[k] = numbers(v[k])
+		}
+	}
+	return v
+}
+
+// msgPackToJSON converts a MessagePack request body to the JSON it stands for
+func msgPackToJSON(body []byte) ([]byte, error) {
+	dec := msgpack.NewDecoder(bytes.NewReader(body))
+	dec.SetMapDecoder(func(d *msgpack.Decoder) (any, error) { return d.DecodeUntypedMap() })
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("invalid MessagePack: %w", err)
+	}
+	if _, err := dec.PeekCode(); err != io.EOF {
+		return nil, fmt.Errorf("invalid MessagePack: data after the top-level value")
+	}
+	v, err := stringKeys(v)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+// stringKeys checks that every map has string keys, as JSON objects do
+func stringKeys(v any) (any, error) {
+	switch v := v.(type) {
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for k, e := range v {
+			key, ok := k.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid MessagePack: map keys must be strings, got %T", k)
+			}
+			var err error
+			if m[key], err = stringKeys(e); err != nil {
+				return nil, err
+			}
+		}
+		return m, nil
+	case []any:
+		for i := range v {
+			var err error
+			if v[i], err = stringKeys(v[i]); err != nil {
+				return nil, err
+			}
+		}
+	case []byte:
+		return nil, fmt.Errorf("invalid MessagePack: binary values are not supported")
+	}
+	return v, nil
+}
@@ -0,0 +1,145 @@
+package utils
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func TestResponseCodec(t *testing.T) {
+	cases := []struct {
+		accept string
+		want   string // empty when nothing is acceptable
+	}{
+		{"", MediaTypeJSON},
+		{"*/*", MediaTypeJSON},
+		{"application/msgpack", MediaTypeMsgPack},
+		{"application/x-msgpack", MediaTypeMsgPack},
+		{"text/*", MediaTypeCSV},
+		{"text/csv;q=0.5, application/msgpack;q=0.8", MediaTypeMsgPack},
+		{"application/*;q=0.2, text/csv", MediaTypeCSV},
+		{"*/*;q=0.1, application/json;q=0", MediaTypeMsgPack},
+		{"text/html", ""},
+		{"application/json;q=0", ""},
+	}
+	for _, c := range cases {
+		r := httptest.NewRequest("GET", "/", nil)
+		if c.accept != "" {
+			r.Header.Set("Accept", c.accept)
+		}
+		codec, ok := ResponseCodec(r)
+		if ok != (c.want != "") || codec.MediaType != c.want {
+			t.Errorf("Accept %q: got %q (%v), want %q", c.accept, codec.MediaType, ok, c.want)
+		}
+	}
+}
+
+func TestRequestCodec(t *testing.T) {
+	for contentType, want := range map[string]bool{
+		"":                                true,
+		"application/json; charset=utf-8": true,
+		"application/msgpack":             true,
+		"text/csv":                        false,
+		"text/plain":                      false,
+		"not a media type;;":              false,
+	} {
+		r := httptest.NewRequest("POST", "/", nil)
+		if contentType != "" {
+			r.Header.Set("Content-Type", contentType)
+		}
+		if _, ok := RequestCodec(r); ok != want {
+			t.Errorf("Content-Type %q: got %v, want %v", contentType, ok, want)
+		}
+	}
+}
+
+func TestWrite_MsgPackMatchesJSON(t *testing.T) {
+	type point struct {
+		At    time.Time `json:"at"`
+		Value float64   `json:"value"`
+		Note  string    `json:"note,omitempty"`
+	}
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept", "application/msgpack")
+	w := httptest.NewRecorder()
+	at := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	WriteWithETag(w, r, http.StatusOK, SuccessResponse{Status: "success", Data: []point{{At: at, Value: 1.5}, {At: at, Value: 2}}})
+
+	if ct := w.Header().Get("Content-Type"); ct != MediaTypeMsgPack || w.Header().Get("Vary") != "Accept" || w.Header().Get("ETag") == "" {
+		t.Fatalf("unexpected headers %v", w.Header())
+	}
+	var got map[string]any
+	if err := msgpack.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	points := got["data"].([]any)
+	first := points[0].(map[string]any)
+	if got["status"] != "success" || first["at"] != "2024-03-01T00:00:00Z" || first["value"] != 1.5 || len(first) != 2 {
+		t.Errorf("expected the JSON structure in MessagePack, got %v", got)
+	}
+
+	// The same response encodes the same way, so its ETag is stable
+	w2 := httptest.NewRecorder()
+	WriteWithETag(w2, r, http.StatusOK, SuccessResponse{Status: "success", Data: []point{{At: at, Value: 1.5}, {At: at, Value: 2}}})
+	if !bytes.Equal(w.Body.Bytes(), w2.Body.Bytes()) {
+		t.Error("expected identical encodings of the same response")
+	}
+}
+
+func TestWrite_CSV(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept", "text/csv")
+	w := httptest.NewRecorder()
+	Write(w, r, http.StatusOK, SuccessResponse{Status: "success", Data: []map[string]any{
+		{"name": "q3", "tags": []string{"a", "b"}},
+		{"name": "q4, final", "count": 2, "nested": map[string]int{"x": 1}},
+	}})
+	// Maps encode to JSON with sorted keys, so columns appear as first seen in that order
+	want := "name,tags,count,nested\nq3,a;b,,\n\"q4, final\",,2,\"{\"\"x\"\":1}\"\n"
+	if w.Header().Get("Content-Type") != "text/csv; charset=utf-8" || w.Body.String() != want {
+		t.Errorf("unexpected CSV %q (%s)", w.Body.String(), w.Header().Get("Content-Type"))
+	}
+
+	w = httptest.NewRecorder()
+	Write(w, r, http.StatusOK, SuccessResponse{Status: "success", Data: csvRows{}})
+	if w.Body.String() != "a,b\n1,2\n" {
+		t.Errorf("expected the data's own CSV records, got %q", w.Body.String())
+	}
+}
+
+type csvRows struct{}
+
+func (csvRows) CSVRecords() ([][]string, error) { return [][]string{{"a", "b"}, {"1", "2"}}, nil }
+
+func TestBodyToJSON_MsgPack(t *testing.T) {
+	body, _ := msgpack.Marshal(map[string]any{"type": "insight", "tags": []string{"kpi"}, "pinned": true})
+	r := httptest.NewRequest("POST", "/", bytes.NewReader(body))
+	r.Header.Set("Content-Type", "application/msgpack")
+	got, err := BodyToJSON(r, body)
+	if err != nil || string(got) != `{"pinned":true,"tags":["kpi"],"type":"insight"}` {
+		t.Errorf("unexpected JSON %s (%v)", got, err)
+	}
+
+	for name, bad := range map[string][]byte{
+		"truncated":   body[:len(body)-2],
+		"trailing":    append(append([]byte{}, body...), 0x01),
+		"integer key": mustMarshal(t, map[int]string{1: "a"}),
+	} {
+		if _, err := BodyToJSON(r, bad); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func mustMarshal(t *testing.T, v any) []byte {
+	t.Helper()
+	b, err := msgpack.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
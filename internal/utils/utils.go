@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"strings"
 )
 
 // ParseQueryInt safely parses ?key= from the URL, returning a default if invalid
@@ -15,6 +16,17 @@ func ParseQueryInt(r *http.Request, key string, defaultVal int) int {
 	return defaultVal
 }
 
+// ParseQueryList parses a comma-separated ?key= value, skipping empty items
+func ParseQueryList(r *http.Request, key string) []string {
+	var out []string
+	for _, v := range strings.Split(r.URL.Query().Get(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
 // WriteJSON writes the given status and data as JSON
 func WriteJSON(w http.ResponseWriter, status int, payload any) {
 	w.Header().Set("Content-Type", "application/json")
@@ -0,0 +1,172 @@
+// Package webhooks delivers the favorite change events written to the outbox to
+// the webhooks registered for them.
+package webhooks
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gitvam/platform-go-challenge/internal/store"
+)
+
+// Headers sent with every delivery. The signature is "sha256=" followed by the hex
+// HMAC-SHA256 of "<timestamp>.<body>" keyed with the webhook's secret.
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	TimestampHeader = "X-Webhook-Timestamp"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+)
+
+// Store is what the dispatcher needs from the store
+type Store interface {
+	EnqueueWebhookDeliveries(limit int) (int64, error)
+	ClaimWebhookDeliveries(limit int, lease time.Duration) ([]store.PendingDelivery, error)
+	CompleteWebhookDelivery(deliveryID int64) error
+	RetryWebhookDelivery(deliveryID int64, lastErr string, at time.Time) error
+	DeadLetterWebhookDelivery(deliveryID int64, lastErr string) error
+}
+
+// Dispatcher moves outbox events to webhook deliveries and sends the due ones.
+// A failed delivery is retried with exponential backoff and dead-lettered after
+// MaxAttempts; receivers must tolerate duplicates, as an event may be sent again
+// when the dispatcher stops between sending and recording the outcome.
+type Dispatcher struct {
+	Store       Store
+	Client      *http.Client
+	BatchSize   int
+	MaxAttempts int
+	// Retries wait BaseBackoff, then twice as long after each failure, up to MaxBackoff
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// Lease is how long a claimed delivery stays hidden from other dispatchers on
+	// top of the time its batch may take to send, see claimLease
+	Lease time.Duration
+
+	now func() time.Time
+}
+
+// NewDispatcher returns a dispatcher with the default retry policy: 10 attempts over about eight and a half hours
+func NewDispatcher(s Store) *Dispatcher {
+	return &Dispatcher{
+		Store:       s,
+		Client:      &http.Client{Timeout: 10 * time.Second, CheckRedirect: noRedirects},
+		BatchSize:   50,
+		MaxAttempts: 10,
+		BaseBackoff: time.Minute,
+		MaxBackoff:  6 * time.Hour,
+		Lease:       time.Minute,
+		now:         time.Now,
+	}
+}
+
+// Run dispatches every interval until the process exits
+func (d *Dispatcher) Run(every time.Duration) {
+	for range time.Tick(every) {
+		if _, err := d.RunOnce(); err != nil {
+			log.Printf("[ERROR] dispatching webhooks: %v", err)
+		}
+	}
+}
+
+// RunOnce enqueues new outbox events and sends one batch of due deliveries,
+// returning how many were sent successfully
+func (d *Dispatcher) RunOnce() (int, error) {
+	if _, err := d.Store.EnqueueWebhookDeliveries(d.BatchSize); err != nil {
+		return 0, err
+	}
+	claimed, err := d.Store.ClaimWebhookDeliveries(d.BatchSize, d.claimLease())
+	if err != nil {
+		return 0, err
+	}
+	delivered := 0
+	for _, p := range claimed {
+		sendErr := d.send(p)
+		switch {
+		case sendErr == nil:
+			err = d.Store.CompleteWebhookDelivery(p.ID)
+			delivered++
+		case p.Attempts >= d.MaxAttempts:
+			log.Printf("[WARN] webhook delivery %d dead-lettered after %d attempts: %v", p.ID, p.Attempts, sendErr)
+			err = d.Store.DeadLetterWebhookDelivery(p.ID, sendErr.Error())
+		default:
+			err = d.Store.RetryWebhookDelivery(p.ID, sendErr.Error(), d.now().Add(Backoff(p.Attempts, d.BaseBackoff, d.MaxBackoff)))
+		}
+		if err != nil {
+			return delivered, err
+		}
+	}
+	return delivered, nil
+}
+
+// claimLease keeps a claimed batch hidden until every delivery in it could have
+// timed out one after another, so a slow batch is not claimed and sent again by
+// another dispatcher while this one is still working through it
+func (d *Dispatcher) claimLease() time.Duration {
+	return d.Lease + time.Duration(d.BatchSize)*d.Client.Timeout
+}
+
+// noRedirects makes a redirect the response of a delivery, failing it, rather
+// than sending the signed event on to wherever the receiver points
+func noRedirects(*http.Request, []*http.Request) error {
+	return http.ErrUseLastResponse
+}
+
+// send POSTs the signed event; any response other than 2xx is a failure
+func (d *Dispatcher) send(p store.PendingDelivery) error {
+	body, err := json.Marshal(p.Event)
+	if err != nil {
+		return err
+	}
+	timestamp := d.now().Unix()
+	req, err := http.NewRequest(http.MethodPost, p.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(p.Secret, timestamp, body))
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(EventHeader, p.Event.Type)
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(p.ID, 10))
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("receiver responded %s", resp.Status)
+	}
+	return nil
+}
+
+// Sign returns the signature header value of a delivery body sent at timestamp
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Backoff is the wait before retrying after the given attempt: base doubled for
+// every attempt after the first, capped at max
+func Backoff(attempt int, base, max time.Duration) time.Duration {
+	wait := base
+	for i := 1; i < attempt; i++ {
+		wait *= 2
+		if wait >= max {
+			return max
+		}
+	}
+	return min(wait, max)
+}
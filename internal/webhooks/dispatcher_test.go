@@ -0,0 +1,228 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gitvam/platform-go-challenge/internal/models"
+	"github.com/gitvam/platform-go-challenge/internal/store"
+)
+
+type memDelivery struct {
+	store.PendingDelivery
+	status    string
+	nextAt    time.Time
+	lastError string
+}
+
+// memStore keeps deliveries in memory; enqueueing is a no-op as deliveries are added directly
+type memStore struct {
+	mu         sync.Mutex
+	now        time.Time
+	deliveries []*memDelivery
+}
+
+func (m *memStore) add(url, secret string, event models.FavoriteEvent) *memDelivery {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	d := &memDelivery{PendingDelivery: store.PendingDelivery{ID: int64(len(m.deliveries) + 1), URL: url, Secret: secret, Event: event},
+		status: models.DeliveryPending, nextAt: m.now}
+	m.deliveries = append(m.deliveries, d)
+	return d
+}
+
+func (m *memStore) EnqueueWebhookDeliveries(limit int) (int64, error) {
+	return 0, nil
+}
+
+func (m *memStore) ClaimWebhookDeliveries(limit int, lease time.Duration) ([]store.PendingDelivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var claimed []store.PendingDelivery
+	for _, d := range m.deliveries {
+		if len(claimed) < limit && d.status == models.DeliveryPending && !d.nextAt.After(m.now) {
+			d.Attempts++
+			d.nextAt = m.now.Add(lease)
+			claimed = append(claimed, d.PendingDelivery)
+		}
+	}
+	return claimed, nil
+}
+
+func (m *memStore) CompleteWebhookDelivery(id int64) error {
+	return m.update(id, func(d *memDelivery) { d.status, d.lastError = models.DeliveryDelivered, "" })
+}
+
+func (m *memStore) RetryWebhookDelivery(id int64, lastErr string, at time.Time) error {
+	return m.update(id, func(d *memDelivery) { d.nextAt, d.lastError = at, lastErr })
+}
+
+func (m *memStore) DeadLetterWebhookDelivery(id int64, lastErr string) error {
+	return m.update(id, func(d *memDelivery) { d.status, d.lastError = models.DeliveryDead, lastErr })
+}
+
+func (m *memStore) update(id int64, change func(*memDelivery)) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	change(m.deliveries[id-1])
+	return nil
+}
+
+func newTestDispatcher(s *memStore) *Dispatcher {
+	d := NewDispatcher(s)
+	d.now = func() time.Time { return s.now }
+	return d
+}
+
+var testEvent = models.FavoriteEvent{
+	ID:     42,
+	Type:   models.EventFavoriteAdded,
+	UserID: "11111111-1111-1111-1111-111111111111",
+	Data:   models.FavoriteEventData{AssetType: "chart", ExternalID: "chart_1", Description: "mine", Tags: []string{}},
+}
+
+func TestDispatcher_DeliversSignedEvents(t *testing.T) {
+	var got *http.Request
+	var body []byte
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	s := &memStore{now: time.Unix(1700000000, 0)}
+	d := s.add(receiver.URL, "s3cret", testEvent)
+	if n, err := newTestDispatcher(s).RunOnce(); err != nil || n != 1 {
+		t.Fatalf("expected 1 delivery, got %d err=%v", n, err)
+	}
+	if d.status != models.DeliveryDelivered {
+		t.Errorf("expected the delivery to be marked delivered, got %s", d.status)
+	}
+
+	timestamp, _ := strconv.ParseInt(got.Header.Get(TimestampHeader), 10, 64)
+	if timestamp != s.now.Unix() || got.Header.Get(SignatureHeader) != Sign("s3cret", timestamp, body) {
+		t.Errorf("expected a valid signature, got %q at %d", got.Header.Get(SignatureHeader), timestamp)
+	}
+	if got.Header.Get(EventHeader) != models.EventFavoriteAdded || got.Header.Get(DeliveryHeader) != "1" {
+		t.Errorf("unexpected event headers: %v", got.Header)
+	}
+	var event models.FavoriteEvent
+	if err := json.Unmarshal(body, &event); err != nil || event.ID != 42 || event.Data.ExternalID != "chart_1" {
+		t.Errorf("expected the event as the body, got %s", body)
+	}
+}
+
+func TestDispatcher_RetriesWithBackoffThenDeadLetters(t *testing.T) {
+	calls := 0
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer receiver.Close()
+
+	s := &memStore{now: time.Unix(1700000000, 0)}
+	d := s.add(receiver.URL, "s3cret", testEvent)
+	dispatcher := newTestDispatcher(s)
+	dispatcher.MaxAttempts = 3
+
+	dispatcher.RunOnce()
+	if d.status != models.DeliveryPending || !d.nextAt.Equal(s.now.Add(time.Minute)) || d.lastError == "" {
+		t.Fatalf("expected a retry in a minute, got %s at %v (%q)", d.status, d.nextAt, d.lastError)
+	}
+	// Not due yet
+	dispatcher.RunOnce()
+	if calls != 1 {
+		t.Errorf("expected no attempt before the backoff passed, got %d calls", calls)
+	}
+
+	s.now = s.now.Add(time.Minute)
+	dispatcher.RunOnce()
+	if !d.nextAt.Equal(s.now.Add(2 * time.Minute)) {
+		t.Errorf("expected the backoff to double, next attempt at %v", d.nextAt)
+	}
+	s.now = s.now.Add(2 * time.Minute)
+	dispatcher.RunOnce()
+	if calls != 3 || d.status != models.DeliveryDead {
+		t.Errorf("expected dead-lettering after 3 attempts, got %d calls and %s", calls, d.status)
+	}
+}
+
+func TestDispatcher_UnreachableReceiverIsRetried(t *testing.T) {
+	receiver := httptest.NewServer(http.NotFoundHandler())
+	url := receiver.URL
+	receiver.Close()
+
+	s := &memStore{now: time.Unix(1700000000, 0)}
+	d := s.add(url, "s3cret", testEvent)
+	if _, err := newTestDispatcher(s).RunOnce(); err != nil {
+		t.Fatalf("a failed delivery must not fail the run: %v", err)
+	}
+	if d.status != models.DeliveryPending || d.lastError == "" {
+		t.Errorf("expected a retry with the error recorded, got %s (%q)", d.status, d.lastError)
+	}
+}
+
+func TestDispatcher_LeaseCoversTheWholeBatch(t *testing.T) {
+	s := &memStore{now: time.Unix(1700000000, 0)}
+	d := s.add("http://127.0.0.1:1", "s3cret", testEvent)
+	dispatcher := newTestDispatcher(s)
+	dispatcher.Store = leaseOnly{s}
+
+	dispatcher.RunOnce()
+	// 50 deliveries that each take the 10s timeout, plus the one minute lease
+	if want := s.now.Add(time.Minute + 500*time.Second); !d.nextAt.Equal(want) {
+		t.Errorf("expected the claim to be hidden until %v, got %v", want, d.nextAt)
+	}
+}
+
+// leaseOnly claims deliveries but drops their outcomes, leaving the claim lease in place
+type leaseOnly struct{ *memStore }
+
+func (leaseOnly) RetryWebhookDelivery(int64, string, time.Time) error { return nil }
+
+func TestDispatcher_DoesNotFollowRedirects(t *testing.T) {
+	redirected := false
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		redirected = true
+	}))
+	defer target.Close()
+	receiver := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusTemporaryRedirect))
+	defer receiver.Close()
+
+	s := &memStore{now: time.Unix(1700000000, 0)}
+	d := s.add(receiver.URL, "s3cret", testEvent)
+	if n, err := newTestDispatcher(s).RunOnce(); err != nil || n != 0 {
+		t.Fatalf("expected no delivery, got %d err=%v", n, err)
+	}
+	if redirected {
+		t.Error("expected the signed event not to be sent to the redirect target")
+	}
+	if d.status != models.DeliveryPending || d.lastError == "" {
+		t.Errorf("expected the redirect to be retried as a failure, got %s (%q)", d.status, d.lastError)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{4, 8 * time.Minute},
+		{9, time.Hour},
+		{100, time.Hour},
+	}
+	for _, tt := range tests {
+		if got := Backoff(tt.attempt, time.Minute, time.Hour); got != tt.want {
+			t.Errorf("Backoff(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+-- Adds the stored responses replayed for requests sent with an
+-- Idempotency-Key header. Run once against databases created before
+-- idempotency keys existed.
+BEGIN;
+
+-- A row with status_code 0 marks a request that is still being processed.
+CREATE TABLE idempotency_keys (
+    user_id UUID NOT NULL,
+    key TEXT NOT NULL,
+    request_hash TEXT NOT NULL,
+    status_code INT NOT NULL DEFAULT 0,
+    content_type TEXT NOT NULL DEFAULT '',
+    response_body BYTEA,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (user_id, key)
+);
+
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
+
+COMMIT;
@@ -0,0 +1,10 @@
+-- Adds the version favorites are served with as their ETag. Existing
+-- favorites start at version 1. Run once against databases created before
+-- conditional requests existed.
+BEGIN;
+
+ALTER TABLE favorites
+    ADD COLUMN version INT NOT NULL DEFAULT 1,
+    ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+COMMIT;
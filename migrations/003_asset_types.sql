@@ -0,0 +1,20 @@
+-- Replaces the fixed list of asset types favorites could reference with the
+-- asset_types table, which the store fills from the registered types on
+-- startup. Run once against databases created before the type registry
+-- existed.
+BEGIN;
+
+CREATE TABLE asset_types (
+    name TEXT PRIMARY KEY,
+    catalog_table TEXT NOT NULL
+);
+
+INSERT INTO asset_types (name, catalog_table) VALUES
+  ('chart', 'charts'),
+  ('insight', 'insights'),
+  ('audience', 'audiences');
+
+ALTER TABLE favorites DROP CONSTRAINT favorites_asset_type_check;
+ALTER TABLE favorites ADD FOREIGN KEY (asset_type) REFERENCES asset_types(name);
+
+COMMIT;
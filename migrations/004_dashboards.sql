@@ -0,0 +1,27 @@
+-- Adds dashboards, the first composite asset type, and the ordered members of
+-- composite assets. Run once against databases created before dashboards
+-- existed.
+BEGIN;
+
+CREATE TABLE dashboards (
+    id SERIAL PRIMARY KEY,
+    external_id TEXT UNIQUE NOT NULL,
+    title TEXT NOT NULL,
+    layout TEXT NOT NULL DEFAULT 'grid',
+    description TEXT
+);
+
+CREATE INDEX idx_dashboards_external_id ON dashboards(external_id);
+
+INSERT INTO asset_types (name, catalog_table) VALUES ('dashboard', 'dashboards');
+
+CREATE TABLE asset_members (
+    parent_type TEXT NOT NULL REFERENCES asset_types(name),
+    parent_id INT NOT NULL,
+    position INT NOT NULL,
+    member_type TEXT NOT NULL REFERENCES asset_types(name),
+    member_id INT NOT NULL,
+    PRIMARY KEY (parent_type, parent_id, position)
+);
+
+COMMIT;
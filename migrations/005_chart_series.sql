@@ -0,0 +1,19 @@
+-- Converts charts from a single unlabeled INTEGER[] data column to typed
+-- axes and named float series. Run once against databases created from an
+-- init.sql older than the series schema; fresh databases need nothing.
+-- Each chart's data becomes one series named after its y-axis title.
+BEGIN;
+
+ALTER TABLE charts
+    ADD COLUMN kind TEXT NOT NULL DEFAULT 'line' CHECK (kind IN ('line', 'bar', 'pie')),
+    ADD COLUMN x_axis JSONB NOT NULL DEFAULT '{"kind": "category"}',
+    ADD COLUMN series JSONB NOT NULL DEFAULT '[]';
+
+UPDATE charts SET series = jsonb_build_array(jsonb_build_object(
+    'name', COALESCE(NULLIF(y_axis_title, ''), 'Series 1'),
+    'values', to_jsonb(data)
+));
+
+ALTER TABLE charts DROP COLUMN data;
+
+COMMIT;
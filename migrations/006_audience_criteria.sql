@@ -0,0 +1,8 @@
+-- Adds composable filter criteria to audiences. Existing audiences keep their
+-- flat columns, which the API reads as an AND of equalities, so no backfill is
+-- needed. Run once against databases created before criteria existed.
+BEGIN;
+
+ALTER TABLE audiences ADD COLUMN criteria JSONB;
+
+COMMIT;
@@ -0,0 +1,27 @@
+-- Adds user-defined collections of favorites. Run once against databases
+-- created before collections existed.
+BEGIN;
+
+CREATE TABLE collections (
+    id SERIAL PRIMARY KEY,
+    user_id UUID NOT NULL,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    position INT NOT NULL,
+    version INT NOT NULL DEFAULT 1,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE collection_favorites (
+    collection_id INT NOT NULL REFERENCES collections(id) ON DELETE CASCADE,
+    favorite_id INT NOT NULL REFERENCES favorites(id) ON DELETE CASCADE,
+    added_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (collection_id, favorite_id)
+);
+
+CREATE UNIQUE INDEX idx_collections_user_name ON collections(user_id, lower(name));
+CREATE INDEX idx_collections_user_position ON collections(user_id, position);
+CREATE INDEX idx_collection_favorites_favorite_id ON collection_favorites(favorite_id);
+
+COMMIT;
@@ -0,0 +1,8 @@
+-- Adds free-form tags to favorites. Run once against databases created
+-- before tags existed.
+BEGIN;
+
+ALTER TABLE favorites ADD COLUMN tags TEXT[] NOT NULL DEFAULT '{}';
+CREATE INDEX idx_favorites_tags ON favorites USING GIN (tags);
+
+COMMIT;
@@ -0,0 +1,14 @@
+-- Adds pinning and manual ordering to favorites. Existing favorites keep the
+-- order they were added in. Run once against databases created before
+-- manual ordering existed.
+BEGIN;
+
+ALTER TABLE favorites ADD COLUMN pinned BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE favorites ADD COLUMN position DOUBLE PRECISION;
+UPDATE favorites f SET position = o.n
+FROM (SELECT id, row_number() OVER (PARTITION BY user_id ORDER BY id) AS n FROM favorites) o
+WHERE f.id = o.id;
+ALTER TABLE favorites ALTER COLUMN position SET NOT NULL;
+CREATE INDEX idx_favorites_user_manual_order ON favorites(user_id, pinned DESC, position);
+
+COMMIT;
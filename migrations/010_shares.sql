@@ -0,0 +1,22 @@
+-- Adds sharing of favorites and collections with other users and through
+-- share links. Run once against databases created before sharing existed.
+BEGIN;
+
+CREATE TABLE shares (
+    id SERIAL PRIMARY KEY,
+    owner_id UUID NOT NULL,
+    favorite_id INT REFERENCES favorites(id) ON DELETE CASCADE,
+    collection_id INT REFERENCES collections(id) ON DELETE CASCADE,
+    grantee_id UUID,
+    token_hash TEXT UNIQUE,
+    permission TEXT NOT NULL CHECK (permission IN ('view', 'edit')),
+    expires_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK ((favorite_id IS NULL) <> (collection_id IS NULL)),
+    CHECK ((grantee_id IS NULL) <> (token_hash IS NULL))
+);
+
+CREATE UNIQUE INDEX idx_shares_item_grantee ON shares((COALESCE(favorite_id, 0)), (COALESCE(collection_id, 0)), grantee_id);
+CREATE INDEX idx_shares_grantee_id ON shares(grantee_id);
+
+COMMIT;
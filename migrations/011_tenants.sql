@@ -0,0 +1,45 @@
+-- Scopes favorites, collections, shares and idempotency keys to a tenant and
+-- lets tenants own catalog assets. Existing user data moves to the "default"
+-- tenant (used for tokens without an org claim) and existing catalog assets
+-- become the global catalog. Run once against databases created before
+-- tenants existed.
+BEGIN;
+
+ALTER TABLE charts ADD COLUMN tenant_id TEXT;
+ALTER TABLE insights ADD COLUMN tenant_id TEXT;
+ALTER TABLE audiences ADD COLUMN tenant_id TEXT;
+ALTER TABLE dashboards ADD COLUMN tenant_id TEXT;
+ALTER TABLE charts DROP CONSTRAINT charts_external_id_key;
+ALTER TABLE insights DROP CONSTRAINT insights_external_id_key;
+ALTER TABLE audiences DROP CONSTRAINT audiences_external_id_key;
+ALTER TABLE dashboards DROP CONSTRAINT dashboards_external_id_key;
+CREATE UNIQUE INDEX idx_charts_tenant_external_id ON charts((COALESCE(tenant_id, '')), external_id);
+CREATE UNIQUE INDEX idx_insights_tenant_external_id ON insights((COALESCE(tenant_id, '')), external_id);
+CREATE UNIQUE INDEX idx_audiences_tenant_external_id ON audiences((COALESCE(tenant_id, '')), external_id);
+CREATE UNIQUE INDEX idx_dashboards_tenant_external_id ON dashboards((COALESCE(tenant_id, '')), external_id);
+
+ALTER TABLE favorites ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE favorites ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE favorites DROP CONSTRAINT favorites_user_id_asset_type_asset_id_key;
+ALTER TABLE favorites ADD UNIQUE (tenant_id, user_id, asset_type, asset_id);
+DROP INDEX idx_favorites_user_manual_order;
+CREATE INDEX idx_favorites_user_manual_order ON favorites(tenant_id, user_id, pinned DESC, position);
+
+ALTER TABLE collections ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE collections ALTER COLUMN tenant_id DROP DEFAULT;
+DROP INDEX idx_collections_user_name;
+DROP INDEX idx_collections_user_position;
+CREATE UNIQUE INDEX idx_collections_user_name ON collections(tenant_id, user_id, lower(name));
+CREATE INDEX idx_collections_user_position ON collections(tenant_id, user_id, position);
+
+ALTER TABLE shares ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE shares ALTER COLUMN tenant_id DROP DEFAULT;
+DROP INDEX idx_shares_grantee_id;
+CREATE INDEX idx_shares_grantee_id ON shares(tenant_id, grantee_id);
+
+ALTER TABLE idempotency_keys ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE idempotency_keys ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE idempotency_keys DROP CONSTRAINT idempotency_keys_pkey;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (tenant_id, user_id, key);
+
+COMMIT;
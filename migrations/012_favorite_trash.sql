@@ -0,0 +1,9 @@
+-- Turns removing a favorite into a soft delete: removed favorites keep their
+-- row, with deleted_at set, until restored or purged after the retention
+-- period. Run once against databases created before the trash existed.
+BEGIN;
+
+ALTER TABLE favorites ADD COLUMN deleted_at TIMESTAMPTZ;
+CREATE INDEX idx_favorites_deleted_at ON favorites(deleted_at) WHERE deleted_at IS NOT NULL;
+
+COMMIT;
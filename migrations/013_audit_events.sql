@@ -0,0 +1,36 @@
+-- Adds the append-only audit trail of changes to favorites, collections and
+-- shares. Changes made before it existed are not backfilled. Run once against
+-- databases created before the audit trail existed.
+BEGIN;
+
+-- Append-only record of every change to favorites, collections and shares,
+-- written in the same transaction as the change. actor_id is NULL for
+-- maintenance such as purging the trash.
+CREATE TABLE audit_events (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id TEXT NOT NULL,
+    user_id UUID NOT NULL,
+    actor_id UUID,
+    action TEXT NOT NULL,
+    entity_type TEXT NOT NULL CHECK (entity_type IN ('favorite', 'collection', 'share')),
+    entity_id TEXT NOT NULL,
+    before JSONB,
+    after JSONB,
+    request_id TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE FUNCTION reject_audit_change() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_events_append_only
+    BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW EXECUTE FUNCTION reject_audit_change();
+
+CREATE INDEX idx_audit_events_user ON audit_events(tenant_id, user_id, created_at DESC);
+CREATE INDEX idx_audit_events_created_at ON audit_events(tenant_id, created_at DESC);
+
+COMMIT;
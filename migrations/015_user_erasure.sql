@@ -0,0 +1,18 @@
+-- Lets erasing a user pseudonymize their audit events, which are otherwise
+-- append-only. Run once against databases created before user erasure existed.
+BEGIN;
+
+-- Erasing a user pseudonymizes their events in its own transaction, after SET
+-- LOCAL audit.pseudonymize = 'on'; what happened, to what and when never changes.
+CREATE OR REPLACE FUNCTION reject_audit_change() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' AND current_setting('audit.pseudonymize', true) = 'on'
+       AND (NEW.id, NEW.tenant_id, NEW.action, NEW.entity_type, NEW.entity_id, NEW.created_at)
+           IS NOT DISTINCT FROM (OLD.id, OLD.tenant_id, OLD.action, OLD.entity_type, OLD.entity_id, OLD.created_at) THEN
+        RETURN NEW;
+    END IF;
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+COMMIT;
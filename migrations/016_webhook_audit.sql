@@ -0,0 +1,10 @@
+-- Lets the audit trail record the creation and deletion of webhooks, under the
+-- admin who made the change. Run once against databases created before
+-- webhook changes were audited.
+BEGIN;
+
+ALTER TABLE audit_events DROP CONSTRAINT audit_events_entity_type_check;
+ALTER TABLE audit_events ADD CONSTRAINT audit_events_entity_type_check
+    CHECK (entity_type IN ('favorite', 'collection', 'share', 'webhook'));
+
+COMMIT;
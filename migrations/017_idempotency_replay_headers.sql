@@ -0,0 +1,10 @@
+-- Stores the ETag and Location sent with idempotent responses so replays carry
+-- them, and lets keys of requests that crashed be reused once their lease ends.
+-- Run once against databases created before idempotency keys had leases.
+BEGIN;
+
+ALTER TABLE idempotency_keys
+    ADD COLUMN response_headers JSONB NOT NULL DEFAULT '{}',
+    ADD COLUMN lease_expires_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+COMMIT;
@@ -0,0 +1,11 @@
+-- Records the transaction that wrote each outbox event, so the event log is
+-- read in commit order and an event committed late is not skipped. Existing
+-- events all get this migration's transaction and keep their ID order. Run once
+-- against databases created before the event log was ordered by transaction.
+BEGIN;
+
+ALTER TABLE outbox_events ADD COLUMN xact_id XID8 NOT NULL DEFAULT pg_current_xact_id();
+
+CREATE INDEX idx_outbox_events_user_log ON outbox_events(tenant_id, user_id, xact_id, id);
+
+COMMIT;
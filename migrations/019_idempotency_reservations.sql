@@ -0,0 +1,8 @@
+-- Identifies the request holding each idempotency key, so a request whose lease
+-- was taken over cannot store its response over the new holder's. Run once
+-- against databases created before idempotency keys had reservations.
+BEGIN;
+
+ALTER TABLE idempotency_keys ADD COLUMN reservation UUID NOT NULL DEFAULT gen_random_uuid();
+
+COMMIT;